@@ -12,6 +12,7 @@ const (
 	ProviderOpenAI = "openai"
 	ProviderGemini = "gemini"
 	ProviderOllama = "ollama"
+	ProviderCohere = "cohere"
 )
 
 // StreamChunk is a single token or error delivered during streaming.
@@ -3,11 +3,13 @@ package adapter
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNew_ValidProviders(t *testing.T) {
@@ -214,3 +216,438 @@ func TestGeminiComplete_APIError(t *testing.T) {
 		t.Errorf("error should mention status code 403: %v", err)
 	}
 }
+
+func TestGeminiEmbedder_Batches(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("x-goog-api-key") != "test-key" {
+			t.Errorf("missing API key header")
+		}
+		if !strings.HasSuffix(r.URL.Path, ":batchEmbedContents") {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		var req geminiBatchEmbedRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Requests) > geminiEmbedBatchSize {
+			t.Errorf("batch too large: %d", len(req.Requests))
+		}
+		var resp geminiBatchEmbedResponse
+		for range req.Requests {
+			resp.Embeddings = append(resp.Embeddings, struct {
+				Values []float32 `json:"values"`
+			}{Values: make([]float32, GeminiEmbedDimension)})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	e := newGeminiEmbedder("test-key", server.Client())
+	e.baseURL = server.URL
+
+	texts := make([]string, geminiEmbedBatchSize+5)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("text %d", i)
+	}
+	vecs, err := e.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("Embed error: %v", err)
+	}
+	if len(vecs) != len(texts) {
+		t.Errorf("got %d vectors, want %d", len(vecs), len(texts))
+	}
+	if len(vecs[0]) != GeminiEmbedDimension {
+		t.Errorf("dimension: got %d, want %d", len(vecs[0]), GeminiEmbedDimension)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 batch requests, got %d", calls)
+	}
+}
+
+func TestGeminiEmbedder_RetriesTransientErrors(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"embeddings":[{"values":[0.1,0.2]}]}`)
+	}))
+	defer server.Close()
+
+	e := newGeminiEmbedder("test-key", server.Client())
+	e.baseURL = server.URL
+	e.backoff = 0
+
+	vecs, err := e.Embed(context.Background(), []string{"hello"})
+	if err != nil {
+		t.Fatalf("Embed error: %v", err)
+	}
+	if len(vecs) != 1 || calls != 2 {
+		t.Errorf("expected success after one retry, got %d vectors in %d calls", len(vecs), calls)
+	}
+}
+
+func TestGeminiEmbedder_NoRetryOnClientError(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	e := newGeminiEmbedder("test-key", server.Client())
+	e.baseURL = server.URL
+	e.backoff = 0
+
+	if _, err := e.Embed(context.Background(), []string{"hello"}); err == nil {
+		t.Fatal("expected error for 400 response")
+	}
+	if calls != 1 {
+		t.Errorf("400 should not be retried, got %d calls", calls)
+	}
+}
+
+func TestGeminiEmbedder_ContextCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	e := newGeminiEmbedder("test-key", server.Client())
+	e.baseURL = server.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := e.Embed(ctx, []string{"hello"}); err == nil {
+		t.Fatal("expected error for cancelled context")
+	}
+}
+
+func TestNewEmbedder_Registry(t *testing.T) {
+	for _, name := range []string{ProviderOpenAI, ProviderOllama, ProviderGemini} {
+		emb, err := NewEmbedder(name, EmbedderOptions{APIKey: "test-key"})
+		if err != nil {
+			t.Errorf("NewEmbedder(%q) error: %v", name, err)
+		}
+		if emb == nil {
+			t.Errorf("NewEmbedder(%q) returned nil", name)
+		}
+	}
+}
+
+func TestNewEmbedder_UnknownProvider(t *testing.T) {
+	_, err := NewEmbedder("nope", EmbedderOptions{})
+	if err == nil {
+		t.Fatal("expected error for unknown provider")
+	}
+	if !strings.Contains(err.Error(), "nope") || !strings.Contains(err.Error(), ProviderOllama) {
+		t.Errorf("error should name the provider and list valid ones: %v", err)
+	}
+}
+
+func TestRegisterEmbedder(t *testing.T) {
+	called := false
+	RegisterEmbedder("fake", func(opts EmbedderOptions) (Embedder, error) {
+		called = true
+		if opts.Model != "m1" {
+			t.Errorf("model: got %q, want m1", opts.Model)
+		}
+		return NewOllama("http://localhost", opts.Model), nil
+	})
+	defer func() {
+		embeddersMu.Lock()
+		delete(embedders, "fake")
+		embeddersMu.Unlock()
+	}()
+
+	if _, err := NewEmbedder("fake", EmbedderOptions{Model: "m1"}); err != nil {
+		t.Fatalf("NewEmbedder(fake) error: %v", err)
+	}
+	if !called {
+		t.Error("registered factory was not called")
+	}
+}
+
+// flakyEmbedder fails the first failN calls, then succeeds.
+type flakyEmbedder struct {
+	calls int
+	failN int
+}
+
+func (f *flakyEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	f.calls++
+	if f.calls <= f.failN {
+		return nil, errors.New("transient")
+	}
+	return make([][]float32, len(texts)), nil
+}
+
+func TestRetryingEmbedder_RetriesThenSucceeds(t *testing.T) {
+	inner := &flakyEmbedder{failN: 2}
+	r := NewRetryingEmbedder(inner, RetryOptions{MaxAttempts: 3, Backoff: time.Millisecond})
+
+	if _, err := r.Embed(context.Background(), []string{"a"}); err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if inner.calls != 3 {
+		t.Errorf("expected 3 calls, got %d", inner.calls)
+	}
+}
+
+func TestRetryingEmbedder_CircuitBreaker(t *testing.T) {
+	inner := &flakyEmbedder{failN: 1 << 30}
+	r := NewRetryingEmbedder(inner, RetryOptions{
+		MaxAttempts:      1,
+		Backoff:          time.Millisecond,
+		FailureThreshold: 2,
+		Cooldown:         time.Minute,
+	})
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, err := r.Embed(context.Background(), []string{"a"}); err == nil {
+			t.Fatal("expected failure")
+		}
+	}
+
+	// Breaker is open: the inner embedder is not called.
+	calls := inner.calls
+	if _, err := r.Embed(context.Background(), []string{"a"}); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if inner.calls != calls {
+		t.Error("open breaker should not call the inner embedder")
+	}
+
+	// After the cooldown a trial call goes through and closes the breaker on success.
+	inner.failN = 0
+	now = now.Add(2 * time.Minute)
+	if _, err := r.Embed(context.Background(), []string{"a"}); err != nil {
+		t.Fatalf("trial call: %v", err)
+	}
+	if _, err := r.Embed(context.Background(), []string{"a"}); err != nil {
+		t.Fatalf("breaker should be closed: %v", err)
+	}
+}
+
+func TestRetryingEmbedder_ContextCancelled(t *testing.T) {
+	inner := &flakyEmbedder{failN: 1 << 30}
+	r := NewRetryingEmbedder(inner, RetryOptions{MaxAttempts: 5, Backoff: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := r.Embed(ctx, []string{"a"}); err == nil {
+		t.Fatal("expected error for cancelled context")
+	}
+	if inner.calls != 1 {
+		t.Errorf("cancelled context should stop retries, got %d calls", inner.calls)
+	}
+}
+
+func TestCohereEmbedder_Batches(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			t.Errorf("missing API key header")
+		}
+		if r.URL.Path != "/v2/embed" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		var req cohereEmbedRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Texts) > cohereEmbedBatchSize {
+			t.Errorf("batch too large: %d", len(req.Texts))
+		}
+		if req.InputType != "search_document" {
+			t.Errorf("input_type: got %q", req.InputType)
+		}
+		var resp cohereEmbedResponse
+		for range req.Texts {
+			resp.Embeddings.Float = append(resp.Embeddings.Float, make([]float32, CohereEmbedDimension))
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	e := newCohereEmbedder("test-key", server.Client())
+	e.baseURL = server.URL
+
+	texts := make([]string, cohereEmbedBatchSize+5)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("text %d", i)
+	}
+	vecs, err := e.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("Embed error: %v", err)
+	}
+	if len(vecs) != len(texts) {
+		t.Errorf("got %d vectors, want %d", len(vecs), len(texts))
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 batch requests, got %d", calls)
+	}
+}
+
+func TestCohereEmbedder_RetriesTransientErrors(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `{"embeddings":{"float":[[0.1,0.2]]}}`)
+	}))
+	defer server.Close()
+
+	e := newCohereEmbedder("test-key", server.Client())
+	e.baseURL = server.URL
+	e.backoff = 0
+
+	vecs, err := e.Embed(context.Background(), []string{"hello"})
+	if err != nil {
+		t.Fatalf("Embed error: %v", err)
+	}
+	if len(vecs) != 1 || calls != 2 {
+		t.Errorf("expected success after one retry, got %d vectors in %d calls", len(vecs), calls)
+	}
+}
+
+func TestCohereReranker_Rerank(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/v2/rerank" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		var req cohereRerankRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Query != "auth" || len(req.Documents) != 3 || req.TopN != 2 {
+			t.Errorf("unexpected request: %+v", req)
+		}
+		if req.Model != CohereRerankModel {
+			t.Errorf("model: got %q", req.Model)
+		}
+		fmt.Fprint(w, `{"results":[{"index":2,"relevance_score":0.9},{"index":0,"relevance_score":0.4}]}`)
+	}))
+	defer server.Close()
+
+	r := newCohereReranker("test-key", "", server.Client())
+	r.baseURL = server.URL
+
+	results, err := r.Rerank(context.Background(), "auth", []string{"a", "b", "c"}, 2)
+	if err != nil {
+		t.Fatalf("Rerank error: %v", err)
+	}
+	if len(results) != 2 || results[0].Index != 2 || results[0].Score != 0.9 || results[1].Index != 0 {
+		t.Errorf("unexpected results: %+v", results)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 request, got %d", calls)
+	}
+}
+
+func TestCohereReranker_NoRetryOnClientError(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	r := newCohereReranker("test-key", "", server.Client())
+	r.baseURL = server.URL
+	r.backoff = 0
+
+	_, err := r.Rerank(context.Background(), "q", []string{"a"}, 0)
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected 401 error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected no retries on client error, got %d calls", calls)
+	}
+}
+
+func TestAdaptiveBatcher_ShrinksOnRateLimit(t *testing.T) {
+	// The server rate-limits any request with more than 30 texts.
+	var sizes []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req geminiBatchEmbedRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		sizes = append(sizes, len(req.Requests))
+		if len(req.Requests) > 30 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		var resp geminiBatchEmbedResponse
+		for range req.Requests {
+			resp.Embeddings = append(resp.Embeddings, struct {
+				Values []float32 `json:"values"`
+			}{Values: []float32{1}})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	e := newGeminiEmbedder("test-key", server.Client())
+	e.baseURL = server.URL
+	e.backoff = 0
+
+	texts := make([]string, 120)
+	vecs, err := e.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("Embed error: %v", err)
+	}
+	if len(vecs) != len(texts) {
+		t.Fatalf("got %d vectors, want %d", len(vecs), len(texts))
+	}
+	// Halve on each 429, grow by a tenth of the maximum after each success.
+	want := []int{100, 50, 25, 35, 17, 27, 37, 18, 28, 5}
+	if fmt.Sprint(sizes) != fmt.Sprint(want) {
+		t.Errorf("request sizes: got %v, want %v", sizes, want)
+	}
+}
+
+func TestAdaptiveBatcher_GivesUpOnPersistentRateLimit(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	e := newCohereEmbedder("test-key", server.Client())
+	e.baseURL = server.URL
+	e.backoff = 0
+
+	_, err := e.Embed(context.Background(), []string{"a", "b"})
+	if !errors.Is(err, ErrRateLimited) || !strings.Contains(err.Error(), "429") {
+		t.Errorf("expected a rate-limit error mentioning 429, got %v", err)
+	}
+	if calls != cohereMaxRetries {
+		t.Errorf("expected %d attempts, got %d", cohereMaxRetries, calls)
+	}
+}
+
+func TestTokenBucket_Paces(t *testing.T) {
+	now := time.Unix(0, 0)
+	tb := newTokenBucket(60, func() time.Time { return now }) // one per second
+
+	if d := tb.reserve(); d != 0 {
+		t.Errorf("first request should not wait, got %v", d)
+	}
+	if d := tb.reserve(); d != time.Second {
+		t.Errorf("second request should wait 1s, got %v", d)
+	}
+	if d := tb.reserve(); d != 2*time.Second {
+		t.Errorf("third queued request should wait 2s, got %v", d)
+	}
+	now = now.Add(10 * time.Second)
+	if d := tb.reserve(); d != 0 {
+		t.Errorf("after refilling, request should not wait, got %v", d)
+	}
+}
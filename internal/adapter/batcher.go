@@ -0,0 +1,168 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited matches (via errors.Is) provider errors caused by the
+// provider asking the client to slow down, such as HTTP 429.
+var ErrRateLimited = errors.New("adapter: rate limited")
+
+// rateLimitedError marks err as a rate-limit response without changing its
+// message.
+type rateLimitedError struct{ err error }
+
+func (e rateLimitedError) Error() string   { return e.err.Error() }
+func (e rateLimitedError) Unwrap() []error { return []error{e.err, ErrRateLimited} }
+
+// batchFunc embeds one provider request. The returned bool reports whether a
+// failure is transient and worth retrying.
+type batchFunc func(ctx context.Context, texts []string) ([][]float32, bool, error)
+
+// adaptiveBatcher splits Embed calls into provider requests whose size
+// adapts to rate limiting, and optionally paces them with a token bucket.
+// A rate-limited request halves the batch size; each success grows it back
+// by a tenth of the maximum. One batcher is shared by all calls on an
+// embedder, so concurrent indexing workers learn the same limits.
+type adaptiveBatcher struct {
+	maxSize     int
+	maxAttempts int // consecutive failed requests before giving up
+
+	mu      sync.Mutex
+	size    int
+	limiter *tokenBucket // nil = unpaced
+}
+
+func newAdaptiveBatcher(maxSize, maxAttempts int) *adaptiveBatcher {
+	return &adaptiveBatcher{maxSize: maxSize, maxAttempts: maxAttempts, size: maxSize}
+}
+
+// SetRateLimit paces requests to at most perMinute per minute, allowing a
+// burst of up to one second's worth. perMinute <= 0 removes the limit.
+func (b *adaptiveBatcher) SetRateLimit(perMinute int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.limiter = nil
+	if perMinute > 0 {
+		b.limiter = newTokenBucket(perMinute, time.Now)
+	}
+}
+
+// BatchSize returns the current request size.
+func (b *adaptiveBatcher) BatchSize() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.size
+}
+
+// embed sends texts through call in adaptively sized batches, retrying
+// transient failures with exponential backoff starting at backoff. op
+// prefixes cancellation errors.
+func (b *adaptiveBatcher) embed(ctx context.Context, op string, texts []string, backoff time.Duration, call batchFunc) ([][]float32, error) {
+	results := make([][]float32, 0, len(texts))
+	delay := backoff
+	failures := 0
+	for start := 0; start < len(texts); {
+		if err := b.wait(ctx); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		batch := texts[start:min(start+b.BatchSize(), len(texts))]
+
+		vecs, retryable, err := call(ctx, batch)
+		if err == nil {
+			b.grow()
+			results = append(results, vecs...)
+			start += len(batch)
+			failures = 0
+			delay = backoff
+			continue
+		}
+		if errors.Is(err, ErrRateLimited) {
+			b.shrink()
+		}
+		failures++
+		if !retryable || ctx.Err() != nil || failures >= b.maxAttempts {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%s: %w", op, ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+	return results, nil
+}
+
+func (b *adaptiveBatcher) wait(ctx context.Context) error {
+	b.mu.Lock()
+	limiter := b.limiter
+	b.mu.Unlock()
+	if limiter == nil {
+		return nil
+	}
+	return limiter.wait(ctx)
+}
+
+func (b *adaptiveBatcher) grow() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.size = min(b.maxSize, b.size+max(1, b.maxSize/10))
+}
+
+func (b *adaptiveBatcher) shrink() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.size = max(1, b.size/2)
+}
+
+// tokenBucket is a token-bucket rate limiter. Tokens may go negative, which
+// queues concurrent callers behind each other instead of letting them race.
+type tokenBucket struct {
+	rate  float64 // tokens per second
+	burst float64
+	now   func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(perMinute int, now func() time.Time) *tokenBucket {
+	rate := float64(perMinute) / 60
+	burst := max(1, rate)
+	return &tokenBucket{rate: rate, burst: burst, now: now, tokens: burst, last: now()}
+}
+
+// reserve takes a token and returns how long the caller must wait before
+// using it.
+func (t *tokenBucket) reserve() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	t.tokens = min(t.burst, t.tokens+now.Sub(t.last).Seconds()*t.rate)
+	t.last = now
+	t.tokens--
+	if t.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-t.tokens / t.rate * float64(time.Second))
+}
+
+// wait blocks until a token is available or ctx is done.
+func (t *tokenBucket) wait(ctx context.Context) error {
+	d := t.reserve()
+	if d <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
@@ -0,0 +1,278 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// CohereEmbedModel is the Cohere embedding model used by CohereEmbedder.
+	CohereEmbedModel = "embed-english-v3.0"
+	// CohereEmbedDimension is the vector size produced by embed-english-v3.0.
+	CohereEmbedDimension = 1024
+	// CohereRerankModel is the Cohere model used by CohereReranker.
+	CohereRerankModel = "rerank-v3.5"
+
+	// cohereEmbedBatchSize is the maximum number of texts Cohere accepts in a
+	// single embed request.
+	cohereEmbedBatchSize = 96
+	cohereMaxRetries     = 3
+	cohereBaseURL        = "https://api.cohere.com"
+)
+
+// cohereClient holds the authentication and transport settings shared by the
+// Cohere embedder and reranker.
+type cohereClient struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+	backoff time.Duration
+}
+
+func newCohereClient(apiKey string, client *http.Client) cohereClient {
+	if apiKey == "" {
+		apiKey = os.Getenv("COHERE_API_KEY")
+	}
+	return cohereClient{
+		apiKey:  apiKey,
+		baseURL: cohereBaseURL,
+		client:  client,
+		backoff: 500 * time.Millisecond,
+	}
+}
+
+// checkKey reports a missing API key.
+func (c *cohereClient) checkKey(op string) error {
+	if c.apiKey == "" {
+		return fmt.Errorf("%s: no API key (set COHERE_API_KEY or add it under [keys] in the global config)", op)
+	}
+	return nil
+}
+
+// postWithRetry POSTs payload to path and decodes the response into out,
+// retrying transient errors with exponential backoff. op prefixes errors.
+func (c *cohereClient) postWithRetry(ctx context.Context, op, path string, payload, out any) error {
+	if err := c.checkKey(op); err != nil {
+		return err
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("%s marshal: %w", op, err)
+	}
+
+	var lastErr error
+	delay := c.backoff
+	for attempt := 0; attempt < cohereMaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%s: %w", op, ctx.Err())
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		retryable, err := c.post(ctx, op, path, body, out)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retryable || ctx.Err() != nil {
+			break
+		}
+	}
+	return lastErr
+}
+
+// post makes a single request. The returned bool reports whether a failure
+// is transient and worth retrying.
+func (c *cohereClient) post(ctx context.Context, op, path string, body []byte, out any) (bool, error) {
+	url := strings.TrimRight(c.baseURL, "/") + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("%s request: %w", op, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		// Network errors are transient unless the context was cancelled.
+		return ctx.Err() == nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		err := fmt.Errorf("%s: status %d: %s", op, resp.StatusCode, respBody)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return true, rateLimitedError{err}
+		}
+		return resp.StatusCode >= 500, err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("%s decode: %w", op, err)
+	}
+	return false, nil
+}
+
+// CohereEmbedder implements Embedder using Cohere's v2 embed endpoint.
+type CohereEmbedder struct {
+	cohereClient
+	*adaptiveBatcher
+	model string
+	// inputType tells Cohere how the text will be used. Memvra embeds
+	// chunks, memories and queries through one interface, so every text is
+	// embedded as a search document.
+	inputType string
+}
+
+// NewCohereEmbedder creates a Cohere embedder. If apiKey is empty,
+// COHERE_API_KEY is used.
+func NewCohereEmbedder(apiKey string) *CohereEmbedder {
+	return newCohereEmbedder(apiKey, &http.Client{})
+}
+
+func newCohereEmbedder(apiKey string, client *http.Client) *CohereEmbedder {
+	return &CohereEmbedder{
+		cohereClient:    newCohereClient(apiKey, client),
+		adaptiveBatcher: newAdaptiveBatcher(cohereEmbedBatchSize, cohereMaxRetries),
+		model:           CohereEmbedModel,
+		inputType:       "search_document",
+	}
+}
+
+type cohereEmbedRequest struct {
+	Model          string   `json:"model"`
+	Texts          []string `json:"texts"`
+	InputType      string   `json:"input_type"`
+	EmbeddingTypes []string `json:"embedding_types"`
+}
+
+type cohereEmbedResponse struct {
+	Embeddings struct {
+		Float [][]float32 `json:"float"`
+	} `json:"embeddings"`
+}
+
+// Embed generates embeddings for texts, splitting them into batches that fit
+// within Cohere's per-request limit. Batches shrink when Cohere rate-limits
+// and grow back on success; transient failures are retried.
+func (e *CohereEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	if err := e.checkKey("cohere embed"); err != nil {
+		return nil, err
+	}
+	return e.embed(ctx, "cohere embed", texts, e.backoff, e.embedBatch)
+}
+
+// embedBatch makes a single embed call. The returned bool reports whether a
+// failure is transient and worth retrying.
+func (e *CohereEmbedder) embedBatch(ctx context.Context, texts []string) ([][]float32, bool, error) {
+	body, err := json.Marshal(cohereEmbedRequest{
+		Model:          e.model,
+		Texts:          texts,
+		InputType:      e.inputType,
+		EmbeddingTypes: []string{"float"},
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("cohere embed marshal: %w", err)
+	}
+	var resp cohereEmbedResponse
+	if retryable, err := e.post(ctx, "cohere embed", "/v2/embed", body, &resp); err != nil {
+		return nil, retryable, err
+	}
+	if len(resp.Embeddings.Float) != len(texts) {
+		return nil, false, fmt.Errorf("cohere embed: got %d embeddings for %d inputs", len(resp.Embeddings.Float), len(texts))
+	}
+	return resp.Embeddings.Float, false, nil
+}
+
+// RerankResult is one document's position in a reranked list.
+type RerankResult struct {
+	Index int     // index of the document in the input slice
+	Score float64 // relevance to the query; higher is more relevant
+}
+
+// Reranker reorders candidate documents by relevance to a query.
+type Reranker interface {
+	// Rerank scores documents against query and returns at most topN results,
+	// most relevant first. topN <= 0 returns every document.
+	Rerank(ctx context.Context, query string, documents []string, topN int) ([]RerankResult, error)
+}
+
+// CohereReranker implements Reranker using Cohere's v2 rerank endpoint.
+type CohereReranker struct {
+	cohereClient
+	model string
+}
+
+// NewCohereReranker creates a Cohere reranker. If apiKey is empty,
+// COHERE_API_KEY is used; if model is empty, CohereRerankModel is used.
+func NewCohereReranker(apiKey, model string) *CohereReranker {
+	return newCohereReranker(apiKey, model, &http.Client{})
+}
+
+func newCohereReranker(apiKey, model string, client *http.Client) *CohereReranker {
+	if model == "" {
+		model = CohereRerankModel
+	}
+	return &CohereReranker{
+		cohereClient: newCohereClient(apiKey, client),
+		model:        model,
+	}
+}
+
+type cohereRerankRequest struct {
+	Model     string   `json:"model"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+	TopN      int      `json:"top_n,omitempty"`
+}
+
+type cohereRerankResponse struct {
+	Results []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float64 `json:"relevance_score"`
+	} `json:"results"`
+}
+
+// Rerank scores documents against query. Transient failures are retried.
+func (r *CohereReranker) Rerank(ctx context.Context, query string, documents []string, topN int) ([]RerankResult, error) {
+	if len(documents) == 0 {
+		return nil, nil
+	}
+	if topN < 0 || topN > len(documents) {
+		topN = 0
+	}
+
+	var resp cohereRerankResponse
+	err := r.postWithRetry(ctx, "cohere rerank", "/v2/rerank", cohereRerankRequest{
+		Model:     r.model,
+		Query:     query,
+		Documents: documents,
+		TopN:      topN,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]RerankResult, 0, len(resp.Results))
+	for _, res := range resp.Results {
+		if res.Index < 0 || res.Index >= len(documents) {
+			return nil, fmt.Errorf("cohere rerank: result index %d out of range", res.Index)
+		}
+		results = append(results, RerankResult{Index: res.Index, Score: res.RelevanceScore})
+	}
+	return results, nil
+}
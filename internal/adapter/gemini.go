@@ -35,7 +35,7 @@ func (g *geminiAdapter) Info() ModelInfo {
 		Provider:           ProviderGemini,
 		MaxContextWindow:   1000000,
 		SupportsStreaming:   true,
-		EmbeddingDimension: 768, // text-embedding-004
+		EmbeddingDimension: GeminiEmbedDimension,
 	}
 }
 
@@ -54,59 +54,9 @@ type geminiEmbedPart struct {
 	Text string `json:"text"`
 }
 
-type geminiEmbedResponse struct {
-	Embedding struct {
-		Values []float32 `json:"values"`
-	} `json:"embedding"`
-}
-
+// Embed delegates to GeminiEmbedder, which batches and retries requests.
 func (g *geminiAdapter) Embed(ctx context.Context, texts []string) ([][]float32, error) {
-	if len(texts) == 0 {
-		return nil, nil
-	}
-
-	const model = "text-embedding-004"
-	baseURL := fmt.Sprintf(
-		"https://generativelanguage.googleapis.com/v1beta/models/%s:embedContent?key=%s",
-		model, g.apiKey,
-	)
-
-	results := make([][]float32, 0, len(texts))
-	for _, text := range texts {
-		body, err := json.Marshal(geminiEmbedRequest{
-			Model: "models/" + model,
-			Content: geminiEmbedContent{
-				Parts: []geminiEmbedPart{{Text: text}},
-			},
-		})
-		if err != nil {
-			return nil, fmt.Errorf("gemini embed marshal: %w", err)
-		}
-
-		req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL, bytes.NewReader(body))
-		if err != nil {
-			return nil, fmt.Errorf("gemini embed request: %w", err)
-		}
-		req.Header.Set("Content-Type", "application/json")
-
-		resp, err := g.client.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("gemini embed: %w", err)
-		}
-		defer func() { _ = resp.Body.Close() }()
-
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("gemini embed: status %d", resp.StatusCode)
-		}
-
-		var result geminiEmbedResponse
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			return nil, fmt.Errorf("gemini embed decode: %w", err)
-		}
-		results = append(results, result.Embedding.Values)
-	}
-
-	return results, nil
+	return newGeminiEmbedder(g.apiKey, g.client).Embed(ctx, texts)
 }
 
 // ---------- Completion types ----------
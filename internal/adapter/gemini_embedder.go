@@ -0,0 +1,137 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// GeminiEmbedModel is the Gemini embedding model used by GeminiEmbedder.
+	GeminiEmbedModel = "text-embedding-004"
+	// GeminiEmbedDimension is the vector size produced by text-embedding-004.
+	GeminiEmbedDimension = 768
+
+	// geminiEmbedBatchSize is the maximum number of texts Gemini accepts in a
+	// single batchEmbedContents request.
+	geminiEmbedBatchSize  = 100
+	geminiEmbedMaxRetries = 3
+	geminiBaseURL         = "https://generativelanguage.googleapis.com/v1beta"
+)
+
+// GeminiEmbedder implements Embedder using Google's text-embedding-004 model
+// via the batchEmbedContents REST endpoint.
+type GeminiEmbedder struct {
+	*adaptiveBatcher
+	apiKey  string
+	model   string
+	baseURL string
+	client  *http.Client
+	backoff time.Duration
+}
+
+// NewGeminiEmbedder creates a Gemini embedder. If apiKey is empty,
+// GEMINI_API_KEY is used.
+func NewGeminiEmbedder(apiKey string) *GeminiEmbedder {
+	return newGeminiEmbedder(apiKey, &http.Client{})
+}
+
+func newGeminiEmbedder(apiKey string, client *http.Client) *GeminiEmbedder {
+	if apiKey == "" {
+		apiKey = os.Getenv("GEMINI_API_KEY")
+	}
+	return &GeminiEmbedder{
+		adaptiveBatcher: newAdaptiveBatcher(geminiEmbedBatchSize, geminiEmbedMaxRetries),
+		apiKey:          apiKey,
+		model:           GeminiEmbedModel,
+		baseURL:         geminiBaseURL,
+		client:          client,
+		backoff:         500 * time.Millisecond,
+	}
+}
+
+type geminiBatchEmbedRequest struct {
+	Requests []geminiEmbedRequest `json:"requests"`
+}
+
+type geminiBatchEmbedResponse struct {
+	Embeddings []struct {
+		Values []float32 `json:"values"`
+	} `json:"embeddings"`
+}
+
+// Embed generates embeddings for texts, splitting them into batches that fit
+// within Gemini's per-request limit. Batches shrink when Gemini rate-limits
+// and grow back on success; transient failures are retried.
+func (e *GeminiEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	if e.apiKey == "" {
+		return nil, fmt.Errorf("gemini embed: no API key (set GEMINI_API_KEY or run `memvra setup`)")
+	}
+	return e.embed(ctx, "gemini embed", texts, e.backoff, e.embedBatch)
+}
+
+// embedBatch makes a single batchEmbedContents call. The returned bool
+// reports whether a failure is transient and worth retrying.
+func (e *GeminiEmbedder) embedBatch(ctx context.Context, texts []string) ([][]float32, bool, error) {
+	reqs := make([]geminiEmbedRequest, len(texts))
+	for i, text := range texts {
+		reqs[i] = geminiEmbedRequest{
+			Model: "models/" + e.model,
+			Content: geminiEmbedContent{
+				Parts: []geminiEmbedPart{{Text: text}},
+			},
+		}
+	}
+
+	body, err := json.Marshal(geminiBatchEmbedRequest{Requests: reqs})
+	if err != nil {
+		return nil, false, fmt.Errorf("gemini embed marshal: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/models/%s:batchEmbedContents", strings.TrimRight(e.baseURL, "/"), e.model)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, false, fmt.Errorf("gemini embed request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-goog-api-key", e.apiKey)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		// Network errors are transient unless the context was cancelled.
+		return nil, ctx.Err() == nil, fmt.Errorf("gemini embed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		err := fmt.Errorf("gemini embed: status %d: %s", resp.StatusCode, respBody)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return nil, true, rateLimitedError{err}
+		}
+		return nil, resp.StatusCode >= 500, err
+	}
+
+	var result geminiBatchEmbedResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, false, fmt.Errorf("gemini embed decode: %w", err)
+	}
+	if len(result.Embeddings) != len(texts) {
+		return nil, false, fmt.Errorf("gemini embed: got %d embeddings for %d inputs", len(result.Embeddings), len(texts))
+	}
+
+	vecs := make([][]float32, len(result.Embeddings))
+	for i, emb := range result.Embeddings {
+		vecs[i] = emb.Values
+	}
+	return vecs, false, nil
+}
@@ -5,14 +5,26 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"time"
 
 	openai "github.com/sashabaranov/go-openai"
 )
 
+const (
+	// openaiEmbedBatchSize is the maximum number of inputs OpenAI accepts in
+	// a single embeddings request.
+	openaiEmbedBatchSize  = 2048
+	openaiEmbedMaxRetries = 3
+)
+
 // openaiAdapter implements LLMAdapter for OpenAI.
 type openaiAdapter struct {
-	client *openai.Client
+	client     *openai.Client
+	embedModel openai.EmbeddingModel
+	batches    *adaptiveBatcher
+	backoff    time.Duration
 }
 
 // NewOpenAI creates an OpenAI adapter. If apiKey is empty, OPENAI_API_KEY is used.
@@ -21,8 +33,22 @@ func NewOpenAI(apiKey string) LLMAdapter {
 		apiKey = os.Getenv("OPENAI_API_KEY")
 	}
 	return &openaiAdapter{
-		client: openai.NewClient(apiKey),
+		client:     openai.NewClient(apiKey),
+		embedModel: openai.SmallEmbedding3,
+		batches:    newAdaptiveBatcher(openaiEmbedBatchSize, openaiEmbedMaxRetries),
+		backoff:    500 * time.Millisecond,
+	}
+}
+
+// NewOpenAIEmbedder creates an OpenAI-backed Embedder using the given
+// embedding model (empty = text-embedding-3-small). Requests are batched
+// adaptively like the other HTTP embedders.
+func NewOpenAIEmbedder(apiKey, model string) Embedder {
+	a := NewOpenAI(apiKey).(*openaiAdapter)
+	if model != "" {
+		a.embedModel = openai.EmbeddingModel(model)
 	}
+	return a
 }
 
 func (o *openaiAdapter) Info() ModelInfo {
@@ -35,24 +61,48 @@ func (o *openaiAdapter) Info() ModelInfo {
 	}
 }
 
+// SetRateLimit paces embedding requests to at most perMinute per minute.
+func (o *openaiAdapter) SetRateLimit(perMinute int) { o.batches.SetRateLimit(perMinute) }
+
 func (o *openaiAdapter) Embed(ctx context.Context, texts []string) ([][]float32, error) {
 	if len(texts) == 0 {
 		return nil, nil
 	}
+	return o.batches.embed(ctx, "openai embed", texts, o.backoff, o.embedBatch)
+}
 
+// embedBatch makes a single embeddings request. The returned bool reports
+// whether a failure is transient and worth retrying.
+func (o *openaiAdapter) embedBatch(ctx context.Context, texts []string) ([][]float32, bool, error) {
 	resp, err := o.client.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{
 		Input: texts,
-		Model: openai.SmallEmbedding3,
+		Model: o.embedModel,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("openai embed: %w", err)
+		wrapped := fmt.Errorf("openai embed: %w", err)
+		status := 0
+		var apiErr *openai.APIError
+		var reqErr *openai.RequestError
+		switch {
+		case errors.As(err, &apiErr):
+			status = apiErr.HTTPStatusCode
+		case errors.As(err, &reqErr):
+			status = reqErr.HTTPStatusCode
+		default:
+			// Network errors are transient unless the context was cancelled.
+			return nil, ctx.Err() == nil, wrapped
+		}
+		if status == http.StatusTooManyRequests {
+			return nil, true, rateLimitedError{wrapped}
+		}
+		return nil, status >= 500, wrapped
 	}
 
 	result := make([][]float32, len(resp.Data))
 	for i, d := range resp.Data {
 		result[i] = d.Embedding
 	}
-	return result, nil
+	return result, false, nil
 }
 
 func (o *openaiAdapter) Complete(ctx context.Context, req CompletionRequest) (<-chan StreamChunk, error) {
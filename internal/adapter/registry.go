@@ -0,0 +1,98 @@
+package adapter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// EmbedderOptions holds the settings passed to an EmbedderFactory.
+type EmbedderOptions struct {
+	Model  string // provider-specific model name (empty = provider default)
+	APIKey string // empty = read from env in the concrete embedder
+	Host   string // base URL for self-hosted providers such as Ollama
+	// RateLimit caps requests per minute for cloud providers (0 = unlimited).
+	RateLimit int
+}
+
+// EmbedderFactory constructs an Embedder from resolved options.
+type EmbedderFactory func(opts EmbedderOptions) (Embedder, error)
+
+var (
+	embeddersMu sync.RWMutex
+	embedders   = map[string]EmbedderFactory{
+		ProviderOpenAI: func(opts EmbedderOptions) (Embedder, error) {
+			return NewOpenAIEmbedder(opts.APIKey, opts.Model), nil
+		},
+		ProviderOllama: func(opts EmbedderOptions) (Embedder, error) {
+			host := opts.Host
+			if host == "" {
+				host = "http://localhost:11434"
+			}
+			model := opts.Model
+			if model == "" {
+				model = "nomic-embed-text"
+			}
+			return NewOllama(host, model), nil
+		},
+		ProviderGemini: func(opts EmbedderOptions) (Embedder, error) {
+			e := NewGeminiEmbedder(opts.APIKey)
+			if opts.Model != "" {
+				e.model = opts.Model
+			}
+			return e, nil
+		},
+		ProviderCohere: func(opts EmbedderOptions) (Embedder, error) {
+			e := NewCohereEmbedder(opts.APIKey)
+			if opts.Model != "" {
+				e.model = opts.Model
+			}
+			return e, nil
+		},
+	}
+)
+
+// RegisterEmbedder adds or replaces the factory for the named provider.
+func RegisterEmbedder(name string, factory EmbedderFactory) {
+	embeddersMu.Lock()
+	defer embeddersMu.Unlock()
+	embedders[name] = factory
+}
+
+// NewEmbedder constructs the Embedder registered under provider.
+// Unknown providers return an error listing the valid names.
+func NewEmbedder(provider string, opts EmbedderOptions) (Embedder, error) {
+	embeddersMu.RLock()
+	factory, ok := embedders[provider]
+	embeddersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("adapter: unknown embedding provider %q; valid providers: %s",
+			provider, strings.Join(EmbedderProviders(), ", "))
+	}
+	emb, err := factory(opts)
+	if err != nil {
+		return nil, err
+	}
+	if rl, ok := emb.(rateLimitSetter); ok && opts.RateLimit > 0 {
+		rl.SetRateLimit(opts.RateLimit)
+	}
+	return emb, nil
+}
+
+// rateLimitSetter is implemented by embedders that can pace their requests.
+type rateLimitSetter interface {
+	SetRateLimit(perMinute int)
+}
+
+// EmbedderProviders returns the sorted names of all registered embedding providers.
+func EmbedderProviders() []string {
+	embeddersMu.RLock()
+	defer embeddersMu.RUnlock()
+	names := make([]string, 0, len(embedders))
+	for name := range embedders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
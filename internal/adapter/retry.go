@@ -0,0 +1,123 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by RetryingEmbedder while its circuit breaker is
+// open and calls are being short-circuited.
+var ErrCircuitOpen = errors.New("adapter: embedder circuit open")
+
+// RetryOptions configures a RetryingEmbedder. Zero values use the defaults.
+type RetryOptions struct {
+	MaxAttempts      int           // attempts per Embed call (default 3)
+	Backoff          time.Duration // delay before the first retry, doubled each time (default 200ms)
+	FailureThreshold int           // consecutive failed calls that trip the breaker (default 5)
+	Cooldown         time.Duration // how long the breaker stays open (default 30s)
+}
+
+func (o RetryOptions) withDefaults() RetryOptions {
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 3
+	}
+	if o.Backoff <= 0 {
+		o.Backoff = 200 * time.Millisecond
+	}
+	if o.FailureThreshold <= 0 {
+		o.FailureThreshold = 5
+	}
+	if o.Cooldown <= 0 {
+		o.Cooldown = 30 * time.Second
+	}
+	return o
+}
+
+// RetryingEmbedder decorates any Embedder with retries, exponential backoff,
+// and a circuit breaker. After FailureThreshold consecutive failed calls the
+// breaker opens and Embed fails fast with ErrCircuitOpen; once Cooldown has
+// elapsed a single trial call is let through, and a success closes it again.
+type RetryingEmbedder struct {
+	inner Embedder
+	opts  RetryOptions
+	now   func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// NewRetryingEmbedder wraps inner with retry and circuit-breaker behaviour.
+func NewRetryingEmbedder(inner Embedder, opts RetryOptions) *RetryingEmbedder {
+	return &RetryingEmbedder{inner: inner, opts: opts.withDefaults(), now: time.Now}
+}
+
+// Embed calls the wrapped embedder, retrying failures with backoff.
+// Context cancellation is never retried and does not count against the breaker.
+func (r *RetryingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if !r.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	var lastErr error
+	delay := r.opts.Backoff
+	for attempt := 0; attempt < r.opts.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("adapter: embed: %w", ctx.Err())
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		vecs, err := r.inner.Embed(ctx, texts)
+		if err == nil {
+			r.recordSuccess()
+			return vecs, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			return nil, err
+		}
+	}
+
+	r.recordFailure()
+	return nil, lastErr
+}
+
+// allow reports whether a call may proceed. While the breaker is open it
+// returns false; after the cooldown it lets one trial call through by
+// re-arming the breaker so concurrent callers keep failing fast.
+func (r *RetryingEmbedder) allow() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.openUntil.IsZero() {
+		return true
+	}
+	now := r.now()
+	if now.Before(r.openUntil) {
+		return false
+	}
+	r.openUntil = now.Add(r.opts.Cooldown)
+	return true
+}
+
+func (r *RetryingEmbedder) recordSuccess() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures = 0
+	r.openUntil = time.Time{}
+}
+
+func (r *RetryingEmbedder) recordFailure() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures++
+	if r.failures >= r.opts.FailureThreshold {
+		r.openUntil = r.now().Add(r.opts.Cooldown)
+	}
+}
@@ -82,38 +82,31 @@ Examples:
 			store := memory.NewStore(database)
 
 			// Build context.
-			tokenizer, err := ctxpkg.NewTokenizer()
-			if err != nil {
-				return fmt.Errorf("init tokenizer: %w", err)
-			}
+			tokenizer := newTokenizer(contextModel(gcfg, providerName))
 			formatter := ctxpkg.NewFormatter()
 
 			// Use a no-op embedder unless memory is requested.
-			var embedder adapter.LLMAdapter
+			ecfg, _ := config.Load(root)
+			var embedder adapter.Embedder
 			if !noMemory {
-				embedderName := gcfg.DefaultEmbedder
-				embedder, _ = adapter.New(embedderName, gcfg.Ollama.EmbedModel, apiKey(gcfg, embedderName), gcfg.Ollama.Host)
+				if emb := buildEmbedder(ecfg); emb != nil {
+					embedder = emb
+				}
 			}
 
-			vectors := memory.NewVectorStore(database)
-			ranker := memory.NewRanker()
-			orchestrator := memory.NewOrchestrator(store, vectors, ranker, embedder)
+			orchestrator := ctxpkg.ConfiguredOrchestrator(store, openVectorStore(database, ecfg), embedder, ecfg, pcfg)
 			builder := ctxpkg.NewBuilder(store, orchestrator, formatter, tokenizer)
+			defer useGlobalMemory(builder)()
 
-			builtCtx, err := builder.Build(context.Background(), ctxpkg.BuildOptions{
-				Question:            question,
-				ProjectRoot:         root,
-				MaxTokens:           gcfg.Context.MaxTokens,
-				TopKChunks:          gcfg.Context.TopKChunks,
-				TopKMemories:        gcfg.Context.TopKMemories,
-				TopKSessions:        gcfg.Context.TopKSessions,
-				SessionTokenBudget:  gcfg.Context.SessionTokenBudget,
-				SimilarityThreshold: gcfg.Context.SimilarityThreshold,
-				ExtraFiles:          files,
-			})
+			opts := ctxpkg.ConfiguredOptions(root, gcfg, pcfg)
+			opts.Question = question
+			opts.Model = contextModel(gcfg, providerName)
+			opts.ExtraFiles = files
+			builtCtx, err := builder.Build(context.Background(), opts)
 			if err != nil {
 				return fmt.Errorf("build context: %w", err)
 			}
+			printBuildWarnings(builtCtx)
 
 			if verbose && len(builtCtx.Sources) > 0 {
 				fmt.Fprintln(os.Stderr, "=== Sources included ===")
@@ -180,6 +173,11 @@ Examples:
 					TokensUsed:      builtCtx.TokensUsed,
 				})
 			}
+			_, _ = store.PruneSessionsByPolicy(memory.RetentionPolicy(gcfg.Retention))
+
+			if err := ctxpkg.RecordAccess(store, gcfg, builtCtx, opts.Model, sessID); err != nil {
+				fmt.Fprintf(os.Stderr, "  warn: audit log: %v\n", err)
+			}
 
 			// Auto-summarize session if enabled.
 			doSummarize := gcfg.Summarization.Enabled || summarize
@@ -213,7 +211,8 @@ Examples:
 					}
 				} else {
 					for _, m := range extracted {
-						saved, saveErr := orchestrator.Remember(context.Background(), m.Content, m.MemoryType, "extracted")
+						m.SourceSessionID = sessID
+						saved, saveErr := orchestrator.RememberMemory(context.Background(), m)
 						if saveErr != nil {
 							continue
 						}
@@ -271,7 +270,35 @@ func apiKey(cfg config.GlobalConfig, provider string) string {
 		return cfg.Keys.OpenAI
 	case adapter.ProviderGemini:
 		return cfg.Keys.Gemini
+	case adapter.ProviderCohere:
+		return cfg.Keys.Cohere
 	default:
 		return ""
 	}
 }
+
+// printBuildWarnings reports inputs the builder skipped or truncated.
+func printBuildWarnings(built *ctxpkg.BuiltContext) {
+	for _, w := range built.Warnings {
+		fmt.Fprintf(os.Stderr, "  warn: %s\n", w)
+	}
+}
+
+// newTokenizer returns a Tokenizer with model's encoding, noting on stderr
+// when it had to fall back to approximate counts.
+func newTokenizer(model string) *ctxpkg.Tokenizer {
+	tokenizer := ctxpkg.NewTokenizerForModel(model)
+	if err := tokenizer.FallbackReason(); err != nil {
+		fmt.Fprintf(os.Stderr, "  warn: %v; token counts are approximate\n", err)
+	}
+	return tokenizer
+}
+
+// contextModel returns the model name used to size the context window when
+// max_tokens is 0. Ollama is resolved to its configured completion model.
+func contextModel(gcfg config.GlobalConfig, provider string) string {
+	if provider == adapter.ProviderOllama {
+		return gcfg.Ollama.CompletionModel
+	}
+	return provider
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/memvra/memvra/internal/config"
+	"github.com/memvra/memvra/internal/db"
+	"github.com/memvra/memvra/internal/memory"
+)
+
+func newAuditCmd() *cobra.Command {
+	var (
+		filter memory.AccessFilter
+		since  string
+		asJSON bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Show which memories, chunks and sessions were fed to which model",
+		Long: `List the access log: every item packed into context by ` + "`memvra ask`" + `
+or memvra_get_context, with the model (or MCP client) it was sent to and
+when. Newest first.
+
+Recording is off by default. Enable it in ~/.config/memvra/config.toml:
+
+  [audit]
+  enabled = true
+
+Examples:
+  memvra audit --since 7d
+  memvra audit --item 3f2a9c --json
+  memvra audit --model claude --type decision`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root, err := findRoot()
+			if err != nil {
+				return err
+			}
+			dbPath, err := ensureInitialized(root)
+			if err != nil {
+				return err
+			}
+
+			if since != "" {
+				d, err := parseDuration(since)
+				if err != nil {
+					return fmt.Errorf("--since: %w", err)
+				}
+				filter.Since = time.Now().Add(-d)
+			}
+
+			database, err := db.Open(dbPath)
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer func() { _ = database.Close() }()
+
+			entries, err := memory.NewStore(database).GetAccessLog(filter)
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				if entries == nil {
+					entries = []memory.AccessEntry{}
+				}
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(entries)
+			}
+
+			if len(entries) == 0 {
+				if gcfg, _ := config.Load(root); !gcfg.Audit.Enabled {
+					fmt.Println("No access log entries. Auditing is off; set enabled = true under [audit] to record them.")
+				} else {
+					fmt.Println("No access log entries match.")
+				}
+				return nil
+			}
+			for _, e := range entries {
+				session := e.SessionID
+				if session == "" {
+					session = "-"
+				}
+				fmt.Printf("%s  %-12s  %-8s  %-32s  session %s\n",
+					e.AccessedAt.Local().Format("2006-01-02 15:04:05"),
+					e.Model, e.ItemType, e.ItemID, session)
+			}
+			fmt.Printf("\n  %d entr%s\n", len(entries), pluralY(len(entries)))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&filter.ItemID, "item", "", "only entries for this memory, chunk or session ID (or file path)")
+	cmd.Flags().StringVar(&filter.ItemType, "type", "", "only this item type: decision, memory, session, file, chunk")
+	cmd.Flags().StringVar(&filter.Model, "model", "", "only context sent to this model or MCP client")
+	cmd.Flags().StringVar(&filter.SessionID, "session", "", "only entries tied to this session ID")
+	cmd.Flags().StringVar(&since, "since", "", "only entries newer than this (e.g. 24h, 7d)")
+	cmd.Flags().IntVar(&filter.Limit, "limit", 100, "maximum entries to show (0 = all)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print as JSON")
+	return cmd
+}
@@ -18,6 +18,19 @@ func autoExportFilenames(cfg config.AutoExportConfig) []string {
 	return names
 }
 
+// scanExcludes returns the gitignore-style patterns kept out of the index:
+// the project's exclude_paths and, unless index_generated is set, the files
+// auto-export writes to the project root.
+func scanExcludes(pcfg config.ProjectConfig) []string {
+	patterns := append([]string(nil), pcfg.ExcludePaths...)
+	if !pcfg.IndexGenerated {
+		for _, name := range export.GeneratedFiles() {
+			patterns = append(patterns, "/"+name)
+		}
+	}
+	return patterns
+}
+
 // AutoExport regenerates all configured export files in the project root.
 // Delegates to export.AutoExport.
 func AutoExport(root string, store *memory.Store) {
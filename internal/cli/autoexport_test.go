@@ -5,11 +5,13 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/memvra/memvra/internal/config"
 	"github.com/memvra/memvra/internal/db"
 	"github.com/memvra/memvra/internal/export"
 	"github.com/memvra/memvra/internal/memory"
+	"github.com/memvra/memvra/internal/scanner"
 )
 
 func TestFormatToFilename(t *testing.T) {
@@ -50,6 +52,35 @@ func TestAutoExportFilenames(t *testing.T) {
 	}
 }
 
+func TestScanExcludes_SkipsGeneratedFiles(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644)
+	os.WriteFile(filepath.Join(root, "CLAUDE.md"), []byte("# Project context\n\nUse JWT auth.\n"), 0o644)
+	os.MkdirAll(filepath.Join(root, "docs"), 0o755)
+	os.WriteFile(filepath.Join(root, "docs", "CLAUDE.md"), []byte("# Hand-written notes\n"), 0o644)
+
+	indexed := func(pcfg config.ProjectConfig) map[string]bool {
+		result := scanner.Scan(scanner.ScanOptions{Root: root, ExcludeGlobs: scanExcludes(pcfg)})
+		paths := make(map[string]bool)
+		for _, sf := range result.Files {
+			paths[sf.File.Path] = true
+		}
+		return paths
+	}
+
+	paths := indexed(config.ProjectConfig{})
+	if paths["CLAUDE.md"] {
+		t.Error("generated CLAUDE.md should not be indexed")
+	}
+	if !paths["main.go"] || !paths[filepath.Join("docs", "CLAUDE.md")] {
+		t.Errorf("expected main.go and docs/CLAUDE.md to be indexed, got %v", paths)
+	}
+
+	if paths := indexed(config.ProjectConfig{IndexGenerated: true}); !paths["CLAUDE.md"] {
+		t.Error("index_generated should index CLAUDE.md")
+	}
+}
+
 func setupAutoExportTestDB(t *testing.T) (string, *memory.Store) {
 	t.Helper()
 	root := t.TempDir()
@@ -229,3 +260,53 @@ func TestAutoExport_UpdatesOnRerun(t *testing.T) {
 		t.Error("should contain newly added memory")
 	}
 }
+
+func TestDiffExports_DetectsStaleFile(t *testing.T) {
+	root, store := setupAutoExportTestDB(t)
+	store.InsertMemory(memory.Memory{
+		Content:    "use PostgreSQL for JSONB support",
+		MemoryType: memory.TypeDecision,
+		Importance: 0.8,
+		Source:     "user",
+	})
+	AutoExport(root, store)
+
+	if err := diffExports(root, store, []string{"claude"}, config.AutoExportConfig{Sessions: config.DefaultAutoExportSessions}); err != nil {
+		t.Fatalf("freshly exported file should be up to date, got %v", err)
+	}
+
+	store.InsertMemory(memory.Memory{
+		Content:    "never store secrets in the repo",
+		MemoryType: memory.TypeConstraint,
+		Importance: 0.8,
+		Source:     "user",
+	})
+	err := diffExports(root, store, []string{"claude"}, config.AutoExportConfig{Sessions: config.DefaultAutoExportSessions})
+	if err == nil || !strings.Contains(err.Error(), "CLAUDE.md") {
+		t.Fatalf("expected stale CLAUDE.md error, got %v", err)
+	}
+
+	// --diff never writes: the file on disk is unchanged.
+	content, _ := os.ReadFile(filepath.Join(root, "CLAUDE.md"))
+	if strings.Contains(string(content), "never store secrets") {
+		t.Error("diff mode must not rewrite export files")
+	}
+}
+
+func TestDiffExports_StableAcrossDays(t *testing.T) {
+	root, store := setupAutoExportTestDB(t)
+	store.SetClock(memory.NewManualClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)))
+	store.InsertMemory(memory.Memory{Content: "use PostgreSQL", MemoryType: memory.TypeDecision, Importance: 0.8})
+	store.SetClock(nil)
+	AutoExport(root, store)
+
+	// The exported date doesn't depend on when the file is rendered, so a
+	// committed export stays up to date as days pass.
+	content, _ := os.ReadFile(filepath.Join(root, "CLAUDE.md"))
+	if !strings.Contains(string(content), "use PostgreSQL · ★★★★☆ · 2025-01-15") || strings.Contains(string(content), " ago") {
+		t.Errorf("expected an absolute memory date, got:\n%s", content)
+	}
+	if err := diffExports(root, store, []string{"claude"}, config.AutoExportConfig{Sessions: config.DefaultAutoExportSessions}); err != nil {
+		t.Errorf("expected the export to be up to date, got %v", err)
+	}
+}
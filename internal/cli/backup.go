@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/memvra/memvra/internal/config"
+	"github.com/memvra/memvra/internal/db"
+)
+
+func newBackupCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "backup [path]",
+		Short: "Snapshot the project database",
+		Long: `Write a consistent snapshot of .memvra/memvra.db using SQLite's online
+backup API, which is safe while memvra watch or the MCP server is running.
+
+Without a path, the snapshot goes to .memvra/backups/memvra-<timestamp>.db.
+If path is a directory, a timestamped file is created inside it.
+
+Examples:
+  memvra backup
+  memvra backup ~/memvra-snapshots/
+  memvra restore .memvra/backups/memvra-20250101-120000.db`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root, err := findRoot()
+			if err != nil {
+				return err
+			}
+			dbPath, err := ensureInitialized(root)
+			if err != nil {
+				return err
+			}
+
+			dest := config.ProjectBackupDir(root)
+			if len(args) == 1 {
+				dest = args[0]
+			}
+			if fi, err := os.Stat(dest); (err == nil && fi.IsDir()) || len(args) == 0 {
+				dest = filepath.Join(dest, backupFilename(time.Now()))
+			}
+
+			database, err := db.Open(dbPath)
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer func() { _ = database.Close() }()
+
+			if err := database.Backup(dest); err != nil {
+				return err
+			}
+
+			size := int64(0)
+			if fi, err := os.Stat(dest); err == nil {
+				size = fi.Size()
+			}
+			fmt.Printf("Backed up to %s (%s)\n", dest, formatBytes(size))
+			return nil
+		},
+	}
+}
+
+func newRestoreCmd() *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "restore <path>",
+		Short: "Replace the project database with a backup",
+		Long: `Validate a snapshot written by "memvra backup" and copy it over
+.memvra/memvra.db. Everything stored since the snapshot is lost, so you are
+asked to confirm unless --yes is given.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			src := args[0]
+
+			root, err := findRoot()
+			if err != nil {
+				return err
+			}
+			dbPath := config.ProjectDBPath(root)
+
+			if err := db.Validate(src); err != nil {
+				return err
+			}
+			if !yes && !confirmPrompt(fmt.Sprintf("Replace %s with %s?", dbPath, src)) {
+				fmt.Println("Aborted.")
+				return nil
+			}
+
+			database, err := db.Open(dbPath)
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer func() { _ = database.Close() }()
+
+			if err := database.Restore(src); err != nil {
+				return err
+			}
+			fmt.Printf("Restored %s from %s\n", dbPath, src)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt")
+
+	return cmd
+}
+
+// backupFilename returns the timestamped name used for backups taken at t.
+func backupFilename(t time.Time) string {
+	return "memvra-" + t.Format("20060102-150405") + ".db"
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// errNoClipboard is returned when no clipboard tool is usable, e.g. on a
+// headless machine or over SSH without X forwarding.
+var errNoClipboard = errors.New("no clipboard available")
+
+// clipboardCommand returns the command line for writing stdin to the system
+// clipboard, or nil if none is available.
+func clipboardCommand() []string {
+	var candidates [][]string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = append(candidates, []string{"pbcopy"})
+	case "windows":
+		candidates = append(candidates, []string{"clip.exe"})
+	default:
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			candidates = append(candidates, []string{"wl-copy"})
+		}
+		if os.Getenv("DISPLAY") != "" {
+			candidates = append(candidates,
+				[]string{"xclip", "-selection", "clipboard"},
+				[]string{"xsel", "--clipboard", "--input"},
+			)
+		}
+		// WSL exposes the Windows clipboard without a display server.
+		candidates = append(candidates, []string{"clip.exe"})
+	}
+	for _, c := range candidates {
+		if _, err := exec.LookPath(c[0]); err == nil {
+			return c
+		}
+	}
+	return nil
+}
+
+// copyToClipboard writes text to the system clipboard.
+func copyToClipboard(text string) error {
+	args := clipboardCommand()
+	if args == nil {
+		return errNoClipboard
+	}
+	c := exec.Command(args[0], args[1:]...)
+	c.Stdin = strings.NewReader(text)
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	return nil
+}
+
+// writeOutput prints text to stdout and, when toClipboard is set, also places it on
+// the clipboard. Clipboard failures are reported on stderr but never fail
+// the command, since the text was already printed.
+func writeOutput(text string, toClipboard bool) error {
+	if _, err := os.Stdout.WriteString(text); err != nil {
+		return err
+	}
+	if !toClipboard {
+		return nil
+	}
+	if err := copyToClipboard(text); err != nil {
+		fmt.Fprintf(os.Stderr, "Could not copy to clipboard (%v); output printed above.\n", err)
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "Copied %d characters to the clipboard.\n", len(text))
+	return nil
+}
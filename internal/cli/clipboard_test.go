@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestCopyToClipboard_NoClipboardTool(t *testing.T) {
+	// An empty PATH and no display mimics a headless SSH session.
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("DISPLAY", "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+
+	if err := copyToClipboard("hello"); !errors.Is(err, errNoClipboard) {
+		t.Errorf("expected errNoClipboard, got %v", err)
+	}
+}
+
+func TestCopyToClipboard_UsesAvailableTool(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("fake xclip only exercised on linux")
+	}
+	bin := t.TempDir()
+	out := filepath.Join(bin, "clipboard.txt")
+	script := "#!/bin/sh\ncat > " + out + "\n"
+	os.WriteFile(filepath.Join(bin, "xclip"), []byte(script), 0o755)
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("DISPLAY", ":0")
+	t.Setenv("WAYLAND_DISPLAY", "")
+
+	if err := copyToClipboard("project context"); err != nil {
+		t.Fatalf("copyToClipboard: %v", err)
+	}
+	got, _ := os.ReadFile(out)
+	if string(got) != "project context" {
+		t.Errorf("clipboard contents: got %q", got)
+	}
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/memvra/memvra/internal/db"
+)
+
+func newCompactCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "compact",
+		Short: "Shrink the database after large deletes",
+		Long: `Rebuild the vector index and VACUUM the .memvra database.
+
+SQLite does not return space freed by deleted memories, sessions, or
+re-indexed chunks to the filesystem, and the vector index keeps room for
+deleted embeddings. Run this after forgetting or pruning a lot of data.
+
+memvra forget and memvra prune already vacuum automatically once a quarter
+of the database is free space.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root, err := findRoot()
+			if err != nil {
+				return err
+			}
+			dbPath, err := ensureInitialized(root)
+			if err != nil {
+				return err
+			}
+
+			database, err := db.Open(dbPath)
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer func() { _ = database.Close() }()
+
+			before, after, err := database.Compact()
+			if err != nil {
+				return fmt.Errorf("compact: %w", err)
+			}
+
+			fmt.Printf("Compacted: %s → %s (%s reclaimed)\n",
+				formatBytes(before), formatBytes(after), formatBytes(max(before-after, 0)))
+			return nil
+		},
+	}
+}
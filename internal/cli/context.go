@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -9,7 +10,9 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/memvra/memvra/internal/adapter"
 	"github.com/memvra/memvra/internal/config"
+	ctxpkg "github.com/memvra/memvra/internal/context"
 	"github.com/memvra/memvra/internal/db"
 	"github.com/memvra/memvra/internal/memory"
 	"github.com/memvra/memvra/internal/scanner"
@@ -19,6 +22,8 @@ func newContextCmd() *cobra.Command {
 	var section string
 	var export bool
 	var edit bool
+	var question string
+	var toClipboard bool
 
 	cmd := &cobra.Command{
 		Use:   "context",
@@ -27,11 +32,15 @@ func newContextCmd() *cobra.Command {
 
 Sections: profile, decisions, conventions, constraints, notes, todos
 
+With --question, print exactly what "memvra ask" would inject for that
+question (system prompt and context), ready to paste into a web chat.
+
 Examples:
   memvra context
   memvra context --section decisions
   memvra context --export
-  memvra context --edit`,
+  memvra context --edit
+  memvra context --question "How does auth work?" --copy`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			root, err := findRoot()
 			if err != nil {
@@ -51,6 +60,14 @@ Examples:
 
 			store := memory.NewStore(database)
 
+			if question != "" {
+				text, err := renderBuiltContext(root, database, store, question)
+				if err != nil {
+					return err
+				}
+				return writeOutput(text, toClipboard)
+			}
+
 			proj, err := store.GetProject()
 			if err != nil {
 				return err
@@ -61,42 +78,36 @@ Examples:
 			out := &strings.Builder{}
 
 			if section == "" || section == "profile" {
-				fmt.Fprintf(out, "# Project Context: %s\n\n", proj.Name)
+				pcfg, _ := config.LoadProject(root)
+				fmt.Fprintf(out, "# Project Context: %s\n\n", pcfg.ProjectName(proj.Name))
 				fmt.Fprintf(out, "## Profile\n\n")
-				fmt.Fprintf(out, "- **Language:** %s\n", ts.Language)
-				if ts.Framework != "" {
-					fmt.Fprintf(out, "- **Framework:** %s\n", ts.Framework)
-				}
-				if ts.Database != "" {
-					fmt.Fprintf(out, "- **Database:** %s\n", ts.Database)
-				}
-				if ts.Architecture != "" {
-					fmt.Fprintf(out, "- **Architecture:** %s\n", ts.Architecture)
-				}
-				if ts.TestFramework != "" {
-					fmt.Fprintf(out, "- **Tests:** %s\n", ts.TestFramework)
-				}
-				if ts.CI != "" {
-					fmt.Fprintf(out, "- **CI:** %s\n", ts.CI)
-				}
-				if len(ts.DetectedPatterns) > 0 {
-					fmt.Fprintf(out, "- **Patterns:** %s\n", strings.Join(ts.DetectedPatterns, ", "))
+				for _, ns := range ts.Components() {
+					if ns.Name != "" {
+						fmt.Fprintf(out, "- **%s:**\n", ns.Name)
+						writeProfileStack(out, ns.TechStack, "  ")
+						continue
+					}
+					writeProfileStack(out, ns.TechStack, "")
 				}
 				fmt.Fprintf(out, "- **Files indexed:** %d (%d chunks)\n", proj.FileCount, proj.ChunkCount)
 				fmt.Fprintf(out, "- **Last updated:** %s\n\n", proj.UpdatedAt.Format(time.RFC3339))
 			}
 
 			// Memory sections.
-			typeSections := []struct {
+			type typeSection struct {
 				label string
 				mt    memory.MemoryType
-			}{
+			}
+			typeSections := []typeSection{
 				{"Decisions", memory.TypeDecision},
 				{"Conventions", memory.TypeConvention},
 				{"Constraints", memory.TypeConstraint},
 				{"Notes", memory.TypeNote},
 				{"TODOs", memory.TypeTodo},
 			}
+			for _, t := range memory.CustomTypes() {
+				typeSections = append(typeSections, typeSection{t.Label(), t})
+			}
 
 			for _, ts := range typeSections {
 				key := strings.ToLower(ts.label)
@@ -134,7 +145,9 @@ Examples:
 				return openInEditor(ctxPath)
 			}
 
-			fmt.Print(out.String())
+			if err := writeOutput(out.String(), toClipboard); err != nil {
+				return err
+			}
 			if export {
 				if err := os.WriteFile(ctxPath, []byte(out.String()), 0o644); err != nil {
 					fmt.Fprintf(os.Stderr, "Warning: could not write context.md: %v\n", err)
@@ -150,10 +163,82 @@ Examples:
 	cmd.Flags().StringVarP(&section, "section", "s", "", "Show only a specific section: profile, decisions, conventions, constraints, notes, todos")
 	cmd.Flags().BoolVar(&export, "export", false, "Also write context to .memvra/context.md")
 	cmd.Flags().BoolVar(&edit, "edit", false, "Open .memvra/context.md in $EDITOR")
+	cmd.Flags().StringVarP(&question, "question", "q", "", "Print the context built for this question instead of the summary")
+	cmd.Flags().BoolVar(&toClipboard, "copy", false, "Also copy the output to the system clipboard")
 
 	return cmd
 }
 
+// renderBuiltContext runs the context Builder for question, the same way
+// `memvra ask` does, and returns the system prompt and context as one block.
+func renderBuiltContext(root string, database *db.DB, store *memory.Store, question string) (string, error) {
+	gcfg, err := config.LoadGlobal()
+	if err != nil {
+		gcfg = config.DefaultGlobal()
+	}
+	pcfg, _ := config.LoadProject(root)
+
+	providerName := gcfg.DefaultModel
+	if pcfg.DefaultModel != "" {
+		providerName = pcfg.DefaultModel
+	}
+	tokenizer := newTokenizer(contextModel(gcfg, providerName))
+	ecfg, _ := config.Load(root)
+	var embedder adapter.Embedder
+	if emb := buildEmbedder(ecfg); emb != nil {
+		embedder = emb
+	}
+	orchestrator := ctxpkg.ConfiguredOrchestrator(store, openVectorStore(database, ecfg), embedder, ecfg, pcfg)
+	builder := ctxpkg.NewBuilder(store, orchestrator, ctxpkg.NewFormatter(), tokenizer)
+	defer useGlobalMemory(builder)()
+
+	opts := ctxpkg.ConfiguredOptions(root, gcfg, pcfg)
+	opts.Question = question
+	opts.Model = contextModel(gcfg, providerName)
+	opts.ExtraFiles = pcfg.AlwaysInclude
+
+	built, err := builder.Build(context.Background(), opts)
+	if err != nil {
+		return "", fmt.Errorf("build context: %w", err)
+	}
+	printBuildWarnings(built)
+
+	var sb strings.Builder
+	if built.SystemPrompt != "" {
+		sb.WriteString(built.SystemPrompt)
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString(built.ContextText)
+	if !strings.HasSuffix(sb.String(), "\n") {
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+// writeProfileStack renders a single stack's fields as markdown bullets,
+// each line prefixed by indent.
+func writeProfileStack(out *strings.Builder, ts scanner.TechStack, indent string) {
+	fmt.Fprintf(out, "%s- **Language:** %s\n", indent, ts.Language)
+	if ts.Framework != "" {
+		fmt.Fprintf(out, "%s- **Framework:** %s\n", indent, ts.Framework)
+	}
+	if ts.Database != "" {
+		fmt.Fprintf(out, "%s- **Database:** %s\n", indent, ts.Database)
+	}
+	if ts.Architecture != "" {
+		fmt.Fprintf(out, "%s- **Architecture:** %s\n", indent, ts.Architecture)
+	}
+	if ts.TestFramework != "" {
+		fmt.Fprintf(out, "%s- **Tests:** %s\n", indent, ts.TestFramework)
+	}
+	if ts.CI != "" {
+		fmt.Fprintf(out, "%s- **CI:** %s\n", indent, ts.CI)
+	}
+	if len(ts.DetectedPatterns) > 0 {
+		fmt.Fprintf(out, "%s- **Patterns:** %s\n", indent, strings.Join(ts.DetectedPatterns, ", "))
+	}
+}
+
 // openInEditor opens a file in the user's preferred editor.
 func openInEditor(path string) error {
 	editor := os.Getenv("EDITOR")
@@ -37,6 +37,7 @@ func newDiffCmd() *cobra.Command {
 		sessionsOnly bool
 		since        string
 		noScan       bool
+		tag          string
 	)
 
 	cmd := &cobra.Command{
@@ -47,12 +48,13 @@ func newDiffCmd() *cobra.Command {
 Shows three sections:
   - File index changes (added, modified, deleted files)
   - New memories since the last update
-  - New sessions since the last update
+  - New sessions since the last update (only those tagged --tag, if set)
 
 Examples:
   memvra diff
   memvra diff --files-only
   memvra diff --since 24h
+  memvra diff --sessions-only --since 7d --tag bugfix
   memvra diff --no-scan`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			root, err := findRoot()
@@ -102,9 +104,11 @@ Examples:
 			}
 
 			if showFiles {
+				pcfg, _ := config.LoadProject(root)
 				result := scanner.Scan(scanner.ScanOptions{
 					Root:          root,
 					MaxChunkLines: gcfg.Context.ChunkMaxLines,
+					ExcludeGlobs:  scanExcludes(pcfg),
 				})
 
 				allDBFiles, err := store.ListFiles()
@@ -155,7 +159,7 @@ Examples:
 				if err != nil {
 					return fmt.Errorf("list recent sessions: %w", err)
 				}
-				printSessionDiff(sessions, anchor)
+				printSessionDiff(sessionsTagged(sessions, tag), anchor, tag)
 			}
 
 			fmt.Println()
@@ -168,6 +172,7 @@ Examples:
 	cmd.Flags().BoolVar(&sessionsOnly, "sessions-only", false, "only show session changes")
 	cmd.Flags().StringVar(&since, "since", "", "override time anchor (e.g. 24h, 7d, 2h30m)")
 	cmd.Flags().BoolVar(&noScan, "no-scan", false, "skip filesystem scan (show only memory/session changes)")
+	cmd.Flags().StringVar(&tag, "tag", "", "only show sessions with this tag (e.g. bugfix, spike)")
 
 	return cmd
 }
@@ -207,10 +212,7 @@ func printMemoryDiff(memories []memory.Memory, since time.Time) {
 	}
 
 	grouped := make(map[memory.MemoryType][]memory.Memory)
-	typeOrder := []memory.MemoryType{
-		memory.TypeDecision, memory.TypeConvention,
-		memory.TypeConstraint, memory.TypeNote, memory.TypeTodo,
-	}
+	typeOrder := memory.AllTypes()
 	for _, m := range memories {
 		grouped[m.MemoryType] = append(grouped[m.MemoryType], m)
 	}
@@ -231,8 +233,27 @@ func printMemoryDiff(memories []memory.Memory, since time.Time) {
 	}
 }
 
-func printSessionDiff(sessions []memory.Session, since time.Time) {
-	fmt.Printf("\n%s=== Sessions (since %s) ===%s\n", cBold, since.Format("2006-01-02 15:04"), cReset)
+// sessionsTagged returns the sessions carrying tag, or all of them when tag
+// is empty.
+func sessionsTagged(sessions []memory.Session, tag string) []memory.Session {
+	if tag == "" {
+		return sessions
+	}
+	var out []memory.Session
+	for _, sess := range sessions {
+		if sess.HasTag(tag) {
+			out = append(out, sess)
+		}
+	}
+	return out
+}
+
+func printSessionDiff(sessions []memory.Session, since time.Time, tag string) {
+	heading := "Sessions"
+	if tag != "" {
+		heading = fmt.Sprintf("Sessions tagged %q", tag)
+	}
+	fmt.Printf("\n%s=== %s (since %s) ===%s\n", cBold, heading, since.Format("2006-01-02 15:04"), cReset)
 
 	if len(sessions) == 0 {
 		fmt.Printf("  %s(none)%s\n", cDim, cReset)
@@ -245,7 +266,11 @@ func printSessionDiff(sessions []memory.Session, since time.Time) {
 		if len(question) > 70 {
 			question = question[:67] + "..."
 		}
-		fmt.Printf("  %s[%s]%s %s\n", cDim, ts, cReset, question)
+		fmt.Printf("  %s[%s]%s %s", cDim, ts, cReset, question)
+		if len(sess.Tags) > 0 {
+			fmt.Printf(" %s(%s)%s", cDim, strings.Join(sess.Tags, ", "), cReset)
+		}
+		fmt.Println()
 
 		if sess.ResponseSummary != "" {
 			summary := sess.ResponseSummary
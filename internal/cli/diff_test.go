@@ -3,6 +3,8 @@ package cli
 import (
 	"testing"
 	"time"
+
+	"github.com/memvra/memvra/internal/memory"
 )
 
 func TestParseDuration(t *testing.T) {
@@ -60,3 +62,27 @@ func TestPluralS(t *testing.T) {
 		t.Error("expected 's' for 0")
 	}
 }
+
+func TestSessionsTagged(t *testing.T) {
+	sessions := []memory.Session{
+		{Question: "fix login redirect", Tags: []string{"bugfix"}},
+		{Question: "try a queue library", Tags: []string{"spike"}},
+		{Question: "untagged work"},
+	}
+	if got := sessionsTagged(sessions, ""); len(got) != 3 {
+		t.Errorf("no tag should keep every session, got %d", len(got))
+	}
+	got := sessionsTagged(sessions, "BugFix")
+	if len(got) != 1 || got[0].Question != "fix login redirect" {
+		t.Errorf("expected only the bugfix session, got %+v", got)
+	}
+	if got := sessionsTagged(sessions, "docs"); len(got) != 0 {
+		t.Errorf("expected no sessions for an unused tag, got %+v", got)
+	}
+}
+
+func TestDiffCmd_TagFlag(t *testing.T) {
+	if newDiffCmd().Flags().Lookup("tag") == nil {
+		t.Fatal("memvra diff should accept --tag")
+	}
+}
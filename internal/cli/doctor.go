@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/memvra/memvra/internal/config"
+	ctxpkg "github.com/memvra/memvra/internal/context"
+	"github.com/memvra/memvra/internal/db"
+	"github.com/memvra/memvra/internal/memory"
+)
+
+// probeTimeout bounds the embedder probe so a dead provider fails fast.
+const probeTimeout = 30 * time.Second
+
+func newDoctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the database, embedder and tokenizer for problems",
+		Long: `Run quick health checks on this project's setup.
+
+The configured embedder is asked to embed a short probe text, so its vector
+dimension is known up front. If it differs from the vectors already in the
+index, semantic search would return garbage; doctor reports the mismatch
+instead of leaving it to be found later.
+
+Exits non-zero when any check fails.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root, err := findRoot()
+			if err != nil {
+				return err
+			}
+			dbPath, err := ensureInitialized(root)
+			if err != nil {
+				return err
+			}
+
+			database, err := db.Open(dbPath)
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer func() { _ = database.Close() }()
+
+			failed := 0
+			check := func(name string, err error, ok string) {
+				if err != nil {
+					failed++
+					fmt.Printf("  ✗ %-10s %v\n", name, err)
+					return
+				}
+				fmt.Printf("  ✓ %-10s %s\n", name, ok)
+			}
+
+			fmt.Printf("Checking %s\n\n", root)
+
+			version, err := db.SchemaVersion(dbPath)
+			check("database", err, fmt.Sprintf("%s (schema %d of %d)", dbPath, version, db.LatestSchemaVersion()))
+
+			gcfg, _ := config.Load(root)
+			vectors := openVectorStore(database, gcfg)
+			dim, err := probeEmbedder(database, vectors, gcfg)
+			var mismatch *memory.DimensionMismatchError
+			switch {
+			case err != nil:
+				check("embedder", fmt.Errorf("%s: %w", gcfg.EmbeddingModelKey(), err), "")
+				if errors.As(err, &mismatch) {
+					fmt.Printf("               switch back to a %d-dimensional embedder, or run `memvra update --reembed` to rebuild the index for this one\n", mismatch.Stored)
+				}
+			case vectors.Dimension() == 0:
+				check("embedder", nil, fmt.Sprintf("%s produces %d-dimensional vectors (no vector index yet)", gcfg.EmbeddingModelKey(), dim))
+			default:
+				check("embedder", nil, fmt.Sprintf("%s produces %d-dimensional vectors, matching the index", gcfg.EmbeddingModelKey(), dim))
+			}
+
+			// An approximate tokenizer still works, so it only warns.
+			tokenizer := ctxpkg.NewTokenizer()
+			if err := tokenizer.FallbackReason(); err != nil {
+				fmt.Printf("  ! %-10s token counts are approximate: %v\n", "tokenizer", err)
+			} else {
+				check("tokenizer", nil, tokenizer.Mode())
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d check%s failed", failed, pluralS(failed))
+			}
+			fmt.Println("\nAll checks passed.")
+			return nil
+		},
+	}
+}
+
+// probeEmbedder embeds a probe text with the configured embedder and
+// checks its dimension against the index (see Orchestrator.ProbeDimension).
+func probeEmbedder(database *db.DB, vectors *memory.VectorStore, gcfg config.GlobalConfig) (int, error) {
+	emb := buildEmbedder(gcfg)
+	if emb == nil {
+		return 0, fmt.Errorf("embedder could not be created; check [embedding] in the config")
+	}
+	orchestrator := memory.NewOrchestrator(memory.NewStore(database), vectors, nil, emb)
+	orchestrator.SetEmbeddingModel(gcfg.EmbeddingModelKey())
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	return orchestrator.ProbeDimension(ctx)
+}
@@ -1,11 +1,17 @@
 package cli
 
 import (
+	"bufio"
 	"fmt"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
 	"github.com/memvra/memvra/internal/config"
 	"github.com/memvra/memvra/internal/db"
@@ -17,21 +23,54 @@ import (
 
 func newExportCmd() *cobra.Command {
 	var (
-		format  string
-		section string
+		format      string
+		section     string
+		types       []string
+		minImp      float64
+		toClipboard bool
+		diffMode    bool
+		preview     bool
 	)
 
 	cmd := &cobra.Command{
 		Use:   "export",
 		Short: "Export context to CLAUDE.md, .cursorrules, or markdown",
 		Long: `Render project memory in a format compatible with other AI tools.
-Output is written to stdout — pipe it to a file.
+Output is written to stdout — pipe it to a file, or add --copy to also
+place it on the clipboard.
 
 Examples:
   memvra export --format claude > CLAUDE.md
   memvra export --format cursor > .cursorrules
   memvra export --format markdown > PROJECT_CONTEXT.md
-  memvra export --format markdown --section decisions`,
+  memvra export --format markdown --section decisions
+  memvra export --format claude --types decision,constraint --min-importance 0.6
+  memvra export --format markdown --copy
+  memvra export --diff                    # exit 1 if CLAUDE.md etc. are stale
+  memvra export --preview                 # show what auto-export would write
+  memvra export --format embeddings > vectors.jsonl
+  memvra export --format embeddings --section decision
+  memvra export --format dot | dot -Tsvg > memories.svg
+
+With --diff, every auto-export format (or just --format, if given) is rendered
+in memory and compared with the file on disk; nothing is written. The command
+exits non-zero when any file differs, so it can gate commits or CI.
+
+With --preview, the files auto-export would write are rendered with the
+[auto_export] settings and printed, each under a "==> FILE <==" header, even
+while auto-export is disabled. Nothing is written.
+
+--format embeddings dumps the stored vectors for the current embedding model
+as JSON Lines: one object per memory or code chunk with its id, type or file
+location, and "embedding" array, for clustering or other offline analysis.
+With --section only memories of that type are written.
+
+--format dot writes a Graphviz graph of memories, colored by type, with an
+edge for each link recorded by ` + "`memvra link`" + ` (supersedes solid, relates_to
+dashed). Rendering it is up to Graphviz.
+
+--types and --min-importance filter memories for this export only, in every
+format; the auto-export settings are not consulted.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			root, err := findRoot()
 			if err != nil {
@@ -51,56 +90,220 @@ Examples:
 
 			store := memory.NewStore(database)
 
+			if preview {
+				if diffMode || section != "" || len(types) > 0 || minImp > 0 || cmd.Flags().Changed("format") {
+					return fmt.Errorf("--format, --section, --types, --min-importance and --diff cannot be combined with --preview")
+				}
+				return previewExports(root, store, toClipboard)
+			}
+
+			if diffMode {
+				if section != "" || len(types) > 0 || minImp > 0 {
+					return fmt.Errorf("--section, --types and --min-importance cannot be combined with --diff")
+				}
+				gcfg, _ := config.Load(root)
+				formats := gcfg.AutoExport.Formats
+				if cmd.Flags().Changed("format") {
+					formats = []string{strings.ToLower(format)}
+				}
+				return diffExports(root, store, formats, gcfg.AutoExport)
+			}
+
 			proj, err := store.GetProject()
 			if err != nil {
 				return fmt.Errorf("get project: %w", err)
 			}
 
 			ts, _ := scanner.TechStackFromJSON(proj.TechStack)
+			pcfg, _ := config.LoadProject(root)
+			proj.Name = pcfg.ProjectName(proj.Name)
 
 			// Filter memories by section if requested.
 			var filterType memory.MemoryType
 			if section != "" {
 				filterType = memory.MemoryType(strings.ToLower(section))
 				if !memory.ValidMemoryType(filterType) {
-					return fmt.Errorf("unknown section %q; valid: decision, convention, constraint, note, todo", section)
+					return fmt.Errorf("unknown section %q; valid: %s", section, memory.TypeNames())
 				}
 			}
 
+			filter := export.MemoryFilter{MinImportance: minImp}
+			for _, t := range types {
+				mt := memory.MemoryType(strings.ToLower(strings.TrimSpace(t)))
+				if !memory.ValidMemoryType(mt) {
+					return fmt.Errorf("unknown type %q in --types; valid: %s", t, memory.TypeNames())
+				}
+				filter.Types = append(filter.Types, mt)
+			}
+
+			if strings.ToLower(format) == export.EmbeddingsFormat {
+				if toClipboard {
+					return fmt.Errorf("--copy cannot be combined with --format embeddings")
+				}
+				return exportEmbeddings(root, database, store, filterType, filter)
+			}
+
 			memories, err := store.ListMemories(filterType)
 			if err != nil {
 				return fmt.Errorf("list memories: %w", err)
 			}
+			memories = filter.Apply(memories)
 
 			exporter, ok := export.Get(strings.ToLower(format))
 			if !ok {
-				return fmt.Errorf("unknown format %q; valid formats: %s",
-					format, strings.Join(export.ValidFormats(), ", "))
+				return fmt.Errorf("unknown format %q; valid formats: %s, %s",
+					format, strings.Join(export.ValidFormats(), ", "), export.EmbeddingsFormat)
 			}
 
-			sessions, _ := store.GetLastNSessions(5)
+			gcfg, _ := config.Load(root)
+			sessions := export.RecentSessions(store, gcfg.AutoExport.Sessions)
 			gitState := gitpkg.CaptureWorkingState(root)
+			links, err := store.ListMemoryLinks()
+			if err != nil {
+				return fmt.Errorf("list memory links: %w", err)
+			}
 
-			output, err := exporter.Export(export.ExportData{
+			data := export.ExportData{
 				Project:  proj,
 				Stack:    ts,
 				Memories: memories,
 				Sessions: sessions,
 				GitState: gitState,
-			})
+				Links:    links,
+			}
+			// Relative dates read better in a terminal; redirected or
+			// copied output may be committed, so it keeps absolute ones.
+			if !toClipboard && term.IsTerminal(int(os.Stdout.Fd())) {
+				data.Now = time.Now()
+			}
+			output, err := exporter.Export(data)
 			if err != nil {
 				return fmt.Errorf("export: %w", err)
 			}
 
-			_, err = os.Stdout.WriteString(output)
-			return err
+			return writeOutput(output, toClipboard)
 		},
 	}
 
 	cmd.Flags().StringVar(&format, "format", "markdown",
-		"output format: claude, cursor, markdown")
+		"output format: claude, cursor, markdown, json, dot, embeddings")
 	cmd.Flags().StringVarP(&section, "section", "s", "",
 		"export only memories of this type: decision, convention, constraint, note, todo")
+	cmd.Flags().StringSliceVar(&types, "types", nil, "export only memories of these types, e.g. decision,constraint")
+	cmd.Flags().Float64Var(&minImp, "min-importance", 0, "export only memories at least this important (0-1)")
+	cmd.Flags().BoolVar(&toClipboard, "copy", false, "Also copy the output to the system clipboard")
+	cmd.Flags().BoolVar(&diffMode, "diff", false, "Show what regenerating export files would change; exit 1 if any are stale")
+	cmd.Flags().BoolVar(&preview, "preview", false, "Print the files auto-export would write, without writing them")
 
 	return cmd
 }
+
+// embeddingsWarnBytes is the estimated output size above which
+// `memvra export --format embeddings` warns before writing.
+const embeddingsWarnBytes = 100 << 20
+
+// exportEmbeddings streams the vectors stored for the configured embedding
+// model to stdout, warning first when the output will be large.
+func exportEmbeddings(root string, database *db.DB, store *memory.Store, memType memory.MemoryType, filter export.MemoryFilter) error {
+	gcfg, _ := config.Load(root)
+	model := gcfg.EmbeddingModelKey()
+	vectors := openVectorStore(database, gcfg)
+
+	counts, err := vectors.Models()
+	if err != nil {
+		return err
+	}
+	dim := vectors.Dimension()
+	if dim == 0 {
+		dim = db.DefaultEmbeddingDimension
+	}
+	for _, mc := range counts {
+		if mc.Model != model {
+			continue
+		}
+		n := mc.Memories
+		if memType == "" && len(filter.Types) == 0 {
+			n += mc.Chunks
+		}
+		if size := export.EstimateEmbeddingsSize(n, dim); size > embeddingsWarnBytes {
+			fmt.Fprintf(os.Stderr, "  warn: exporting %d vectors (about %d MB); pass --section to export only memories of one type\n",
+				n, size>>20)
+		}
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	n, err := export.WriteEmbeddings(out, store, vectors, export.EmbeddingsOptions{Model: model, MemoryType: memType, Filter: filter})
+	if err != nil {
+		return err
+	}
+	if err := out.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d embeddings for model %s.\n", n, model)
+	return nil
+}
+
+// previewExports prints every file auto-export would write, in filename
+// order, without touching the working tree.
+func previewExports(root string, store *memory.Store, toClipboard bool) error {
+	files, err := export.PreviewAutoExport(root, store)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no export formats configured; set formats under [auto_export]")
+	}
+
+	var b strings.Builder
+	for i, name := range slices.Sorted(maps.Keys(files)) {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "==> %s <==\n%s", name, files[name])
+		if !strings.HasSuffix(files[name], "\n") {
+			b.WriteString("\n")
+		}
+	}
+	if gcfg, _ := config.Load(root); !gcfg.AutoExport.Enabled {
+		fmt.Fprintln(os.Stderr, "  auto-export is off; set enabled = true under [auto_export] to write these files.")
+	}
+	return writeOutput(b.String(), toClipboard)
+}
+
+// diffExports renders the given formats in memory and prints a unified diff
+// against the files on disk. It returns an error if any file is out of date.
+func diffExports(root string, store *memory.Store, formats []string, cfg config.AutoExportConfig) error {
+	if len(formats) == 0 {
+		return fmt.Errorf("no export formats configured; pass --format")
+	}
+	for _, f := range formats {
+		if _, ok := export.Get(f); !ok {
+			return fmt.Errorf("unknown format %q; valid formats: %s", f, strings.Join(export.ValidFormats(), ", "))
+		}
+	}
+
+	files, err := export.RenderFiles(root, store, formats, cfg)
+	if err != nil {
+		return err
+	}
+
+	var stale []string
+	for _, f := range files {
+		current, err := os.ReadFile(filepath.Join(root, f.Filename))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("read %s: %w", f.Filename, err)
+		}
+		d := export.UnifiedDiff(f.Filename, f.Filename+" (regenerated)", string(current), f.Content)
+		if d == "" {
+			continue
+		}
+		fmt.Print(d)
+		stale = append(stale, f.Filename)
+	}
+
+	if len(stale) > 0 {
+		return fmt.Errorf("export files out of date: %s", strings.Join(stale, ", "))
+	}
+	fmt.Fprintln(os.Stderr, "Export files are up to date.")
+	return nil
+}
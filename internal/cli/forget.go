@@ -19,15 +19,25 @@ func newForgetCmd() *cobra.Command {
 	var all bool
 
 	cmd := &cobra.Command{
-		Use:   "forget",
+		Use:   "forget [id-prefix | content]",
 		Short: "Remove specific memories or reset all",
 		Long: `Remove stored memories from the project.
 
+A memory can be named by its full ID, a unique prefix of it, or its exact
+content. If several memories match, none is deleted and the candidates are
+listed.
+
 Examples:
-  memvra forget --id mem_abc123
+  memvra forget --id 3f9a1c
+  memvra forget "Use tabs for indentation"
   memvra forget --type todo
   memvra forget --all`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				memID = args[0]
+			}
+
 			root, err := findRoot()
 			if err != nil {
 				return err
@@ -70,21 +80,29 @@ Examples:
 				fmt.Printf("Deleted %d %s memories.\n", n, mt)
 
 			case memID != "":
-				if err := store.DeleteMemory(memID); err != nil {
+				m, err := store.FindMemory(memID)
+				if err != nil {
 					return err
 				}
-				fmt.Printf("Deleted memory %s.\n", memID)
+				if err := store.DeleteMemory(m.ID); err != nil {
+					return err
+				}
+				fmt.Printf("Deleted memory %s.\n", m.ID)
 
 			default:
 				// Interactive mode: list memories and let user choose.
-				return forgetInteractive(store)
+				if err := forgetInteractive(store); err != nil {
+					return err
+				}
 			}
 
+			// Reclaim space once enough has been deleted (best-effort).
+			_, _ = database.AutoVacuum()
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVar(&memID, "id", "", "Delete a specific memory by ID")
+	cmd.Flags().StringVar(&memID, "id", "", "Delete a specific memory by ID, unique ID prefix, or exact content")
 	cmd.Flags().StringVarP(&memType, "type", "t", "", "Delete all memories of this type")
 	cmd.Flags().BoolVar(&all, "all", false, "Delete all memories (requires confirmation)")
 
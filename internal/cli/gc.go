@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/memvra/memvra/internal/config"
+	"github.com/memvra/memvra/internal/db"
+	"github.com/memvra/memvra/internal/memory"
+)
+
+func newGCCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "gc",
+		Short: "Remove embeddings whose chunk or memory no longer exists",
+		Long: `Delete orphaned vectors from the embedding index.
+
+Chunks and memories removed outside Memvra's normal paths (for example by
+editing the database directly or by older versions) can leave their
+embeddings behind, where they keep taking up search slots. gc removes them
+for every embedding model. Run memvra compact afterwards to reclaim the
+space.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root, err := findRoot()
+			if err != nil {
+				return err
+			}
+			dbPath, err := ensureInitialized(root)
+			if err != nil {
+				return err
+			}
+
+			database, err := db.Open(dbPath)
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer func() { _ = database.Close() }()
+
+			gcfg, _ := config.Load(root)
+			store := memory.NewStore(database)
+			res, err := openVectorStore(database, gcfg).GarbageCollect(store)
+			if err != nil {
+				return fmt.Errorf("gc: %w", err)
+			}
+			fmt.Printf("Removed %d orphaned embeddings (%d chunks, %d memories)\n", res.Total(), res.Chunks, res.Memories)
+			return nil
+		},
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/memvra/memvra/internal/adapter"
 	"github.com/memvra/memvra/internal/config"
+	"github.com/memvra/memvra/internal/db"
 	"github.com/memvra/memvra/internal/memory"
 	"github.com/memvra/memvra/internal/scanner"
 )
@@ -19,46 +21,24 @@ const (
 	fileModified
 )
 
-// upsertScannedFile indexes a single scanned file: upserts the file record,
-// replaces its chunks, and returns the new file ID. force causes re-indexing
-// even if the content hash matches.
-func upsertScannedFile(store *memory.Store, sf scanner.ScannedFile, force bool) (fileID string, status fileStatus, err error) {
-	existing, lookupErr := store.GetFileByPath(sf.File.Path)
-
-	isNew := lookupErr != nil
-
-	if isNew || force {
-		fileID, err = store.UpsertFile(sf.File)
-		if err != nil {
-			return "", fileUnchanged, fmt.Errorf("upsert %s: %w", sf.File.Path, err)
-		}
-		_ = store.DeleteChunksByFileID(fileID)
-		for _, chunk := range sf.Chunks {
-			chunk.FileID = fileID
-			_ = store.InsertChunk(chunk)
-		}
-		if isNew {
-			return fileID, fileAdded, nil
-		}
-		return fileID, fileModified, nil
-	}
-
-	// Existing file — check hash.
-	if existing.ContentHash == sf.File.ContentHash {
-		return existing.ID, fileUnchanged, nil
-	}
-
-	// Content changed — re-chunk.
-	fileID, err = store.UpsertFile(sf.File)
+// upsertScannedFile indexes a single scanned file: upserts the file record
+// and, if its content hash changed, replaces its chunks and deletes the
+// embeddings of the old ones. force re-indexes even if the hash matches.
+func upsertScannedFile(store *memory.Store, vectors *memory.VectorStore, sf scanner.ScannedFile, force bool) (fileID string, status fileStatus, err error) {
+	res, err := store.IndexFile(sf.File, sf.Chunks, force)
 	if err != nil {
 		return "", fileUnchanged, fmt.Errorf("upsert %s: %w", sf.File.Path, err)
 	}
-	_ = store.DeleteChunksByFileID(fileID)
-	for _, chunk := range sf.Chunks {
-		chunk.FileID = fileID
-		_ = store.InsertChunk(chunk)
+	for _, id := range res.StaleChunkIDs {
+		_ = vectors.DeleteChunkEmbedding(id)
+	}
+	switch {
+	case res.Added:
+		return res.FileID, fileAdded, nil
+	case res.Changed:
+		return res.FileID, fileModified, nil
 	}
-	return fileID, fileModified, nil
+	return res.FileID, fileUnchanged, nil
 }
 
 // pruneDeletedFile removes a file and its vector embeddings from the store.
@@ -70,47 +50,65 @@ func pruneDeletedFile(store *memory.Store, vectors *memory.VectorStore, fileID s
 	_ = store.DeleteFile(fileID)
 }
 
-// embedFileChunks generates embeddings for all chunks of the given file IDs.
-// Returns the count of chunks successfully embedded.
-func embedFileChunks(ctx context.Context, store *memory.Store, vectors *memory.VectorStore, embedder interface {
-	Embed(ctx context.Context, texts []string) ([][]float32, error)
-}, fileIDs []string) int {
-	embeddedCount := 0
-	const batchSize = 32
-
+// embedFileChunks generates embeddings for all chunks of the given file IDs
+// on the worker pool described by opts. Returns the count of chunks
+// successfully embedded.
+func embedFileChunks(ctx context.Context, store *memory.Store, vectors *memory.VectorStore, opts memory.EmbedOptions, embedder adapter.Embedder, fileIDs []string) int {
+	var chunks []memory.Chunk
 	for _, fileID := range fileIDs {
-		chunks, err := store.ListChunksByFileID(fileID)
-		if err != nil || len(chunks) == 0 {
+		fileChunks, err := store.ListChunksByFileID(fileID)
+		if err != nil {
 			continue
 		}
+		chunks = append(chunks, fileChunks...)
+	}
+	// Best-effort: chunks embedded before a failure are kept.
+	n, _ := memory.EmbedChunks(ctx, embedder, vectors, chunks, opts)
+	return n
+}
 
-		for i := 0; i < len(chunks); i += batchSize {
-			end := i + batchSize
-			if end > len(chunks) {
-				end = len(chunks)
-			}
-			batch := chunks[i:end]
-
-			texts := make([]string, len(batch))
-			for j, c := range batch {
-				texts[j] = c.Content
-			}
-
-			vecs, err := embedder.Embed(ctx, texts)
-			if err != nil {
-				break
+// embedOptions returns the indexing pipeline settings for gcfg. With
+// strip_boilerplate on, code chunks are embedded without their license
+// header and imports, by the language of the file they came from.
+func embedOptions(gcfg config.GlobalConfig, store *memory.Store) memory.EmbedOptions {
+	opts := memory.EmbedOptions{
+		Model:   gcfg.EmbeddingModelKey(),
+		Workers: gcfg.Embedding.Workers,
+	}
+	if gcfg.Embedding.StripBoilerplate {
+		languages := make(map[string]string)
+		if files, err := store.ListFiles(); err == nil {
+			for _, f := range files {
+				languages[f.ID] = f.Language
 			}
-			for j, vec := range vecs {
-				if j >= len(batch) {
-					break
-				}
-				if err := vectors.UpsertChunkEmbedding(batch[j].ID, vec); err == nil {
-					embeddedCount++
-				}
+		}
+		opts.Text = func(c memory.Chunk) string {
+			if c.ChunkType != "code" && c.ChunkType != "test" {
+				return c.Content
 			}
+			return memory.StripBoilerplate(c.Content, languages[c.FileID])
 		}
 	}
-	return embeddedCount
+	return opts
+}
+
+// withEmbeddingCache wraps embedder in the persistent embedding cache so
+// chunks whose text was embedded before are not sent to the provider again.
+// Returns embedder unchanged when it is nil or noCache is set.
+func withEmbeddingCache(embedder adapter.Embedder, store *memory.Store, gcfg config.GlobalConfig, noCache bool) adapter.Embedder {
+	if embedder == nil || noCache {
+		return embedder
+	}
+	return memory.NewCachedEmbedder(embedder, store, gcfg.EmbeddingModelKey())
+}
+
+// openVectorStore returns the project's vector store. Embeddings written
+// before vectors were keyed by model are adopted under the configured model.
+func openVectorStore(database *db.DB, gcfg config.GlobalConfig) *memory.VectorStore {
+	if n, err := database.AdoptLegacyVectors(gcfg.EmbeddingModelKey()); err == nil && n > 0 {
+		fmt.Fprintf(os.Stderr, "Migrated %d embeddings to model %s.\n", n, gcfg.EmbeddingModelKey())
+	}
+	return memory.NewVectorStore(database)
 }
 
 // refreshProjectCounts updates the file and chunk counts on the project record.
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/memvra/memvra/internal/db"
+	"github.com/memvra/memvra/internal/memory"
+	"github.com/memvra/memvra/internal/scanner"
+)
+
+func TestUpsertScannedFile_ReplacesStaleChunkEmbeddings(t *testing.T) {
+	database, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("db.Open: %v", err)
+	}
+	defer database.Close()
+	store := memory.NewStore(database)
+	vectors := memory.NewVectorStore(database)
+	const model = "test:model"
+
+	scanned := func(hash, content string) scanner.ScannedFile {
+		return scanner.ScannedFile{
+			File:   memory.File{Path: "main.go", Language: "go", LastModified: time.Now(), ContentHash: hash},
+			Chunks: []memory.Chunk{{Content: content, StartLine: 1, EndLine: 1, ChunkType: "code"}},
+		}
+	}
+	embedAll := func() {
+		chunks, _ := store.ListAllChunks()
+		for _, c := range chunks {
+			vec := make([]float32, db.DefaultEmbeddingDimension)
+			vec[0] = 1
+			if err := vectors.UpsertChunkEmbedding(model, c.ID, vec); err != nil {
+				t.Fatalf("UpsertChunkEmbedding: %v", err)
+			}
+		}
+	}
+	embeddedIDs := func() map[string]bool {
+		ids := map[string]bool{}
+		_ = vectors.EachChunkEmbedding(model, func(e memory.StoredEmbedding) error {
+			ids[e.ID] = true
+			return nil
+		})
+		return ids
+	}
+
+	fileID, status, err := upsertScannedFile(store, vectors, scanned("h1", "package main"), false)
+	if err != nil || status != fileAdded {
+		t.Fatalf("first index: status %v, err %v", status, err)
+	}
+	embedAll()
+	before, _ := store.ListChunksByFileID(fileID)
+
+	// Same hash: nothing is rewritten and the embedding survives.
+	if _, status, _ := upsertScannedFile(store, vectors, scanned("h1", "package main"), false); status != fileUnchanged {
+		t.Fatalf("unchanged file: got status %v", status)
+	}
+	if after, _ := store.ListChunksByFileID(fileID); len(after) != 1 || after[0].ID != before[0].ID {
+		t.Fatalf("unchanged file should keep its chunks, got %+v", after)
+	}
+	if !embeddedIDs()[before[0].ID] {
+		t.Fatal("unchanged file lost its chunk embedding")
+	}
+
+	// Changed content: old chunk and its embedding are gone.
+	newID, status, err := upsertScannedFile(store, vectors, scanned("h2", "package main\n\nfunc main() {}"), false)
+	if err != nil || status != fileModified || newID != fileID {
+		t.Fatalf("changed file: id %q status %v err %v", newID, status, err)
+	}
+	after, _ := store.ListChunksByFileID(fileID)
+	if len(after) != 1 || after[0].ID == before[0].ID {
+		t.Fatalf("expected a fresh chunk, got %+v", after)
+	}
+	if embeddedIDs()[before[0].ID] {
+		t.Error("stale chunk embedding was not deleted")
+	}
+	if n, _ := store.CountChunks(); n != 1 {
+		t.Errorf("expected 1 chunk after re-index, got %d", n)
+	}
+}
@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/memvra/memvra/internal/config"
+	"github.com/memvra/memvra/internal/db"
+)
+
+// projectInfo is the output of `memvra info`. Its JSON field names are a
+// stable interface for scripts; add fields rather than renaming them.
+type projectInfo struct {
+	Version          string         `json:"version"`
+	Root             string         `json:"root"`
+	Initialized      bool           `json:"initialized"`
+	DBPath           string         `json:"db_path"`
+	SchemaVersion    *int           `json:"schema_version"` // nil until initialized
+	LatestSchema     int            `json:"latest_schema_version"`
+	ConfigPath       string         `json:"config_path"`
+	GlobalConfigPath string         `json:"global_config_path"`
+	Embedder         embedderInfo   `json:"embedder"`
+	AutoExport       autoExportInfo `json:"auto_export"`
+}
+
+type embedderInfo struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	Key      string `json:"key"` // "provider:model", as vectors are keyed
+}
+
+type autoExportInfo struct {
+	Enabled       bool     `json:"enabled"`
+	Formats       []string `json:"formats"`
+	MinImportance float64  `json:"min_importance"`
+	Sessions      int      `json:"sessions"`
+}
+
+func newInfoCmd() *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "info",
+		Short: "Show where Memvra keeps this project's data and how it is configured",
+		Long: `Print the resolved project root, database path, schema version, embedder
+and auto-export settings. Nothing is created or migrated.
+
+Use --json for a stable machine-readable form, e.g. for scripts that need
+the database location.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root, err := findRoot()
+			if err != nil {
+				return err
+			}
+			info, err := collectInfo(root)
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(info)
+			}
+			printInfo(info)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print as JSON")
+	return cmd
+}
+
+// collectInfo resolves paths and settings for the project at root without
+// touching the database beyond a read-only schema query.
+func collectInfo(root string) (projectInfo, error) {
+	cfg, err := config.Load(root)
+	if err != nil {
+		return projectInfo{}, fmt.Errorf("load config: %w", err)
+	}
+	globalPath, err := config.GlobalConfigPath()
+	if err != nil {
+		return projectInfo{}, err
+	}
+
+	provider, model := cfg.EmbeddingSettings()
+	formats := cfg.AutoExport.Formats
+	if formats == nil {
+		formats = []string{}
+	}
+	info := projectInfo{
+		Version:          version,
+		Root:             root,
+		DBPath:           config.ProjectDBPath(root),
+		LatestSchema:     db.LatestSchemaVersion(),
+		ConfigPath:       config.ProjectConfigPath(root),
+		GlobalConfigPath: globalPath,
+		Embedder: embedderInfo{
+			Provider: provider,
+			Model:    model,
+			Key:      cfg.EmbeddingModelKey(),
+		},
+		AutoExport: autoExportInfo{
+			Enabled:       cfg.AutoExport.Enabled,
+			Formats:       formats,
+			MinImportance: cfg.AutoExport.MinImportance,
+			Sessions:      cfg.AutoExport.Sessions,
+		},
+	}
+
+	if _, err := os.Stat(info.DBPath); err == nil {
+		info.Initialized = true
+		v, err := db.SchemaVersion(info.DBPath)
+		if err != nil {
+			return projectInfo{}, err
+		}
+		info.SchemaVersion = &v
+	}
+	return info, nil
+}
+
+func printInfo(info projectInfo) {
+	fmt.Printf("\nRoot:        %s\n", info.Root)
+	if info.Initialized {
+		fmt.Printf("Database:    %s\n", info.DBPath)
+		schema := fmt.Sprintf("%d", *info.SchemaVersion)
+		if *info.SchemaVersion < info.LatestSchema {
+			schema += fmt.Sprintf(" (migrates to %d on next use)", info.LatestSchema)
+		}
+		fmt.Printf("Schema:      %s\n", schema)
+	} else {
+		fmt.Printf("Database:    %s (not initialized — run `memvra init`)\n", info.DBPath)
+	}
+	fmt.Printf("Config:      %s\n", info.ConfigPath)
+	fmt.Printf("Global:      %s\n", info.GlobalConfigPath)
+	fmt.Printf("Embedder:    %s\n", info.Embedder.Key)
+	if info.AutoExport.Enabled {
+		fmt.Printf("Auto-export: %s\n", strings.Join(info.AutoExport.Formats, ", "))
+	} else {
+		fmt.Println("Auto-export: off")
+	}
+	fmt.Println()
+}
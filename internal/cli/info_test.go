@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/memvra/memvra/internal/config"
+	"github.com/memvra/memvra/internal/db"
+)
+
+func TestCollectInfo(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+
+	info, err := collectInfo(root)
+	if err != nil {
+		t.Fatalf("collectInfo: %v", err)
+	}
+	if info.Initialized || info.SchemaVersion != nil {
+		t.Errorf("expected uninitialized project, got %+v", info)
+	}
+	if info.DBPath != config.ProjectDBPath(root) {
+		t.Errorf("db path: got %q, want %q", info.DBPath, config.ProjectDBPath(root))
+	}
+	if _, err := os.Stat(info.DBPath); !os.IsNotExist(err) {
+		t.Error("collectInfo must not create the database")
+	}
+
+	database, err := db.Open(config.ProjectDBPath(root))
+	if err != nil {
+		t.Fatalf("db.Open: %v", err)
+	}
+	database.Close()
+
+	info, err = collectInfo(root)
+	if err != nil {
+		t.Fatalf("collectInfo: %v", err)
+	}
+	if !info.Initialized || info.SchemaVersion == nil || *info.SchemaVersion != db.LatestSchemaVersion() {
+		t.Errorf("expected initialized project at latest schema, got %+v", info)
+	}
+	if info.Embedder.Key != "ollama:nomic-embed-text" {
+		t.Errorf("embedder key: got %q", info.Embedder.Key)
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
 
 	"github.com/memvra/memvra/internal/adapter"
@@ -21,12 +20,16 @@ import (
 func newInitCmd() *cobra.Command {
 	var projectRoot string
 	var skipPrompt bool
+	var noCache bool
 
 	cmd := &cobra.Command{
 		Use:   "init",
 		Short: "Initialize Memvra in the current project",
 		Long: `Scan the project directory, detect the tech stack, chunk source files,
-and set up the .memvra/ directory with a SQLite database and config.`,
+and set up the .memvra/ directory with a SQLite database and config.
+
+Safe to re-run: the index is refreshed, while an existing .memvra/config.toml
+and stored memories are left untouched.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Determine project root.
 			root := projectRoot
@@ -42,24 +45,26 @@ and set up the .memvra/ directory with a SQLite database and config.`,
 			}
 			root, _ = filepath.Abs(root)
 
+			// Re-running init refreshes the index but never clobbers a
+			// config the user may have edited.
+			_, statErr := os.Stat(config.ProjectConfigPath(root))
+			reinit := statErr == nil
+
 			fmt.Println("Scanning project...")
 
-			// Load any existing global config for scan options.
-			gcfg, _ := config.LoadGlobal()
+			// Load any existing config (global + project) for scan options.
+			gcfg, _ := config.Load(root)
+			pcfg, _ := config.LoadProject(root)
 
 			// Run the scanner.
+			bar := newProgressBar("Scanning files", -1)
 			scanOpts := scanner.ScanOptions{
 				Root:          root,
 				MaxChunkLines: gcfg.Context.ChunkMaxLines,
+				ExcludeGlobs:  scanExcludes(pcfg),
+				Progress:      progressTo(bar),
 			}
 
-			bar := progressbar.NewOptions(-1,
-				progressbar.OptionSetDescription("  Indexing files"),
-				progressbar.OptionSpinnerType(14),
-				progressbar.OptionSetWriter(os.Stderr),
-				progressbar.OptionClearOnFinish(),
-			)
-
 			result := scanner.Scan(scanOpts)
 			_ = bar.Finish()
 
@@ -82,24 +87,22 @@ and set up the .memvra/ directory with a SQLite database and config.`,
 			defer func() { _ = database.Close() }()
 
 			store := memory.NewStore(database)
+			store.SetChunkCompression(gcfg.Storage.CompressChunks)
 
-			// Persist all files and chunks.
+			vectors := openVectorStore(database, gcfg)
+
+			// Persist all files and chunks. On re-init, unchanged files keep
+			// their chunks and embeddings.
+			indexBar := newProgressBar("Indexing files", len(result.Files))
 			for _, sf := range result.Files {
-				fileID, err := store.UpsertFile(sf.File)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "  Warning: could not index %s: %v\n", sf.File.Path, err)
-					continue
-				}
-				// Re-index: remove old chunks then insert new ones.
-				_ = store.DeleteChunksByFileID(fileID)
-				for _, chunk := range sf.Chunks {
-					chunk.FileID = fileID
-					if err := store.InsertChunk(chunk); err != nil {
-						fmt.Fprintf(os.Stderr, "  Warning: chunk error for %s: %v\n", sf.File.Path, err)
-					}
+				_ = indexBar.Add(1)
+				if _, _, err := upsertScannedFile(store, vectors, sf, false); err != nil {
+					fmt.Fprintf(os.Stderr, "  Warning: %v\n", err)
 				}
 			}
 
+			_ = indexBar.Finish()
+
 			fileCount, _ := store.CountFiles()
 			chunkCount, _ := store.CountChunks()
 
@@ -119,16 +122,12 @@ and set up the .memvra/ directory with a SQLite database and config.`,
 
 			// --- Embedding phase ---
 			// Build embedder from config; skip silently if unavailable or unconfigured.
-			embedder := buildEmbedder(gcfg)
-			vectors := memory.NewVectorStore(database)
+			embedder := withEmbeddingCache(buildEmbedder(gcfg), store, gcfg, noCache)
 			if embedder != nil {
-				embBar := progressbar.NewOptions(-1,
-					progressbar.OptionSetDescription("  Generating embeddings"),
-					progressbar.OptionSpinnerType(14),
-					progressbar.OptionSetWriter(os.Stderr),
-					progressbar.OptionClearOnFinish(),
-				)
-				embeddedCount, embErr := embedAllChunks(context.Background(), store, vectors, embedder)
+				embBar := newProgressBar("Generating embeddings", -1)
+				opts := embedOptions(gcfg, store)
+				opts.Progress = progressTo(embBar)
+				embeddedCount, embErr := embedAllChunks(context.Background(), store, vectors, opts, embedder)
 				_ = embBar.Finish()
 				if embErr != nil {
 					// Connection-refused means the embedder (e.g. Ollama) isn't running.
@@ -168,19 +167,18 @@ and set up the .memvra/ directory with a SQLite database and config.`,
 						// Embed the memory too (best-effort).
 						if embedder != nil {
 							if vecs, err := embedder.Embed(context.Background(), []string{line}); err == nil && len(vecs) > 0 {
-								_ = vectors.UpsertMemoryEmbedding(id, vecs[0])
+								_ = vectors.UpsertMemoryEmbedding(gcfg.EmbeddingModelKey(), id, vecs[0])
 							}
 						}
 					}
 				}
 			}
 
-			// Write project config.
-			pcfg := config.ProjectConfig{
-				Project: config.ProjectMeta{Name: result.Stack.ProjectName},
-			}
-			if err := config.SaveProject(root, pcfg); err != nil {
-				fmt.Fprintf(os.Stderr, "  Warning: could not write project config: %v\n", err)
+			// Write the default project config on first run only.
+			if !reinit {
+				if err := config.SaveProject(root, config.DefaultProject(result.Stack.ProjectName)); err != nil {
+					fmt.Fprintf(os.Stderr, "  Warning: could not write project config: %v\n", err)
+				}
 			}
 
 			// Ensure .memvra/ and auto-export files are in .gitignore.
@@ -190,19 +188,36 @@ and set up the .memvra/ directory with a SQLite database and config.`,
 			AutoExport(root, store)
 
 			fmt.Println()
-			fmt.Println("Memvra initialized. Project context saved to .memvra/")
-			fmt.Println(`Tip: Run "memvra status" to see your project profile.`)
+			if reinit {
+				fmt.Println("Memvra re-initialized. Index refreshed; existing config and memories kept.")
+			} else {
+				fmt.Println("Memvra initialized. Project context saved to .memvra/")
+			}
+			fmt.Println()
+			fmt.Println("Next steps:")
+			fmt.Println(`  memvra status                 See the detected project profile`)
+			fmt.Println(`  memvra remember "<fact>"      Store a decision, convention, or constraint`)
+			fmt.Println(`  memvra ask "<question>"       Ask with project context injected`)
+			fmt.Println(`  Edit .memvra/config.toml      Turn on [auto_export] to keep CLAUDE.md in sync`)
 			return nil
 		},
 	}
 
 	cmd.Flags().StringVarP(&projectRoot, "root", "r", "", "Project root directory (default: auto-detect from cwd)")
 	cmd.Flags().BoolVar(&skipPrompt, "no-prompt", false, "Skip the interactive notes prompt")
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "Bypass the embedding cache and re-embed every chunk")
 
 	return cmd
 }
 
 func describeStack(ts scanner.TechStack) string {
+	if len(ts.Stacks) > 0 {
+		parts := make([]string, 0, len(ts.Stacks))
+		for _, ns := range ts.Stacks {
+			parts = append(parts, ns.Name+": "+describeStack(ns.TechStack))
+		}
+		return strings.Join(parts, ", ")
+	}
 	if ts.Framework != "" && ts.Language != "" {
 		return ts.Framework + " (" + ts.Language + ")"
 	}
@@ -212,47 +227,50 @@ func describeStack(ts scanner.TechStack) string {
 	return ts.ProjectName
 }
 
-// buildEmbedder constructs an Embedder from the global config.
-// Returns nil if no embedder is configured or available.
+// buildEmbedder constructs the configured Embedder via the adapter registry,
+// wrapped with retries and a circuit breaker so transient provider errors
+// don't abort indexing. Returns nil if the provider is unknown.
 func buildEmbedder(gcfg config.GlobalConfig) adapter.Embedder {
-	name := gcfg.DefaultEmbedder
-	if name == "" {
-		name = "ollama"
-	}
-	var apiKey string
-	if name == adapter.ProviderOpenAI {
-		apiKey = gcfg.Keys.OpenAI
-	}
-	emb, err := adapter.New(name, gcfg.Ollama.EmbedModel, apiKey, gcfg.Ollama.Host)
+	name, model := gcfg.EmbeddingSettings()
+	emb, err := adapter.NewEmbedder(name, adapter.EmbedderOptions{
+		Model:     model,
+		APIKey:    apiKey(gcfg, name),
+		Host:      gcfg.Ollama.Host,
+		RateLimit: gcfg.Embedding.RateLimit,
+	})
 	if err != nil {
+		fmt.Fprintf(os.Stderr, "  warn: %v\n", err)
 		return nil
 	}
-	return emb
+	return adapter.NewRetryingEmbedder(emb, adapter.RetryOptions{})
 }
 
-// embedAllChunks fetches every chunk from the store and batch-embeds them,
-// writing the resulting vectors into vec_chunks. Returns the number embedded.
-func embedAllChunks(ctx context.Context, store *memory.Store, vectors *memory.VectorStore, embedder adapter.Embedder) (int, error) {
+// embedAllChunks fetches every chunk from the store and embeds them on the
+// worker pool described by opts. Returns the number embedded.
+func embedAllChunks(ctx context.Context, store *memory.Store, vectors *memory.VectorStore, opts memory.EmbedOptions, embedder adapter.Embedder) (int, error) {
 	chunks, err := store.ListAllChunks()
 	if err != nil {
 		return 0, fmt.Errorf("list chunks: %w", err)
 	}
-	if len(chunks) == 0 {
-		return 0, nil
+	return memory.EmbedChunks(ctx, embedder, vectors, chunks, opts)
+}
+
+// embedAllMemories embeds every stored memory under model. Returns the
+// number embedded.
+func embedAllMemories(ctx context.Context, store *memory.Store, vectors *memory.VectorStore, model string, embedder adapter.Embedder) (int, error) {
+	mems, err := store.ListMemories("")
+	if err != nil {
+		return 0, fmt.Errorf("list memories: %w", err)
 	}
 
-	const batchSize = 32
+	const batchSize = memory.DefaultEmbedBatchSize
 	embedded := 0
-	for i := 0; i < len(chunks); i += batchSize {
-		end := i + batchSize
-		if end > len(chunks) {
-			end = len(chunks)
-		}
-		batch := chunks[i:end]
+	for i := 0; i < len(mems); i += batchSize {
+		batch := mems[i:min(i+batchSize, len(mems))]
 
 		texts := make([]string, len(batch))
-		for j, c := range batch {
-			texts[j] = c.Content
+		for j, m := range batch {
+			texts[j] = m.Content
 		}
 
 		vecs, err := embedder.Embed(ctx, texts)
@@ -264,14 +282,12 @@ func embedAllChunks(ctx context.Context, store *memory.Store, vectors *memory.Ve
 			if j >= len(batch) {
 				break
 			}
-			if err := vectors.UpsertChunkEmbedding(batch[j].ID, vec); err != nil {
-				// Non-fatal: log and continue.
+			if err := vectors.UpsertMemoryEmbedding(model, batch[j].ID, vec); err != nil {
 				continue
 			}
 			embedded++
 		}
 	}
-
 	return embedded, nil
 }
 
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/memvra/memvra/internal/db"
+	"github.com/memvra/memvra/internal/memory"
+)
+
+func newLinkCmd() *cobra.Command {
+	var linkType string
+
+	cmd := &cobra.Command{
+		Use:   "link <from> <to>",
+		Short: "Record how one memory relates to another",
+		Long: `Link two memories, each named by its full ID, a unique ID prefix, or its
+exact content. The link is directed: <from> supersedes or relates to <to>.
+
+Links are drawn by ` + "`memvra export --format dot`" + ` and removed with
+either memory.
+
+Examples:
+  memvra link 3f9a1c 8b2e07                  # 3f9a1c supersedes 8b2e07
+  memvra link 3f9a1c 51d4aa --type relates_to`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lt := memory.LinkType(strings.ToLower(linkType))
+			if !memory.ValidLinkType(lt) {
+				return fmt.Errorf("unknown link type %q (valid: %s, %s)", linkType, memory.LinkSupersedes, memory.LinkRelatesTo)
+			}
+
+			root, err := findRoot()
+			if err != nil {
+				return err
+			}
+			dbPath, err := ensureInitialized(root)
+			if err != nil {
+				return err
+			}
+
+			database, err := db.Open(dbPath)
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer func() { _ = database.Close() }()
+
+			store := memory.NewStore(database)
+			from, err := store.FindMemory(args[0])
+			if err != nil {
+				return err
+			}
+			to, err := store.FindMemory(args[1])
+			if err != nil {
+				return err
+			}
+			if err := store.LinkMemories(from.ID, to.ID, lt); err != nil {
+				return err
+			}
+			fmt.Printf("Linked %s %s %s.\n", from.ID, lt, to.ID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&linkType, "type", "t", string(memory.LinkSupersedes), "link type: supersedes, relates_to")
+
+	return cmd
+}
@@ -5,10 +5,14 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/memvra/memvra/internal/config"
 	mcppkg "github.com/memvra/memvra/internal/mcp"
 )
 
 func newMCPCmd() *cobra.Command {
+	var tools []string
+	var readOnly bool
+
 	cmd := &cobra.Command{
 		Use:   "mcp",
 		Short: "Start the MCP (Model Context Protocol) server",
@@ -19,7 +23,14 @@ to this server to automatically save progress, store decisions, and
 retrieve project context — without you running any commands.
 
 To register Memvra with your AI tools, run:
-  memvra mcp install`,
+  memvra mcp install
+
+Use --tools (or tools under [mcp] in the config) to expose only some
+tools, e.g. a read-only set without memvra_forget:
+  memvra mcp --tools memvra_get_context,memvra_search,memvra_list_memories
+
+Use --read-only to open the database without write access; tools that
+save, remember or forget are then not offered at all.`,
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			root, err := findRoot()
@@ -27,16 +38,30 @@ To register Memvra with your AI tools, run:
 				return fmt.Errorf("no Memvra project found: %w", err)
 			}
 
-			srv, err := mcppkg.NewServer(root)
+			if !cmd.Flags().Changed("tools") {
+				gcfg, _ := config.Load(root)
+				tools = gcfg.MCP.Tools
+			}
+
+			newServer := mcppkg.NewServer
+			if readOnly {
+				newServer = mcppkg.NewReadOnlyServer
+			}
+			srv, err := newServer(root)
 			if err != nil {
 				return fmt.Errorf("start MCP server: %w", err)
 			}
 			defer srv.Close()
 
+			if err := srv.SetTools(tools); err != nil {
+				return err
+			}
 			return srv.Run()
 		},
 	}
 
+	cmd.Flags().StringSliceVar(&tools, "tools", nil, "comma-separated tools to expose (default: all, or [mcp] tools from config)")
+	cmd.Flags().BoolVar(&readOnly, "read-only", false, "open the database read-only and hide tools that write")
 	cmd.AddCommand(newMCPInstallCmd())
 	return cmd
 }
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"os"
+	"time"
+
+	"github.com/schollz/progressbar/v3"
+
+	"github.com/memvra/memvra/internal/memory"
+)
+
+// newProgressBar returns a progress bar on stderr for total steps, or a
+// spinner with a running count when total is -1. It is cleared on Finish;
+// extra options are applied after the defaults.
+func newProgressBar(description string, total int, extra ...progressbar.Option) *progressbar.ProgressBar {
+	opts := []progressbar.Option{
+		progressbar.OptionSetDescription("  " + description),
+		progressbar.OptionSpinnerType(14),
+		progressbar.OptionSetWriter(os.Stderr),
+		progressbar.OptionShowCount(),
+		progressbar.OptionThrottle(100 * time.Millisecond),
+		progressbar.OptionClearOnFinish(),
+	}
+	return progressbar.NewOptions(total, append(opts, extra...)...)
+}
+
+// progressTo returns a ProgressFunc that drives bar.
+func progressTo(bar *progressbar.ProgressBar) memory.ProgressFunc {
+	return func(done, total int) {
+		if total > 0 && int64(total) != bar.GetMax64() {
+			bar.ChangeMax(total)
+		}
+		_ = bar.Set(done)
+	}
+}
@@ -11,6 +11,10 @@ import (
 	"github.com/memvra/memvra/internal/scanner"
 )
 
+// defaultPruneKeep is how many sessions `memvra prune` keeps when neither
+// flags nor [retention] give a policy.
+const defaultPruneKeep = 100
+
 func newPruneCmd() *cobra.Command {
 	var (
 		olderThanDays int
@@ -23,12 +27,17 @@ func newPruneCmd() *cobra.Command {
 		Short: "Remove old sessions to reduce database size",
 		Long: `Prune old session records from the .memvra database.
 
-By default, keeps the latest 100 sessions. Use flags to customise:
+By default, applies the [retention] policy from the config (keep_last_n,
+keep_days), or keeps the latest 100 sessions if none is set. Use flags to
+customise:
 
-  memvra prune                    # keep latest 100 sessions
+  memvra prune                    # apply [retention], or keep latest 100
   memvra prune --older-than 30    # delete sessions older than 30 days
   memvra prune --keep 50          # keep only the latest 50 sessions
-  memvra prune --dry-run          # preview what would be deleted`,
+  memvra prune --dry-run          # preview what would be deleted
+
+With both flags, a session is deleted only when it is outside both limits.
+In-progress and blocked sessions are always kept.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			root, err := scanner.FindProjectRoot(".")
 			if err != nil {
@@ -44,36 +53,45 @@ By default, keeps the latest 100 sessions. Use flags to customise:
 
 			store := memory.NewStore(database)
 
+			policy := memory.RetentionPolicy{KeepLastN: keepLatest, KeepDays: olderThanDays}
+			if policy.IsZero() {
+				gcfg, _ := config.Load(root)
+				policy = memory.RetentionPolicy(gcfg.Retention)
+			}
+			if policy.IsZero() {
+				policy.KeepLastN = defaultPruneKeep
+			}
+
 			before, _ := store.CountSessions()
 
 			if dryRun {
 				fmt.Printf("Current sessions: %d\n", before)
-				if olderThanDays > 0 {
-					fmt.Printf("Would delete sessions older than %d days\n", olderThanDays)
-				} else {
-					fmt.Printf("Would keep latest %d sessions\n", keepLatest)
+				if policy.KeepLastN > 0 {
+					fmt.Printf("Would keep latest %d sessions\n", policy.KeepLastN)
+				}
+				if policy.KeepDays > 0 {
+					fmt.Printf("Would keep sessions from the last %d days\n", policy.KeepDays)
 				}
+				fmt.Println("In-progress and blocked sessions are always kept")
 				return nil
 			}
 
-			var pruned int
-			if olderThanDays > 0 {
-				pruned, err = store.PruneSessions(olderThanDays)
-			} else {
-				pruned, err = store.PruneSessionsKeepLatest(keepLatest)
-			}
+			pruned, err := store.PruneSessionsByPolicy(policy)
 			if err != nil {
 				return err
 			}
 
 			after, _ := store.CountSessions()
 			fmt.Printf("Pruned %d sessions (%d → %d)\n", pruned, before, after)
+
+			// Reclaim space once enough has been deleted (best-effort).
+			_, _ = database.AutoVacuum()
 			return nil
 		},
 	}
 
-	cmd.Flags().IntVar(&olderThanDays, "older-than", 0, "Delete sessions older than N days")
-	cmd.Flags().IntVar(&keepLatest, "keep", 100, "Keep only the latest N sessions")
+	cmd.Flags().IntVar(&olderThanDays, "older-than", 0, "Delete sessions older than N days (default: [retention] keep_days)")
+	cmd.Flags().IntVar(&keepLatest, "keep", 0, "Keep only the latest N sessions (default: [retention] keep_last_n, else 100)")
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview what would be pruned without deleting")
 
 	return cmd
@@ -9,12 +9,14 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/memvra/memvra/internal/config"
+	ctxpkg "github.com/memvra/memvra/internal/context"
 	"github.com/memvra/memvra/internal/db"
 	"github.com/memvra/memvra/internal/memory"
 )
 
 func newRememberCmd() *cobra.Command {
 	var memType string
+	var global bool
 
 	cmd := &cobra.Command{
 		Use:   "remember <statement>",
@@ -24,11 +26,31 @@ func newRememberCmd() *cobra.Command {
 Examples:
   memvra remember "We switched from Devise to custom JWT auth"
   memvra remember "All background jobs must be idempotent" --type constraint
-  memvra remember "TODO: Add rate limiting to document upload endpoint"`,
+  memvra remember "TODO: Add rate limiting to document upload endpoint"
+
+With --global, the memory goes to ~/.memvra/global.db instead and is added
+to the system prompt of every project (conventions and constraints by
+default). A project memory on the same topic takes precedence:
+  memvra remember "Prefer table-driven tests" --type convention --global`,
 		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			statement := strings.Join(args, " ")
 
+			// Determine memory type.
+			var mt memory.MemoryType
+			if memType != "" {
+				mt = memory.MemoryType(strings.ToLower(memType))
+				if !memory.ValidMemoryType(mt) {
+					return fmt.Errorf("unknown memory type %q (valid: %s)", memType, memory.TypeNames())
+				}
+			} else {
+				mt = memory.ClassifyMemoryType(statement)
+			}
+
+			if global {
+				return rememberGlobal(statement, mt)
+			}
+
 			root, err := findRoot()
 			if err != nil {
 				return err
@@ -47,27 +69,11 @@ Examples:
 
 			store := memory.NewStore(database)
 
-			// Determine memory type.
-			var mt memory.MemoryType
-			if memType != "" {
-				mt = memory.MemoryType(strings.ToLower(memType))
-				if !memory.ValidMemoryType(mt) {
-					return fmt.Errorf("unknown memory type %q (valid: decision, convention, constraint, note, todo)", memType)
-				}
-			} else {
-				mt = memory.ClassifyMemoryType(statement)
-			}
-
 			m := memory.Memory{
 				Content:    statement,
 				MemoryType: mt,
 				Source:     "user",
-				Importance: 0.6,
-			}
-
-			// Decisions and constraints are slightly more important.
-			if mt == memory.TypeDecision || mt == memory.TypeConstraint {
-				m.Importance = 0.8
+				Importance: memory.ImportanceFor(importanceDefaults(root), mt),
 			}
 
 			id, err := store.InsertMemory(m)
@@ -76,11 +82,11 @@ Examples:
 			}
 
 			// Embed the memory (best-effort — non-fatal on failure).
-			gcfg, _ := config.LoadGlobal()
+			gcfg, _ := config.Load(root)
 			if embedder := buildEmbedder(gcfg); embedder != nil {
-				vectors := memory.NewVectorStore(database)
+				vectors := openVectorStore(database, gcfg)
 				if vecs, embErr := embedder.Embed(context.Background(), []string{statement}); embErr == nil && len(vecs) > 0 {
-					_ = vectors.UpsertMemoryEmbedding(id, vecs[0])
+					_ = vectors.UpsertMemoryEmbedding(gcfg.EmbeddingModelKey(), id, vecs[0])
 				}
 			}
 
@@ -94,7 +100,72 @@ Examples:
 	}
 
 	cmd.Flags().StringVarP(&memType, "type", "t", "",
-		"Memory type: decision, convention, constraint, note, todo (auto-detected if not set)")
+		"Memory type: decision, convention, constraint, note, todo, or a custom type from memory_types (auto-detected if not set)")
+	cmd.Flags().BoolVar(&global, "global", false, "store in the user-level memory shared by every project")
 
 	return cmd
 }
+
+// rememberGlobal stores statement in the user-level memory. Global memories
+// are only ever listed into the system prompt, so they are not embedded.
+func rememberGlobal(statement string, mt memory.MemoryType) error {
+	store, closeStore, err := openGlobalStore(true)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	id, err := store.InsertMemory(memory.Memory{
+		Content:    statement,
+		MemoryType: mt,
+		Source:     "user",
+		Importance: memory.ImportanceFor(memory.DefaultImportance(), mt),
+	})
+	if err != nil {
+		return fmt.Errorf("store memory: %w", err)
+	}
+
+	fmt.Printf("Stored globally as: %s\n", mt)
+	fmt.Printf("  %q\n", statement)
+	fmt.Printf("  id: %s\n", id)
+	return nil
+}
+
+// openGlobalStore opens the user-level memory store (see
+// memory.OpenGlobalStore). Without create, a missing store yields nil. The
+// returned func closes it and is safe to call either way.
+func openGlobalStore(create bool) (*memory.Store, func(), error) {
+	path, err := config.GlobalDBPath()
+	if err != nil {
+		return nil, func() {}, err
+	}
+	store, database, err := memory.OpenGlobalStore(path, create)
+	if err != nil || store == nil {
+		return nil, func() {}, err
+	}
+	return store, func() { _ = database.Close() }, nil
+}
+
+// useGlobalMemory adds the user-level memories, if any, to builder's system
+// prompt. Call the returned func once the context is built.
+func useGlobalMemory(builder *ctxpkg.Builder) func() {
+	global, closeGlobal, err := openGlobalStore(false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  warn: %v\n", err)
+	}
+	if global != nil {
+		builder.SetGlobalStore(global)
+	}
+	return closeGlobal
+}
+
+// importanceDefaults returns the per-type importance configured for the
+// project, or the built-in defaults if the project config is invalid.
+func importanceDefaults(root string) map[memory.MemoryType]float64 {
+	pcfg, err := config.LoadProject(root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  warn: %v\n", err)
+		return memory.DefaultImportance()
+	}
+	return memory.ParseImportance(pcfg.ImportanceDefaults())
+}
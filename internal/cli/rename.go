@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/memvra/memvra/internal/adapter"
+	"github.com/memvra/memvra/internal/config"
+	"github.com/memvra/memvra/internal/db"
+	"github.com/memvra/memvra/internal/memory"
+)
+
+func newRenameCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "rename <old> <new>",
+		Short: "Replace text across all stored memories",
+		Long: `Replace every occurrence of <old> with <new> in stored memories.
+
+Matching is case-sensitive. Changed memories keep their IDs and are
+re-embedded, so search stays in step with the new text.
+
+Examples:
+  memvra rename UserService AccountService
+  memvra rename MySQL Postgres --dry-run`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			old, replacement := args[0], args[1]
+			if old == "" {
+				return fmt.Errorf("<old> must not be empty")
+			}
+
+			root, err := findRoot()
+			if err != nil {
+				return err
+			}
+			dbPath, err := ensureInitialized(root)
+			if err != nil {
+				return err
+			}
+
+			database, err := db.Open(dbPath)
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer func() { _ = database.Close() }()
+
+			store := memory.NewStore(database)
+			matches, err := store.MemoriesContaining(old)
+			if err != nil {
+				return err
+			}
+			if len(matches) == 0 {
+				fmt.Printf("No memories contain %q.\n", old)
+				return nil
+			}
+
+			if dryRun {
+				fmt.Printf("Would update %d memories:\n\n", len(matches))
+				for _, m := range matches {
+					fmt.Printf("  %s [%s]\n", m.ID, m.MemoryType)
+					fmt.Printf("    - %s\n", m.Content)
+					fmt.Printf("    + %s\n", strings.ReplaceAll(m.Content, old, replacement))
+				}
+				return nil
+			}
+
+			n, err := store.ReplaceInMemories(old, replacement)
+			if err != nil {
+				return err
+			}
+
+			// Re-embed the changed memories (best-effort — non-fatal on failure).
+			gcfg, _ := config.Load(root)
+			if embedder := buildEmbedder(gcfg); embedder != nil {
+				if err := reembedMemories(embedder, openVectorStore(database, gcfg), gcfg.EmbeddingModelKey(), store, matches); err != nil {
+					fmt.Fprintf(os.Stderr, "  Warning: re-embedding failed: %v\n", err)
+				}
+			}
+
+			fmt.Printf("Updated %d memories.\n", n)
+			AutoExport(root, store)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the memories that would change without updating them")
+	return cmd
+}
+
+// reembedMemories re-reads each of memories from store and replaces its
+// embedding under model.
+func reembedMemories(embedder adapter.Embedder, vectors *memory.VectorStore, model string, store *memory.Store, memories []memory.Memory) error {
+	ids := make([]string, 0, len(memories))
+	texts := make([]string, 0, len(memories))
+	for _, old := range memories {
+		m, err := store.GetMemoryByID(old.ID)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, m.ID)
+		texts = append(texts, m.Content)
+	}
+	vecs, err := embedder.Embed(context.Background(), texts)
+	if err != nil {
+		return err
+	}
+	for i, vec := range vecs {
+		if i >= len(ids) {
+			break
+		}
+		if err := vectors.UpsertMemoryEmbedding(model, ids[i], vec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -6,6 +6,9 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+
+	"github.com/memvra/memvra/internal/config"
+	"github.com/memvra/memvra/internal/memory"
 )
 
 var (
@@ -28,6 +31,9 @@ your project across sessions.
 Run 'memvra init' in any project directory to get started.`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return registerMemoryTypes()
+	},
 }
 
 // Execute runs the root command.
@@ -45,9 +51,13 @@ func init() {
 		newAskCmd(),
 		newRememberCmd(),
 		newForgetCmd(),
+		newRenameCmd(),
+		newLinkCmd(),
 		newContextCmd(),
+		newSearchCmd(),
 		newDiffCmd(),
 		newStatusCmd(),
+		newInfoCmd(),
 		newUpdateCmd(),
 		newWatchCmd(),
 		newWrapCmd(),
@@ -55,11 +65,32 @@ func init() {
 		newHookCmd(),
 		newSetupCmd(),
 		newPruneCmd(),
+		newCompactCmd(),
+		newGCCmd(),
+		newBackupCmd(),
+		newRestoreCmd(),
 		newMCPCmd(),
+		newServeCmd(),
+		newAuditCmd(),
+		newDoctorCmd(),
 		newVersionCmd(),
 	)
 }
 
+// registerMemoryTypes makes the project's custom memory_types valid for
+// every command. Outside a project there is nothing to register.
+func registerMemoryTypes() error {
+	root, err := findRoot()
+	if err != nil {
+		return nil
+	}
+	pcfg, _ := config.LoadProject(root)
+	if err := memory.RegisterTypes(pcfg.MemoryTypes); err != nil {
+		return fmt.Errorf("memory_types in %s: %w", config.ProjectConfigPath(root), err)
+	}
+	return nil
+}
+
 func newVersionCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "version",
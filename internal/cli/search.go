@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/memvra/memvra/internal/config"
+	ctxpkg "github.com/memvra/memvra/internal/context"
+	"github.com/memvra/memvra/internal/db"
+	"github.com/memvra/memvra/internal/memory"
+)
+
+func newSearchCmd() *cobra.Command {
+	var (
+		topK       int
+		threshold  float64
+		chunkMin   float64
+		memoryMin  float64
+		explain    bool
+		noSnippets bool
+		expand     bool
+		exclude    []string
+		only       string
+		language   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Semantically search indexed code and memories",
+		Long: `Run the same retrieval used by "memvra ask" and list what it finds.
+
+Each code result is followed by a snippet: the lines containing query words
+(marked with ">") and a little surrounding code, or the start of the chunk
+when it matched on meaning alone.
+
+With --explain, each result shows its raw vector distance, the similarity
+derived from it, every adjustment applied (importance, test-file penalty),
+and the final score — useful when tuning similarity_threshold.
+
+With --expand, the query is augmented with related terms before embedding
+(e.g. "auth" also searches for login, session and token), which helps short
+queries find more.
+
+Words prefixed with "-" (or given with --exclude) drop code whose path or
+content contains them, and memories whose content does. Excluded results are
+replaced by the next best matches, so up to --top-k results are still shown.
+Put "--" before a query containing "-term" so it is not read as a flag.
+
+Examples:
+  memvra search "auth middleware"
+  memvra search "database migrations" --explain --top-k 5
+  memvra search auth --expand
+  memvra search -- error handling -test -fixture`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query, terms := memory.SplitExclusions(strings.Join(args, " "))
+			terms = append(terms, exclude...)
+
+			root, err := findRoot()
+			if err != nil {
+				return err
+			}
+
+			dbPath := config.ProjectDBPath(root)
+			if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+				return fmt.Errorf("memvra not initialized — run `memvra init` first")
+			}
+
+			database, err := db.Open(dbPath)
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer func() { _ = database.Close() }()
+
+			store := memory.NewStore(database)
+			gcfg, _ := config.Load(root)
+			if !gcfg.Output.Color || os.Getenv("NO_COLOR") != "" {
+				disableColors()
+			}
+
+			embedder := buildEmbedder(gcfg)
+			if embedder == nil {
+				return fmt.Errorf("no embedder available — run `memvra setup` to configure one")
+			}
+			pcfg, _ := config.LoadProject(root)
+			orchestrator := ctxpkg.ConfiguredOrchestrator(store, openVectorStore(database, gcfg), embedder, gcfg, pcfg)
+
+			// An explicit --threshold applies to both kinds unless a
+			// per-kind flag overrides it; otherwise the config decides.
+			if !cmd.Flags().Changed("threshold") {
+				threshold = gcfg.Context.SimilarityThreshold
+				if !cmd.Flags().Changed("chunk-threshold") {
+					chunkMin = gcfg.Context.ChunkThreshold
+				}
+				if !cmd.Flags().Changed("memory-threshold") {
+					memoryMin = gcfg.Context.MemoryThreshold
+				}
+			}
+			topKChunks, topKMemories := topK, topK
+			if !cmd.Flags().Changed("top-k") {
+				topKChunks, topKMemories = pcfg.SearchTopK(topK)
+			}
+			opts := memory.RetrieveOptions{
+				TopKChunks:          topKChunks,
+				TopKMemories:        topKMemories,
+				SimilarityThreshold: threshold,
+				ChunkThreshold:      chunkMin,
+				MemoryThreshold:     memoryMin,
+				Explain:             explain,
+				ExpandQuery:         expand,
+				Exclude:             terms,
+				Language:            language,
+			}
+			if err := opts.LimitToCategory(only); err != nil {
+				return fmt.Errorf("--only: %w", err)
+			}
+			result, err := orchestrator.Retrieve(context.Background(), query, opts)
+			if err != nil {
+				return fmt.Errorf("search: %w", err)
+			}
+			if w := result.FallbackWarning(); w != "" {
+				fmt.Fprintf(os.Stderr, "  Warning: %s\n", w)
+			}
+
+			if len(result.Memories) == 0 && len(result.Chunks) == 0 {
+				fmt.Println("No results found.")
+				return nil
+			}
+
+			if len(result.Memories) > 0 {
+				fmt.Printf("Memories (%d)\n", len(result.Memories))
+				for i, m := range result.Memories {
+					fmt.Printf("  %d. [%s] %s (id: %s)\n", i+1, m.MemoryType, truncateLabel(m.Content, 80), shortID(m.ID))
+					printExplanation(result.Explanations, m.ID)
+				}
+				fmt.Println()
+			}
+
+			if len(result.Chunks) > 0 {
+				fmt.Printf("Code (%d)\n", len(result.Chunks))
+				fileIDs := make([]string, len(result.Chunks))
+				for i, c := range result.Chunks {
+					fileIDs[i] = c.FileID
+				}
+				files, _ := store.GetFilesByIDs(fileIDs)
+				for i, c := range result.Chunks {
+					path := c.FileID
+					if file, ok := files[c.FileID]; ok {
+						path = file.Path
+					}
+					fmt.Printf("  %d. %s:%d-%d\n", i+1, path, c.StartLine, c.EndLine)
+					printExplanation(result.Explanations, c.ID)
+					if !noSnippets {
+						printSnippet(memory.ExtractSnippet(c, query, memory.DefaultSnippetContext, memory.DefaultSnippetLines))
+					}
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&topK, "top-k", "k", 10, "Maximum results per kind (code and memories); defaults to the project's [context] top_k_chunks / top_k_memories when set")
+	cmd.Flags().Float64Var(&threshold, "threshold", 0, "Minimum similarity (default: context.similarity_threshold)")
+	cmd.Flags().Float64Var(&chunkMin, "chunk-threshold", 0, "Minimum similarity for code results (default: context.chunk_threshold, else --threshold)")
+	cmd.Flags().Float64Var(&memoryMin, "memory-threshold", 0, "Minimum similarity for memories (default: context.memory_threshold, else --threshold)")
+	cmd.Flags().BoolVar(&explain, "explain", false, "Show how each result was scored")
+	cmd.Flags().BoolVar(&noSnippets, "no-snippets", false, "List code results without snippets")
+	cmd.Flags().BoolVar(&expand, "expand", false, "Add related terms to the query before searching")
+	cmd.Flags().StringArrayVar(&exclude, "exclude", nil, "Drop results containing this term in their path or content (repeatable)")
+	cmd.Flags().StringVar(&language, "language", "", "Only return code from files of this language (e.g. go, typescript)")
+	cmd.Flags().StringVar(&only, "only", "", "Search only one kind of result: chunks or memories")
+
+	return cmd
+}
+
+// printExplanation prints the score breakdown for id, if one was recorded.
+func printExplanation(explanations map[string]memory.ScoreExplanation, id string) {
+	e, ok := explanations[id]
+	if !ok {
+		return
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "     distance %.4f → similarity %.4f", e.Distance, e.Similarity)
+	for _, a := range e.Adjustments {
+		fmt.Fprintf(&sb, " × %s %.2f", a.Name, a.Factor)
+	}
+	for i, t := range e.Terms {
+		sep := " +"
+		if i == 0 {
+			sep = ";"
+		}
+		fmt.Fprintf(&sb, "%s %.2f×%s %.4f", sep, t.Weight, t.Name, t.Value)
+	}
+	fmt.Fprintf(&sb, " = score %.4f", e.FinalScore)
+	fmt.Println(sb.String())
+}
+
+// printSnippet prints a code snippet indented under its result, with query
+// terms highlighted when colors are enabled.
+func printSnippet(s memory.Snippet) {
+	text := s.Format(func(term string) string { return cBold + cYellow + term + cReset })
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		fmt.Printf("     %s\n", line)
+	}
+	fmt.Println()
+}
+
+// shortID returns the first 8 characters of an ID for display.
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/memvra/memvra/internal/adapter"
+	"github.com/memvra/memvra/internal/config"
+	ctxpkg "github.com/memvra/memvra/internal/context"
+	"github.com/memvra/memvra/internal/db"
+	"github.com/memvra/memvra/internal/httpapi"
+	"github.com/memvra/memvra/internal/memory"
+)
+
+// httpTokenEnv supplies the API token without putting it on the command line.
+const httpTokenEnv = "MEMVRA_HTTP_TOKEN"
+
+func newServeCmd() *cobra.Command {
+	var addr string
+	var token string
+	var corsOrigins []string
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve project memory as a JSON API over HTTP",
+		Long: `Start a read-only JSON API over this project's memory, for custom
+frontends such as an internal web UI. This is plain HTTP, separate from
+` + "`memvra mcp`" + `.
+
+Endpoints (all GET):
+  /api/search?q=...     code chunks and memories (top_k, only, language, exclude, source)
+  /api/memories         stored memories (type)
+  /api/sessions         recent sessions, newest first (limit, tag)
+  /api/context?q=...    assembled context, as memvra_get_context returns it (max_tokens, session_tag)
+  /api/context/stream   the same, as server-sent events while sections are built
+
+Set a token with --token, $` + httpTokenEnv + `, or token under [http] in the
+config; clients then send "Authorization: Bearer <token>". Allow browser
+pages on other origins with --cors-origin or cors_origins under [http].
+
+Examples:
+  memvra serve --http :9090
+  MEMVRA_HTTP_TOKEN=s3cret memvra serve --http :9090 --cors-origin http://localhost:5173`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root, err := findRoot()
+			if err != nil {
+				return err
+			}
+			dbPath, err := ensureInitialized(root)
+			if err != nil {
+				return err
+			}
+
+			database, err := db.Open(dbPath)
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer func() { _ = database.Close() }()
+
+			store := memory.NewStore(database)
+			store.SetReadOnly(true)
+			gcfg, _ := config.Load(root)
+			pcfg, _ := config.LoadProject(root)
+
+			var embedder adapter.Embedder
+			if emb := buildEmbedder(gcfg); emb != nil {
+				embedder = emb
+			}
+			orchestrator := ctxpkg.ConfiguredOrchestrator(store, openVectorStore(database, gcfg), embedder, gcfg, pcfg)
+
+			if !cmd.Flags().Changed("token") {
+				token = os.Getenv(httpTokenEnv)
+				if token == "" {
+					token = gcfg.HTTP.Token
+				}
+			}
+			if !cmd.Flags().Changed("cors-origin") {
+				corsOrigins = gcfg.HTTP.CORSOrigins
+			}
+
+			api := httpapi.NewServer(root, store, orchestrator, httpapi.Options{
+				Token:       token,
+				CORSOrigins: corsOrigins,
+				AuditStore:  memory.NewStore(database),
+			})
+			srv := &http.Server{
+				Addr:              addr,
+				Handler:           api.Handler(),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+			go func() {
+				<-ctx.Done()
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				_ = srv.Shutdown(shutdownCtx)
+			}()
+
+			if token == "" {
+				fmt.Fprintf(os.Stderr, "  Warning: no token set; anyone who can reach %s can read this project's memory\n", addr)
+			}
+			fmt.Printf("Serving %s on http://%s (Ctrl-C to stop)\n", root, addr)
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("serve: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "http", "localhost:9090", "address to listen on")
+	cmd.Flags().StringVar(&token, "token", "", "bearer token clients must send (default: $"+httpTokenEnv+", or [http] token)")
+	cmd.Flags().StringSliceVar(&corsOrigins, "cors-origin", nil, `browser origins allowed to call the API, or "*" (default: [http] cors_origins)`)
+	return cmd
+}
@@ -65,6 +65,7 @@ func newSetupCmd() *cobra.Command {
 			fmt.Println("For embeddings (semantic search), use:")
 			fmt.Println("  [1] Local embeddings via Ollama (private, free — requires Ollama)")
 			fmt.Println("  [2] OpenAI embeddings (better quality, small cost)")
+			fmt.Println("  [3] Gemini embeddings (text-embedding-004)")
 			fmt.Print("> ")
 
 			embedChoice := readLineBuf(reader)
@@ -75,6 +76,12 @@ func newSetupCmd() *cobra.Command {
 					fmt.Print("Enter your OpenAI API key: ")
 					cfg.Keys.OpenAI = readLineBuf(reader)
 				}
+			case "3":
+				cfg.DefaultEmbedder = "gemini"
+				if cfg.Keys.Gemini == "" {
+					fmt.Print("Enter your Gemini API key: ")
+					cfg.Keys.Gemini = readLineBuf(reader)
+				}
 			default:
 				cfg.DefaultEmbedder = "ollama"
 				fmt.Printf("Ollama host (press Enter for %s): ", cfg.Ollama.Host)
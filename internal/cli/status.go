@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -79,11 +80,7 @@ func newStatusCmd() *cobra.Command {
 			if totalMem > 0 {
 				fmt.Printf(" (")
 				first := true
-				types := []memory.MemoryType{
-					memory.TypeDecision, memory.TypeConvention,
-					memory.TypeConstraint, memory.TypeNote, memory.TypeTodo,
-				}
-				for _, t := range types {
+				for _, t := range memory.AllTypes() {
 					if n, ok := memCounts[t]; ok && n > 0 {
 						if !first {
 							fmt.Printf(", ")
@@ -99,6 +96,23 @@ func newStatusCmd() *cobra.Command {
 			fmt.Printf("Sessions: %d\n", sessions)
 			fmt.Printf("Updated:  %s\n", lastUpdated)
 			fmt.Printf("Model:    %s (default)\n", modelName)
+			if models, err := memory.NewVectorStore(database).Models(); err == nil && len(models) > 0 {
+				active := gcfg.EmbeddingModelKey()
+				if ecfg, err := config.Load(root); err == nil {
+					active = ecfg.EmbeddingModelKey()
+				}
+				for i, mc := range models {
+					label := "Vectors:  "
+					if i > 0 {
+						label = "          "
+					}
+					marker := ""
+					if mc.Model == active {
+						marker = " (active)"
+					}
+					fmt.Printf("%s%s: %d chunks, %d memories%s\n", label, mc.Model, mc.Chunks, mc.Memories, marker)
+				}
+			}
 			fmt.Printf("DB size:  %s\n", formatBytes(dbSize))
 			fmt.Println()
 
@@ -108,14 +122,21 @@ func newStatusCmd() *cobra.Command {
 }
 
 func describeStackFull(ts scanner.TechStack) string {
-	s := ts.Language
-	if ts.Framework != "" {
-		s += " / " + ts.Framework
-	}
-	if ts.Database != "" {
-		s += " + " + ts.Database
+	parts := make([]string, 0, len(ts.Stacks))
+	for _, ns := range ts.Components() {
+		s := ns.Language
+		if ns.Framework != "" {
+			s += " / " + ns.Framework
+		}
+		if ns.Database != "" {
+			s += " + " + ns.Database
+		}
+		if ns.Name != "" {
+			s = ns.Name + ": " + s
+		}
+		parts = append(parts, s)
 	}
-	return s
+	return strings.Join(parts, "; ")
 }
 
 func formatBytes(b int64) string {
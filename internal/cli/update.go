@@ -17,6 +17,9 @@ import (
 func newUpdateCmd() *cobra.Command {
 	var force bool
 	var quiet bool
+	var noCache bool
+	var reembed bool
+	var gc bool
 
 	cmd := &cobra.Command{
 		Use:   "update",
@@ -24,7 +27,14 @@ func newUpdateCmd() *cobra.Command {
 		Long: `Detect changed files since the last scan and re-index only those files.
 Re-generates embeddings for modified/added files and prunes deleted files.
 Use --force to re-index everything regardless of content hash.
-Use --quiet to suppress output (useful for git hooks).`,
+Use --quiet to suppress output (useful for git hooks).
+Use --no-cache to bypass the embedding cache and re-embed every changed chunk.
+Use --reembed after changing the embedding model to embed every chunk and
+memory under the new model. Vectors from other models of the same dimension
+are kept, so switching back does not require another re-embed; when the new
+model's vectors are a different size, the vector index is recreated for it
+and the old vectors are dropped.
+Use --gc to also remove embeddings left behind by deleted chunks or memories.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			root, err := findRoot()
 			if err != nil {
@@ -43,29 +53,28 @@ Use --quiet to suppress output (useful for git hooks).`,
 			defer func() { _ = database.Close() }()
 
 			store := memory.NewStore(database)
-			vectors := memory.NewVectorStore(database)
-			gcfg, _ := config.LoadGlobal()
-
-			if !quiet {
-				bar := progressbar.NewOptions(-1,
-					progressbar.OptionSetDescription("  Scanning"),
-					progressbar.OptionSpinnerType(14),
-					progressbar.OptionSetWriter(os.Stderr),
-					progressbar.OptionClearOnFinish(),
-				)
-				defer func() { _ = bar.Finish() }()
-			}
+			gcfg, _ := config.Load(root)
+			store.SetChunkCompression(gcfg.Storage.CompressChunks)
+			vectors := openVectorStore(database, gcfg)
+			pcfg, _ := config.LoadProject(root)
 
+			visible := progressbar.OptionSetVisibility(!quiet)
+			bar := newProgressBar("Scanning files", -1, visible)
 			result := scanner.Scan(scanner.ScanOptions{
 				Root:          root,
 				MaxChunkLines: gcfg.Context.ChunkMaxLines,
+				ExcludeGlobs:  scanExcludes(pcfg),
+				Progress:      progressTo(bar),
 			})
+			_ = bar.Finish()
 
 			var modified, added, skipped int
 			changedFileIDs := make([]string, 0)
 
+			indexBar := newProgressBar("Indexing files", len(result.Files), visible)
 			for _, sf := range result.Files {
-				fileID, status, err := upsertScannedFile(store, sf, force)
+				_ = indexBar.Add(1)
+				fileID, status, err := upsertScannedFile(store, vectors, sf, force)
 				if err != nil {
 					fmt.Fprintf(os.Stderr, "  Warning: %v\n", err)
 					continue
@@ -82,6 +91,8 @@ Use --quiet to suppress output (useful for git hooks).`,
 				}
 			}
 
+			_ = indexBar.Finish()
+
 			// Prune files that are no longer on disk.
 			var deleted int
 			allDBFiles, err := store.ListFiles()
@@ -98,6 +109,13 @@ Use --quiet to suppress output (useful for git hooks).`,
 				}
 			}
 
+			var orphans memory.GCResult
+			if gc {
+				if orphans, err = vectors.GarbageCollect(store); err != nil {
+					fmt.Fprintf(os.Stderr, "  Warning: %v\n", err)
+				}
+			}
+
 			refreshProjectCounts(store)
 
 			if !quiet {
@@ -108,6 +126,47 @@ Use --quiet to suppress output (useful for git hooks).`,
 				fmt.Printf("Deleted:  %d files\n", deleted)
 				fmt.Printf("Skipped:  %d files (unchanged)\n", skipped)
 				fmt.Printf("Total:    %d files, %d chunks\n", fileCount, chunkCount)
+				if gc {
+					fmt.Printf("GC:       %d orphaned embeddings removed\n", orphans.Total())
+				}
+			}
+
+			if reembed {
+				embedder := withEmbeddingCache(buildEmbedder(gcfg), store, gcfg, noCache)
+				if embedder == nil {
+					return fmt.Errorf("no embedder available — run `memvra setup` to configure one")
+				}
+				model := gcfg.EmbeddingModelKey()
+				// The vector tables hold one dimension, so recreate them if
+				// the embedder's vectors are a different size.
+				dim, err := memory.NewOrchestrator(store, vectors, nil, embedder).ProbeDimension(context.Background())
+				if dim == 0 {
+					return fmt.Errorf("re-embed: %w", err)
+				}
+				resized, err := vectors.Resize(dim)
+				if err != nil {
+					return fmt.Errorf("re-embed: %w", err)
+				}
+				if resized && !quiet {
+					fmt.Printf("Recreated the vector index for %d-dimensional vectors; embeddings from other models were dropped.\n", dim)
+				}
+				embBar := newProgressBar("Generating embeddings", -1, visible)
+				opts := embedOptions(gcfg, store)
+				opts.Progress = progressTo(embBar)
+				chunkCount, err := embedAllChunks(context.Background(), store, vectors, opts, embedder)
+				_ = embBar.Finish()
+				if err != nil {
+					return fmt.Errorf("re-embed chunks: %w", err)
+				}
+				memCount, err := embedAllMemories(context.Background(), store, vectors, model, embedder)
+				if err != nil {
+					return fmt.Errorf("re-embed memories: %w", err)
+				}
+				if !quiet {
+					fmt.Printf("%d chunks and %d memories embedded with %s\n", chunkCount, memCount, model)
+				}
+				AutoExport(root, store)
+				return nil
 			}
 
 			// Re-embed changed/added chunks.
@@ -115,22 +174,16 @@ Use --quiet to suppress output (useful for git hooks).`,
 				AutoExport(root, store)
 				return nil
 			}
-			embedder := buildEmbedder(gcfg)
+			embedder := withEmbeddingCache(buildEmbedder(gcfg), store, gcfg, noCache)
 			if embedder == nil {
 				return nil
 			}
 
-			if !quiet {
-				embBar := progressbar.NewOptions(-1,
-					progressbar.OptionSetDescription("  Generating embeddings"),
-					progressbar.OptionSpinnerType(14),
-					progressbar.OptionSetWriter(os.Stderr),
-					progressbar.OptionClearOnFinish(),
-				)
-				defer func() { _ = embBar.Finish() }()
-			}
-
-			embeddedCount := embedFileChunks(context.Background(), store, vectors, embedder, changedFileIDs)
+			embBar := newProgressBar("Generating embeddings", -1, visible)
+			opts := embedOptions(gcfg, store)
+			opts.Progress = progressTo(embBar)
+			embeddedCount := embedFileChunks(context.Background(), store, vectors, opts, embedder, changedFileIDs)
+			_ = embBar.Finish()
 
 			if !quiet && embeddedCount > 0 {
 				fmt.Printf("%d chunks re-embedded\n", embeddedCount)
@@ -143,6 +196,9 @@ Use --quiet to suppress output (useful for git hooks).`,
 
 	cmd.Flags().BoolVar(&force, "force", false, "re-index all files, ignoring content hashes")
 	cmd.Flags().BoolVar(&quiet, "quiet", false, "suppress output (used by git hooks)")
+	cmd.Flags().BoolVar(&reembed, "reembed", false, "embed every chunk and memory with the configured embedding model")
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "bypass the embedding cache and call the embedder for every chunk")
+	cmd.Flags().BoolVar(&gc, "gc", false, "remove orphaned embeddings after re-indexing (see memvra gc)")
 
 	return cmd
 }
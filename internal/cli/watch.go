@@ -16,6 +16,7 @@ import (
 
 	"github.com/memvra/memvra/internal/config"
 	"github.com/memvra/memvra/internal/db"
+	"github.com/memvra/memvra/internal/export"
 	"github.com/memvra/memvra/internal/memory"
 	"github.com/memvra/memvra/internal/scanner"
 )
@@ -29,10 +30,14 @@ func newWatchCmd() *cobra.Command {
 		Long: `Start a long-running watcher that monitors the project directory for file
 changes (create, modify, delete) and incrementally updates the index.
 
+The project database is watched too: whenever memories or sessions change —
+from this watcher, another memvra command, or any other writer — the export
+files (CLAUDE.md, .cursorrules, ...) are regenerated if auto-export is enabled.
+
 Changes are debounced so that rapid edits (e.g. saving multiple files at once)
 are batched into a single re-index pass.
 
-Press Ctrl-C to stop.`,
+Press Ctrl-C to stop; pending changes are flushed before exiting.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			root, err := findRoot()
 			if err != nil {
@@ -51,8 +56,9 @@ Press Ctrl-C to stop.`,
 			defer func() { _ = database.Close() }()
 
 			store := memory.NewStore(database)
-			vectors := memory.NewVectorStore(database)
-			gcfg, _ := config.LoadGlobal()
+			gcfg, _ := config.Load(root)
+			store.SetChunkCompression(gcfg.Storage.CompressChunks)
+			vectors := openVectorStore(database, gcfg)
 
 			watcher, err := fsnotify.NewWatcher()
 			if err != nil {
@@ -60,12 +66,22 @@ Press Ctrl-C to stop.`,
 			}
 			defer func() { _ = watcher.Close() }()
 
-			ignore := scanner.NewIgnoreMatcher(root)
+			pcfg, _ := config.LoadProject(root)
+			ignore := scanner.NewIgnoreMatcher(root).WithPatterns(scanExcludes(pcfg))
 
 			// Add all non-ignored directories recursively.
 			if err := addWatchDirs(watcher, root, ignore); err != nil {
 				return fmt.Errorf("add watch directories: %w", err)
 			}
+			// .memvra is hard-ignored above; watch it only for database writes.
+			if err := watcher.Add(filepath.Dir(dbPath)); err != nil {
+				return fmt.Errorf("watch database: %w", err)
+			}
+			// Export files are written by this watcher; reacting to them would loop.
+			exportFiles := make(map[string]bool)
+			for _, name := range autoExportFilenames(gcfg.AutoExport) {
+				exportFiles[name] = true
+			}
 
 			debounce := time.Duration(debounceMs) * time.Millisecond
 
@@ -80,13 +96,32 @@ Press Ctrl-C to stop.`,
 
 			// Collect changed relative paths, debounce, then process.
 			pending := make(map[string]fsnotify.Op)
+			exportPending := false
 			timer := time.NewTimer(debounce)
 			timer.Stop() // Don't fire immediately.
 
+			flush := func() {
+				if len(pending) > 0 {
+					batch := pending
+					pending = make(map[string]fsnotify.Op)
+					processChanges(ctx, root, batch, store, vectors, ignore, gcfg)
+				}
+				if exportPending && gcfg.AutoExport.Enabled {
+					fmt.Printf("[%s] database changed, regenerating exports\n", time.Now().Format("15:04:05"))
+					export.AutoExport(root, store)
+				}
+				exportPending = false
+			}
+
 			for {
 				select {
 				case <-sigCh:
 					fmt.Println("\nStopping watcher.")
+					// Re-indexing writes the database; export whatever it changed.
+					if len(pending) > 0 {
+						exportPending = true
+					}
+					flush()
 					return nil
 
 				case event, ok := <-watcher.Events:
@@ -99,6 +134,15 @@ Press Ctrl-C to stop.`,
 						continue
 					}
 
+					if isDatabaseEvent(rel) {
+						exportPending = true
+						timer.Reset(debounce)
+						continue
+					}
+					if exportFiles[rel] {
+						continue
+					}
+
 					// Skip events inside hard-ignored or .memvra dirs.
 					if shouldIgnoreEvent(rel, ignore) {
 						continue
@@ -132,13 +176,7 @@ Press Ctrl-C to stop.`,
 					fmt.Fprintf(os.Stderr, "  watch error: %v\n", err)
 
 				case <-timer.C:
-					if len(pending) == 0 {
-						continue
-					}
-					batch := pending
-					pending = make(map[string]fsnotify.Op)
-
-					processChanges(ctx, root, batch, store, vectors, ignore, gcfg)
+					flush()
 
 				case <-ctx.Done():
 					return nil
@@ -173,6 +211,13 @@ func addWatchDirs(watcher *fsnotify.Watcher, root string, ignore *scanner.Ignore
 	})
 }
 
+// isDatabaseEvent reports whether a path relative to the project root is the
+// project database or one of its WAL/shared-memory files.
+func isDatabaseEvent(rel string) bool {
+	dir, name := filepath.Split(rel)
+	return filepath.Clean(dir) == ".memvra" && strings.HasPrefix(name, "memvra.db")
+}
+
 // shouldIgnoreEvent checks whether a relative path should be ignored by the watcher.
 func shouldIgnoreEvent(rel string, ignore *scanner.IgnoreMatcher) bool {
 	parts := strings.Split(rel, string(filepath.Separator))
@@ -218,7 +263,7 @@ func processChanges(
 			continue
 		}
 
-		fileID, status, err := upsertScannedFile(store, *sf, false)
+		fileID, status, err := upsertScannedFile(store, vectors, *sf, false)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "  warning: %v\n", err)
 			continue
@@ -244,8 +289,8 @@ func processChanges(
 
 	// Re-embed if we have an embedder.
 	if len(changedFileIDs) > 0 {
-		if embedder := buildEmbedder(gcfg); embedder != nil {
-			n := embedFileChunks(ctx, store, vectors, embedder, changedFileIDs)
+		if embedder := withEmbeddingCache(buildEmbedder(gcfg), store, gcfg, false); embedder != nil {
+			n := embedFileChunks(ctx, store, vectors, embedOptions(gcfg, store), embedder, changedFileIDs)
 			if n > 0 {
 				fmt.Printf(" (%d chunks embedded)", n)
 			}
@@ -34,6 +34,24 @@ func TestShouldIgnoreEvent(t *testing.T) {
 	}
 }
 
+func TestIsDatabaseEvent(t *testing.T) {
+	tests := []struct {
+		rel  string
+		want bool
+	}{
+		{filepath.Join(".memvra", "memvra.db"), true},
+		{filepath.Join(".memvra", "memvra.db-wal"), true},
+		{filepath.Join(".memvra", "config.toml"), false},
+		{"memvra.db", false},
+		{filepath.Join("sub", ".memvra", "memvra.db"), false},
+	}
+	for _, tt := range tests {
+		if got := isDatabaseEvent(tt.rel); got != tt.want {
+			t.Errorf("isDatabaseEvent(%q) = %v, want %v", tt.rel, got, tt.want)
+		}
+	}
+}
+
 func TestAddWatchDirs_SkipsIgnored(t *testing.T) {
 	dir := t.TempDir()
 
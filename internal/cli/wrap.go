@@ -19,6 +19,7 @@ import (
 
 	"github.com/memvra/memvra/internal/adapter"
 	"github.com/memvra/memvra/internal/config"
+	ctxpkg "github.com/memvra/memvra/internal/context"
 	"github.com/memvra/memvra/internal/db"
 	"github.com/memvra/memvra/internal/memory"
 )
@@ -61,7 +62,7 @@ Examples:
 			var gcfg config.GlobalConfig
 
 			if rootErr == nil {
-				gcfg, _ = config.LoadGlobal()
+				gcfg, _ = config.Load(root)
 				dbPath := config.ProjectDBPath(root)
 				if _, statErr := os.Stat(dbPath); statErr == nil {
 					d, dbErr := db.Open(dbPath)
@@ -117,6 +118,7 @@ Examples:
 				ResponseSummary: truncateLabel(capturedClean, 300),
 				ModelUsed:       toolName,
 			})
+			_, _ = store.PruneSessionsByPolicy(memory.RetentionPolicy(gcfg.Retention))
 
 			// 7. Determine LLM for summarization/extraction.
 			providerName := gcfg.DefaultModel
@@ -154,15 +156,15 @@ Examples:
 					gcfg.Extraction.MaxExtracts,
 				)
 				if err == nil && len(extracted) > 0 {
-					vectors := memory.NewVectorStore(database)
-					ranker := memory.NewRanker()
 					var embedder adapter.Embedder
 					if emb := buildEmbedder(gcfg); emb != nil {
 						embedder = emb
 					}
-					orchestrator := memory.NewOrchestrator(store, vectors, ranker, embedder)
+					pcfg, _ := config.LoadProject(root)
+					orchestrator := ctxpkg.ConfiguredOrchestrator(store, openVectorStore(database, gcfg), embedder, gcfg, pcfg)
 					for _, m := range extracted {
-						_, _ = orchestrator.Remember(context.Background(), m.Content, m.MemoryType, "extracted")
+						m.SourceSessionID = sessID
+						_, _ = orchestrator.RememberMemory(context.Background(), m)
 					}
 					fmt.Fprintf(os.Stderr, "[memvra wrap] %d memor%s extracted\n",
 						len(extracted), pluralY(len(extracted)))
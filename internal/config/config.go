@@ -21,6 +21,85 @@ type GlobalConfig struct {
 	Extraction      ExtractionConfig    `toml:"extraction"`
 	Summarization   SummarizationConfig `toml:"summarization"`
 	AutoExport      AutoExportConfig    `toml:"auto_export"`
+	Embedding       EmbeddingConfig     `toml:"embedding"`
+	Redaction       RedactionConfig     `toml:"redaction"`
+	Storage         StorageConfig       `toml:"storage"`
+	MCP             MCPConfig           `toml:"mcp"`
+	HTTP            HTTPConfig          `toml:"http"`
+	Audit           AuditConfig         `toml:"audit"`
+	Retention       RetentionConfig     `toml:"retention"`
+}
+
+// MCPConfig controls the MCP server. When either threshold is reached since
+// the last memvra_save_progress, the next memvra_get_context response carries
+// a reminder to save progress. Zero disables that threshold.
+type MCPConfig struct {
+	ProgressReminderCalls   int `toml:"progress_reminder_calls"`
+	ProgressReminderMinutes int `toml:"progress_reminder_minutes"`
+	// Tools limits the tools `memvra mcp` exposes, by name (empty = all).
+	Tools []string `toml:"tools"`
+	// MergeSessionThreshold, when above zero, makes memvra_save_progress
+	// update a session saved within MergeSessionWindowHours whose task and
+	// summary share at least this fraction of words (0-1), instead of adding
+	// a near-duplicate.
+	MergeSessionThreshold   float64 `toml:"merge_session_threshold"`
+	MergeSessionWindowHours int     `toml:"merge_session_window_hours"`
+}
+
+// HTTPConfig controls the JSON API started by `memvra serve --http`.
+type HTTPConfig struct {
+	// Token, when set, must be sent as "Authorization: Bearer <token>".
+	Token string `toml:"token"`
+	// CORSOrigins lists browser origins allowed to call the API ("*" = any).
+	CORSOrigins []string `toml:"cors_origins"`
+}
+
+// RetentionConfig bounds session history. Sessions outside every limit set
+// are pruned after each new session and by `memvra prune`; in-progress and
+// blocked sessions are always kept. Zero disables a limit.
+type RetentionConfig struct {
+	KeepLastN int `toml:"keep_last_n"`
+	KeepDays  int `toml:"keep_days"`
+}
+
+// AuditConfig controls the access log: when enabled, every memory, chunk,
+// session and file packed into context for a model is recorded, for
+// `memvra audit`.
+type AuditConfig struct {
+	Enabled bool `toml:"enabled"`
+}
+
+// StorageConfig controls how indexed content is stored in the project DB.
+type StorageConfig struct {
+	// CompressChunks gzips chunk text at write time. Existing chunks are read
+	// either way, so the flag can be toggled without re-indexing.
+	CompressChunks bool `toml:"compress_chunks"`
+}
+
+// RedactionConfig controls masking of secrets in content saved through the
+// MCP server. Patterns are regular expressions that replace the built-in set
+// when non-empty.
+type RedactionConfig struct {
+	Enabled  bool     `toml:"enabled"`
+	Patterns []string `toml:"patterns"`
+}
+
+// EmbeddingConfig selects the embedding provider and model. It can be set
+// globally or overridden per project under [embedding] in .memvra/config.toml.
+// An empty Provider falls back to DefaultEmbedder.
+type EmbeddingConfig struct {
+	Provider string `toml:"provider"`
+	Model    string `toml:"model"`
+	// Workers is how many embedding batches are in flight at once while
+	// indexing. Lower it if the provider rate-limits you.
+	Workers int `toml:"workers"`
+	// RateLimit caps embedding requests per minute for cloud providers
+	// (0 = unlimited). Batch sizes also shrink automatically on HTTP 429.
+	RateLimit int `toml:"rate_limit"`
+	// StripBoilerplate embeds code chunks without their license header and
+	// import block (see memory.StripBoilerplate). Off by default since some
+	// queries target imports; run `memvra update --reembed` after changing it.
+	StripBoilerplate bool `toml:"strip_boilerplate,omitempty"`
 }
 
 // AutoExportConfig controls automatic regeneration of export files
@@ -28,8 +107,17 @@ type GlobalConfig struct {
 type AutoExportConfig struct {
 	Enabled bool     `toml:"enabled"`
 	Formats []string `toml:"formats"`
+	// MinImportance drops memories below this importance from export files (0 = keep all).
+	MinImportance float64 `toml:"min_importance"`
+	// Sessions is how many recent sessions are listed under Recent Activity
+	// (0 = omit the section).
+	Sessions int `toml:"sessions"`
 }
 
+// DefaultAutoExportSessions is the Recent Activity length used when
+// [auto_export] does not set sessions.
+const DefaultAutoExportSessions = 5
+
 // ExtractionConfig controls auto-extraction of memories from LLM responses.
 type ExtractionConfig struct {
 	Enabled     bool `toml:"enabled"`
@@ -46,6 +134,7 @@ type KeysConfig struct {
 	Anthropic string `toml:"anthropic"`
 	OpenAI    string `toml:"openai"`
 	Gemini    string `toml:"gemini"`
+	Cohere    string `toml:"cohere"`
 }
 
 type OllamaConfig struct {
@@ -55,13 +144,53 @@ type OllamaConfig struct {
 }
 
 type ContextConfig struct {
-	MaxTokens          int     `toml:"max_tokens"`
-	ChunkMaxLines      int     `toml:"chunk_max_lines"`
+	MaxTokens           int     `toml:"max_tokens"`
+	ChunkMaxLines       int     `toml:"chunk_max_lines"`
 	SimilarityThreshold float64 `toml:"similarity_threshold"`
+	// ChunkThreshold and MemoryThreshold replace SimilarityThreshold for
+	// code chunks and memories respectively (0 = similarity_threshold).
+	ChunkThreshold     float64 `toml:"chunk_threshold"`
+	MemoryThreshold    float64 `toml:"memory_threshold"`
 	TopKChunks         int     `toml:"top_k_chunks"`
 	TopKMemories       int     `toml:"top_k_memories"`
 	TopKSessions       int     `toml:"top_k_sessions"`
 	SessionTokenBudget int     `toml:"session_token_budget"`
+	// SessionSummaryTokens caps each session summary in the history block
+	// (0 = built-in default, negative = no cap).
+	SessionSummaryTokens int `toml:"session_summary_tokens"`
+	// SystemPromptTypes lists memory types pinned into the system prompt.
+	SystemPromptTypes []string `toml:"system_prompt_types"`
+	// ContextTypes lists memory types allowed in the context body. Types in
+	// neither list are left out of built context entirely.
+	ContextTypes []string `toml:"context_types"`
+	// MinImportance drops memories below this importance from built context (0 = keep all).
+	MinImportance float64 `toml:"min_importance"`
+	// MinConfidence drops AI-inferred memories extracted with a lower
+	// confidence from built context (0 = keep all).
+	MinConfidence float64 `toml:"min_confidence"`
+	// MaxMemories caps how many retrieved memories are packed into built
+	// context, however much of the budget is left (0 = unlimited).
+	MaxMemories int `toml:"max_memories"`
+	// MaxFileBytes truncates explicitly included files (--files,
+	// always_include) after this many bytes.
+	MaxFileBytes int64 `toml:"max_file_bytes"`
+	// StopPhrases are ignored when comparing memories for duplicates, so
+	// "We decided to use X" matches "Use X". Unset uses the built-in English
+	// list; an empty list disables phrase stripping.
+	StopPhrases []string `toml:"stop_phrases"`
+	// SectionOrder arranges the context body, e.g. ["sessions", "decisions",
+	// "chunks", "memories"]. Omitted sections follow in the default order.
+	SectionOrder []string `toml:"section_order"`
+	// ContextLines adds up to this many surrounding lines from the same file
+	// to each retrieved code chunk (0 = chunks as indexed).
+	ContextLines int `toml:"context_lines"`
+	// SystemPromptTemplate is a Go text/template replacing the built-in
+	// system prompt, e.g. to add house rules (empty = built-in). See the
+	// README for the fields it can use.
+	SystemPromptTemplate string `toml:"system_prompt_template"`
+	// OpenTodos lists every todo in an "Open TODOs" section of built
+	// context, whether or not it matches the question.
+	OpenTodos bool `toml:"open_todos"`
 }
 
 type OutputConfig struct {
@@ -72,15 +201,148 @@ type OutputConfig struct {
 
 // ProjectConfig holds per-project overrides stored in .memvra/config.toml.
 type ProjectConfig struct {
-	DefaultModel  string            `toml:"default_model"`
+	DefaultModel string `toml:"default_model"`
+	// DBPath moves the project database off .memvra/memvra.db; relative
+	// paths resolve against the project root. MEMVRA_DB_PATH overrides it.
+	DBPath        string            `toml:"db_path,omitempty"`
 	Project       ProjectMeta       `toml:"project"`
 	Conventions   map[string]string `toml:"conventions"`
 	AlwaysInclude []string          `toml:"always_include"`
 	Exclude       []string          `toml:"exclude"`
+	Embedding     EmbeddingConfig   `toml:"embedding"`
+	// ExcludePaths are gitignore-style patterns for sensitive paths that are
+	// never chunked, embedded, returned by search, or injected into context,
+	// even if indexed.
+	ExcludePaths []string `toml:"exclude_paths"`
+	// IndexGenerated indexes the files auto-export writes (CLAUDE.md,
+	// .cursorrules, ...). They are built from memory, so by default they are
+	// skipped to keep exported context from being re-embedded into retrieval.
+	IndexGenerated bool `toml:"index_generated,omitempty"`
+	// MemoryTypes adds custom memory types, e.g. ["risk", "glossary"], to
+	// the built-in decision, convention, constraint, note and todo. They go
+	// in the context body and get their own section in exports.
+	MemoryTypes []string `toml:"memory_types,omitempty"`
+	// Importance overrides the default importance per memory type
+	// (e.g. todo = 0.9). Values must be within [0, 1].
+	Importance map[string]float64 `toml:"importance"`
+	// Boost multiplies the retrieval score of code chunks whose path matches
+	// a gitignore-style pattern (e.g. "docs/" = 1.5, "vendor/" = 0.5).
+	// Values must be positive.
+	Boost map[string]float64 `toml:"boost"`
+	// AutoExport replaces the global [auto_export] section when set.
+	AutoExport *AutoExportConfig `toml:"auto_export,omitempty"`
+	// Context overrides the global [context] budgets for this project.
+	Context ProjectContextConfig `toml:"context,omitempty"`
+	// Ranking weighs similarity, importance and recency in memory scores.
+	Ranking RankingConfig `toml:"ranking,omitempty"`
+}
+
+// RankingConfig mirrors memory.RankerOptions field for field, so one
+// converts directly to the other. All weights zero keeps the default
+// similarity × importance score.
+type RankingConfig struct {
+	SimilarityWeight    float64 `toml:"similarity_weight,omitempty"`
+	ImportanceWeight    float64 `toml:"importance_weight,omitempty"`
+	RecencyWeight       float64 `toml:"recency_weight,omitempty"`
+	RecencyHalfLifeDays float64 `toml:"recency_half_life_days,omitempty"`
+	PreferConcise       bool    `toml:"prefer_concise,omitempty"`
+}
+
+// ProjectContextConfig holds per-project defaults for context budgets. Unset
+// fields keep the global [context] value; explicit CLI flags and MCP tool
+// arguments override both.
+type ProjectContextConfig struct {
+	MaxTokens    *int `toml:"max_tokens,omitempty"`
+	TopKChunks   *int `toml:"top_k_chunks,omitempty"`
+	TopKMemories *int `toml:"top_k_memories,omitempty"`
+	TopKSessions *int `toml:"top_k_sessions,omitempty"`
+}
+
+// ModelSizedContext reports whether the context budget comes from the
+// window of the project's default_model: one is set and [context]
+// max_tokens is not. Explicit per-call budgets still override it.
+func (p ProjectConfig) ModelSizedContext() bool {
+	return p.DefaultModel != "" && p.Context.MaxTokens == nil
+}
+
+// SearchTopK returns the per-kind result limits for a search: the project's
+// top_k_chunks and top_k_memories where set, otherwise fallback. Searches
+// don't use the global [context] values, whose memory limit is sized for
+// context injection rather than browsing.
+func (p ProjectConfig) SearchTopK(fallback int) (chunks, memories int) {
+	chunks, memories = fallback, fallback
+	if p.Context.TopKChunks != nil {
+		chunks = *p.Context.TopKChunks
+	}
+	if p.Context.TopKMemories != nil {
+		memories = *p.Context.TopKMemories
+	}
+	return chunks, memories
+}
+
+// Apply copies the fields that are set onto c.
+func (p ProjectContextConfig) Apply(c *ContextConfig) {
+	if p.MaxTokens != nil {
+		c.MaxTokens = *p.MaxTokens
+	}
+	if p.TopKChunks != nil {
+		c.TopKChunks = *p.TopKChunks
+	}
+	if p.TopKMemories != nil {
+		c.TopKMemories = *p.TopKMemories
+	}
+	if p.TopKSessions != nil {
+		c.TopKSessions = *p.TopKSessions
+	}
+}
+
+// DefaultProject returns the config scaffolded by `memvra init`: auto-export
+// is off, with CLAUDE.md ready to enable as the only format.
+func DefaultProject(name string) ProjectConfig {
+	return ProjectConfig{
+		Project: ProjectMeta{Name: name},
+		AutoExport: &AutoExportConfig{
+			Enabled:  false,
+			Formats:  []string{"claude"},
+			Sessions: DefaultAutoExportSessions,
+		},
+	}
+}
+
+// DefaultImportance returns the built-in importance that memvra remember
+// and the MCP remember tool give each memory type.
+func DefaultImportance() map[string]float64 {
+	return map[string]float64{
+		"decision":   0.8,
+		"constraint": 0.8,
+		"convention": 0.6,
+		"todo":       0.6,
+		"note":       0.6,
+	}
+}
+
+// ImportanceDefaults returns DefaultImportance with the project's
+// [importance] overrides applied.
+func (p ProjectConfig) ImportanceDefaults() map[string]float64 {
+	out := DefaultImportance()
+	for t, v := range p.Importance {
+		out[t] = v
+	}
+	return out
 }
 
 type ProjectMeta struct {
 	Name string `toml:"name"`
+	// DisplayName replaces the project name in exports and built context.
+	DisplayName string `toml:"display_name"`
+}
+
+// ProjectName returns the configured display name, or name when none is set.
+func (p ProjectConfig) ProjectName(name string) string {
+	if p.Project.DisplayName != "" {
+		return p.Project.DisplayName
+	}
+	return name
 }
 
 // DefaultGlobal returns sensible defaults.
@@ -94,13 +356,18 @@ func DefaultGlobal() GlobalConfig {
 			CompletionModel: "llama3.2",
 		},
 		Context: ContextConfig{
-			MaxTokens:           8000,
-			ChunkMaxLines:       150,
-			SimilarityThreshold: 0.3,
-			TopKChunks:          10,
-			TopKMemories:        5,
-			TopKSessions:        3,
-			SessionTokenBudget:  500,
+			MaxTokens:            8000,
+			ChunkMaxLines:        150,
+			SimilarityThreshold:  0.3,
+			TopKChunks:           10,
+			TopKMemories:         5,
+			TopKSessions:         3,
+			SessionTokenBudget:   500,
+			SessionSummaryTokens: 150,
+			SystemPromptTypes:    []string{"convention", "constraint"},
+			ContextTypes:         []string{"decision", "note", "todo"},
+			MaxFileBytes:         256 << 10,
+			OpenTodos:            true,
 		},
 		Output: OutputConfig{
 			Stream: true,
@@ -115,12 +382,50 @@ func DefaultGlobal() GlobalConfig {
 			MaxTokens: 256,
 		},
 		AutoExport: AutoExportConfig{
+			Enabled:  true,
+			Formats:  []string{"claude", "cursor", "markdown", "json"},
+			Sessions: DefaultAutoExportSessions,
+		},
+		Redaction: RedactionConfig{
 			Enabled: true,
-			Formats: []string{"claude", "cursor", "markdown", "json"},
+		},
+		Embedding: EmbeddingConfig{
+			Workers: 4,
+		},
+		MCP: MCPConfig{
+			ProgressReminderCalls:   20,
+			ProgressReminderMinutes: 30,
+			MergeSessionWindowHours: 24,
 		},
 	}
 }
 
+// EmbeddingSettings returns the effective embedding provider and model.
+// The [embedding] section wins over DefaultEmbedder; for Ollama the model
+// falls back to the [ollama] embed_model setting.
+func (c GlobalConfig) EmbeddingSettings() (provider, model string) {
+	provider = c.Embedding.Provider
+	if provider == "" {
+		provider = c.DefaultEmbedder
+	}
+	if provider == "" {
+		provider = "ollama"
+	}
+	model = c.Embedding.Model
+	if model == "" && provider == "ollama" {
+		model = c.Ollama.EmbedModel
+	}
+	return provider, model
+}
+
+// EmbeddingModelKey identifies the effective embedding model as
+// "provider:model". Stored vectors and cached embeddings are keyed by it so
+// vectors from different models are never compared.
+func (c GlobalConfig) EmbeddingModelKey() string {
+	provider, model := c.EmbeddingSettings()
+	return provider + ":" + model
+}
+
 // GlobalConfigPath returns the path to the global config file.
 func GlobalConfigPath() (string, error) {
 	home, err := os.UserHomeDir()
@@ -130,6 +435,16 @@ func GlobalConfigPath() (string, error) {
 	return filepath.Join(home, ".config", "memvra", "config.toml"), nil
 }
 
+// GlobalDBPath returns the path to the user-level memory database shared by
+// every project (see `memvra remember --global`).
+func GlobalDBPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".memvra", "global.db"), nil
+}
+
 // LoadGlobal loads the global config, applying defaults for any missing values.
 func LoadGlobal() (GlobalConfig, error) {
 	cfg := DefaultGlobal()
@@ -153,6 +468,9 @@ func LoadGlobal() (GlobalConfig, error) {
 	if v := os.Getenv("GEMINI_API_KEY"); v != "" {
 		cfg.Keys.Gemini = v
 	}
+	if v := os.Getenv("COHERE_API_KEY"); v != "" {
+		cfg.Keys.Cohere = v
+	}
 
 	return cfg, nil
 }
@@ -180,23 +498,91 @@ func SaveGlobal(cfg GlobalConfig) error {
 // LoadProject loads .memvra/config.toml from the given project root.
 func LoadProject(root string) (ProjectConfig, error) {
 	var cfg ProjectConfig
-	path := filepath.Join(root, ".memvra", "config.toml")
+	path := ProjectConfigPath(root)
 
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return cfg, nil
 	}
 
-	if _, err := toml.DecodeFile(path, &cfg); err != nil {
+	md, err := toml.DecodeFile(path, &cfg)
+	if err != nil {
 		return cfg, fmt.Errorf("config: load project: %w", err)
 	}
+	// A project [auto_export] section replaces the global one wholesale, so
+	// an unset sessions count must still mean the default rather than zero.
+	if cfg.AutoExport != nil && !md.IsDefined("auto_export", "sessions") {
+		cfg.AutoExport.Sessions = DefaultAutoExportSessions
+	}
+	for t, v := range cfg.Importance {
+		if v < 0 || v > 1 {
+			return cfg, fmt.Errorf("config: load project: importance for %q must be between 0 and 1, got %v", t, v)
+		}
+	}
+	for name, v := range map[string]*int{
+		"max_tokens":     cfg.Context.MaxTokens,
+		"top_k_chunks":   cfg.Context.TopKChunks,
+		"top_k_memories": cfg.Context.TopKMemories,
+		"top_k_sessions": cfg.Context.TopKSessions,
+	} {
+		if v != nil && *v < 0 {
+			return cfg, fmt.Errorf("config: load project: context %s must not be negative, got %d", name, *v)
+		}
+	}
+	for pattern, v := range cfg.Boost {
+		if v <= 0 {
+			return cfg, fmt.Errorf("config: load project: boost for %q must be positive, got %v", pattern, v)
+		}
+	}
+	for name, v := range map[string]float64{
+		"similarity_weight":      cfg.Ranking.SimilarityWeight,
+		"importance_weight":      cfg.Ranking.ImportanceWeight,
+		"recency_weight":         cfg.Ranking.RecencyWeight,
+		"recency_half_life_days": cfg.Ranking.RecencyHalfLifeDays,
+	} {
+		if v < 0 {
+			return cfg, fmt.Errorf("config: load project: ranking %s must not be negative, got %v", name, v)
+		}
+	}
 	return cfg, nil
 }
 
-// ProjectDBPath returns the path to the project's SQLite database.
+// DBPathEnv names the environment variable that overrides where the
+// project database lives, e.g. on a mounted volume in a container.
+const DBPathEnv = "MEMVRA_DB_PATH"
+
+// ProjectDBPath returns the path to the project's SQLite database:
+// $MEMVRA_DB_PATH when set, else the project's db_path (relative to root),
+// else .memvra/memvra.db under root.
+//
+// db_path is decoded on its own rather than through LoadProject, so an
+// invalid value elsewhere in the project config is reported by the commands
+// that use it instead of silently moving the database.
 func ProjectDBPath(root string) string {
+	if p := os.Getenv(DBPathEnv); p != "" {
+		return p
+	}
+	var cfg struct {
+		DBPath string `toml:"db_path"`
+	}
+	if _, err := toml.DecodeFile(ProjectConfigPath(root), &cfg); err == nil && cfg.DBPath != "" {
+		if filepath.IsAbs(cfg.DBPath) {
+			return cfg.DBPath
+		}
+		return filepath.Join(root, cfg.DBPath)
+	}
 	return filepath.Join(root, ".memvra", "memvra.db")
 }
 
+// ProjectBackupDir returns the default directory for `memvra backup` snapshots.
+func ProjectBackupDir(root string) string {
+	return filepath.Join(root, ".memvra", "backups")
+}
+
+// ProjectConfigPath returns the path to the project's config.toml.
+func ProjectConfigPath(root string) string {
+	return filepath.Join(root, ".memvra", "config.toml")
+}
+
 // ProjectConfigDirPath returns the path to the project's .memvra/ directory.
 func ProjectConfigDirPath(root string) string {
 	return filepath.Join(root, ".memvra")
@@ -216,6 +602,27 @@ func Load(root string) (GlobalConfig, error) {
 		if project.DefaultModel != "" {
 			global.DefaultModel = project.DefaultModel
 		}
+		if project.Embedding.Provider != "" {
+			global.Embedding.Provider = project.Embedding.Provider
+			// A provider switch invalidates a model chosen for the old provider.
+			global.Embedding.Model = ""
+		}
+		if project.Embedding.Model != "" {
+			global.Embedding.Model = project.Embedding.Model
+		}
+		if project.Embedding.Workers > 0 {
+			global.Embedding.Workers = project.Embedding.Workers
+		}
+		if project.Embedding.RateLimit > 0 {
+			global.Embedding.RateLimit = project.Embedding.RateLimit
+		}
+		if project.Embedding.StripBoilerplate {
+			global.Embedding.StripBoilerplate = true
+		}
+		if project.AutoExport != nil {
+			global.AutoExport = *project.AutoExport
+		}
+		project.Context.Apply(&global.Context)
 		for k, v := range project.Conventions {
 			_ = k
 			_ = v
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -18,6 +19,9 @@ func TestDefaultGlobal(t *testing.T) {
 	if cfg.Context.MaxTokens != 8000 {
 		t.Errorf("max tokens: got %d, want 8000", cfg.Context.MaxTokens)
 	}
+	if !cfg.Redaction.Enabled {
+		t.Error("redaction should be enabled by default")
+	}
 	if cfg.Context.ChunkMaxLines != 150 {
 		t.Errorf("chunk max lines: got %d, want 150", cfg.Context.ChunkMaxLines)
 	}
@@ -70,6 +74,35 @@ func TestProjectDBPath(t *testing.T) {
 	}
 }
 
+func TestProjectDBPath_Override(t *testing.T) {
+	root := t.TempDir()
+	SaveProject(root, ProjectConfig{DBPath: "data/memvra.db"})
+	if got, want := ProjectDBPath(root), filepath.Join(root, "data", "memvra.db"); got != want {
+		t.Errorf("relative db_path: got %q, want %q", got, want)
+	}
+
+	abs := filepath.Join(t.TempDir(), "shared.db")
+	SaveProject(root, ProjectConfig{DBPath: abs})
+	if got := ProjectDBPath(root); got != abs {
+		t.Errorf("absolute db_path: got %q, want %q", got, abs)
+	}
+
+	// An invalid value elsewhere in the config doesn't hide db_path.
+	SaveProject(root, ProjectConfig{DBPath: abs, Boost: map[string]float64{"vendor/": -1}})
+	if _, err := LoadProject(root); err == nil {
+		t.Fatal("expected LoadProject to reject the negative boost")
+	}
+	if got := ProjectDBPath(root); got != abs {
+		t.Errorf("invalid config: got %q, want %q", got, abs)
+	}
+
+	env := filepath.Join(t.TempDir(), "volume", "memvra.db")
+	t.Setenv(DBPathEnv, env)
+	if got := ProjectDBPath(root); got != env {
+		t.Errorf("%s should win over db_path: got %q, want %q", DBPathEnv, got, env)
+	}
+}
+
 func TestProjectConfigDirPath(t *testing.T) {
 	got := ProjectConfigDirPath("/home/user/project")
 	want := filepath.Join("/home/user/project", ".memvra")
@@ -154,3 +187,210 @@ func TestGlobalConfigPath(t *testing.T) {
 		t.Errorf("expected config.toml, got %q", filepath.Base(path))
 	}
 }
+
+func TestLoad_MergesProjectEmbedding(t *testing.T) {
+	dir := t.TempDir()
+	SaveProject(dir, ProjectConfig{Embedding: EmbeddingConfig{Provider: "gemini", Model: "text-embedding-004"}})
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	provider, model := cfg.EmbeddingSettings()
+	if provider != "gemini" || model != "text-embedding-004" {
+		t.Errorf("embedding settings: got %q/%q, want gemini/text-embedding-004", provider, model)
+	}
+}
+
+func TestLoad_DefaultProjectAutoExport(t *testing.T) {
+	dir := t.TempDir()
+	if err := SaveProject(dir, DefaultProject("demo")); err != nil {
+		t.Fatalf("SaveProject: %v", err)
+	}
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.AutoExport.Enabled {
+		t.Error("scaffolded project config should disable auto-export")
+	}
+	if len(cfg.AutoExport.Formats) != 1 || cfg.AutoExport.Formats[0] != "claude" {
+		t.Errorf("auto-export formats: got %v, want [claude]", cfg.AutoExport.Formats)
+	}
+}
+
+func TestLoadProject_AutoExportSessionsDefault(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".memvra"), 0o755)
+	os.WriteFile(ProjectConfigPath(dir), []byte("[auto_export]\nenabled = true\n"), 0o644)
+
+	pcfg, err := LoadProject(dir)
+	if err != nil {
+		t.Fatalf("LoadProject: %v", err)
+	}
+	if pcfg.AutoExport.Sessions != DefaultAutoExportSessions {
+		t.Errorf("unset sessions: got %d, want %d", pcfg.AutoExport.Sessions, DefaultAutoExportSessions)
+	}
+
+	os.WriteFile(ProjectConfigPath(dir), []byte("[auto_export]\nenabled = true\nsessions = 0\n"), 0o644)
+	pcfg, _ = LoadProject(dir)
+	if pcfg.AutoExport.Sessions != 0 {
+		t.Errorf("explicit zero sessions: got %d, want 0", pcfg.AutoExport.Sessions)
+	}
+}
+
+func TestLoad_NoProjectAutoExportKeepsGlobal(t *testing.T) {
+	dir := t.TempDir()
+	SaveProject(dir, ProjectConfig{DefaultModel: "openai"})
+
+	pcfg, _ := LoadProject(dir)
+	if pcfg.AutoExport != nil {
+		t.Fatalf("expected no [auto_export] section, got %+v", *pcfg.AutoExport)
+	}
+}
+
+func TestEmbeddingSettings_Defaults(t *testing.T) {
+	cfg := DefaultGlobal()
+	provider, model := cfg.EmbeddingSettings()
+	if provider != "ollama" {
+		t.Errorf("provider: got %q, want ollama", provider)
+	}
+	if model != "nomic-embed-text" {
+		t.Errorf("model: got %q, want nomic-embed-text", model)
+	}
+
+	cfg.DefaultEmbedder = "openai"
+	provider, model = cfg.EmbeddingSettings()
+	if provider != "openai" || model != "" {
+		t.Errorf("openai settings: got %q/%q, want openai/\"\"", provider, model)
+	}
+}
+
+func TestEmbeddingModelKey(t *testing.T) {
+	cfg := DefaultGlobal()
+	if got := cfg.EmbeddingModelKey(); got != "ollama:nomic-embed-text" {
+		t.Errorf("default key: got %q", got)
+	}
+
+	cfg.Embedding.Provider = "voyage"
+	cfg.Embedding.Model = "voyage-code-3"
+	if got := cfg.EmbeddingModelKey(); got != "voyage:voyage-code-3" {
+		t.Errorf("voyage key: got %q", got)
+	}
+}
+
+func TestLoadProject_ImportanceOverrides(t *testing.T) {
+	dir := t.TempDir()
+	SaveProject(dir, ProjectConfig{Importance: map[string]float64{"todo": 0.9}})
+
+	pcfg, err := LoadProject(dir)
+	if err != nil {
+		t.Fatalf("LoadProject: %v", err)
+	}
+	imp := pcfg.ImportanceDefaults()
+	if imp["todo"] != 0.9 {
+		t.Errorf("todo importance: got %v, want 0.9", imp["todo"])
+	}
+	if imp["decision"] != 0.8 {
+		t.Errorf("decision importance should keep default 0.8, got %v", imp["decision"])
+	}
+}
+
+func TestLoadProject_ImportanceOutOfRange(t *testing.T) {
+	dir := t.TempDir()
+	SaveProject(dir, ProjectConfig{Importance: map[string]float64{"note": 1.5}})
+
+	if _, err := LoadProject(dir); err == nil {
+		t.Fatal("expected error for importance outside [0, 1]")
+	}
+}
+
+func TestLoadProject_Boost(t *testing.T) {
+	dir := t.TempDir()
+	SaveProject(dir, ProjectConfig{Boost: map[string]float64{"docs/": 1.5, "vendor/": 0.5}})
+
+	pcfg, err := LoadProject(dir)
+	if err != nil {
+		t.Fatalf("LoadProject: %v", err)
+	}
+	if pcfg.Boost["docs/"] != 1.5 || pcfg.Boost["vendor/"] != 0.5 {
+		t.Errorf("unexpected boosts: %v", pcfg.Boost)
+	}
+
+	SaveProject(dir, ProjectConfig{Boost: map[string]float64{"vendor/": 0}})
+	if _, err := LoadProject(dir); err == nil {
+		t.Fatal("expected error for a non-positive boost")
+	}
+}
+
+func TestLoadProject_Ranking(t *testing.T) {
+	dir := t.TempDir()
+	SaveProject(dir, ProjectConfig{Ranking: RankingConfig{SimilarityWeight: 0.7, ImportanceWeight: 0.3}})
+
+	pcfg, err := LoadProject(dir)
+	if err != nil {
+		t.Fatalf("LoadProject: %v", err)
+	}
+	if pcfg.Ranking.SimilarityWeight != 0.7 || pcfg.Ranking.ImportanceWeight != 0.3 {
+		t.Errorf("unexpected ranking: %+v", pcfg.Ranking)
+	}
+
+	SaveProject(dir, ProjectConfig{Ranking: RankingConfig{RecencyWeight: -0.1}})
+	if _, err := LoadProject(dir); err == nil {
+		t.Fatal("expected error for a negative ranking weight")
+	}
+}
+
+func TestLoad_MergesProjectContext(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".memvra"), 0o755)
+	os.WriteFile(ProjectConfigPath(dir), []byte("[context]\nmax_tokens = 3000\ntop_k_sessions = 0\n"), 0o644)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Context.MaxTokens != 3000 {
+		t.Errorf("max_tokens: got %d, want 3000", cfg.Context.MaxTokens)
+	}
+	if cfg.Context.TopKSessions != 0 {
+		t.Errorf("top_k_sessions: an explicit 0 should override the global default, got %d", cfg.Context.TopKSessions)
+	}
+	if cfg.Context.TopKChunks != DefaultGlobal().Context.TopKChunks {
+		t.Errorf("top_k_chunks: unset field should keep the global value, got %d", cfg.Context.TopKChunks)
+	}
+
+	// Saving a config without [context] must not write the section.
+	SaveProject(dir, ProjectConfig{DefaultModel: "openai"})
+	data, _ := os.ReadFile(ProjectConfigPath(dir))
+	if strings.Contains(string(data), "[context]") {
+		t.Errorf("empty [context] section written:\n%s", data)
+	}
+
+	os.WriteFile(ProjectConfigPath(dir), []byte("[context]\ntop_k_chunks = -1\n"), 0o644)
+	if _, err := LoadProject(dir); err == nil {
+		t.Error("expected error for a negative top_k_chunks")
+	}
+}
+
+func TestProjectConfig_ModelSizedContext(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".memvra"), 0o755)
+
+	os.WriteFile(ProjectConfigPath(dir), []byte("default_model = \"claude\"\n"), 0o644)
+	pcfg, _ := LoadProject(dir)
+	if !pcfg.ModelSizedContext() {
+		t.Error("a default_model without [context] max_tokens should size the context")
+	}
+
+	os.WriteFile(ProjectConfigPath(dir), []byte("default_model = \"claude\"\n[context]\nmax_tokens = 3000\n"), 0o644)
+	pcfg, _ = LoadProject(dir)
+	if pcfg.ModelSizedContext() {
+		t.Error("an explicit max_tokens should win over default_model")
+	}
+
+	if (ProjectConfig{}).ModelSizedContext() {
+		t.Error("no default_model: the global budget applies")
+	}
+}
@@ -1,11 +1,16 @@
 package context
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"unicode"
 
 	"github.com/memvra/memvra/internal/memory"
 	"github.com/memvra/memvra/internal/scanner"
@@ -13,15 +18,121 @@ import (
 
 // BuildOptions controls how context is assembled.
 type BuildOptions struct {
-	Question            string
-	ProjectRoot         string   // used to resolve ExtraFiles relative paths
-	MaxTokens           int
-	TopKChunks          int
-	TopKMemories        int
-	TopKSessions        int      // how many recent session summaries to inject (0 = skip)
-	SessionTokenBudget  int      // max tokens for session history block
-	SimilarityThreshold float64
-	ExtraFiles          []string // paths to always include
+	Question           string
+	ProjectRoot        string // used to resolve ExtraFiles relative paths
+	MaxTokens          int    // 0 = derive from Model's context window
+	Model              string // target LLM, used to size MaxTokens when unset
+	TopKChunks         int
+	TopKMemories       int
+	TopKSessions       int // how many recent session summaries to inject (0 = skip)
+	SessionTokenBudget int // max tokens for session history block
+	// SessionSummaryTokens caps each session summary in the history block,
+	// cutting longer ones with an ellipsis so one long summary does not
+	// crowd out the rest (0 = DefaultSessionSummaryTokens, < 0 = no cap).
+	SessionSummaryTokens int
+	SimilarityThreshold  float64
+	// ChunkThreshold and MemoryThreshold override SimilarityThreshold for
+	// retrieved code and memories (see memory.RetrieveOptions).
+	ChunkThreshold  float64
+	MemoryThreshold float64
+	ExtraFiles      []string // paths to always include
+	// SystemPromptTypes are memory types pinned into the system prompt
+	// (nil = conventions and constraints).
+	SystemPromptTypes []memory.MemoryType
+	// ContextTypes are memory types allowed in the context body (nil =
+	// decisions, notes, todos). Decisions, conventions, and constraints listed
+	// here are pinned in full; other types enter only through retrieval.
+	// Types in neither list are excluded entirely, except custom types (see
+	// memory.RegisterType), which default to the body and are pinned there.
+	ContextTypes []memory.MemoryType
+	// MinImportance excludes memories below this importance (0 = keep all).
+	MinImportance float64
+	// MinConfidence excludes inferred memories recorded with a lower
+	// confidence (0 = keep all).
+	MinConfidence float64
+	// MaxMemories caps how many retrieved memories are packed into the
+	// context body, keeping the highest-ranked, however much budget is left
+	// (0 = unlimited). TopKMemories bounds retrieval; this bounds packing.
+	MaxMemories int
+	// ExcludePaths are gitignore-style patterns for files that must never be
+	// injected, whether requested via ExtraFiles or found by retrieval.
+	ExcludePaths []string
+	// Sources restricts memories to these Memory.Source values (empty = all).
+	Sources []string
+	// ProjectName replaces the stored project name when set (see the
+	// project display_name setting).
+	ProjectName string
+	// MaxFileBytes truncates each of ExtraFiles after this many bytes
+	// (0 = DefaultMaxFileBytes). Files over SkipFileBytes are not read at all.
+	MaxFileBytes int64
+	// StopPhrases are dropped from memory content when comparing memories
+	// for duplicates (nil = memory.DefaultStopPhrases, empty = none).
+	StopPhrases []string
+	// SectionOrder arranges the sections of ContextText, using the Section*
+	// names (nil = DefaultSectionOrder). Sections left out keep their default
+	// relative order after the listed ones. Order does not change budget
+	// priority: sections are still filled in the default order.
+	SectionOrder []string
+	// SessionTag prefers sessions carrying this tag in the history block;
+	// tagged sessions beyond the recent window are pulled in too. Untagged
+	// sessions still fill any remaining slots.
+	SessionTag string
+	// ContextLines widens retrieved chunks with this many neighbouring lines
+	// (see memory.RetrieveOptions.ContextLines). The added lines count
+	// against MaxTokens like the rest of the chunk.
+	ContextLines int
+	// SystemPromptTemplate replaces the built-in system prompt with a
+	// text/template executed with SystemPromptData (empty = built-in). A
+	// template that fails falls back to the built-in prompt with a warning.
+	SystemPromptTemplate string
+	// OpenTodos lists every todo memory in an "Open TODOs" section,
+	// regardless of relevance to Question, so the backlog is always visible.
+	// Todos are added most important first until the budget runs out. It has
+	// no effect when todos are excluded by ContextTypes.
+	OpenTodos bool
+	// OnSection, if set, receives each non-empty ContextText section as soon
+	// as it and every section ordered before it are final, so callers can
+	// stream output. Joined with "\n", the texts equal ContextText.
+	OnSection func(name, text string)
+}
+
+// Context sections, in the sense of BuildOptions.SectionOrder.
+const (
+	SectionFiles     = "files"     // explicitly requested files
+	SectionSessions  = "sessions"  // recent session summaries
+	SectionDecisions = "decisions" // pinned memory blocks (decisions by default)
+	SectionTodos     = "todos"     // open todos, see BuildOptions.OpenTodos
+	SectionMemories  = "memories"  // retrieved memories
+	SectionChunks    = "chunks"    // retrieved code chunks
+)
+
+// DefaultSectionOrder is the order of ContextText sections when
+// BuildOptions.SectionOrder is unset.
+var DefaultSectionOrder = []string{SectionFiles, SectionSessions, SectionDecisions, SectionTodos, SectionMemories, SectionChunks}
+
+// Size limits for ExtraFiles. Source files fit well within DefaultMaxFileBytes;
+// anything over SkipFileBytes (logs, dumps) is skipped rather than read.
+const (
+	DefaultMaxFileBytes int64 = 256 << 10
+	SkipFileBytes       int64 = 64 << 20
+)
+
+// DefaultSessionSummaryTokens is the per-session summary cap used when
+// BuildOptions.SessionSummaryTokens is unset.
+const DefaultSessionSummaryTokens = 150
+
+var (
+	defaultSystemPromptTypes = []memory.MemoryType{memory.TypeConvention, memory.TypeConstraint}
+	defaultContextTypes      = []memory.MemoryType{memory.TypeDecision, memory.TypeNote, memory.TypeTodo}
+)
+
+// pinnedTypes are injected in full wherever they are placed, as are custom
+// types (see isPinned). Other types are too numerous to pin and come in
+// through retrieval instead.
+var pinnedTypes = map[memory.MemoryType]bool{
+	memory.TypeDecision:   true,
+	memory.TypeConvention: true,
+	memory.TypeConstraint: true,
 }
 
 // BuiltContext is the result of a context build operation.
@@ -35,6 +146,53 @@ type BuiltContext struct {
 	// Sources lists what was included, for --verbose output.
 	// Each entry is a short human-readable label.
 	Sources []string
+	// SourceRefs is the machine-readable form of Sources, with one entry per
+	// included item (each injected session is listed individually).
+	SourceRefs []Source
+	// SystemPromptTokens is the size of SystemPrompt, which is not counted
+	// against MaxTokens.
+	SystemPromptTokens int
+	// NearModelLimit is set when SystemPrompt and ContextText together leave
+	// less room in the Model's context window than the completion reserve.
+	// A warning explaining it is added to Warnings.
+	NearModelLimit bool
+	// Warnings describe inputs that were skipped or cut short, such as
+	// oversized ExtraFiles.
+	Warnings []string
+}
+
+// Source types reported in SourceRefs.
+const (
+	SourceDecision = "decision"
+	SourceMemory   = "memory"
+	SourceSession  = "session"
+	SourceFile     = "file"
+	SourceChunk    = "chunk"
+)
+
+// Source identifies a single item included in a built context.
+type Source struct {
+	Type      string `json:"type"`
+	ID        string `json:"id"`             // memory, session, or chunk ID; the path for explicit files
+	Path      string `json:"path,omitempty"` // file path for file and chunk sources
+	StartLine int    `json:"start_line,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+}
+
+// AccessEntries returns an access log entry for each item in SourceRefs,
+// for memory.Store.RecordAccess. model names the model or client the
+// context was built for; sessionID may be empty.
+func (bc *BuiltContext) AccessEntries(model, sessionID string) []memory.AccessEntry {
+	entries := make([]memory.AccessEntry, 0, len(bc.SourceRefs))
+	for _, ref := range bc.SourceRefs {
+		entries = append(entries, memory.AccessEntry{
+			ItemID:    ref.ID,
+			ItemType:  ref.Type,
+			Model:     model,
+			SessionID: sessionID,
+		})
+	}
+	return entries
 }
 
 // Builder assembles token-budget-aware prompts from project memory.
@@ -45,6 +203,7 @@ type Builder struct {
 	}
 	formatter *Formatter
 	tokenizer *Tokenizer
+	global    *memory.Store // user-level memories; see SetGlobalStore
 }
 
 // NewBuilder creates a Builder.
@@ -64,10 +223,18 @@ func NewBuilder(
 	}
 }
 
+// SetGlobalStore adds the user-level memories in global (see
+// memory.OpenGlobalStore) to the system prompt, after the project's own.
+// A global memory is left out when a project memory of the same type
+// covers the same ground, so project memory wins on conflict.
+func (b *Builder) SetGlobalStore(global *memory.Store) {
+	b.global = global
+}
+
 // Build constructs the context for the given question within the token budget.
 func (b *Builder) Build(ctx context.Context, opts BuildOptions) (*BuiltContext, error) {
 	if opts.MaxTokens == 0 {
-		opts.MaxTokens = 8000
+		opts.MaxTokens = maxTokensForModel(opts.Model)
 	}
 	if opts.TopKChunks == 0 {
 		opts.TopKChunks = 10
@@ -75,61 +242,154 @@ func (b *Builder) Build(ctx context.Context, opts BuildOptions) (*BuiltContext,
 	if opts.TopKMemories == 0 {
 		opts.TopKMemories = 5
 	}
+	if opts.MaxFileBytes <= 0 {
+		opts.MaxFileBytes = DefaultMaxFileBytes
+	}
 	if opts.SimilarityThreshold == 0 {
 		opts.SimilarityThreshold = 0.3
 	}
 	if opts.SessionTokenBudget == 0 {
 		opts.SessionTokenBudget = 500
 	}
+	if opts.SessionSummaryTokens == 0 {
+		opts.SessionSummaryTokens = DefaultSessionSummaryTokens
+	}
+	if opts.SystemPromptTypes == nil {
+		opts.SystemPromptTypes = defaultSystemPromptTypes
+	}
+	if opts.ContextTypes == nil {
+		opts.ContextTypes = defaultContextTypes
+	}
+	opts.ContextTypes = withCustomTypes(opts.ContextTypes, opts.SystemPromptTypes)
+	if opts.StopPhrases == nil {
+		opts.StopPhrases = memory.DefaultStopPhrases
+	}
+
+	order, orderWarnings := resolveSectionOrder(opts.SectionOrder)
 
 	remaining := opts.MaxTokens
-	var contextSections []string
+	contextSections := make(map[string][]string, len(order))
 	var sources []string
+	var refs []Source
+	warnings := orderWarnings
+
+	// finish marks sections final and passes every leading run of finished
+	// sections to opts.OnSection, in output order.
+	finished := make(map[string]bool, len(order))
+	next := 0
+	finish := func(names ...string) {
+		for _, name := range names {
+			finished[name] = true
+		}
+		for ; next < len(order) && finished[order[next]]; next++ {
+			blocks := contextSections[order[next]]
+			if opts.OnSection != nil && len(blocks) > 0 {
+				opts.OnSection(order[next], strings.Join(blocks, "\n"))
+			}
+		}
+	}
 
 	// --- Step 1: Project profile (always included) ---
 	proj, err := b.store.GetProject()
 	if err != nil {
 		proj = memory.Project{Name: "unknown"}
 	}
+	if opts.ProjectName != "" {
+		proj.Name = opts.ProjectName
+	}
 	ts, _ := scanner.TechStackFromJSON(proj.TechStack)
 
-	// --- Step 2: Conventions + constraints (always included) ---
-	conventions, _ := b.store.ListMemories(memory.TypeConvention)
-	constraints, _ := b.store.ListMemories(memory.TypeConstraint)
-	decisions, _ := b.store.ListMemories(memory.TypeDecision)
+	// --- Step 2: System-prompt memory types (conventions + constraints by default) ---
+	// Track memories already injected so retrieval doesn't repeat them.
+	// Deduplication is by identity (ID or content), not by type, so a
+	// relevant decision missing from the decisions block still gets in.
+	included := newMemorySet(memory.NewNormalizer(opts.StopPhrases))
+	inSystemPrompt := make(map[memory.MemoryType]bool, len(opts.SystemPromptTypes))
+	var promptGroups []MemoryGroup
+	for _, t := range opts.SystemPromptTypes {
+		if inSystemPrompt[t] {
+			continue
+		}
+		inSystemPrompt[t] = true
+		items, _ := b.store.ListMemories(t)
+		items = filterMemories(items, opts)
+		if b.global != nil {
+			shared, _ := b.global.ListMemories(t)
+			items = append(items, withoutConflicts(filterMemories(shared, opts), items)...)
+		}
+		promptGroups = append(promptGroups, MemoryGroup{Type: t, Items: items})
+		included.add(items...)
+	}
+
+	systemPrompt, err := b.formatter.RenderSystemPrompt(opts.SystemPromptTemplate, proj, ts, promptGroups)
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("%v; using the built-in system prompt", err))
+		systemPrompt = b.formatter.FormatSystemPromptGroups(proj, ts, promptGroups)
+	}
 
-	systemPrompt := b.formatter.FormatSystemPrompt(proj, ts, conventions, constraints)
+	inContext := make(map[memory.MemoryType]bool, len(opts.ContextTypes))
+	for _, t := range opts.ContextTypes {
+		if !inSystemPrompt[t] {
+			inContext[t] = true
+		}
+	}
+
+	excluded := scanner.NewPathMatcher(opts.ExcludePaths)
 
 	// --- Step 3: Explicitly requested files (highest priority, always included) ---
 	for _, relPath := range opts.ExtraFiles {
+		if excluded.Match(relPath) {
+			continue
+		}
 		absPath := relPath
 		if opts.ProjectRoot != "" && !filepath.IsAbs(relPath) {
 			absPath = filepath.Join(opts.ProjectRoot, relPath)
 		}
-		content, err := os.ReadFile(absPath)
-		if err != nil {
+		info, err := os.Stat(absPath)
+		if err != nil || info.IsDir() {
 			// File not found or unreadable — skip gracefully.
 			continue
 		}
+		if info.Size() > SkipFileBytes {
+			warnings = append(warnings, fmt.Sprintf("skipped %s: %d MB exceeds the %d MB limit", relPath, info.Size()>>20, SkipFileBytes>>20))
+			continue
+		}
+		content, truncated, err := readHead(absPath, opts.MaxFileBytes)
+		if err != nil {
+			continue
+		}
 		c := memory.Chunk{
-			Content:   string(content),
+			Content:   content,
 			StartLine: 1,
-			EndLine:   strings.Count(string(content), "\n") + 1,
+			EndLine:   strings.Count(content, "\n") + 1,
 			ChunkType: "code",
 		}
+		if truncated {
+			c.Content += fmt.Sprintf("\n... (truncated: first %d of %d bytes)", len(content), info.Size())
+			warnings = append(warnings, fmt.Sprintf("truncated %s to its first %d KB", relPath, opts.MaxFileBytes>>10))
+		}
 		block := b.formatter.FormatChunk(c, relPath)
 		tokens := b.tokenizer.Count(block)
 		if tokens <= remaining {
-			contextSections = append(contextSections, block)
+			contextSections[SectionFiles] = append(contextSections[SectionFiles], block)
 			remaining -= tokens
 			sources = append(sources, fmt.Sprintf("file (explicit): %s", relPath))
+			refs = append(refs, Source{Type: SourceFile, ID: relPath, Path: relPath})
 		}
 	}
 
+	finish(SectionFiles)
+
 	// --- Step 3b: Recent session summaries (budget-gated) ---
 	sessionsUsed := 0
 	if opts.TopKSessions > 0 && remaining > 200 {
-		sessions, _ := b.store.GetLastNSessions(opts.TopKSessions)
+		candidates, _ := b.store.GetLastNSessions(opts.TopKSessions * sessionCandidateFactor)
+		if opts.SessionTag != "" {
+			tagged, _ := b.store.GetLastNSessionsTagged(opts.TopKSessions, opts.SessionTag)
+			candidates = mergeSessions(candidates, tagged)
+		}
+		sessions := pickSessions(candidates, opts.TopKSessions, opts.SessionTag)
+		sessions = b.capSummaries(sessions, opts.SessionSummaryTokens)
 		if len(sessions) > 0 {
 			block := b.formatter.FormatSessionHistory(sessions)
 			tokens := b.tokenizer.Count(block)
@@ -138,34 +398,89 @@ func (b *Builder) Build(ctx context.Context, opts BuildOptions) (*BuiltContext,
 				allowed = remaining
 			}
 			if tokens <= allowed {
-				contextSections = append(contextSections, block)
+				contextSections[SectionSessions] = append(contextSections[SectionSessions], block)
 				remaining -= tokens
 				sessionsUsed = len(sessions)
 				sources = append(sources, fmt.Sprintf("recent sessions: %d", len(sessions)))
+				for _, sess := range sessions {
+					refs = append(refs, Source{Type: SourceSession, ID: sess.ID})
+				}
 			}
 		}
 	}
 
+	finish(SectionSessions)
+
 	// --- Step 4: Retrieve semantically relevant content ---
 	retrieval, _ := b.orchestrator.Retrieve(ctx, opts.Question, memory.RetrieveOptions{
 		TopKChunks:          opts.TopKChunks,
 		TopKMemories:        opts.TopKMemories,
 		SimilarityThreshold: opts.SimilarityThreshold,
+		ChunkThreshold:      opts.ChunkThreshold,
+		MemoryThreshold:     opts.MemoryThreshold,
+		Sources:             opts.Sources,
+		ContextLines:        opts.ContextLines,
 	})
+	if retrieval != nil {
+		if w := retrieval.FallbackWarning(); w != "" {
+			warnings = append(warnings, w)
+		}
+	}
 
-	// --- Step 5: Decision block ---
-	if len(decisions) > 0 {
-		block := b.formatter.FormatMemories(memory.TypeDecision, decisions)
+	// --- Step 5: Pinned context blocks (decisions by default) ---
+	for _, t := range opts.ContextTypes {
+		if !inContext[t] || !isPinned(t) {
+			continue
+		}
+		items, _ := b.store.ListMemories(t)
+		items = filterMemories(items, opts)
+		if len(items) == 0 {
+			continue
+		}
+		block := b.formatter.FormatMemories(t, items)
 		tokens := b.tokenizer.Count(block)
 		if tokens <= remaining {
-			contextSections = append(contextSections, block)
+			contextSections[SectionDecisions] = append(contextSections[SectionDecisions], block)
+			remaining -= tokens
+			for _, m := range items {
+				sources = append(sources, fmt.Sprintf("%s: %s", t, truncateStr(m.Content, 60)))
+				refs = append(refs, Source{Type: memorySourceType(t), ID: m.ID})
+			}
+			included.add(items...)
+		}
+	}
+
+	finish(SectionDecisions)
+
+	// --- Step 5b: Open todos, whatever the question (optional) ---
+	if opts.OpenTodos && inContext[memory.TypeTodo] {
+		todos, _ := b.store.ListMemories(memory.TypeTodo)
+		var fit []memory.Memory
+		block, tokens := "", 0
+		for _, m := range filterMemories(todos, opts) {
+			if included.has(m) {
+				continue
+			}
+			next := b.formatter.FormatOpenTodos(append(fit, m))
+			nextTokens := b.tokenizer.Count(next)
+			if nextTokens > remaining {
+				break
+			}
+			fit, block, tokens = append(fit, m), next, nextTokens
+		}
+		if len(fit) > 0 {
+			contextSections[SectionTodos] = append(contextSections[SectionTodos], block)
 			remaining -= tokens
-			for _, d := range decisions {
-				sources = append(sources, fmt.Sprintf("decision: %s", truncateStr(d.Content, 60)))
+			for _, m := range fit {
+				sources = append(sources, fmt.Sprintf("todo: %s", truncateStr(m.Content, 60)))
+				refs = append(refs, Source{Type: SourceMemory, ID: m.ID})
 			}
+			included.add(fit...)
 		}
 	}
 
+	finish(SectionTodos)
+
 	// --- Step 6: Fill remaining budget with retrieved chunks and memories ---
 	chunksUsed := 0
 	memoriesUsed := 0
@@ -173,52 +488,102 @@ func (b *Builder) Build(ctx context.Context, opts BuildOptions) (*BuiltContext,
 	if retrieval != nil {
 		// Add relevant memories first.
 		for _, m := range retrieval.Memories {
-			if m.MemoryType == memory.TypeConvention || m.MemoryType == memory.TypeConstraint || m.MemoryType == memory.TypeDecision {
-				continue // Already included via system prompt or decisions block.
+			if opts.MaxMemories > 0 && memoriesUsed >= opts.MaxMemories {
+				break
 			}
-			block := "- " + m.Content + "\n"
+			if included.has(m) || !inContext[m.MemoryType] {
+				continue // Already pinned, or this type is excluded from the body.
+			}
+			if m.Done() || m.Importance < opts.MinImportance || m.BelowConfidence(opts.MinConfidence) {
+				continue
+			}
+			block := formatMemoryItem(m)
 			tokens := b.tokenizer.Count(block)
 			if tokens <= remaining {
-				contextSections = append(contextSections, block)
+				contextSections[SectionMemories] = append(contextSections[SectionMemories], block)
 				remaining -= tokens
 				memoriesUsed++
+				included.add(m)
 				sources = append(sources, fmt.Sprintf("memory (%s): %s", m.MemoryType, truncateStr(m.Content, 60)))
+				refs = append(refs, Source{Type: memorySourceType(m.MemoryType), ID: m.ID})
 			}
 		}
 
-		// Add relevant chunks.
+		// Add relevant chunks, resolving every file path in one query.
+		// Chunks that are cut short or left out for budget are listed as
+		// related files, so the assistant still knows where to look.
+		files, _ := b.store.GetFilesByIDs(chunkFileIDs(retrieval.Chunks))
+		var related []string
+		packing := true
 		for _, c := range retrieval.Chunks {
-			// Resolve file path from the file record.
-			filePath := ""
-			if file, err := b.store.GetFileByID(c.FileID); err == nil {
-				filePath = file.Path
+			filePath := files[c.FileID].Path
+			if filePath != "" && excluded.Match(filePath) {
+				continue // Indexed before the path was excluded.
+			}
+			if !packing {
+				related = appendRelated(related, c, filePath)
+				continue
 			}
 			block := b.formatter.FormatChunk(c, filePath)
 			tokens := b.tokenizer.Count(block)
 			if tokens <= remaining {
-				contextSections = append(contextSections, block)
+				contextSections[SectionChunks] = append(contextSections[SectionChunks], block)
 				remaining -= tokens
 				chunksUsed++
 				sources = append(sources, fmt.Sprintf("chunk: %s:%d-%d", filePath, c.StartLine, c.EndLine))
-			} else if remaining > 100 {
-				// Truncate the chunk to fit.
-				truncated := b.tokenizer.Truncate(c.Content, remaining-50)
-				c.Content = truncated
-				block = b.formatter.FormatChunk(c, filePath)
-				contextSections = append(contextSections, block)
-				remaining = 0
+				refs = append(refs, chunkSource(c, filePath))
+				continue
+			}
+			packing = false
+			if block, tokens, ok := b.fitChunk(c, filePath, remaining-relatedFilesReserve); ok {
+				// Show as much of the chunk as fits rather than dropping it.
+				contextSections[SectionChunks] = append(contextSections[SectionChunks], block)
+				remaining -= tokens
 				chunksUsed++
 				sources = append(sources, fmt.Sprintf("chunk (truncated): %s:%d-%d", filePath, c.StartLine, c.EndLine))
-				break
-			} else {
+				refs = append(refs, chunkSource(c, filePath))
+			}
+			related = appendRelated(related, c, filePath)
+		}
+
+		var fit []string
+		block, tokens := "", 0
+		for _, r := range related {
+			next := b.formatter.FormatRelatedFiles(append(fit, r))
+			nextTokens := b.tokenizer.Count(next)
+			if nextTokens > remaining {
 				break
 			}
+			fit, block, tokens = append(fit, r), next, nextTokens
+		}
+		if len(fit) > 0 {
+			contextSections[SectionChunks] = append(contextSections[SectionChunks], block)
+			remaining -= tokens
 		}
 	}
 
-	contextText := strings.Join(contextSections, "\n")
+	finish(SectionMemories, SectionChunks)
+
+	var ordered []string
+	for _, name := range order {
+		ordered = append(ordered, contextSections[name]...)
+	}
+	contextText := strings.Join(ordered, "\n")
 	tokensUsed := opts.MaxTokens - remaining
 
+	// MaxTokens only budgets the context body; the system prompt comes on
+	// top, so check the sum against the model's real window.
+	systemTokens := b.tokenizer.Count(systemPrompt)
+	nearLimit := false
+	if window, ok := ModelContextWindow(opts.Model); ok {
+		if limit := maxTokensForModel(opts.Model); systemTokens+tokensUsed > limit {
+			nearLimit = true
+			warnings = append(warnings, fmt.Sprintf(
+				"system prompt (%d tokens) plus context (%d tokens) leaves under %d of %s's %d-token window for the response; lower max_tokens or trim pinned memories",
+				systemTokens, tokensUsed, window-limit, opts.Model, window))
+		}
+	}
+
 	return &BuiltContext{
 		SystemPrompt: systemPrompt,
 		ContextText:  contextText,
@@ -227,12 +592,295 @@ func (b *Builder) Build(ctx context.Context, opts BuildOptions) (*BuiltContext,
 		MemoriesUsed: memoriesUsed,
 		SessionsUsed: sessionsUsed,
 		Sources:      sources,
+		SourceRefs:   refs,
+		Warnings:     warnings,
+
+		SystemPromptTokens: systemTokens,
+		NearModelLimit:     nearLimit,
 	}, nil
 }
 
+// resolveSectionOrder returns order with unknown and repeated names dropped
+// and missing sections appended in their default order, plus a warning for
+// each name it dropped.
+func resolveSectionOrder(order []string) ([]string, []string) {
+	known := make(map[string]bool, len(DefaultSectionOrder))
+	for _, name := range DefaultSectionOrder {
+		known[name] = true
+	}
+	var resolved, warnings []string
+	seen := make(map[string]bool, len(DefaultSectionOrder))
+	for _, name := range order {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch {
+		case !known[name]:
+			warnings = append(warnings, fmt.Sprintf("ignoring unknown context section %q (valid: %s)", name, strings.Join(DefaultSectionOrder, ", ")))
+		case seen[name]:
+			warnings = append(warnings, fmt.Sprintf("ignoring repeated context section %q", name))
+		default:
+			seen[name] = true
+			resolved = append(resolved, name)
+		}
+	}
+	for _, name := range DefaultSectionOrder {
+		if !seen[name] {
+			resolved = append(resolved, name)
+		}
+	}
+	return resolved, warnings
+}
+
+// readHead reads at most limit bytes of the file at path, cut back to the
+// last complete line, and reports whether anything was left out.
+func readHead(path string, limit int64) (string, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false, err
+	}
+	defer func() { _ = f.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return "", false, err
+	}
+	if int64(len(data)) <= limit {
+		return string(data), false, nil
+	}
+	data = data[:limit]
+	if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+		data = data[:i]
+	}
+	return string(data), true, nil
+}
+
+// truncatedMarker ends a chunk cut short to fit the token budget.
+const truncatedMarker = "\n... (truncated)"
+
+// minTruncatedChunkTokens is the least chunk content worth showing once
+// truncated; with less room the chunk is left out.
+const minTruncatedChunkTokens = 50
+
+// fitChunk formats c cut down, with truncatedMarker, to fit in budget
+// tokens. It returns the block and its token count, or false when less
+// than minTruncatedChunkTokens of content would fit.
+func (b *Builder) fitChunk(c memory.Chunk, filePath string, budget int) (string, int, bool) {
+	content := c.Content
+	c.Content = truncatedMarker
+	avail := budget - b.tokenizer.Count(b.formatter.FormatChunk(c, filePath))
+	for avail >= minTruncatedChunkTokens {
+		c.Content = b.tokenizer.Truncate(content, avail) + truncatedMarker
+		block := b.formatter.FormatChunk(c, filePath)
+		tokens := b.tokenizer.Count(block)
+		if tokens <= budget {
+			return block, tokens, true
+		}
+		// Tokens can merge differently across the cut; shrink and retry.
+		avail -= tokens - budget
+	}
+	return "", 0, false
+}
+
+// relatedFilesReserve is kept back from a truncated chunk so the related
+// files footer has room for a few entries.
+const relatedFilesReserve = 60
+
+// appendRelated adds c's file:line pointer to related, skipping chunks
+// without a path and pointers already listed.
+func appendRelated(related []string, c memory.Chunk, filePath string) []string {
+	if filePath == "" {
+		return related
+	}
+	ref := fmt.Sprintf("%s:%d-%d", filePath, c.StartLine, c.EndLine)
+	if slices.Contains(related, ref) {
+		return related
+	}
+	return append(related, ref)
+}
+
+// isPinned reports whether memories of type t are injected in full, each
+// type in its own block, rather than through retrieval.
+func isPinned(t memory.MemoryType) bool {
+	return pinnedTypes[t] || !memory.IsBuiltinType(t)
+}
+
+// withCustomTypes appends to contextTypes every registered custom type
+// placed in neither list, so customs land in the context body by default.
+// contextTypes itself is not modified.
+func withCustomTypes(contextTypes, promptTypes []memory.MemoryType) []memory.MemoryType {
+	out := contextTypes
+	for _, t := range memory.CustomTypes() {
+		if !slices.Contains(contextTypes, t) && !slices.Contains(promptTypes, t) {
+			out = append(out[:len(out):len(out)], t)
+		}
+	}
+	return out
+}
+
+// memorySet records memories by ID and by normalised content so the same
+// memory is recognised whether or not it carries an ID.
+type memorySet struct {
+	keys       map[string]struct{}
+	normalizer *memory.Normalizer
+}
+
+func newMemorySet(n *memory.Normalizer) memorySet {
+	return memorySet{keys: make(map[string]struct{}), normalizer: n}
+}
+
+func (s memorySet) add(mems ...memory.Memory) {
+	for _, m := range mems {
+		if m.ID != "" {
+			s.keys["id:"+m.ID] = struct{}{}
+		}
+		s.keys["content:"+s.normalizer.Normalize(m.Content)] = struct{}{}
+	}
+}
+
+func (s memorySet) has(m memory.Memory) bool {
+	if m.ID != "" {
+		if _, ok := s.keys["id:"+m.ID]; ok {
+			return true
+		}
+	}
+	_, ok := s.keys["content:"+s.normalizer.Normalize(m.Content)]
+	return ok
+}
+
+// globalConflictSimilarity is the word overlap (see memory.WordSimilarity)
+// at which a global memory is taken to address the same thing as a project
+// memory, e.g. "indent with tabs" and "indent with spaces".
+const globalConflictSimilarity = 0.5
+
+// withoutConflicts returns the global memories that don't conflict with
+// any of the project memories.
+func withoutConflicts(global, project []memory.Memory) []memory.Memory {
+	var out []memory.Memory
+	for _, g := range global {
+		conflict := false
+		for _, p := range project {
+			if memory.WordSimilarity(g.Content, p.Content) >= globalConflictSimilarity {
+				conflict = true
+				break
+			}
+		}
+		if !conflict {
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+// memorySourceType maps a memory type to its SourceRefs type.
+func memorySourceType(t memory.MemoryType) string {
+	if t == memory.TypeDecision {
+		return SourceDecision
+	}
+	return SourceMemory
+}
+
+func chunkSource(c memory.Chunk, filePath string) Source {
+	return Source{Type: SourceChunk, ID: c.ID, Path: filePath, StartLine: c.StartLine, EndLine: c.EndLine}
+}
+
+// chunkFileIDs returns the distinct file IDs referenced by chunks.
+func chunkFileIDs(chunks []memory.Chunk) []string {
+	seen := make(map[string]bool, len(chunks))
+	ids := make([]string, 0, len(chunks))
+	for _, c := range chunks {
+		if !seen[c.FileID] {
+			seen[c.FileID] = true
+			ids = append(ids, c.FileID)
+		}
+	}
+	return ids
+}
+
 func truncateStr(s string, max int) string {
 	if len(s) <= max {
 		return s
 	}
 	return s[:max] + "..."
 }
+
+// filterMemories applies the importance, confidence, and source filters in
+// opts to memories listed from the store.
+func filterMemories(items []memory.Memory, opts BuildOptions) []memory.Memory {
+	items = memory.FilterOpen(items)
+	items = memory.FilterByImportance(items, opts.MinImportance)
+	items = memory.FilterByConfidence(items, opts.MinConfidence)
+	return memory.FilterBySource(items, opts.Sources)
+}
+
+// sessionCandidateFactor widens the pool of recent sessions considered for
+// the history block, so unfinished work a few sessions back can still make
+// the cut.
+const sessionCandidateFactor = 3
+
+// capSummaries returns sessions with each ResponseSummary cut to at most
+// limit tokens, ending in an ellipsis where it was cut. A limit below 0
+// leaves them whole.
+func (b *Builder) capSummaries(sessions []memory.Session, limit int) []memory.Session {
+	if limit < 0 {
+		return sessions
+	}
+	out := make([]memory.Session, len(sessions))
+	for i, s := range sessions {
+		if b.tokenizer.Count(s.ResponseSummary) > limit {
+			s.ResponseSummary = strings.TrimRightFunc(b.tokenizer.Truncate(s.ResponseSummary, limit), unicode.IsSpace) + "…"
+		}
+		out[i] = s
+	}
+	return out
+}
+
+// pickSessions chooses up to n of candidates (newest first), taking
+// sessions tagged tag (if set), then in-progress and blocked sessions,
+// before the rest so the next assistant sees what was left open. The result
+// stays newest first.
+func pickSessions(candidates []memory.Session, n int, tag string) []memory.Session {
+	if len(candidates) <= n {
+		return candidates
+	}
+	keep := make(map[int]bool, n)
+	if tag != "" {
+		for i, s := range candidates {
+			if len(keep) < n && s.HasTag(tag) {
+				keep[i] = true
+			}
+		}
+	}
+	for i, s := range candidates {
+		if len(keep) < n && s.Unfinished() {
+			keep[i] = true
+		}
+	}
+	for i := range candidates {
+		if len(keep) < n {
+			keep[i] = true
+		}
+	}
+	out := make([]memory.Session, 0, n)
+	for i, s := range candidates {
+		if keep[i] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// mergeSessions adds the sessions in extra missing from recent and returns
+// the union newest first.
+func mergeSessions(recent, extra []memory.Session) []memory.Session {
+	seen := make(map[string]bool, len(recent))
+	for _, s := range recent {
+		seen[s.ID] = true
+	}
+	out := recent
+	for _, s := range extra {
+		if !seen[s.ID] {
+			out = append(out, s)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out
+}
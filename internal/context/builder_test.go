@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -33,10 +34,7 @@ func setupBuilderTestDB(t *testing.T, orch *stubOrchestrator) (*db.DB, *memory.S
 	t.Cleanup(func() { database.Close() })
 
 	store := memory.NewStore(database)
-	tokenizer, err := NewTokenizer()
-	if err != nil {
-		t.Fatalf("NewTokenizer: %v", err)
-	}
+	tokenizer := NewTokenizer()
 	formatter := NewFormatter()
 	builder := NewBuilder(store, orch, formatter, tokenizer)
 	return database, store, builder
@@ -117,6 +115,77 @@ func TestBuilder_Build_ConventionsAndConstraints(t *testing.T) {
 	}
 }
 
+func TestBuilder_Build_GlobalMemories(t *testing.T) {
+	orch := &stubOrchestrator{result: &memory.RetrievalResult{}}
+	_, store, builder := setupBuilderTestDB(t, orch)
+	seedProject(t, store)
+	store.InsertMemory(memory.Memory{Content: "indent with spaces", MemoryType: memory.TypeConvention, Importance: 0.7})
+
+	globalDB, err := db.Open(filepath.Join(t.TempDir(), "global.db"))
+	if err != nil {
+		t.Fatalf("open global db: %v", err)
+	}
+	t.Cleanup(func() { globalDB.Close() })
+	global := memory.NewStore(globalDB)
+	global.InsertMemory(memory.Memory{Content: "indent with tabs", MemoryType: memory.TypeConvention, Importance: 0.7})
+	global.InsertMemory(memory.Memory{Content: "prefer table-driven tests", MemoryType: memory.TypeConvention, Importance: 0.7})
+	global.InsertMemory(memory.Memory{Content: "never commit secrets", MemoryType: memory.TypeConstraint, Importance: 0.8})
+	builder.SetGlobalStore(global)
+
+	result, err := builder.Build(context.Background(), BuildOptions{Question: "style"})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	for _, want := range []string{"indent with spaces", "prefer table-driven tests", "never commit secrets"} {
+		if !strings.Contains(result.SystemPrompt, want) {
+			t.Errorf("system prompt missing %q:\n%s", want, result.SystemPrompt)
+		}
+	}
+	// The project's own convention wins over the conflicting global one.
+	if strings.Contains(result.SystemPrompt, "indent with tabs") {
+		t.Errorf("conflicting global memory should be dropped:\n%s", result.SystemPrompt)
+	}
+}
+
+func TestBuilder_Build_SystemPromptTemplate(t *testing.T) {
+	orch := &stubOrchestrator{result: &memory.RetrievalResult{}}
+	_, store, builder := setupBuilderTestDB(t, orch)
+	seedProject(t, store)
+	store.InsertMemory(memory.Memory{Content: "use camelCase", MemoryType: memory.TypeConvention, Importance: 0.7})
+	store.InsertMemory(memory.Memory{Content: "never expose API keys", MemoryType: memory.TypeConstraint, Importance: 0.8})
+
+	tmpl := `House rules for {{.Project}} ({{.Stack.Language}}):
+{{range .Conventions}}* {{.}}
+{{end}}{{range .Constraints}}! {{.}}
+{{end}}Always write tests.`
+	result, err := builder.Build(context.Background(), BuildOptions{
+		Question:             "code review",
+		SystemPromptTemplate: tmpl,
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	want := "House rules for testproject (Go):\n* use camelCase\n! never expose API keys\nAlways write tests."
+	if result.SystemPrompt != want {
+		t.Errorf("system prompt =\n%s\nwant\n%s", result.SystemPrompt, want)
+	}
+
+	// A broken template falls back to the built-in prompt with a warning.
+	result, err = builder.Build(context.Background(), BuildOptions{
+		Question:             "code review",
+		SystemPromptTemplate: "{{.NoSuchField}}",
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if !strings.Contains(result.SystemPrompt, `"testproject"`) || !strings.Contains(result.SystemPrompt, "When answering:") {
+		t.Errorf("expected the built-in prompt, got:\n%s", result.SystemPrompt)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "system prompt template") {
+		t.Errorf("warnings = %q, want one about the template", result.Warnings)
+	}
+}
+
 func TestBuilder_Build_Decisions(t *testing.T) {
 	orch := &stubOrchestrator{result: &memory.RetrievalResult{}}
 	_, store, builder := setupBuilderTestDB(t, orch)
@@ -200,7 +269,8 @@ func TestBuilder_Build_RetrievedMemories(t *testing.T) {
 }
 
 func TestBuilder_Build_SkipsDuplicateTypes(t *testing.T) {
-	// Orchestrator returns a convention — should be skipped since it's already in system prompt.
+	// Orchestrator returns memories already injected via the system prompt
+	// or decisions block — they should be skipped.
 	orch := &stubOrchestrator{
 		result: &memory.RetrievalResult{
 			Memories: []memory.Memory{
@@ -213,6 +283,9 @@ func TestBuilder_Build_SkipsDuplicateTypes(t *testing.T) {
 	}
 	_, store, builder := setupBuilderTestDB(t, orch)
 	seedProject(t, store)
+	store.InsertMemory(memory.Memory{Content: "use camelCase", MemoryType: memory.TypeConvention, Importance: 0.7})
+	store.InsertMemory(memory.Memory{Content: "always validate", MemoryType: memory.TypeConstraint, Importance: 0.8})
+	store.InsertMemory(memory.Memory{Content: "decided X", MemoryType: memory.TypeDecision, Importance: 0.8})
 
 	result, err := builder.Build(context.Background(), BuildOptions{
 		Question: "review",
@@ -226,6 +299,185 @@ func TestBuilder_Build_SkipsDuplicateTypes(t *testing.T) {
 	}
 }
 
+func TestBuilder_Build_IncludesDistinctRetrievedDecision(t *testing.T) {
+	orch := &stubOrchestrator{
+		result: &memory.RetrievalResult{
+			Memories: []memory.Memory{
+				{Content: "Use PostgreSQL", MemoryType: memory.TypeDecision, Importance: 0.8},
+				{Content: "Cache sessions in Redis", MemoryType: memory.TypeDecision, Importance: 0.8},
+			},
+		},
+	}
+	_, store, builder := setupBuilderTestDB(t, orch)
+	seedProject(t, store)
+	store.InsertMemory(memory.Memory{Content: "Use PostgreSQL", MemoryType: memory.TypeDecision, Importance: 0.8})
+
+	result, err := builder.Build(context.Background(), BuildOptions{Question: "caching"})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if !strings.Contains(result.ContextText, "Cache sessions in Redis") {
+		t.Error("distinct retrieved decision should be included")
+	}
+	if strings.Count(result.ContextText, "Use PostgreSQL") != 1 {
+		t.Error("stored decision should appear exactly once")
+	}
+	if result.MemoriesUsed != 1 {
+		t.Errorf("expected 1 retrieved memory used, got %d", result.MemoriesUsed)
+	}
+}
+
+func TestBuilder_Build_ConfigurableTypePlacement(t *testing.T) {
+	orch := &stubOrchestrator{
+		result: &memory.RetrievalResult{
+			Memories: []memory.Memory{
+				{Content: "scratch note", MemoryType: memory.TypeNote, Importance: 0.5},
+				{Content: "fix login", MemoryType: memory.TypeTodo, Importance: 0.5},
+			},
+		},
+	}
+	_, store, builder := setupBuilderTestDB(t, orch)
+	seedProject(t, store)
+	store.InsertMemory(memory.Memory{Content: "Use PostgreSQL", MemoryType: memory.TypeDecision, Importance: 0.8})
+	store.InsertMemory(memory.Memory{Content: "use camelCase", MemoryType: memory.TypeConvention, Importance: 0.7})
+
+	result, err := builder.Build(context.Background(), BuildOptions{
+		Question:          "anything",
+		SystemPromptTypes: []memory.MemoryType{memory.TypeConvention, memory.TypeDecision},
+		ContextTypes:      []memory.MemoryType{memory.TypeTodo},
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if !strings.Contains(result.SystemPrompt, "Use PostgreSQL") {
+		t.Error("decision should be in the system prompt")
+	}
+	if strings.Contains(result.ContextText, "Use PostgreSQL") {
+		t.Error("decision should not be repeated in the context body")
+	}
+	if strings.Contains(result.ContextText, "scratch note") {
+		t.Error("notes should be excluded when not in ContextTypes")
+	}
+	if !strings.Contains(result.ContextText, "fix login") {
+		t.Error("todos in ContextTypes should be included")
+	}
+}
+
+func TestBuilder_Build_CustomTypes(t *testing.T) {
+	if err := memory.RegisterTypes([]string{"risk", "glossary"}); err != nil {
+		t.Fatalf("RegisterTypes: %v", err)
+	}
+	orch := &stubOrchestrator{result: &memory.RetrievalResult{}}
+	_, store, builder := setupBuilderTestDB(t, orch)
+	seedProject(t, store)
+	store.InsertMemory(memory.Memory{Content: "vendor API may be sunset", MemoryType: "risk", Importance: 0.5})
+	store.InsertMemory(memory.Memory{Content: "SKU: stock keeping unit", MemoryType: "glossary", Importance: 0.5})
+
+	// Custom types default to the context body, each in its own section.
+	result, err := builder.Build(context.Background(), BuildOptions{Question: "anything"})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if !strings.Contains(result.ContextText, "## Risks\n\n- vendor API may be sunset") {
+		t.Errorf("expected a Risks section in the context body:\n%s", result.ContextText)
+	}
+	if !strings.Contains(result.ContextText, "## Glossaries\n\n- SKU: stock keeping unit") {
+		t.Errorf("expected a Glossaries section in the context body:\n%s", result.ContextText)
+	}
+	if strings.Contains(result.SystemPrompt, "vendor API") {
+		t.Error("custom types should not be in the system prompt by default")
+	}
+
+	// Placed explicitly in the system prompt, a custom type leaves the body.
+	moved, err := builder.Build(context.Background(), BuildOptions{
+		Question:          "anything",
+		SystemPromptTypes: []memory.MemoryType{memory.TypeConvention, "risk"},
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if !strings.Contains(moved.SystemPrompt, "vendor API may be sunset") {
+		t.Errorf("risk placed in the system prompt is missing from it:\n%s", moved.SystemPrompt)
+	}
+	if strings.Contains(moved.ContextText, "vendor API") {
+		t.Errorf("risk placed in the system prompt should not be in the body:\n%s", moved.ContextText)
+	}
+}
+
+func TestBuilder_Build_MaxMemories(t *testing.T) {
+	var mems []memory.Memory
+	for i := 1; i <= 8; i++ {
+		mems = append(mems, memory.Memory{Content: fmt.Sprintf("note number %d", i), MemoryType: memory.TypeNote, Importance: 0.5})
+	}
+	orch := &stubOrchestrator{result: &memory.RetrievalResult{Memories: mems}}
+	_, store, builder := setupBuilderTestDB(t, orch)
+	seedProject(t, store)
+
+	result, err := builder.Build(context.Background(), BuildOptions{
+		Question:    "notes",
+		MaxMemories: 3,
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if result.MemoriesUsed != 3 {
+		t.Errorf("expected 3 memories packed, got %d", result.MemoriesUsed)
+	}
+	// Retrieval order is rank order, so the first three are kept.
+	if !strings.Contains(result.ContextText, "note number 3") || strings.Contains(result.ContextText, "note number 4") {
+		t.Errorf("expected the three highest-ranked memories:\n%s", result.ContextText)
+	}
+}
+
+func TestBuilder_Build_WarnsOnFallbackRetrieval(t *testing.T) {
+	orch := &stubOrchestrator{result: &memory.RetrievalResult{
+		Memories: []memory.Memory{{Content: "a note", MemoryType: memory.TypeNote, Importance: 0.5}},
+		Mode:     memory.ModeFallbackList,
+		Reason:   "no embedder configured",
+	}}
+	_, store, builder := setupBuilderTestDB(t, orch)
+	seedProject(t, store)
+
+	result, err := builder.Build(context.Background(), BuildOptions{Question: "anything"})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "semantic search unavailable (no embedder configured)") {
+		t.Errorf("expected a fallback warning, got %v", result.Warnings)
+	}
+}
+
+func TestBuilder_Build_MinImportance(t *testing.T) {
+	orch := &stubOrchestrator{
+		result: &memory.RetrievalResult{
+			Memories: []memory.Memory{
+				{Content: "minor note", MemoryType: memory.TypeNote, Importance: 0.3},
+			},
+		},
+	}
+	_, store, builder := setupBuilderTestDB(t, orch)
+	seedProject(t, store)
+	store.InsertMemory(memory.Memory{Content: "Use PostgreSQL", MemoryType: memory.TypeDecision, Importance: 0.8})
+	store.InsertMemory(memory.Memory{Content: "low-value decision", MemoryType: memory.TypeDecision, Importance: 0.2})
+
+	result, err := builder.Build(context.Background(), BuildOptions{
+		Question:      "anything",
+		MinImportance: 0.5,
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if strings.Contains(result.ContextText, "minor note") {
+		t.Error("0.3-importance note should be excluded")
+	}
+	if strings.Contains(result.ContextText, "low-value decision") {
+		t.Error("0.2-importance decision should be excluded")
+	}
+	if !strings.Contains(result.ContextText, "Use PostgreSQL") {
+		t.Error("0.8-importance decision should survive")
+	}
+}
+
 func TestBuilder_Build_TokenBudget(t *testing.T) {
 	// Return a large chunk to test budget enforcement.
 	bigContent := strings.Repeat("word ", 5000)
@@ -252,6 +504,72 @@ func TestBuilder_Build_TokenBudget(t *testing.T) {
 	}
 }
 
+func TestBuilder_Build_TruncatesOversizedChunk(t *testing.T) {
+	// One relevant chunk far larger than the whole budget.
+	bigContent := "func handleLogin() {\n" + strings.Repeat("\tvalidate(input)\n", 3000) + "}\n"
+	orch := &stubOrchestrator{
+		result: &memory.RetrievalResult{
+			Chunks: []memory.Chunk{
+				{Content: bigContent, StartLine: 1, EndLine: 3002, ChunkType: "code"},
+			},
+		},
+	}
+	_, store, builder := setupBuilderTestDB(t, orch)
+	seedProject(t, store)
+
+	result, err := builder.Build(context.Background(), BuildOptions{Question: "how does login work?", MaxTokens: 400})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if result.ChunksUsed != 1 {
+		t.Fatalf("expected the oversized chunk to be included, ChunksUsed = %d", result.ChunksUsed)
+	}
+	if !strings.Contains(result.ContextText, "func handleLogin() {") {
+		t.Errorf("expected the start of the chunk:\n%s", result.ContextText)
+	}
+	if !strings.Contains(result.ContextText, "... (truncated)\n```") {
+		t.Errorf("expected a truncation marker inside the code block:\n%s", result.ContextText)
+	}
+	if result.TokensUsed > 400 {
+		t.Errorf("TokensUsed = %d exceeds the budget", result.TokensUsed)
+	}
+}
+
+func TestBuilder_Build_RelatedFiles(t *testing.T) {
+	orch := &stubOrchestrator{result: &memory.RetrievalResult{}}
+	_, store, builder := setupBuilderTestDB(t, orch)
+	seedProject(t, store)
+
+	authID, _ := store.UpsertFile(memory.File{Path: "internal/auth/login.go", Language: "go", LastModified: time.Now(), ContentHash: "a"})
+	sessID, _ := store.UpsertFile(memory.File{Path: "internal/auth/session.go", Language: "go", LastModified: time.Now(), ContentHash: "s"})
+	orch.result.Chunks = []memory.Chunk{
+		{ID: "c1", FileID: authID, Content: "func login() {}", StartLine: 1, EndLine: 3, ChunkType: "code"},
+		{ID: "c2", FileID: authID, Content: strings.Repeat("\tcheck(password)\n", 2000), StartLine: 10, EndLine: 2010, ChunkType: "code"},
+		{ID: "c3", FileID: sessID, Content: "func newSession() {}", StartLine: 40, EndLine: 42, ChunkType: "code"},
+	}
+
+	result, err := builder.Build(context.Background(), BuildOptions{Question: "how does login work?", MaxTokens: 300})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if !strings.Contains(result.ContextText, "func login() {}") {
+		t.Errorf("the first chunk should be shown in full:\n%s", result.ContextText)
+	}
+	if strings.Contains(result.ContextText, "func newSession") {
+		t.Errorf("the chunk after the oversized one should not be shown:\n%s", result.ContextText)
+	}
+	footer := "## Related files\n\nRelevant, but not shown in full:\n\n- internal/auth/login.go:10-2010\n- internal/auth/session.go:40-42\n"
+	if !strings.Contains(result.ContextText, footer) {
+		t.Errorf("expected the truncated and dropped chunks in a related files footer:\n%s", result.ContextText)
+	}
+	if strings.Contains(result.ContextText, "login.go:1-3") {
+		t.Error("chunks shown in full should not be listed as related files")
+	}
+	if result.TokensUsed > 300 {
+		t.Errorf("TokensUsed = %d exceeds the budget", result.TokensUsed)
+	}
+}
+
 func TestBuilder_Build_ExtraFiles(t *testing.T) {
 	orch := &stubOrchestrator{result: &memory.RetrievalResult{}}
 	_, store, builder := setupBuilderTestDB(t, orch)
@@ -301,6 +619,98 @@ func TestBuilder_Build_ExtraFiles_Missing(t *testing.T) {
 	}
 }
 
+func TestBuilder_Build_ExtraFiles_Truncated(t *testing.T) {
+	orch := &stubOrchestrator{result: &memory.RetrievalResult{}}
+	_, store, builder := setupBuilderTestDB(t, orch)
+	seedProject(t, store)
+
+	filePath := filepath.Join(t.TempDir(), "big.log")
+	os.WriteFile(filePath, []byte(strings.Repeat("log line\n", 1000)), 0o644)
+
+	result, err := builder.Build(context.Background(), BuildOptions{
+		Question:     "explain",
+		MaxTokens:    100000,
+		ExtraFiles:   []string{filePath},
+		MaxFileBytes: 100,
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if !strings.Contains(result.ContextText, "(truncated: first 98 of 9000 bytes)") {
+		t.Errorf("expected a truncation marker, got:\n%s", result.ContextText)
+	}
+	if n := strings.Count(result.ContextText, "log line"); n != 11 {
+		t.Errorf("expected 11 complete lines, got %d", n)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "truncated") {
+		t.Errorf("expected a truncation warning, got %v", result.Warnings)
+	}
+}
+
+func TestBuilder_Build_ExtraFiles_SkipsHuge(t *testing.T) {
+	orch := &stubOrchestrator{result: &memory.RetrievalResult{}}
+	_, store, builder := setupBuilderTestDB(t, orch)
+	seedProject(t, store)
+
+	// A sparse file: large on paper, cheap on disk.
+	filePath := filepath.Join(t.TempDir(), "huge.log")
+	f, _ := os.Create(filePath)
+	f.Truncate(SkipFileBytes + 1)
+	f.Close()
+
+	result, err := builder.Build(context.Background(), BuildOptions{
+		Question:   "explain",
+		ExtraFiles: []string{filePath},
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	for _, s := range result.Sources {
+		if strings.Contains(s, "huge.log") {
+			t.Errorf("oversized file should be skipped, got source %q", s)
+		}
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "skipped") {
+		t.Errorf("expected a skip warning, got %v", result.Warnings)
+	}
+}
+
+func TestBuilder_Build_ExcludePaths(t *testing.T) {
+	orch := &stubOrchestrator{result: &memory.RetrievalResult{}}
+	_, store, builder := setupBuilderTestDB(t, orch)
+	seedProject(t, store)
+
+	secretID, _ := store.UpsertFile(memory.File{Path: "secrets/keys.go", Language: "go", LastModified: time.Now(), ContentHash: "s"})
+	apiID, _ := store.UpsertFile(memory.File{Path: "internal/api/handler.go", Language: "go", LastModified: time.Now(), ContentHash: "h"})
+	orch.result.Chunks = []memory.Chunk{
+		{ID: "chunk-secret", FileID: secretID, Content: "const apiKey = \"sk-live\"", StartLine: 1, EndLine: 1, ChunkType: "code"},
+		{ID: "chunk-api", FileID: apiID, Content: "func handler() {}", StartLine: 1, EndLine: 1, ChunkType: "code"},
+	}
+
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "secrets"), 0o755)
+	os.WriteFile(filepath.Join(root, "secrets", "prod.env"), []byte("DB_PASSWORD=hunter2"), 0o644)
+
+	result, err := builder.Build(context.Background(), BuildOptions{
+		Question:     "how does the API work?",
+		ProjectRoot:  root,
+		ExtraFiles:   []string{"secrets/prod.env"},
+		ExcludePaths: []string{"secrets/"},
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if strings.Contains(result.ContextText, "sk-live") || strings.Contains(result.ContextText, "hunter2") {
+		t.Error("excluded paths must not appear in context")
+	}
+	if !strings.Contains(result.ContextText, "func handler() {}") {
+		t.Error("non-excluded chunk should still be included")
+	}
+	if result.ChunksUsed != 1 {
+		t.Errorf("expected 1 chunk used, got %d", result.ChunksUsed)
+	}
+}
+
 func TestBuilder_Build_SessionHistory(t *testing.T) {
 	orch := &stubOrchestrator{result: &memory.RetrievalResult{}}
 	_, store, builder := setupBuilderTestDB(t, orch)
@@ -337,6 +747,47 @@ func TestBuilder_Build_SessionHistory(t *testing.T) {
 	}
 }
 
+func TestPickSessions_PrefersUnfinished(t *testing.T) {
+	// Newest first, as returned by GetLastNSessions.
+	candidates := []memory.Session{
+		{ID: "s5", Status: memory.SessionCompleted},
+		{ID: "s4", Status: memory.SessionCompleted},
+		{ID: "s3", Status: memory.SessionBlocked},
+		{ID: "s2", Status: memory.SessionCompleted},
+		{ID: "s1", Status: memory.SessionInProgress},
+	}
+	var ids []string
+	for _, s := range pickSessions(candidates, 3, "") {
+		ids = append(ids, s.ID)
+	}
+	if got := strings.Join(ids, ","); got != "s5,s3,s1" {
+		t.Errorf("picked %s, want s5,s3,s1 (unfinished first, newest order kept)", got)
+	}
+	if got := pickSessions(candidates[:2], 3, ""); len(got) != 2 {
+		t.Errorf("fewer candidates than n should all be kept, got %d", len(got))
+	}
+}
+
+func TestPickSessions_PrefersTag(t *testing.T) {
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	recent := []memory.Session{
+		{ID: "s5", Status: memory.SessionCompleted, CreatedAt: base.Add(5 * time.Hour)},
+		{ID: "s4", Status: memory.SessionBlocked, CreatedAt: base.Add(4 * time.Hour)},
+		{ID: "s3", Status: memory.SessionCompleted, CreatedAt: base.Add(3 * time.Hour), Tags: []string{"spike"}},
+	}
+	older := []memory.Session{
+		{ID: "s3", Status: memory.SessionCompleted, CreatedAt: base.Add(3 * time.Hour), Tags: []string{"spike"}},
+		{ID: "s1", Status: memory.SessionCompleted, CreatedAt: base.Add(1 * time.Hour), Tags: []string{"spike"}},
+	}
+	var ids []string
+	for _, s := range pickSessions(mergeSessions(recent, older), 3, "Spike") {
+		ids = append(ids, s.ID)
+	}
+	if got := strings.Join(ids, ","); got != "s4,s3,s1" {
+		t.Errorf("picked %s, want s4,s3,s1 (tagged first, then unfinished, newest order kept)", got)
+	}
+}
+
 func TestBuilder_Build_EmptyProject(t *testing.T) {
 	orch := &stubOrchestrator{result: &memory.RetrievalResult{}}
 	_, _, builder := setupBuilderTestDB(t, orch)
@@ -357,6 +808,22 @@ func TestBuilder_Build_EmptyProject(t *testing.T) {
 	}
 }
 
+func TestBuilder_Build_ProjectNameOverride(t *testing.T) {
+	orch := &stubOrchestrator{result: &memory.RetrievalResult{}}
+	_, _, builder := setupBuilderTestDB(t, orch)
+
+	result, err := builder.Build(context.Background(), BuildOptions{
+		Question:    "what is this?",
+		ProjectName: "Billing Service",
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if !strings.Contains(result.SystemPrompt, `"Billing Service"`) || strings.Contains(result.SystemPrompt, "unknown") {
+		t.Errorf("expected the display name in the system prompt, got:\n%s", result.SystemPrompt)
+	}
+}
+
 func TestBuilder_Build_SourceTracking(t *testing.T) {
 	orch := &stubOrchestrator{
 		result: &memory.RetrievalResult{
@@ -409,6 +876,19 @@ func TestBuilder_Build_SourceTracking(t *testing.T) {
 	if !hasSession {
 		t.Errorf("sources should track sessions, got: %v", result.Sources)
 	}
+
+	refTypes := make(map[string]bool)
+	for _, ref := range result.SourceRefs {
+		refTypes[ref.Type] = true
+		if ref.Type == SourceSession && ref.ID == "" {
+			t.Error("session source ref should carry the session ID")
+		}
+	}
+	for _, typ := range []string{SourceDecision, SourceMemory, SourceSession} {
+		if !refTypes[typ] {
+			t.Errorf("source refs missing type %q: %+v", typ, result.SourceRefs)
+		}
+	}
 }
 
 func TestBuilder_Build_SessionHistory_ZeroSkips(t *testing.T) {
@@ -432,6 +912,58 @@ func TestBuilder_Build_SessionHistory_ZeroSkips(t *testing.T) {
 	}
 }
 
+func TestBuilder_Build_SessionSummaryCapped(t *testing.T) {
+	orch := &stubOrchestrator{result: &memory.RetrievalResult{}}
+	_, store, builder := setupBuilderTestDB(t, orch)
+	seedProject(t, store)
+
+	long := strings.Repeat("the deploy pipeline builds images and pushes them ", 40)
+	store.InsertSession(memory.Session{
+		Question: "how do I deploy?", ContextUsed: "{}", ResponseSummary: long, ModelUsed: "claude",
+	})
+	store.InsertSession(memory.Session{
+		Question: "what about CI?", ContextUsed: "{}", ResponseSummary: "Use GitHub Actions.", ModelUsed: "claude",
+	})
+
+	build := func(cap int) string {
+		t.Helper()
+		result, err := builder.Build(context.Background(), BuildOptions{
+			Question:             "related question",
+			TopKSessions:         5,
+			SessionTokenBudget:   5000,
+			SessionSummaryTokens: cap,
+		})
+		if err != nil {
+			t.Fatalf("Build: %v", err)
+		}
+		if result.SessionsUsed != 2 {
+			t.Fatalf("expected 2 sessions used, got %d", result.SessionsUsed)
+		}
+		return result.ContextText
+	}
+
+	text := build(20)
+	if strings.Contains(text, strings.TrimSpace(long)) {
+		t.Error("long summary should be cut to the cap")
+	}
+	var cut string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "the deploy pipeline") {
+			cut = line
+		}
+	}
+	if !strings.HasSuffix(cut, "…") || strings.HasSuffix(cut, " …") {
+		t.Errorf("cut summary should end in an ellipsis, got %q", cut)
+	}
+	if !strings.Contains(text, "Use GitHub Actions.") {
+		t.Error("short summary should be kept whole")
+	}
+
+	if text := build(-1); !strings.Contains(text, strings.TrimSpace(long)) {
+		t.Error("negative cap should keep the summary whole")
+	}
+}
+
 func TestBuilder_Build_MultipleChunksUntilBudgetExhausted(t *testing.T) {
 	// Create many small chunks — only some should fit.
 	chunks := make([]memory.Chunk, 50)
@@ -464,3 +996,247 @@ func TestBuilder_Build_MultipleChunksUntilBudgetExhausted(t *testing.T) {
 		t.Error("expected at least some chunks to be used")
 	}
 }
+
+func TestModelContextWindow(t *testing.T) {
+	tests := []struct {
+		model  string
+		window int
+		known  bool
+	}{
+		{"claude-sonnet-4-6", 200000, true},
+		{"gpt-4o-mini", 128000, true},
+		{"gpt-4", 8192, true},
+		{"Llama3.1:8b", 131072, true},
+		{"some-local-model", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		window, ok := ModelContextWindow(tt.model)
+		if window != tt.window || ok != tt.known {
+			t.Errorf("ModelContextWindow(%q) = %d, %v; want %d, %v", tt.model, window, ok, tt.window, tt.known)
+		}
+	}
+}
+
+func TestMaxTokensForModel(t *testing.T) {
+	if got := maxTokensForModel(""); got != defaultMaxTokens {
+		t.Errorf("unknown model: got %d, want %d", got, defaultMaxTokens)
+	}
+	// Large windows reserve a capped completion budget.
+	if got := maxTokensForModel("claude"); got != 200000-8192 {
+		t.Errorf("claude: got %d", got)
+	}
+	// Small windows reserve a quarter for the completion.
+	if got := maxTokensForModel("gpt-4"); got != 8192-2048 {
+		t.Errorf("gpt-4: got %d", got)
+	}
+}
+
+func TestBuilder_Build_DedupIgnoresStopPhrases(t *testing.T) {
+	orch := &stubOrchestrator{
+		result: &memory.RetrievalResult{
+			Memories: []memory.Memory{
+				{Content: "We decided to use PostgreSQL.", MemoryType: memory.TypeDecision, Importance: 0.8},
+			},
+		},
+	}
+	_, store, builder := setupBuilderTestDB(t, orch)
+	seedProject(t, store)
+	store.InsertMemory(memory.Memory{Content: "Use PostgreSQL", MemoryType: memory.TypeDecision, Importance: 0.8})
+
+	result, err := builder.Build(context.Background(), BuildOptions{Question: "database"})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if result.MemoriesUsed != 0 {
+		t.Errorf("expected reworded decision to be deduplicated, got %d memories used", result.MemoriesUsed)
+	}
+
+	// An empty stop-list disables phrase stripping.
+	result, err = builder.Build(context.Background(), BuildOptions{Question: "database", StopPhrases: []string{}})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if result.MemoriesUsed != 1 {
+		t.Errorf("expected 1 memory used with stop phrases disabled, got %d", result.MemoriesUsed)
+	}
+}
+
+func TestBuilder_Build_SectionOrder(t *testing.T) {
+	orch := &stubOrchestrator{
+		result: &memory.RetrievalResult{
+			Memories: []memory.Memory{
+				{Content: "retrieved note", MemoryType: memory.TypeNote, Importance: 0.5},
+			},
+		},
+	}
+	_, store, builder := setupBuilderTestDB(t, orch)
+	seedProject(t, store)
+	store.InsertMemory(memory.Memory{Content: "Use PostgreSQL", MemoryType: memory.TypeDecision, Importance: 0.8})
+	store.InsertSession(memory.Session{
+		Question: "how do I deploy?", ContextUsed: "{}", ResponseSummary: "Use docker compose.", ModelUsed: "claude",
+	})
+
+	result, err := builder.Build(context.Background(), BuildOptions{
+		Question:     "deploy",
+		TopKSessions: 1,
+		SectionOrder: []string{"memories", "decisions"},
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	note := strings.Index(result.ContextText, "retrieved note")
+	decision := strings.Index(result.ContextText, "Use PostgreSQL")
+	session := strings.Index(result.ContextText, "how do I deploy?")
+	if note < 0 || decision < 0 || session < 0 {
+		t.Fatalf("expected all sections in context, got:\n%s", result.ContextText)
+	}
+	// Listed sections first, then the omitted sessions section.
+	if !(note < decision && decision < session) {
+		t.Errorf("unexpected section order: note@%d decision@%d session@%d", note, decision, session)
+	}
+}
+
+func TestBuilder_Build_OnSection(t *testing.T) {
+	orch := &stubOrchestrator{
+		result: &memory.RetrievalResult{
+			Memories: []memory.Memory{
+				{Content: "retrieved note", MemoryType: memory.TypeNote, Importance: 0.5},
+			},
+		},
+	}
+	_, store, builder := setupBuilderTestDB(t, orch)
+	seedProject(t, store)
+	store.InsertMemory(memory.Memory{Content: "Use PostgreSQL", MemoryType: memory.TypeDecision, Importance: 0.8})
+	store.InsertSession(memory.Session{
+		Question: "how do I deploy?", ContextUsed: "{}", ResponseSummary: "Use docker compose.", ModelUsed: "claude",
+	})
+
+	for _, order := range [][]string{nil, {"memories", "decisions"}} {
+		var names, texts []string
+		result, err := builder.Build(context.Background(), BuildOptions{
+			Question:     "deploy",
+			TopKSessions: 1,
+			SectionOrder: order,
+			OnSection: func(name, text string) {
+				names = append(names, name)
+				texts = append(texts, text)
+			},
+		})
+		if err != nil {
+			t.Fatalf("Build: %v", err)
+		}
+		want, _ := resolveSectionOrder(order)
+		want = slices.DeleteFunc(want, func(n string) bool { return n == SectionFiles || n == SectionTodos || n == SectionChunks })
+		if !slices.Equal(names, want) {
+			t.Errorf("order %v: sections streamed as %v, want %v", order, names, want)
+		}
+		if got := strings.Join(texts, "\n"); got != result.ContextText {
+			t.Errorf("order %v: streamed text differs from ContextText:\n%s\n---\n%s", order, got, result.ContextText)
+		}
+	}
+}
+
+func TestBuilder_Build_OpenTodos(t *testing.T) {
+	orch := &stubOrchestrator{result: &memory.RetrievalResult{}}
+	_, store, builder := setupBuilderTestDB(t, orch)
+	seedProject(t, store)
+	store.InsertMemory(memory.Memory{Content: "add rate limiting to uploads", MemoryType: memory.TypeTodo, Importance: 0.9})
+	store.InsertMemory(memory.Memory{Content: "document the deploy script", MemoryType: memory.TypeTodo, Importance: 0.4})
+
+	// The todos have nothing to do with the question and aren't retrieved.
+	result, err := builder.Build(context.Background(), BuildOptions{Question: "how does auth work?", OpenTodos: true})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if !strings.Contains(result.ContextText, "## Open TODOs\n\n- add rate limiting to uploads\n- document the deploy script\n") {
+		t.Errorf("expected an Open TODOs section, most important first:\n%s", result.ContextText)
+	}
+
+	// Only what fits the budget is listed.
+	budget := NewTokenizer().Count(NewFormatter().FormatOpenTodos([]memory.Memory{{Content: "add rate limiting to uploads"}}))
+	small, err := builder.Build(context.Background(), BuildOptions{Question: "auth", OpenTodos: true, MaxTokens: budget})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if !strings.Contains(small.ContextText, "rate limiting") || strings.Contains(small.ContextText, "deploy script") {
+		t.Errorf("expected only the first todo within the budget:\n%s", small.ContextText)
+	}
+	if small.TokensUsed > budget {
+		t.Errorf("TokensUsed = %d exceeds the budget", small.TokensUsed)
+	}
+
+	// Disabled, or todos excluded from context: no section.
+	for _, opts := range []BuildOptions{
+		{Question: "auth"},
+		{Question: "auth", OpenTodos: true, ContextTypes: []memory.MemoryType{memory.TypeDecision}},
+	} {
+		result, err := builder.Build(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("Build: %v", err)
+		}
+		if strings.Contains(result.ContextText, "Open TODOs") {
+			t.Errorf("unexpected Open TODOs section with %+v:\n%s", opts, result.ContextText)
+		}
+	}
+}
+
+func TestResolveSectionOrder(t *testing.T) {
+	order, warnings := resolveSectionOrder(nil)
+	if strings.Join(order, ",") != strings.Join(DefaultSectionOrder, ",") {
+		t.Errorf("nil order: got %v", order)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("nil order: unexpected warnings %v", warnings)
+	}
+
+	order, warnings = resolveSectionOrder([]string{"Chunks", "bogus", "sessions", "chunks"})
+	want := "chunks,sessions,files,decisions,todos,memories"
+	if got := strings.Join(order, ","); got != want {
+		t.Errorf("order: got %s, want %s", got, want)
+	}
+	if len(warnings) != 2 {
+		t.Errorf("expected warnings for the unknown and repeated names, got %v", warnings)
+	}
+}
+
+func TestBuilder_Build_WarnsWhenSystemPromptOverflowsModel(t *testing.T) {
+	orch := &stubOrchestrator{result: &memory.RetrievalResult{}}
+	_, store, builder := setupBuilderTestDB(t, orch)
+	seedProject(t, store)
+	// gpt-4 has an 8192-token window; pin far more than that into the
+	// system prompt, which MaxTokens does not budget.
+	store.InsertMemory(memory.Memory{
+		Content:    strings.Repeat("always wrap errors with context ", 2000),
+		MemoryType: memory.TypeConvention,
+		Importance: 0.7,
+	})
+
+	result, err := builder.Build(context.Background(), BuildOptions{
+		Question:  "anything",
+		Model:     "gpt-4",
+		MaxTokens: 1000,
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if !result.NearModelLimit {
+		t.Errorf("expected NearModelLimit with a %d-token system prompt", result.SystemPromptTokens)
+	}
+	if len(result.Warnings) == 0 || !strings.Contains(result.Warnings[len(result.Warnings)-1], "window") {
+		t.Errorf("expected a model window warning, got %v", result.Warnings)
+	}
+
+	// A large window leaves plenty of room.
+	result, err = builder.Build(context.Background(), BuildOptions{
+		Question:  "anything",
+		Model:     "claude",
+		MaxTokens: 1000,
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if result.NearModelLimit {
+		t.Error("did not expect NearModelLimit for a 200k window")
+	}
+}
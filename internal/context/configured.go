@@ -0,0 +1,86 @@
+package context
+
+import (
+	"github.com/memvra/memvra/internal/adapter"
+	"github.com/memvra/memvra/internal/config"
+	"github.com/memvra/memvra/internal/memory"
+	"github.com/memvra/memvra/internal/scanner"
+)
+
+// The Configured* functions set up retrieval and context building from a
+// project's configuration. The CLI, MCP server, HTTP API and Go library all
+// go through them, so a question gets the same context on every surface.
+
+// ConfiguredRanker returns a Ranker with the project's [boost] path
+// multipliers and [ranking] options.
+func ConfiguredRanker(pcfg config.ProjectConfig) *memory.Ranker {
+	r := memory.NewRanker()
+	r.SetPathBoosts(pcfg.Boost)
+	r.SetOptions(memory.RankerOptions(pcfg.Ranking))
+	return r
+}
+
+// ConfiguredOrchestrator returns an orchestrator over store and vectors set
+// up for the project: its ranker, the embedding model key, the importance
+// table, the stop phrases used to spot duplicates and the exclude_paths no
+// search may return. embedder may be nil,
+// in which case retrieval falls back to listing memories.
+func ConfiguredOrchestrator(store *memory.Store, vectors *memory.VectorStore, embedder adapter.Embedder, gcfg config.GlobalConfig, pcfg config.ProjectConfig) *memory.Orchestrator {
+	o := memory.NewOrchestrator(store, vectors, ConfiguredRanker(pcfg), embedder)
+	o.SetEmbeddingModel(gcfg.EmbeddingModelKey())
+	o.SetImportanceDefaults(memory.ParseImportance(pcfg.ImportanceDefaults()))
+	o.SetStopPhrases(gcfg.Context.StopPhrases)
+	if len(pcfg.ExcludePaths) > 0 {
+		o.SetExcludedPaths(scanner.NewPathMatcher(pcfg.ExcludePaths).Match)
+	}
+	return o
+}
+
+// ConfiguredOptions returns the build options for the project at root: the
+// global [context] settings with the project's [context] overrides applied,
+// targeting the project's default_model. When the project names a model
+// but no max_tokens, MaxTokens is left 0 so the budget is sized from Model.
+// Callers set Question and any per-request overrides, including Model.
+func ConfiguredOptions(root string, gcfg config.GlobalConfig, pcfg config.ProjectConfig) BuildOptions {
+	pcfg.Context.Apply(&gcfg.Context)
+	if pcfg.ModelSizedContext() {
+		gcfg.Context.MaxTokens = 0
+	}
+	return BuildOptions{
+		ProjectRoot:          root,
+		MaxTokens:            gcfg.Context.MaxTokens,
+		Model:                pcfg.DefaultModel,
+		TopKChunks:           gcfg.Context.TopKChunks,
+		TopKMemories:         gcfg.Context.TopKMemories,
+		TopKSessions:         gcfg.Context.TopKSessions,
+		SessionTokenBudget:   gcfg.Context.SessionTokenBudget,
+		SessionSummaryTokens: gcfg.Context.SessionSummaryTokens,
+		SimilarityThreshold:  gcfg.Context.SimilarityThreshold,
+		ChunkThreshold:       gcfg.Context.ChunkThreshold,
+		MemoryThreshold:      gcfg.Context.MemoryThreshold,
+		SystemPromptTypes:    memory.ParseMemoryTypes(gcfg.Context.SystemPromptTypes),
+		ContextTypes:         memory.ParseMemoryTypes(gcfg.Context.ContextTypes),
+		MinImportance:        gcfg.Context.MinImportance,
+		MinConfidence:        gcfg.Context.MinConfidence,
+		MaxMemories:          gcfg.Context.MaxMemories,
+		ExcludePaths:         pcfg.ExcludePaths,
+		ProjectName:          pcfg.Project.DisplayName,
+		MaxFileBytes:         gcfg.Context.MaxFileBytes,
+		StopPhrases:          gcfg.Context.StopPhrases,
+		SectionOrder:         gcfg.Context.SectionOrder,
+		ContextLines:         gcfg.Context.ContextLines,
+		SystemPromptTemplate: gcfg.Context.SystemPromptTemplate,
+		OpenTodos:            gcfg.Context.OpenTodos,
+	}
+}
+
+// RecordAccess writes the items of built to store's access log when [audit]
+// is enabled. model names the model or client the context was built for;
+// sessionID may be empty. Every surface that hands context to a model
+// calls it, so `memvra audit` sees all of them.
+func RecordAccess(store *memory.Store, gcfg config.GlobalConfig, built *BuiltContext, model, sessionID string) error {
+	if !gcfg.Audit.Enabled {
+		return nil
+	}
+	return store.RecordAccess(built.AccessEntries(model, sessionID))
+}
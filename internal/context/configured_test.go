@@ -0,0 +1,44 @@
+package context
+
+import (
+	"testing"
+
+	"github.com/memvra/memvra/internal/config"
+)
+
+func TestConfiguredOptions(t *testing.T) {
+	gcfg := config.DefaultGlobal()
+	sessions := 7
+
+	// A project default_model with no max_tokens sizes the budget from the
+	// model, and project [context] settings win over the global ones.
+	opts := ConfiguredOptions("/proj", gcfg, config.ProjectConfig{
+		DefaultModel: "gpt-4o",
+		Context:      config.ProjectContextConfig{TopKSessions: &sessions},
+		ExcludePaths: []string{"secrets/"},
+	})
+	if opts.Model != "gpt-4o" || opts.MaxTokens != 0 {
+		t.Errorf("expected a model-sized budget for gpt-4o, got model %q max_tokens %d", opts.Model, opts.MaxTokens)
+	}
+	if opts.TopKSessions != 7 {
+		t.Errorf("project top_k_sessions not applied: got %d", opts.TopKSessions)
+	}
+	if opts.ProjectRoot != "/proj" || len(opts.ExcludePaths) != 1 {
+		t.Errorf("unexpected options %+v", opts)
+	}
+
+	// An explicit project max_tokens keeps its budget.
+	budget := 3000
+	opts = ConfiguredOptions("/proj", gcfg, config.ProjectConfig{
+		DefaultModel: "gpt-4o",
+		Context:      config.ProjectContextConfig{MaxTokens: &budget},
+	})
+	if opts.MaxTokens != 3000 {
+		t.Errorf("expected max_tokens 3000, got %d", opts.MaxTokens)
+	}
+
+	// Without a project model the global budget applies.
+	if opts := ConfiguredOptions("/proj", gcfg, config.ProjectConfig{}); opts.MaxTokens != gcfg.Context.MaxTokens {
+		t.Errorf("expected global max_tokens %d, got %d", gcfg.Context.MaxTokens, opts.MaxTokens)
+	}
+}
@@ -3,6 +3,7 @@ package context
 import (
 	"fmt"
 	"strings"
+	"text/template"
 
 	"github.com/memvra/memvra/internal/memory"
 	"github.com/memvra/memvra/internal/scanner"
@@ -14,30 +15,41 @@ type Formatter struct{}
 // NewFormatter creates a Formatter.
 func NewFormatter() *Formatter { return &Formatter{} }
 
-// FormatProjectProfile renders the project profile block.
+// FormatProjectProfile renders the project profile block. Polyglot projects
+// get one sub-section per named stack.
 func (f *Formatter) FormatProjectProfile(proj memory.Project, ts scanner.TechStack) string {
 	var b strings.Builder
 	fmt.Fprintf(&b, "## Project Profile\n\n")
 	fmt.Fprintf(&b, "- **Project:** %s\n", proj.Name)
+	for _, ns := range ts.Components() {
+		if ns.Name != "" {
+			fmt.Fprintf(&b, "\n### %s\n\n", ns.Name)
+		}
+		writeStackFields(&b, ns.TechStack)
+	}
+	return b.String()
+}
+
+// writeStackFields renders the non-empty fields of a single stack as bullets.
+func writeStackFields(b *strings.Builder, ts scanner.TechStack) {
 	if ts.Language != "" {
-		fmt.Fprintf(&b, "- **Language:** %s\n", ts.Language)
+		fmt.Fprintf(b, "- **Language:** %s\n", ts.Language)
 	}
 	if ts.Framework != "" {
-		fmt.Fprintf(&b, "- **Framework:** %s\n", ts.Framework)
+		fmt.Fprintf(b, "- **Framework:** %s\n", ts.Framework)
 	}
 	if ts.Database != "" {
-		fmt.Fprintf(&b, "- **Database:** %s\n", ts.Database)
+		fmt.Fprintf(b, "- **Database:** %s\n", ts.Database)
 	}
 	if ts.Architecture != "" {
-		fmt.Fprintf(&b, "- **Architecture:** %s\n", ts.Architecture)
+		fmt.Fprintf(b, "- **Architecture:** %s\n", ts.Architecture)
 	}
 	if ts.TestFramework != "" {
-		fmt.Fprintf(&b, "- **Tests:** %s\n", ts.TestFramework)
+		fmt.Fprintf(b, "- **Tests:** %s\n", ts.TestFramework)
 	}
 	if len(ts.DetectedPatterns) > 0 {
-		fmt.Fprintf(&b, "- **Patterns:** %s\n", strings.Join(ts.DetectedPatterns, ", "))
+		fmt.Fprintf(b, "- **Patterns:** %s\n", strings.Join(ts.DetectedPatterns, ", "))
 	}
-	return b.String()
 }
 
 // FormatMemories renders a slice of memories as a markdown list.
@@ -46,15 +58,52 @@ func (f *Formatter) FormatMemories(memType memory.MemoryType, items []memory.Mem
 		return ""
 	}
 	var b strings.Builder
-	label := strings.Title(string(memType)) + "s" //nolint:staticcheck
-	fmt.Fprintf(&b, "## %s\n\n", label)
+	fmt.Fprintf(&b, "## %s\n\n", memType.Label())
+	for _, m := range items {
+		b.WriteString(formatMemoryItem(m))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// FormatOpenTodos renders todo memories as the "Open TODOs" block.
+func (f *Formatter) FormatOpenTodos(items []memory.Memory) string {
+	if len(items) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("## Open TODOs\n\n")
 	for _, m := range items {
-		fmt.Fprintf(&b, "- %s\n", m.Content)
+		b.WriteString(formatMemoryItem(m))
 	}
 	b.WriteString("\n")
 	return b.String()
 }
 
+// FormatRelatedFiles renders file:line pointers to retrieved code that did
+// not fit in the context, or was cut short, as the "Related files" block.
+func (f *Formatter) FormatRelatedFiles(refs []string) string {
+	if len(refs) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("## Related files\n\nRelevant, but not shown in full:\n\n")
+	for _, ref := range refs {
+		fmt.Fprintf(&b, "- %s\n", ref)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// formatMemoryItem renders one memory as a list item, marking memories an AI
+// inferred so they can be weighed below what a person stated.
+func formatMemoryItem(m memory.Memory) string {
+	if m.Source == memory.SourceExtracted {
+		return "- " + m.Content + " _(AI-inferred)_\n"
+	}
+	return "- " + m.Content + "\n"
+}
+
 // FormatChunk renders a single code chunk with its source location.
 func (f *Formatter) FormatChunk(c memory.Chunk, filePath string) string {
 	var b strings.Builder
@@ -66,25 +115,98 @@ func (f *Formatter) FormatChunk(c memory.Chunk, filePath string) string {
 	return b.String()
 }
 
+// MemoryGroup is a set of memories of one type rendered as a single section.
+type MemoryGroup struct {
+	Type  memory.MemoryType
+	Items []memory.Memory
+}
+
 // FormatSystemPrompt builds the system prompt from profile + conventions + constraints.
 func (f *Formatter) FormatSystemPrompt(proj memory.Project, ts scanner.TechStack, conventions, constraints []memory.Memory) string {
+	return f.FormatSystemPromptGroups(proj, ts, []MemoryGroup{
+		{Type: memory.TypeConvention, Items: conventions},
+		{Type: memory.TypeConstraint, Items: constraints},
+	})
+}
+
+// DefaultInstructions closes the built-in system prompt. Templates can keep
+// it with {{.Instructions}}.
+const DefaultInstructions = `When answering:
+1. Respect established conventions and constraints
+2. Reference specific files and line numbers when relevant
+3. Be consistent with existing patterns in the codebase
+4. Flag if a suggestion contradicts stored decisions or constraints
+`
+
+// SystemPromptData is what a system-prompt template is executed with.
+type SystemPromptData struct {
+	Project      string            // project name
+	Stack        scanner.TechStack // detected tech stack
+	Profile      string            // the rendered "## Project Profile" block
+	Memories     string            // every pinned memory section, rendered
+	Conventions  []string          // pinned conventions
+	Constraints  []string          // pinned constraints
+	Groups       []MemoryGroup     // all pinned memories by type, in order
+	Instructions string            // DefaultInstructions
+}
+
+// FormatSystemPromptGroups builds the system prompt from the profile plus
+// one section per non-empty memory group, in the order given.
+func (f *Formatter) FormatSystemPromptGroups(proj memory.Project, ts scanner.TechStack, groups []MemoryGroup) string {
+	d := f.systemPromptData(proj, ts, groups)
 	var b strings.Builder
-	fmt.Fprintf(&b, "You are an AI assistant working on the project %q.\n\n", proj.Name)
-	b.WriteString(f.FormatProjectProfile(proj, ts))
-	if len(conventions) > 0 {
-		b.WriteString(f.FormatMemories(memory.TypeConvention, conventions))
-	}
-	if len(constraints) > 0 {
-		b.WriteString(f.FormatMemories(memory.TypeConstraint, constraints))
-	}
-	b.WriteString("\nWhen answering:\n")
-	b.WriteString("1. Respect established conventions and constraints\n")
-	b.WriteString("2. Reference specific files and line numbers when relevant\n")
-	b.WriteString("3. Be consistent with existing patterns in the codebase\n")
-	b.WriteString("4. Flag if a suggestion contradicts stored decisions or constraints\n")
+	fmt.Fprintf(&b, "You are an AI assistant working on the project %q.\n\n", d.Project)
+	b.WriteString(d.Profile)
+	b.WriteString(d.Memories)
+	b.WriteString("\n")
+	b.WriteString(d.Instructions)
 	return b.String()
 }
 
+// RenderSystemPrompt builds the system prompt from tmpl, a text/template
+// executed with SystemPromptData. An empty tmpl gives the built-in prompt.
+func (f *Formatter) RenderSystemPrompt(tmpl string, proj memory.Project, ts scanner.TechStack, groups []MemoryGroup) (string, error) {
+	if tmpl == "" {
+		return f.FormatSystemPromptGroups(proj, ts, groups), nil
+	}
+	t, err := template.New("system_prompt").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("system prompt template: %w", err)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, f.systemPromptData(proj, ts, groups)); err != nil {
+		return "", fmt.Errorf("system prompt template: %w", err)
+	}
+	return b.String(), nil
+}
+
+func (f *Formatter) systemPromptData(proj memory.Project, ts scanner.TechStack, groups []MemoryGroup) SystemPromptData {
+	d := SystemPromptData{
+		Project:      proj.Name,
+		Stack:        ts,
+		Profile:      f.FormatProjectProfile(proj, ts),
+		Groups:       groups,
+		Instructions: DefaultInstructions,
+	}
+	var memories strings.Builder
+	for _, g := range groups {
+		if len(g.Items) == 0 {
+			continue
+		}
+		memories.WriteString(f.FormatMemories(g.Type, g.Items))
+		for _, m := range g.Items {
+			switch g.Type {
+			case memory.TypeConvention:
+				d.Conventions = append(d.Conventions, m.Content)
+			case memory.TypeConstraint:
+				d.Constraints = append(d.Constraints, m.Content)
+			}
+		}
+	}
+	d.Memories = memories.String()
+	return d
+}
+
 // FormatSessionHistory renders recent sessions as a context block.
 // Sessions are assumed newest-first (as returned by GetLastNSessions);
 // they are reversed here to chronological order for natural reading.
@@ -94,10 +216,22 @@ func (f *Formatter) FormatSessionHistory(sessions []memory.Session) string {
 	}
 	var b strings.Builder
 	fmt.Fprintf(&b, "## Recent Sessions\n\n")
+	// Sessions are newest first: lead with where the last one left off.
+	if steps := sessions[0].NextSteps; len(steps) > 0 {
+		b.WriteString("**Next steps** (from the latest session):\n")
+		for _, step := range steps {
+			fmt.Fprintf(&b, "- %s\n", step)
+		}
+		b.WriteString("\n")
+	}
 	for i := len(sessions) - 1; i >= 0; i-- {
 		s := sessions[i]
 		ts := s.CreatedAt.Format("2006-01-02 15:04")
-		fmt.Fprintf(&b, "**[%s]** %s\n", ts, s.Question)
+		if s.Unfinished() {
+			fmt.Fprintf(&b, "**[%s]** [%s] %s\n", ts, s.Status, s.Question)
+		} else {
+			fmt.Fprintf(&b, "**[%s]** %s\n", ts, s.Question)
+		}
 		if s.ResponseSummary != "" {
 			fmt.Fprintf(&b, "%s\n", s.ResponseSummary)
 		}
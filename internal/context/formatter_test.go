@@ -54,6 +54,22 @@ func TestFormatProjectProfile_MinimalStack(t *testing.T) {
 	}
 }
 
+func TestFormatProjectProfile_NamedStacks(t *testing.T) {
+	f := NewFormatter()
+	proj := memory.Project{Name: "polyglot"}
+	ts := scanner.TechStack{Stacks: []scanner.NamedStack{
+		{Name: "backend", TechStack: scanner.TechStack{Language: "Go"}},
+		{Name: "frontend", TechStack: scanner.TechStack{Language: "TypeScript", Framework: "React"}},
+	}}
+
+	result := f.FormatProjectProfile(proj, ts)
+	for _, check := range []string{"### backend", "### frontend", "Go", "TypeScript", "React"} {
+		if !strings.Contains(result, check) {
+			t.Errorf("missing %q in profile", check)
+		}
+	}
+}
+
 func TestFormatMemories(t *testing.T) {
 	f := NewFormatter()
 	items := []memory.Memory{
@@ -173,6 +189,20 @@ func TestTruncateStr(t *testing.T) {
 	}
 }
 
+func TestFormatMemories_MarksInferred(t *testing.T) {
+	f := NewFormatter()
+	result := f.FormatMemories(memory.TypeDecision, []memory.Memory{
+		{Content: "Use PostgreSQL", Source: memory.SourceUser},
+		{Content: "Cache is Redis", Source: memory.SourceExtracted},
+	})
+	if !strings.Contains(result, "- Use PostgreSQL\n") {
+		t.Errorf("user memory should be unmarked:\n%s", result)
+	}
+	if !strings.Contains(result, "- Cache is Redis _(AI-inferred)_\n") {
+		t.Errorf("extracted memory should be marked:\n%s", result)
+	}
+}
+
 func TestFormatSessionHistory_WithSummary(t *testing.T) {
 	f := NewFormatter()
 	sessions := []memory.Session{
@@ -194,6 +224,38 @@ func TestFormatSessionHistory_WithSummary(t *testing.T) {
 	}
 }
 
+func TestFormatSessionHistory_MarksUnfinished(t *testing.T) {
+	f := NewFormatter()
+	result := f.FormatSessionHistory([]memory.Session{
+		{Question: "Add rate limiting", Status: memory.SessionInProgress},
+		{Question: "Fix login", Status: memory.SessionCompleted},
+	})
+	if !strings.Contains(result, "[in_progress] Add rate limiting") {
+		t.Errorf("expected status badge on unfinished session:\n%s", result)
+	}
+	if strings.Contains(result, "[completed]") {
+		t.Errorf("completed sessions should not be badged:\n%s", result)
+	}
+}
+
+func TestFormatSessionHistory_LeadsWithNextSteps(t *testing.T) {
+	f := NewFormatter()
+	result := f.FormatSessionHistory([]memory.Session{
+		{Question: "Add rate limiting", NextSteps: []string{"wire limiter into router", "add tests"}},
+		{Question: "Older work", NextSteps: []string{"stale step"}},
+	})
+	steps := strings.Index(result, "- wire limiter into router\n- add tests")
+	if steps < 0 {
+		t.Fatalf("expected latest next steps as a list:\n%s", result)
+	}
+	if steps > strings.Index(result, "Older work") {
+		t.Errorf("next steps should come before the session list:\n%s", result)
+	}
+	if strings.Contains(result, "stale step") {
+		t.Errorf("only the latest session's next steps are surfaced:\n%s", result)
+	}
+}
+
 func TestFormatSessionHistory_Empty(t *testing.T) {
 	f := NewFormatter()
 	result := f.FormatSessionHistory(nil)
@@ -0,0 +1,70 @@
+package context
+
+import "strings"
+
+// defaultMaxTokens is the context budget used when neither MaxTokens nor a
+// known Model is given.
+const defaultMaxTokens = 8000
+
+// modelWindows maps model name prefixes to their context window in tokens.
+// Lookup uses the longest matching prefix, so "gpt-4o-mini" resolves via
+// "gpt-4o" while plain "gpt-4" keeps its smaller window. Provider names are
+// included so `--model claude` style values resolve too.
+var modelWindows = map[string]int{
+	// Anthropic
+	"claude": 200000,
+
+	// OpenAI
+	"openai":        128000,
+	"gpt-4o":        128000,
+	"gpt-4.1":       1047576,
+	"gpt-4-turbo":   128000,
+	"gpt-4":         8192,
+	"gpt-3.5-turbo": 16385,
+	"o1":            200000,
+	"o3":            200000,
+	"o4":            200000,
+
+	// Google
+	"gemini":         1048576,
+	"gemini-1.5-pro": 2097152,
+
+	// Common Ollama models
+	"llama3":    8192,
+	"llama3.1":  131072,
+	"llama3.2":  131072,
+	"mistral":   32768,
+	"qwen2.5":   32768,
+	"codellama": 16384,
+}
+
+// ModelContextWindow returns the context window for model and whether the
+// model is known. Matching is case-insensitive on the longest known prefix.
+func ModelContextWindow(model string) (int, bool) {
+	name := strings.ToLower(strings.TrimSpace(model))
+	if name == "" {
+		return 0, false
+	}
+	best, window := "", 0
+	for prefix, w := range modelWindows {
+		if strings.HasPrefix(name, prefix) && len(prefix) > len(best) {
+			best, window = prefix, w
+		}
+	}
+	return window, best != ""
+}
+
+// maxTokensForModel derives a context budget from the model's window,
+// reserving a quarter of it (capped at 8192 tokens) for the completion.
+// Unknown models fall back to defaultMaxTokens.
+func maxTokensForModel(model string) int {
+	window, ok := ModelContextWindow(model)
+	if !ok {
+		return defaultMaxTokens
+	}
+	reserve := window / 4
+	if reserve > 8192 {
+		reserve = 8192
+	}
+	return window - reserve
+}
@@ -3,32 +3,111 @@ package context
 
 import (
 	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	tiktoken "github.com/pkoukk/tiktoken-go"
 )
 
+// Tokenizer modes, as reported by Tokenizer.Mode. An exact tokenizer
+// reports its encoding name, TokenizerExact unless built for a model that
+// counts with another (see EncodingForModel).
+const (
+	// TokenizerExact counts with the cl100k_base encoding.
+	TokenizerExact = "cl100k_base"
+	// TokenizerO200K counts with the o200k_base encoding of newer OpenAI
+	// models.
+	TokenizerO200K = "o200k_base"
+	// TokenizerApproximate counts with a built-in heuristic, used when the
+	// encoding can't be loaded (it is downloaded on first use).
+	TokenizerApproximate = "approximate"
+)
+
 // Tokenizer wraps tiktoken for approximate token counting.
 type Tokenizer struct {
 	enc *tiktoken.Tiktoken
+	// encoding is the name enc was loaded from.
+	encoding string
+	// fallback is why enc could not be loaded; nil when enc is set.
+	fallback error
 }
 
 // NewTokenizer creates a Tokenizer using the cl100k_base encoding
 // (used by GPT-4 and Claude — a good approximation for all providers).
-func NewTokenizer() (*Tokenizer, error) {
-	enc, err := tiktoken.GetEncoding("cl100k_base")
+// When the encoding is unavailable, e.g. offline on first use, it falls back
+// to a deterministic heuristic instead of failing; see Mode.
+func NewTokenizer() *Tokenizer {
+	return newTokenizer(TokenizerExact)
+}
+
+// NewTokenizerForModel creates a Tokenizer with the encoding model counts
+// with, falling back like NewTokenizer. An empty or unknown model gets
+// cl100k_base.
+func NewTokenizerForModel(model string) *Tokenizer {
+	return newTokenizer(EncodingForModel(model))
+}
+
+func newTokenizer(encoding string) *Tokenizer {
+	enc, err := tiktoken.GetEncoding(encoding)
 	if err != nil {
-		return nil, fmt.Errorf("tokenizer: get encoding: %w", err)
+		return &Tokenizer{fallback: fmt.Errorf("tokenizer: get encoding: %w", err)}
+	}
+	return &Tokenizer{enc: enc, encoding: encoding}
+}
+
+// o200kModels are the model name prefixes that count with o200k_base.
+// "openai" is included because its default completion model is gpt-4o.
+var o200kModels = []string{"gpt-4o", "gpt-4.1", "o1", "o3", "o4", "openai"}
+
+// EncodingForModel returns the tiktoken encoding for model: TokenizerO200K
+// for the GPT-4o generation and OpenAI reasoning models, TokenizerExact
+// (cl100k_base) for everything else, which approximates Claude, Gemini
+// and local models well.
+func EncodingForModel(model string) string {
+	name := strings.ToLower(strings.TrimSpace(model))
+	for _, prefix := range o200kModels {
+		if strings.HasPrefix(name, prefix) {
+			return TokenizerO200K
+		}
 	}
-	return &Tokenizer{enc: enc}, nil
+	return TokenizerExact
+}
+
+// Mode returns the encoding name, or TokenizerApproximate.
+func (t *Tokenizer) Mode() string {
+	if t.enc == nil {
+		return TokenizerApproximate
+	}
+	return t.encoding
+}
+
+// FallbackReason returns why the tokenizer is approximate, or nil when it
+// uses the exact encoding.
+func (t *Tokenizer) FallbackReason() error {
+	return t.fallback
 }
 
 // Count returns the approximate number of tokens in s.
 func (t *Tokenizer) Count(s string) int {
+	if t.enc == nil {
+		return len(approxTokenEnds(s))
+	}
 	return len(t.enc.Encode(s, nil, nil))
 }
 
 // Truncate truncates s to at most maxTokens tokens, returning the result.
 func (t *Tokenizer) Truncate(s string, maxTokens int) string {
+	if t.enc == nil {
+		ends := approxTokenEnds(s)
+		if len(ends) <= maxTokens {
+			return s
+		}
+		if maxTokens <= 0 {
+			return ""
+		}
+		return s[:ends[maxTokens-1]]
+	}
 	tokens := t.enc.Encode(s, nil, nil)
 	if len(tokens) <= maxTokens {
 		return s
@@ -36,3 +115,61 @@ func (t *Tokenizer) Truncate(s string, maxTokens int) string {
 	// Decode the truncated token slice back to a string.
 	return t.enc.Decode(tokens[:maxTokens])
 }
+
+// Piece sizes for approxTokenEnds, close to cl100k_base's averages for
+// English prose and code.
+const (
+	approxLettersPerToken = 4
+	approxDigitsPerToken  = 3
+)
+
+// approxTokenEnds splits s roughly as a BPE tokenizer would and returns the
+// byte offset at which each token ends. Letters are grouped into pieces of up
+// to approxLettersPerToken, digits into pieces of up to approxDigitsPerToken,
+// and CJK characters and other symbols are a token each. Spaces belong to the
+// token that follows them; a run of line breaks is one token.
+func approxTokenEnds(s string) []int {
+	var ends []int
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == '\n' || r == '\r':
+			i += size
+			for i < len(s) && (s[i] == '\n' || s[i] == '\r') {
+				i++
+			}
+		case unicode.IsSpace(r):
+			i += size
+			continue
+		case isWordRune(r):
+			i = scanRun(s, i+size, approxLettersPerToken-1, isWordRune)
+		case unicode.IsDigit(r):
+			i = scanRun(s, i+size, approxDigitsPerToken-1, unicode.IsDigit)
+		default:
+			i += size
+		}
+		ends = append(ends, i)
+	}
+	return ends
+}
+
+// scanRun advances from i over at most n more runes matching fn and returns
+// the new offset.
+func scanRun(s string, i, n int, fn func(rune) bool) int {
+	for ; n > 0 && i < len(s); n-- {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if !fn(r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// isWordRune reports whether r is a letter that BPE merges with its
+// neighbours. Ideographic scripts are excluded: they run about a token per
+// character.
+func isWordRune(r rune) bool {
+	return (unicode.IsLetter(r) || r == '_') &&
+		!unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
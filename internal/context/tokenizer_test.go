@@ -1,12 +1,15 @@
 package context
 
-import "testing"
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tiktoken "github.com/pkoukk/tiktoken-go"
+)
 
 func TestTokenizer_Count(t *testing.T) {
-	tok, err := NewTokenizer()
-	if err != nil {
-		t.Fatalf("NewTokenizer: %v", err)
-	}
+	tok := NewTokenizer()
 
 	count := tok.Count("Hello, world!")
 	if count <= 0 {
@@ -15,10 +18,7 @@ func TestTokenizer_Count(t *testing.T) {
 }
 
 func TestTokenizer_Count_EmptyString(t *testing.T) {
-	tok, err := NewTokenizer()
-	if err != nil {
-		t.Fatalf("NewTokenizer: %v", err)
-	}
+	tok := NewTokenizer()
 
 	count := tok.Count("")
 	if count != 0 {
@@ -27,10 +27,7 @@ func TestTokenizer_Count_EmptyString(t *testing.T) {
 }
 
 func TestTokenizer_Truncate(t *testing.T) {
-	tok, err := NewTokenizer()
-	if err != nil {
-		t.Fatalf("NewTokenizer: %v", err)
-	}
+	tok := NewTokenizer()
 
 	long := "This is a fairly long string that should have more than five tokens in total."
 	truncated := tok.Truncate(long, 5)
@@ -48,10 +45,7 @@ func TestTokenizer_Truncate(t *testing.T) {
 }
 
 func TestTokenizer_Truncate_ShortString(t *testing.T) {
-	tok, err := NewTokenizer()
-	if err != nil {
-		t.Fatalf("NewTokenizer: %v", err)
-	}
+	tok := NewTokenizer()
 
 	short := "Hi"
 	result := tok.Truncate(short, 100)
@@ -59,3 +53,81 @@ func TestTokenizer_Truncate_ShortString(t *testing.T) {
 		t.Errorf("short string should not be truncated: got %q", result)
 	}
 }
+
+// failingLoader stands in for an offline machine with no cached encoding.
+type failingLoader struct{}
+
+func (failingLoader) LoadTiktokenBpe(string) (map[string]int, error) {
+	return nil, errors.New("offline")
+}
+
+func TestNewTokenizer_Offline(t *testing.T) {
+	tiktoken.SetBpeLoader(failingLoader{})
+	t.Cleanup(func() { tiktoken.SetBpeLoader(tiktoken.NewDefaultBpeLoader()) })
+
+	// The encoding may already be cached by an earlier test; either way
+	// construction must succeed and count.
+	tok := NewTokenizer()
+	if tok.Count("Hello, world!") <= 0 {
+		t.Errorf("Count = %d in mode %s, want > 0", tok.Count("Hello, world!"), tok.Mode())
+	}
+	if tok.Mode() == TokenizerApproximate && tok.FallbackReason() == nil {
+		t.Error("approximate tokenizer has no FallbackReason")
+	}
+}
+
+func TestEncodingForModel(t *testing.T) {
+	tests := map[string]string{
+		"":              TokenizerExact,
+		"claude":        TokenizerExact,
+		"gpt-4":         TokenizerExact,
+		"gpt-3.5-turbo": TokenizerExact,
+		"llama3.1":      TokenizerExact,
+		"openai":        TokenizerO200K,
+		"GPT-4o-mini":   TokenizerO200K,
+		"gpt-4.1":       TokenizerO200K,
+		"o3-mini":       TokenizerO200K,
+	}
+	for model, want := range tests {
+		if got := EncodingForModel(model); got != want {
+			t.Errorf("EncodingForModel(%q) = %q, want %q", model, got, want)
+		}
+	}
+
+	tok := NewTokenizerForModel("gpt-4o")
+	if mode := tok.Mode(); mode != TokenizerO200K && mode != TokenizerApproximate {
+		t.Errorf("Mode = %q, want %q (or approximate offline)", mode, TokenizerO200K)
+	}
+}
+
+func TestTokenizer_Approximate(t *testing.T) {
+	tok := &Tokenizer{fallback: errors.New("offline")}
+	if tok.Mode() != TokenizerApproximate {
+		t.Fatalf("Mode = %q, want %q", tok.Mode(), TokenizerApproximate)
+	}
+
+	cases := map[string]int{
+		"":                 0,
+		"Hello, world!":    6, // Hell o , worl d !
+		"id 12345":         3, // id 123 45
+		"a\n\n\nb":         3,
+		"日本語":              3,
+		"func main() {}\n": 7, // func main ( ) { } \n
+	}
+	for s, want := range cases {
+		if got := tok.Count(s); got != want {
+			t.Errorf("Count(%q) = %d, want %d", s, got, want)
+		}
+	}
+
+	long := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20)
+	for _, n := range []int{0, 1, 5, 50} {
+		got := tok.Truncate(long, n)
+		if !strings.HasPrefix(long, got) {
+			t.Errorf("Truncate(%d) = %q, not a prefix", n, got)
+		}
+		if c := tok.Count(got); c > n {
+			t.Errorf("Truncate(%d) left %d tokens", n, c)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Backup copies the database to dest using SQLite's online backup API, which
+// produces a consistent snapshot even while other connections are writing
+// (unlike copying a WAL-mode database file). An existing dest is overwritten.
+func (d *DB) Backup(dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return fmt.Errorf("backup: create directory: %w", err)
+	}
+	destDB, err := sql.Open("sqlite3", dest)
+	if err != nil {
+		return fmt.Errorf("backup: open %s: %w", dest, err)
+	}
+	defer func() { _ = destDB.Close() }()
+
+	if err := copyDatabase(destDB, d.conn); err != nil {
+		return fmt.Errorf("backup: %w", err)
+	}
+	return nil
+}
+
+// Restore replaces the database contents with the snapshot at src, after
+// checking that src is an intact Memvra database. The copy goes through the
+// backup API, so the live database's WAL stays consistent.
+func (d *DB) Restore(src string) error {
+	if err := Validate(src); err != nil {
+		return err
+	}
+	srcDB, err := sql.Open("sqlite3", "file:"+src+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("restore: open %s: %w", src, err)
+	}
+	defer func() { _ = srcDB.Close() }()
+
+	if err := copyDatabase(d.conn, srcDB); err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+	return nil
+}
+
+// Validate reports whether path holds an intact Memvra database: it must pass
+// SQLite's integrity check and contain the core tables.
+func Validate(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	conn, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("validate: open %s: %w", path, err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	var result string
+	if err := conn.QueryRow(`PRAGMA integrity_check`).Scan(&result); err != nil {
+		return fmt.Errorf("validate: %s is not a readable SQLite database: %w", path, err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("validate: %s failed integrity check: %s", path, result)
+	}
+	for _, table := range []string{"schema_migrations", "project", "memories", "sessions"} {
+		var n int
+		if err := conn.QueryRow(
+			`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, table,
+		).Scan(&n); err != nil || n == 0 {
+			return fmt.Errorf("validate: %s is not a Memvra database (missing %s table)", path, table)
+		}
+	}
+	return nil
+}
+
+// copyDatabase copies every page of src's main database into dest.
+func copyDatabase(dest, src *sql.DB) error {
+	ctx := context.Background()
+	destConn, err := dest.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = destConn.Close() }()
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = srcConn.Close() }()
+
+	return destConn.Raw(func(destRaw any) error {
+		return srcConn.Raw(func(srcRaw any) error {
+			destSQLite, ok := destRaw.(*sqlite3.SQLiteConn)
+			srcSQLite, ok2 := srcRaw.(*sqlite3.SQLiteConn)
+			if !ok || !ok2 {
+				return fmt.Errorf("unexpected driver connection type")
+			}
+			b, err := destSQLite.Backup("main", srcSQLite, "main")
+			if err != nil {
+				return err
+			}
+			if _, err := b.Step(-1); err != nil {
+				_ = b.Finish()
+				return err
+			}
+			return b.Finish()
+		})
+	})
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"fmt"
+)
+
+const (
+	// AutoVacuumFreeRatio is the share of free pages at which AutoVacuum
+	// reclaims space.
+	AutoVacuumFreeRatio = 0.25
+	// autoVacuumMinFreePages keeps AutoVacuum from rewriting small databases
+	// over a handful of free pages.
+	autoVacuumMinFreePages = 256
+)
+
+// Size returns the size of the database in bytes (page count × page size).
+func (d *DB) Size() (int64, error) {
+	var pages, pageSize int64
+	if err := d.conn.QueryRow(`PRAGMA page_count`).Scan(&pages); err != nil {
+		return 0, fmt.Errorf("page count: %w", err)
+	}
+	if err := d.conn.QueryRow(`PRAGMA page_size`).Scan(&pageSize); err != nil {
+		return 0, fmt.Errorf("page size: %w", err)
+	}
+	return pages * pageSize, nil
+}
+
+// Compact rebuilds the vector tables and runs VACUUM, returning the database
+// size before and after. sqlite-vec keeps the storage of deleted vectors
+// allocated, so VACUUM alone does not shrink them; rebuilding copies the live
+// vectors into fresh tables.
+func (d *DB) Compact() (before, after int64, err error) {
+	if before, err = d.Size(); err != nil {
+		return 0, 0, err
+	}
+	if err := d.rebuildVectorTables(); err != nil {
+		return before, before, err
+	}
+	if _, err := d.conn.Exec(`VACUUM`); err != nil {
+		return before, before, fmt.Errorf("vacuum: %w", err)
+	}
+	if after, err = d.Size(); err != nil {
+		return before, before, err
+	}
+	return before, after, nil
+}
+
+// AutoVacuum runs VACUUM when at least AutoVacuumFreeRatio of the database
+// pages are free, e.g. after many memories or sessions were deleted. It
+// reports whether a vacuum ran.
+func (d *DB) AutoVacuum() (bool, error) {
+	var free, pages int64
+	if err := d.conn.QueryRow(`PRAGMA freelist_count`).Scan(&free); err != nil {
+		return false, fmt.Errorf("freelist count: %w", err)
+	}
+	if err := d.conn.QueryRow(`PRAGMA page_count`).Scan(&pages); err != nil {
+		return false, fmt.Errorf("page count: %w", err)
+	}
+	if pages == 0 || free < autoVacuumMinFreePages || float64(free)/float64(pages) < AutoVacuumFreeRatio {
+		return false, nil
+	}
+	if _, err := d.conn.Exec(`VACUUM`); err != nil {
+		return false, fmt.Errorf("vacuum: %w", err)
+	}
+	return true, nil
+}
+
+// rebuildVectorTables recreates each vector table with only its live rows,
+// keeping the dimension it was created with. It is a no-op when the tables
+// don't exist (sqlite-vec unavailable).
+func (d *DB) rebuildVectorTables() error {
+	var existing int
+	if err := d.conn.QueryRow(
+		`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name IN (?, ?)`,
+		vectorTables[0], vectorTables[1],
+	).Scan(&existing); err != nil {
+		return fmt.Errorf("check vector tables: %w", err)
+	}
+	if existing != len(vectorTables) {
+		return nil
+	}
+	dimension := d.VectorDimension()
+	if dimension == 0 {
+		dimension = DefaultEmbeddingDimension
+	}
+
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("rebuild vector tables: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, t := range vectorTables {
+		if _, err := tx.Exec(fmt.Sprintf(
+			`CREATE TEMP TABLE %[1]s_rebuild AS SELECT model, id, embedding FROM %[1]s`, t,
+		)); err != nil {
+			return fmt.Errorf("copy %s: %w", t, err)
+		}
+		if _, err := tx.Exec(`DROP TABLE ` + t); err != nil {
+			return fmt.Errorf("drop %s: %w", t, err)
+		}
+	}
+	if err := applyVectorTables(tx, dimension); err != nil {
+		return err
+	}
+	for _, t := range vectorTables {
+		if _, err := tx.Exec(fmt.Sprintf(
+			`INSERT INTO %[1]s (key, model, id, embedding) SELECT `+vectorKeySQL+`, model, id, embedding FROM %[1]s_rebuild`, t,
+		)); err != nil {
+			return fmt.Errorf("restore %s: %w", t, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf(`DROP TABLE %s_rebuild`, t)); err != nil {
+			return fmt.Errorf("drop %s copy: %w", t, err)
+		}
+	}
+	return tx.Commit()
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -19,13 +20,19 @@ func init() {
 const (
 	// DefaultEmbeddingDimension is used when creating vec0 virtual tables.
 	// nomic-embed-text produces 768-dim vectors; text-embedding-3-small produces 1536.
-	// We default to 768 to match nomic-embed-text (the default Ollama embed model).
+	// We default to 768 to match nomic-embed-text (the default Ollama embed model);
+	// ResizeVectorTables recreates the tables for other embedders.
 	DefaultEmbeddingDimension = 768
 )
 
+// ErrReadOnly is returned (wrapped) by write operations on a database
+// opened with OpenReadOnly.
+var ErrReadOnly = errors.New("database is read-only")
+
 // DB wraps a *sql.DB and exposes helpers.
 type DB struct {
-	conn *sql.DB
+	conn     *sql.DB
+	readOnly bool
 }
 
 // Open opens (or creates) the SQLite database at path and applies migrations.
@@ -53,13 +60,101 @@ func Open(path string) (*DB, error) {
 		return nil, fmt.Errorf("apply migrations: %w", err)
 	}
 
-	if err := applyVectorTables(conn, DefaultEmbeddingDimension); err != nil {
-		// Non-fatal: sqlite-vec may not be available in all build configurations.
-		// Vector search will degrade gracefully to keyword/type-based retrieval.
-		_ = err
+	d := &DB{conn: conn}
+	// Non-fatal: sqlite-vec may not be available in all build configurations.
+	// Vector search will degrade gracefully to keyword/type-based retrieval,
+	// and the vector store retries creating the tables on its first write.
+	_ = d.EnsureVectorTables()
+
+	return d, nil
+}
+
+// OpenReadOnly opens the existing database at path without write access.
+// SQLite itself rejects writes (mode=ro), so nothing in the process can
+// change the file; migrations are not applied, and a database that needs
+// them is an error rather than a source of confusing query failures.
+func OpenReadOnly(path string) (*DB, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolve path: %w", err)
+	}
+	if _, err := os.Stat(absPath); err != nil {
+		return nil, fmt.Errorf("open read-only: %w", err)
+	}
+
+	dsn := fmt.Sprintf("file:%s?mode=ro&_foreign_keys=on&_busy_timeout=5000", absPath)
+	conn, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite: %w", err)
 	}
+	conn.SetMaxOpenConns(1)
+
+	var version sql.NullInt64
+	if err := conn.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("open read-only: %w", err)
+	}
+	if !version.Valid || int(version.Int64) < LatestSchemaVersion() {
+		_ = conn.Close()
+		return nil, fmt.Errorf("open read-only: schema is at version %d, need %d; open the database once with write access to migrate it",
+			version.Int64, LatestSchemaVersion())
+	}
+	return &DB{conn: conn, readOnly: true}, nil
+}
+
+// ReadOnly reports whether the database was opened with OpenReadOnly.
+func (d *DB) ReadOnly() bool {
+	return d.readOnly
+}
+
+// EnsureVectorTables creates the sqlite-vec tables if they are missing, e.g.
+// in a database created before vector support, and upgrades tables created
+// in an older layout.
+func (d *DB) EnsureVectorTables() error {
+	if d.readOnly {
+		return ErrReadOnly
+	}
+	if err := applyVectorTables(d.conn, DefaultEmbeddingDimension); err != nil {
+		return err
+	}
+	return d.upgradeVectorTables()
+}
+
+// ResizeVectorTables drops the sqlite-vec tables and recreates them, empty,
+// for vectors of dimension components. vec0 tables hold a single dimension,
+// so every stored embedding, of every model, is discarded.
+func (d *DB) ResizeVectorTables(dimension int) error {
+	if d.readOnly {
+		return ErrReadOnly
+	}
+	if dimension <= 0 {
+		return fmt.Errorf("resize vector tables: invalid dimension %d", dimension)
+	}
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("resize vector tables: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, t := range vectorTables {
+		if _, err := tx.Exec(`DROP TABLE IF EXISTS ` + t); err != nil {
+			return fmt.Errorf("drop %s: %w", t, err)
+		}
+	}
+	if err := applyVectorTables(tx, dimension); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
 
-	return &DB{conn: conn}, nil
+// HasVectorTables reports whether both sqlite-vec tables exist.
+func (d *DB) HasVectorTables() bool {
+	var n int
+	err := d.conn.QueryRow(
+		`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name IN (?, ?)`,
+		vectorTables[0], vectorTables[1],
+	).Scan(&n)
+	return err == nil && n == len(vectorTables)
 }
 
 // Conn returns the underlying *sql.DB for use by store/vector layers.
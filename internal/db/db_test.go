@@ -1,7 +1,11 @@
 package db
 
 import (
+	"database/sql"
+	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -35,7 +39,7 @@ func TestOpen_TablesExist(t *testing.T) {
 	}
 	defer database.Close()
 
-	tables := []string{"project", "files", "chunks", "memories", "sessions", "schema_migrations"}
+	tables := []string{"project", "files", "chunks", "memories", "sessions", "schema_migrations", "embedding_cache"}
 	for _, table := range tables {
 		var count int
 		err := database.Conn().QueryRow(
@@ -68,6 +72,53 @@ func TestOpen_MigrationsRecorded(t *testing.T) {
 	}
 }
 
+func TestVectorDimension(t *testing.T) {
+	database, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer database.Close()
+
+	if got := database.VectorDimension(); got != DefaultEmbeddingDimension {
+		t.Errorf("VectorDimension = %d, want %d", got, DefaultEmbeddingDimension)
+	}
+
+	if err := database.ResizeVectorTables(1024); err != nil {
+		t.Fatalf("ResizeVectorTables: %v", err)
+	}
+	if got := database.VectorDimension(); got != 1024 {
+		t.Errorf("after resize: VectorDimension = %d, want 1024", got)
+	}
+	// Compaction rebuilds the tables at their current dimension.
+	if _, _, err := database.Compact(); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	if got := database.VectorDimension(); got != 1024 {
+		t.Errorf("after compact: VectorDimension = %d, want 1024", got)
+	}
+}
+
+func TestSchemaVersion(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	if _, err := SchemaVersion(dbPath); err == nil {
+		t.Error("expected error for missing database")
+	}
+
+	database, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	database.Close()
+
+	v, err := SchemaVersion(dbPath)
+	if err != nil {
+		t.Fatalf("SchemaVersion: %v", err)
+	}
+	if v != LatestSchemaVersion() {
+		t.Errorf("expected version %d, got %d", LatestSchemaVersion(), v)
+	}
+}
+
 func TestOpen_Idempotent(t *testing.T) {
 	dbPath := filepath.Join(t.TempDir(), "test.db")
 
@@ -102,7 +153,7 @@ func TestOpen_VectorTables(t *testing.T) {
 
 	// sqlite-vec tables might not exist if the extension isn't loaded,
 	// but we should at least not crash.
-	for _, table := range []string{"vec_chunks", "vec_memories"} {
+	for _, table := range []string{"vec_chunk_embeddings", "vec_memory_embeddings"} {
 		var count int
 		database.Conn().QueryRow(
 			`SELECT COUNT(*) FROM sqlite_master WHERE name=?`, table,
@@ -112,6 +163,213 @@ func TestOpen_VectorTables(t *testing.T) {
 	}
 }
 
+func TestAdoptLegacyVectors(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	database, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer database.Close()
+
+	// Recreate the pre-model-key layout with one stored vector.
+	conn := database.Conn()
+	if _, err := conn.Exec(`CREATE VIRTUAL TABLE vec_chunks USING vec0(id TEXT PRIMARY KEY, embedding float[768])`); err != nil {
+		t.Skipf("sqlite-vec unavailable: %v", err)
+	}
+	vec := "[" + strings.TrimSuffix(strings.Repeat("0.5,", 768), ",") + "]"
+	if _, err := conn.Exec(`INSERT INTO vec_chunks (id, embedding) VALUES ('c1', ?)`, vec); err != nil {
+		t.Fatalf("seed legacy row: %v", err)
+	}
+
+	n, err := database.AdoptLegacyVectors("ollama:nomic-embed-text")
+	if err != nil {
+		t.Fatalf("AdoptLegacyVectors: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("adopted: got %d, want 1", n)
+	}
+
+	var model, id string
+	if err := conn.QueryRow(`SELECT model, id FROM vec_chunk_embeddings`).Scan(&model, &id); err != nil {
+		t.Fatalf("read adopted row: %v", err)
+	}
+	if model != "ollama:nomic-embed-text" || id != "c1" {
+		t.Errorf("adopted row: got %q/%q", model, id)
+	}
+
+	var legacy int
+	conn.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'vec_chunks'`).Scan(&legacy)
+	if legacy != 0 {
+		t.Error("legacy table should be dropped after adoption")
+	}
+
+	// Running again is a no-op.
+	if n, err := database.AdoptLegacyVectors("openai:"); err != nil || n != 0 {
+		t.Errorf("second adoption: got %d, %v", n, err)
+	}
+}
+
+func TestUpgradeVectorTables_AddsKey(t *testing.T) {
+	database, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer database.Close()
+	conn := database.Conn()
+
+	// Recreate the unkeyed model-partitioned layout.
+	vec := "[" + strings.TrimSuffix(strings.Repeat("0.5,", 768), ",") + "]"
+	for _, table := range vectorTables {
+		if _, err := conn.Exec(`DROP TABLE ` + table); err != nil {
+			t.Skipf("sqlite-vec unavailable: %v", err)
+		}
+		conn.Exec(fmt.Sprintf(`CREATE VIRTUAL TABLE %s USING vec0(model TEXT partition key, id TEXT, embedding float[768])`, table))
+	}
+	conn.Exec(`INSERT INTO vec_chunk_embeddings (model, id, embedding) VALUES ('m', 'c1', ?)`, vec)
+
+	if err := database.upgradeVectorTables(); err != nil {
+		t.Fatalf("upgradeVectorTables: %v", err)
+	}
+
+	var id string
+	if err := conn.QueryRow(`SELECT id FROM vec_chunk_embeddings WHERE key = ?`, VectorKey("m", "c1")).Scan(&id); err != nil || id != "c1" {
+		t.Errorf("keyed lookup after upgrade: %q, %v", id, err)
+	}
+}
+
+func TestCompact_ReclaimsDeletedVectors(t *testing.T) {
+	database, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer database.Close()
+	conn := database.Conn()
+
+	vec := "[" + strings.TrimSuffix(strings.Repeat("0.5,", 768), ",") + "]"
+	for i := 0; i < 1500; i++ {
+		if _, err := conn.Exec(
+			`INSERT INTO vec_chunk_embeddings (key, model, id, embedding) VALUES (?, 'm', ?, ?)`, VectorKey("m", fmt.Sprint(i)), fmt.Sprint(i), vec,
+		); err != nil {
+			t.Skipf("sqlite-vec unavailable: %v", err)
+		}
+	}
+	conn.Exec(`DELETE FROM vec_chunk_embeddings WHERE CAST(id AS INTEGER) >= 10`)
+
+	before, after, err := database.Compact()
+	if err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	if after >= before {
+		t.Errorf("expected compact to shrink the DB: %d → %d bytes", before, after)
+	}
+
+	var n int
+	conn.QueryRow(`SELECT COUNT(*) FROM vec_chunk_embeddings WHERE model = 'm'`).Scan(&n)
+	if n != 10 {
+		t.Errorf("live vectors after compact: got %d, want 10", n)
+	}
+	var id string
+	if err := conn.QueryRow(
+		`SELECT id FROM vec_chunk_embeddings WHERE embedding MATCH ? AND k = 1 AND model = 'm'`, vec,
+	).Scan(&id); err != nil {
+		t.Errorf("search after compact: %v", err)
+	}
+}
+
+func TestAutoVacuum_Threshold(t *testing.T) {
+	database, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer database.Close()
+	conn := database.Conn()
+
+	if ran, _ := database.AutoVacuum(); ran {
+		t.Error("fresh DB should not need a vacuum")
+	}
+
+	body := strings.Repeat("x", 2000)
+	for i := 0; i < 1000; i++ {
+		conn.Exec(`INSERT INTO memories (content, memory_type) VALUES (?, 'note')`, body)
+	}
+	conn.Exec(`DELETE FROM memories`)
+
+	ran, err := database.AutoVacuum()
+	if err != nil {
+		t.Fatalf("AutoVacuum: %v", err)
+	}
+	if !ran {
+		t.Error("expected a vacuum after deleting most rows")
+	}
+	var free int
+	conn.QueryRow(`PRAGMA freelist_count`).Scan(&free)
+	if free != 0 {
+		t.Errorf("free pages after vacuum: %d", free)
+	}
+}
+
+func TestBackupRestore_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	database, err := Open(filepath.Join(dir, "memvra.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer database.Close()
+	conn := database.Conn()
+
+	conn.Exec(`INSERT INTO memories (id, content, memory_type) VALUES ('m1', 'use PostgreSQL', 'decision')`)
+	conn.Exec(`INSERT INTO sessions (id, question, response_summary) VALUES ('s1', 'how to migrate?', 'added goose')`)
+
+	backupPath := filepath.Join(dir, "backups", "snapshot.db")
+	if err := database.Backup(backupPath); err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+
+	// Risky operation: wipe memories and sessions, then add something new.
+	conn.Exec(`DELETE FROM memories`)
+	conn.Exec(`DELETE FROM sessions`)
+	conn.Exec(`INSERT INTO memories (id, content, memory_type) VALUES ('m2', 'scratch', 'note')`)
+
+	if err := database.Restore(backupPath); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	var content string
+	if err := conn.QueryRow(`SELECT content FROM memories WHERE id = 'm1'`).Scan(&content); err != nil || content != "use PostgreSQL" {
+		t.Errorf("memory after restore: %q, %v", content, err)
+	}
+	var summary string
+	if err := conn.QueryRow(`SELECT response_summary FROM sessions WHERE id = 's1'`).Scan(&summary); err != nil || summary != "added goose" {
+		t.Errorf("session after restore: %q, %v", summary, err)
+	}
+	var n int
+	conn.QueryRow(`SELECT COUNT(*) FROM memories`).Scan(&n)
+	if n != 1 {
+		t.Errorf("memories after restore: got %d, want 1", n)
+	}
+}
+
+func TestValidate_RejectsNonMemvraFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	garbage := filepath.Join(dir, "garbage.db")
+	os.WriteFile(garbage, []byte("not a database at all, just some text padding it out"), 0o644)
+	if err := Validate(garbage); err == nil {
+		t.Error("expected error for a non-SQLite file")
+	}
+
+	if err := Validate(filepath.Join(dir, "missing.db")); err == nil {
+		t.Error("expected error for a missing file")
+	}
+
+	other, _ := Open(filepath.Join(dir, "other.db"))
+	other.Conn().Exec(`DROP TABLE memories`)
+	other.Close()
+	if err := Validate(filepath.Join(dir, "other.db")); err == nil || !strings.Contains(err.Error(), "memories") {
+		t.Errorf("expected missing-table error, got %v", err)
+	}
+}
+
 func TestConn_ReturnsNonNil(t *testing.T) {
 	dbPath := filepath.Join(t.TempDir(), "test.db")
 	database, err := Open(dbPath)
@@ -141,3 +399,72 @@ func TestClose(t *testing.T) {
 		t.Error("expected Ping to fail after Close")
 	}
 }
+
+// describeSchema lists the columns and indexes of the regular tables in
+// conn, skipping migration bookkeeping and the sqlite-vec tables.
+func describeSchema(t *testing.T, conn *sql.DB) []string {
+	t.Helper()
+	rows, err := conn.Query(`SELECT type, name, tbl_name FROM sqlite_master
+		WHERE type IN ('table', 'index') AND name NOT LIKE 'sqlite_%'
+		AND name NOT LIKE 'vec_%' AND name != 'schema_migrations' ORDER BY type, name`)
+	if err != nil {
+		t.Fatalf("list schema: %v", err)
+	}
+	var objects [][3]string
+	for rows.Next() {
+		var o [3]string
+		rows.Scan(&o[0], &o[1], &o[2])
+		objects = append(objects, o)
+	}
+	rows.Close()
+
+	var out []string
+	for _, o := range objects {
+		if o[0] == "index" {
+			out = append(out, fmt.Sprintf("index %s on %s", o[1], o[2]))
+			continue
+		}
+		cols, err := conn.Query(fmt.Sprintf(`PRAGMA table_info(%s)`, o[1]))
+		if err != nil {
+			t.Fatalf("table_info %s: %v", o[1], err)
+		}
+		for cols.Next() {
+			var (
+				cid, notNull, pk int
+				name, typ        string
+				dflt             sql.NullString
+			)
+			cols.Scan(&cid, &name, &typ, &notNull, &dflt, &pk)
+			out = append(out, fmt.Sprintf("%s.%s %s notnull=%d default=%s pk=%d", o[1], name, typ, notNull, dflt.String, pk))
+		}
+		cols.Close()
+	}
+	return out
+}
+
+func TestSchemaSQL_MatchesMigrations(t *testing.T) {
+	database, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer database.Close()
+
+	schema, err := os.ReadFile("schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "ref.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ref.Close()
+	if _, err := ref.Exec(string(schema)); err != nil {
+		t.Fatalf("apply schema.sql: %v", err)
+	}
+
+	got, want := describeSchema(t, ref), describeSchema(t, database.Conn())
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("schema.sql is out of sync with the migrations:\nschema.sql:\n%s\nmigrations:\n%s",
+			strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -3,6 +3,10 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
 )
 
 // migrations is an ordered list of SQL migration statements.
@@ -74,6 +78,52 @@ var migrations = []string{
 		version    INTEGER PRIMARY KEY,
 		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
 	)`,
+
+	// Migration 2: persistent embedding cache keyed by sha256(model + text)
+	`CREATE TABLE IF NOT EXISTS embedding_cache (
+		key        TEXT PRIMARY KEY,
+		model      TEXT NOT NULL,
+		embedding  BLOB NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`,
+
+	// Migration 3: how a session ended (completed, in_progress, blocked)
+	`ALTER TABLE sessions ADD COLUMN status TEXT NOT NULL DEFAULT 'completed'`,
+
+	// Migration 4: follow-up work recorded with a session, as a JSON array
+	`ALTER TABLE sessions ADD COLUMN next_steps TEXT NOT NULL DEFAULT '[]'`,
+
+	// Migrations 5-6: provenance of AI-inferred memories
+	`ALTER TABLE memories ADD COLUMN source_session_id TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE memories ADD COLUMN confidence REAL NOT NULL DEFAULT 0`,
+
+	// Migration 7: free-form session tags, as a JSON array
+	`ALTER TABLE sessions ADD COLUMN tags TEXT NOT NULL DEFAULT '[]'`,
+
+	// Migration 8: audit trail of items packed into context ([audit] enabled)
+	`CREATE TABLE IF NOT EXISTS access_log (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		item_id     TEXT NOT NULL,
+		item_type   TEXT NOT NULL,
+		model       TEXT NOT NULL DEFAULT '',
+		session_id  TEXT NOT NULL DEFAULT '',
+		accessed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_access_log_item ON access_log(item_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_access_log_accessed ON access_log(accessed_at)`,
+
+	// Migration 9: when a todo was marked done ('' = open)
+	`ALTER TABLE memories ADD COLUMN completed_at TEXT NOT NULL DEFAULT ''`,
+
+	// Migration 10: typed links between memories (supersedes, relates_to)
+	`CREATE TABLE IF NOT EXISTS memory_links (
+		from_id    TEXT NOT NULL REFERENCES memories(id) ON DELETE CASCADE,
+		to_id      TEXT NOT NULL REFERENCES memories(id) ON DELETE CASCADE,
+		link_type  TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (from_id, to_id, link_type)
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_memory_links_to ON memory_links(to_id)`,
 }
 
 // applyMigrations runs any migrations that have not yet been applied.
@@ -108,16 +158,52 @@ func applyMigrations(conn *sql.DB) error {
 	return nil
 }
 
+// LatestSchemaVersion is the version a database reaches once every migration
+// has been applied.
+func LatestSchemaVersion() int { return len(migrations) - 1 }
+
+// SchemaVersion returns the highest migration version recorded in the
+// database at path. The database is opened read-only, so unlike Open this
+// never migrates it.
+func SchemaVersion(path string) (int, error) {
+	if _, err := os.Stat(path); err != nil {
+		return 0, fmt.Errorf("schema version: %w", err)
+	}
+	conn, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return 0, fmt.Errorf("schema version: open %s: %w", path, err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	var version sql.NullInt64
+	if err := conn.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("schema version: %w", err)
+	}
+	if !version.Valid {
+		return 0, fmt.Errorf("schema version: %s has no recorded migrations", path)
+	}
+	return int(version.Int64), nil
+}
+
 // applyVectorTables creates the sqlite-vec virtual tables.
 // Called separately after the vec extension is confirmed loaded.
-func applyVectorTables(conn *sql.DB, dimension int) error {
+//
+// Embeddings are partitioned by model key so vectors from several embedding
+// models can coexist for the same chunk or memory. Rows are addressed by
+// VectorKey(model, id); sqlite-vec indexes only the primary key, so lookups
+// by that key stay fast where filtering on id alone would scan the table.
+func applyVectorTables(conn execer, dimension int) error {
 	stmts := []string{
-		fmt.Sprintf(`CREATE VIRTUAL TABLE IF NOT EXISTS vec_chunks USING vec0(
-			id TEXT PRIMARY KEY,
+		fmt.Sprintf(`CREATE VIRTUAL TABLE IF NOT EXISTS vec_chunk_embeddings USING vec0(
+			key TEXT PRIMARY KEY,
+			model TEXT partition key,
+			+id TEXT,
 			embedding float[%d]
 		)`, dimension),
-		fmt.Sprintf(`CREATE VIRTUAL TABLE IF NOT EXISTS vec_memories USING vec0(
-			id TEXT PRIMARY KEY,
+		fmt.Sprintf(`CREATE VIRTUAL TABLE IF NOT EXISTS vec_memory_embeddings USING vec0(
+			key TEXT PRIMARY KEY,
+			model TEXT partition key,
+			+id TEXT,
 			embedding float[%d]
 		)`, dimension),
 	}
@@ -130,3 +216,103 @@ func applyVectorTables(conn *sql.DB, dimension int) error {
 
 	return nil
 }
+
+// vectorTables lists the sqlite-vec tables created by applyVectorTables.
+var vectorTables = []string{"vec_chunk_embeddings", "vec_memory_embeddings"}
+
+// VectorKey returns the primary key of the vector row for id under model.
+func VectorKey(model, id string) string {
+	return model + "\x00" + id
+}
+
+// vectorKeySQL computes VectorKey from model and id columns in SQL.
+const vectorKeySQL = `model || char(0) || id`
+
+// upgradeVectorTables rebuilds vector tables created before rows were keyed
+// by VectorKey (model partition plus an unindexed id column).
+func (d *DB) upgradeVectorTables() error {
+	var sqlText string
+	err := d.conn.QueryRow(
+		`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?`, vectorTables[0],
+	).Scan(&sqlText)
+	if err != nil || strings.Contains(sqlText, "key TEXT PRIMARY KEY") {
+		return nil
+	}
+	return d.rebuildVectorTables()
+}
+
+// vectorDimensionRe extracts the declared size of the embedding column.
+var vectorDimensionRe = regexp.MustCompile(`embedding\s+float\[(\d+)\]`)
+
+// VectorDimension returns the vector size the embedding tables were created
+// with, or 0 if they don't exist. Every stored and query vector must have
+// this many components.
+func (d *DB) VectorDimension() int {
+	var sqlText string
+	err := d.conn.QueryRow(
+		`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?`, vectorTables[0],
+	).Scan(&sqlText)
+	if err != nil {
+		return 0
+	}
+	m := vectorDimensionRe.FindStringSubmatch(sqlText)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// legacyVectorTables maps the pre-model-key vector tables (one row per id,
+// no model column) to the tables that replaced them.
+var legacyVectorTables = [][2]string{
+	{"vec_chunks", "vec_chunk_embeddings"},
+	{"vec_memories", "vec_memory_embeddings"},
+}
+
+// AdoptLegacyVectors moves embeddings from the legacy vec_chunks and
+// vec_memories tables into the model-keyed tables under model, then drops the
+// legacy tables. Those rows carry no model information, so callers should pass
+// the model that is configured when the database is first opened after
+// upgrading. It is a no-op once the legacy tables are gone.
+func (d *DB) AdoptLegacyVectors(model string) (int, error) {
+	total := 0
+	for _, t := range legacyVectorTables {
+		var exists int
+		if err := d.conn.QueryRow(
+			`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, t[0],
+		).Scan(&exists); err != nil {
+			return total, fmt.Errorf("check %s: %w", t[0], err)
+		}
+		if exists == 0 {
+			continue
+		}
+
+		tx, err := d.conn.Begin()
+		if err != nil {
+			return total, fmt.Errorf("adopt %s: %w", t[0], err)
+		}
+		res, err := tx.Exec(fmt.Sprintf(
+			`INSERT INTO %s (key, model, id, embedding) SELECT ? || char(0) || id, ?, id, embedding FROM %s`, t[1], t[0],
+		), model, model)
+		if err != nil {
+			_ = tx.Rollback()
+			return total, fmt.Errorf("adopt %s: %w", t[0], err)
+		}
+		if _, err := tx.Exec(`DROP TABLE ` + t[0]); err != nil {
+			_ = tx.Rollback()
+			return total, fmt.Errorf("drop %s: %w", t[0], err)
+		}
+		if err := tx.Commit(); err != nil {
+			return total, fmt.Errorf("adopt %s: %w", t[0], err)
+		}
+		n, _ := res.RowsAffected()
+		total += int(n)
+	}
+	return total, nil
+}
@@ -44,7 +44,10 @@ CREATE TABLE IF NOT EXISTS memories (
     source        TEXT,                         -- 'user' (manual) or 'extracted' (from session)
     related_files TEXT,                         -- JSON array of file paths
     created_at    DATETIME DEFAULT CURRENT_TIMESTAMP,
-    updated_at    DATETIME DEFAULT CURRENT_TIMESTAMP
+    updated_at    DATETIME DEFAULT CURRENT_TIMESTAMP,
+    source_session_id TEXT NOT NULL DEFAULT '', -- session an inferred memory came from
+    confidence    REAL NOT NULL DEFAULT 0,      -- 0.0 to 1.0 for inferred memories
+    completed_at  TEXT NOT NULL DEFAULT ''      -- when a todo was marked done ('' = open)
 );
 
 -- Session history
@@ -55,10 +58,43 @@ CREATE TABLE IF NOT EXISTS sessions (
     response_summary TEXT,                      -- Brief summary of the AI response
     model_used       TEXT,
     tokens_used      INTEGER,
-    created_at       DATETIME DEFAULT CURRENT_TIMESTAMP
+    created_at       DATETIME DEFAULT CURRENT_TIMESTAMP,
+    status           TEXT NOT NULL DEFAULT 'completed', -- completed, in_progress, blocked
+    next_steps       TEXT NOT NULL DEFAULT '[]',  -- JSON array of follow-up work
+    tags             TEXT NOT NULL DEFAULT '[]'   -- JSON array of free-form tags
 );
 
--- Virtual table for vector similarity search (sqlite-vec)
+-- Embedding cache: vectors keyed by sha256(model + input text) so
+-- re-indexing unchanged content skips the embedding provider.
+CREATE TABLE IF NOT EXISTS embedding_cache (
+    key        TEXT PRIMARY KEY,
+    model      TEXT NOT NULL,
+    embedding  BLOB NOT NULL,                   -- little-endian float32 blob
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Audit trail of items packed into context (written when [audit] is enabled)
+CREATE TABLE IF NOT EXISTS access_log (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    item_id     TEXT NOT NULL,
+    item_type   TEXT NOT NULL,                  -- decision, memory, session, file or chunk
+    model       TEXT NOT NULL DEFAULT '',       -- model or client the context was built for
+    session_id  TEXT NOT NULL DEFAULT '',
+    accessed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Typed links between memories (supersedes, relates_to)
+CREATE TABLE IF NOT EXISTS memory_links (
+    from_id    TEXT NOT NULL REFERENCES memories(id) ON DELETE CASCADE,
+    to_id      TEXT NOT NULL REFERENCES memories(id) ON DELETE CASCADE,
+    link_type  TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (from_id, to_id, link_type)
+);
+
+-- Virtual tables for vector similarity search (sqlite-vec), partitioned by
+-- embedding model key so several models can coexist per chunk or memory.
+-- They hold vectors of one dimension, sized to the embedder.
 -- NOTE: These are created conditionally in Go code after the extension loads.
 
 -- Indexes
@@ -66,3 +102,6 @@ CREATE INDEX IF NOT EXISTS idx_memories_type   ON memories(memory_type);
 CREATE INDEX IF NOT EXISTS idx_chunks_file      ON chunks(file_id);
 CREATE INDEX IF NOT EXISTS idx_sessions_created ON sessions(created_at DESC);
 CREATE INDEX IF NOT EXISTS idx_files_path       ON files(path);
+CREATE INDEX IF NOT EXISTS idx_access_log_item  ON access_log(item_id);
+CREATE INDEX IF NOT EXISTS idx_access_log_accessed ON access_log(accessed_at);
+CREATE INDEX IF NOT EXISTS idx_memory_links_to  ON memory_links(to_id);
@@ -2,8 +2,11 @@ package export
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/memvra/memvra/internal/config"
@@ -28,26 +31,46 @@ func FormatToFilename(format string) string {
 	}
 }
 
-// AutoExport regenerates all configured export files in the project root.
-// It is best-effort: failures are logged to stderr but never abort the caller.
-func AutoExport(root string, store *memory.Store) {
-	gcfg, _ := config.Load(root)
-	if !gcfg.AutoExport.Enabled || len(gcfg.AutoExport.Formats) == 0 {
-		return
+// GeneratedFiles returns every file auto-export can write, relative to the
+// project root, sorted. They are rendered from memory, so they are kept out
+// of the index unless the project sets index_generated.
+func GeneratedFiles() []string {
+	var names []string
+	for _, format := range ValidFormats() {
+		if name := FormatToFilename(format); name != "" {
+			names = append(names, name)
+		}
 	}
+	sort.Strings(names)
+	return names
+}
+
+// RenderedFile is one export format rendered in memory.
+type RenderedFile struct {
+	Format   string
+	Filename string // relative to the project root
+	Content  string
+}
 
+// RenderFiles renders the given formats from the project's current memory
+// state without writing anything, applying cfg's importance floor and session
+// count. Unknown formats are skipped; the first exporter error aborts.
+func RenderFiles(root string, store *memory.Store, formats []string, cfg config.AutoExportConfig) ([]RenderedFile, error) {
 	proj, err := store.GetProject()
 	if err != nil {
-		return
+		return nil, fmt.Errorf("export: get project: %w", err)
 	}
 	ts, _ := scanner.TechStackFromJSON(proj.TechStack)
+	pcfg, _ := config.LoadProject(root)
+	proj.Name = pcfg.ProjectName(proj.Name)
 
 	memories, err := store.ListMemories("")
 	if err != nil {
-		return
+		return nil, fmt.Errorf("export: list memories: %w", err)
 	}
+	memories = memory.FilterByImportance(memories, cfg.MinImportance)
 
-	sessions, _ := store.GetLastNSessions(5)
+	sessions := RecentSessions(store, cfg.Sessions)
 	gitState := gitpkg.CaptureWorkingState(root)
 
 	data := ExportData{
@@ -58,28 +81,77 @@ func AutoExport(root string, store *memory.Store) {
 		GitState: gitState,
 	}
 
-	var exported []string
-	for _, format := range gcfg.AutoExport.Formats {
+	var files []RenderedFile
+	for _, format := range formats {
 		exporter, ok := Get(format)
 		if !ok {
 			continue
 		}
-		output, err := exporter.Export(data)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "  warn: auto-export %s failed: %v\n", format, err)
-			continue
-		}
-
 		filename := FormatToFilename(format)
 		if filename == "" {
 			continue
 		}
-		outPath := filepath.Join(root, filename)
-		if err := os.WriteFile(outPath, []byte(output), 0o644); err != nil {
-			fmt.Fprintf(os.Stderr, "  warn: write %s failed: %v\n", filename, err)
+		output, err := exporter.Export(data)
+		if err != nil {
+			return nil, fmt.Errorf("export: %s: %w", format, err)
+		}
+		files = append(files, RenderedFile{Format: format, Filename: filename, Content: output})
+	}
+	return files, nil
+}
+
+// RecentSessions returns the n most recent sessions for the Recent Activity
+// section, or none when n <= 0.
+func RecentSessions(store *memory.Store, n int) []memory.Session {
+	if n <= 0 {
+		return nil
+	}
+	sessions, _ := store.GetLastNSessions(n)
+	return sessions
+}
+
+// PreviewAutoExport renders the files AutoExport would write, keyed by
+// filename relative to the project root, without touching the working tree.
+// It ignores whether auto-export is enabled, so the output can be vetted
+// before turning it on.
+func PreviewAutoExport(root string, store *memory.Store) (map[string]string, error) {
+	gcfg, _ := config.Load(root)
+	files, err := RenderFiles(root, store, gcfg.AutoExport.Formats, gcfg.AutoExport)
+	if err != nil {
+		return nil, err
+	}
+	preview := make(map[string]string, len(files))
+	for _, f := range files {
+		preview[f.Filename] = f.Content
+	}
+	return preview, nil
+}
+
+// AutoExport regenerates all configured export files in the project root.
+// It is best-effort: failures are logged to stderr but never abort the caller.
+func AutoExport(root string, store *memory.Store) {
+	gcfg, _ := config.Load(root)
+	if !gcfg.AutoExport.Enabled || len(gcfg.AutoExport.Formats) == 0 {
+		return
+	}
+
+	if _, err := store.GetProject(); err != nil {
+		return // Not initialized yet — nothing to export.
+	}
+	files, err := PreviewAutoExport(root, store)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  warn: auto-export failed: %v\n", err)
+		return
+	}
+
+	var exported []string
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		outPath := filepath.Join(root, name)
+		if err := os.WriteFile(outPath, []byte(files[name]), 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "  warn: write %s failed: %v\n", name, err)
 			continue
 		}
-		exported = append(exported, filename)
+		exported = append(exported, name)
 	}
 
 	if len(exported) > 0 {
@@ -0,0 +1,99 @@
+package export
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/memvra/memvra/internal/config"
+	"github.com/memvra/memvra/internal/db"
+	"github.com/memvra/memvra/internal/memory"
+)
+
+// setupExportStore returns a store with a project and three sessions.
+func setupExportStore(t *testing.T) *memory.Store {
+	t.Helper()
+	database, err := db.Open(filepath.Join(t.TempDir(), "export_test.db"))
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	store := memory.NewStore(database)
+	store.UpsertProject(memory.Project{Name: "testapp", TechStack: `{"language":"Go"}`})
+	for i := 1; i <= 3; i++ {
+		store.InsertSession(memory.Session{Question: fmt.Sprintf("task %d", i), ModelUsed: "claude"})
+	}
+	return store
+}
+
+func TestRenderFiles_SessionCount(t *testing.T) {
+	store := setupExportStore(t)
+
+	files, err := RenderFiles(t.TempDir(), store, []string{"claude"}, config.AutoExportConfig{Sessions: 2})
+	if err != nil {
+		t.Fatalf("RenderFiles: %v", err)
+	}
+	if n := strings.Count(files[0].Content, "task "); n != 2 {
+		t.Errorf("expected 2 sessions listed, got %d:\n%s", n, files[0].Content)
+	}
+}
+
+func TestRenderFiles_ZeroSessionsOmitsRecentActivity(t *testing.T) {
+	store := setupExportStore(t)
+
+	files, err := RenderFiles(t.TempDir(), store, []string{"claude", "cursor", "markdown", "json"}, config.AutoExportConfig{})
+	if err != nil {
+		t.Fatalf("RenderFiles: %v", err)
+	}
+	for _, f := range files {
+		if strings.Contains(f.Content, "Recent Activity") || strings.Contains(f.Content, "recent_activity") || strings.Contains(f.Content, "task 1") {
+			t.Errorf("%s: expected no session history:\n%s", f.Format, f.Content)
+		}
+	}
+}
+
+func TestRenderFiles_DisplayName(t *testing.T) {
+	store := setupExportStore(t)
+	root := t.TempDir()
+	config.SaveProject(root, config.ProjectConfig{Project: config.ProjectMeta{Name: "testapp", DisplayName: "Test App"}})
+
+	files, err := RenderFiles(root, store, []string{"claude", "json"}, config.AutoExportConfig{})
+	if err != nil {
+		t.Fatalf("RenderFiles: %v", err)
+	}
+	for _, f := range files {
+		if !strings.Contains(f.Content, "Test App") || strings.Contains(f.Content, "testapp") {
+			t.Errorf("%s: expected the display name instead of the project name:\n%s", f.Format, f.Content)
+		}
+	}
+}
+
+func TestPreviewAutoExport(t *testing.T) {
+	store := setupExportStore(t)
+	root := t.TempDir()
+	config.SaveProject(root, config.ProjectConfig{
+		Project:    config.ProjectMeta{Name: "testapp"},
+		AutoExport: &config.AutoExportConfig{Formats: []string{"claude", "cursor"}, Sessions: 1},
+	})
+
+	preview, err := PreviewAutoExport(root, store)
+	if err != nil {
+		t.Fatalf("PreviewAutoExport: %v", err)
+	}
+	if len(preview) != 2 || preview["CLAUDE.md"] == "" || preview[".cursorrules"] == "" {
+		t.Fatalf("expected CLAUDE.md and .cursorrules, got %v", slices.Collect(maps.Keys(preview)))
+	}
+	if n := strings.Count(preview["CLAUDE.md"], "task "); n != 1 {
+		t.Errorf("expected the [auto_export] session count to apply, got %d sessions", n)
+	}
+	for name := range preview {
+		if _, err := os.Stat(filepath.Join(root, name)); !os.IsNotExist(err) {
+			t.Errorf("%s was written to the working tree", name)
+		}
+	}
+}
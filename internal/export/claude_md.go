@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/memvra/memvra/internal/memory"
+	"github.com/memvra/memvra/internal/scanner"
 )
 
 const mcpInstructions = `## Memvra Integration
@@ -35,32 +36,44 @@ func (e *ClaudeMDExporter) Export(data ExportData) (string, error) {
 	b.WriteString(renderSessionsMarkdown(data.Sessions))
 
 	fmt.Fprintf(&b, "## Project Profile\n\n")
+	for _, ns := range ts.Components() {
+		if ns.Name != "" {
+			fmt.Fprintf(&b, "### %s\n\n", ns.Name)
+		}
+		writeClaudeStack(&b, ns.TechStack)
+		b.WriteString("\n")
+	}
+
+	b.WriteString(memorySection("Architectural Decisions", memory.TypeDecision, data.Memories, data.Now))
+	b.WriteString(memorySection("Coding Conventions", memory.TypeConvention, data.Memories, data.Now))
+	b.WriteString(memorySection("Constraints", memory.TypeConstraint, data.Memories, data.Now))
+	b.WriteString(memorySection("Notes", memory.TypeNote, data.Memories, data.Now))
+	b.WriteString(memorySection("TODOs", memory.TypeTodo, data.Memories, data.Now))
+	for _, t := range customTypes(data.Memories) {
+		b.WriteString(memorySection(t.Label(), t, data.Memories, data.Now))
+	}
 
+	return b.String(), nil
+}
+
+// writeClaudeStack renders a single stack's fields as a markdown list.
+func writeClaudeStack(b *strings.Builder, ts scanner.TechStack) {
 	if ts.Language != "" {
-		fmt.Fprintf(&b, "- **Language:** %s\n", ts.Language)
+		fmt.Fprintf(b, "- **Language:** %s\n", ts.Language)
 	}
 	if ts.Framework != "" {
-		fmt.Fprintf(&b, "- **Framework:** %s\n", ts.Framework)
+		fmt.Fprintf(b, "- **Framework:** %s\n", ts.Framework)
 	}
 	if ts.Database != "" {
-		fmt.Fprintf(&b, "- **Database:** %s\n", ts.Database)
+		fmt.Fprintf(b, "- **Database:** %s\n", ts.Database)
 	}
 	if ts.Architecture != "" {
-		fmt.Fprintf(&b, "- **Architecture:** %s\n", ts.Architecture)
+		fmt.Fprintf(b, "- **Architecture:** %s\n", ts.Architecture)
 	}
 	if ts.TestFramework != "" {
-		fmt.Fprintf(&b, "- **Test framework:** %s\n", ts.TestFramework)
+		fmt.Fprintf(b, "- **Test framework:** %s\n", ts.TestFramework)
 	}
 	if len(ts.DetectedPatterns) > 0 {
-		fmt.Fprintf(&b, "- **Patterns:** %s\n", strings.Join(ts.DetectedPatterns, ", "))
+		fmt.Fprintf(b, "- **Patterns:** %s\n", strings.Join(ts.DetectedPatterns, ", "))
 	}
-	b.WriteString("\n")
-
-	b.WriteString(memorySection("Architectural Decisions", memory.TypeDecision, data.Memories))
-	b.WriteString(memorySection("Coding Conventions", memory.TypeConvention, data.Memories))
-	b.WriteString(memorySection("Constraints", memory.TypeConstraint, data.Memories))
-	b.WriteString(memorySection("Notes", memory.TypeNote, data.Memories))
-	b.WriteString(memorySection("TODOs", memory.TypeTodo, data.Memories))
-
-	return b.String(), nil
 }
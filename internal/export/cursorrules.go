@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/memvra/memvra/internal/memory"
+	"github.com/memvra/memvra/internal/scanner"
 )
 
 // CursorRulesExporter renders context in .cursorrules format.
@@ -22,37 +23,32 @@ func (e *CursorRulesExporter) Export(data ExportData) (string, error) {
 	b.WriteString(renderSessionsPlainText(data.Sessions))
 
 	// Cursor rules are plain text instructions, not markdown.
-	if ts.Language != "" {
-		fmt.Fprintf(&b, "This is a %s project", ts.Language)
-		if ts.Framework != "" {
-			fmt.Fprintf(&b, " using %s", ts.Framework)
-		}
-		fmt.Fprintf(&b, ".\n")
-	}
-	if ts.Database != "" {
-		fmt.Fprintf(&b, "The database is %s.\n", ts.Database)
-	}
-	if ts.Architecture != "" {
-		fmt.Fprintf(&b, "Architecture pattern: %s.\n", ts.Architecture)
+	for _, ns := range ts.Components() {
+		writeCursorStack(&b, ns)
 	}
 	b.WriteString("\n")
 
-	for _, memType := range []struct {
+	type section struct {
 		label string
 		mt    memory.MemoryType
-	}{
+	}
+	sections := []section{
 		{"Architectural Decisions", memory.TypeDecision},
 		{"Coding Conventions", memory.TypeConvention},
 		{"Constraints", memory.TypeConstraint},
 		{"Notes", memory.TypeNote},
-	} {
+	}
+	for _, t := range customTypes(data.Memories) {
+		sections = append(sections, section{t.Label(), t})
+	}
+	for _, memType := range sections {
 		items := filterByType(data.Memories, memType.mt)
 		if len(items) == 0 {
 			continue
 		}
 		fmt.Fprintf(&b, "# %s\n", memType.label)
 		for _, m := range items {
-			fmt.Fprintf(&b, "- %s\n", m.Content)
+			fmt.Fprintf(&b, "- %s%s\n", m.Content, inferredNote(m, "(", ")"))
 		}
 		b.WriteString("\n")
 	}
@@ -63,9 +59,31 @@ func (e *CursorRulesExporter) Export(data ExportData) (string, error) {
 func filterByType(memories []memory.Memory, mt memory.MemoryType) []memory.Memory {
 	var out []memory.Memory
 	for _, m := range memories {
-		if m.MemoryType == mt {
+		if m.MemoryType == mt && !m.Done() {
 			out = append(out, m)
 		}
 	}
 	return out
 }
+
+// writeCursorStack renders a single stack as plain-text sentences. Named
+// stacks are prefixed with their component name.
+func writeCursorStack(b *strings.Builder, ns scanner.NamedStack) {
+	prefix := ""
+	if ns.Name != "" {
+		prefix = ns.Name + ": "
+	}
+	if ns.Language != "" {
+		fmt.Fprintf(b, "%sThis is a %s project", prefix, ns.Language)
+		if ns.Framework != "" {
+			fmt.Fprintf(b, " using %s", ns.Framework)
+		}
+		fmt.Fprintf(b, ".\n")
+	}
+	if ns.Database != "" {
+		fmt.Fprintf(b, "%sThe database is %s.\n", prefix, ns.Database)
+	}
+	if ns.Architecture != "" {
+		fmt.Fprintf(b, "%sArchitecture pattern: %s.\n", prefix, ns.Architecture)
+	}
+}
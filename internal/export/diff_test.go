@@ -0,0 +1,71 @@
+package export
+
+import "testing"
+
+func TestUnifiedDiff_Identical(t *testing.T) {
+	if got := UnifiedDiff("a", "b", "same\n", "same\n"); got != "" {
+		t.Errorf("expected empty diff, got %q", got)
+	}
+}
+
+func TestUnifiedDiff_Change(t *testing.T) {
+	from := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	to := "1\n2\n3\n4\nfive\n6\n7\n8\n9\n10\n11\n"
+
+	want := `--- CLAUDE.md
++++ CLAUDE.md (regenerated)
+@@ -2,9 +2,10 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+ 9
+ 10
++11
+`
+	if got := UnifiedDiff("CLAUDE.md", "CLAUDE.md (regenerated)", from, to); got != want {
+		t.Errorf("diff mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnifiedDiff_SeparateHunks(t *testing.T) {
+	from := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	to := "A\nb\nc\nd\ne\nf\ng\nh\ni\nj\nK\n"
+
+	want := `--- x
++++ y
+@@ -1,4 +1,4 @@
+-a
++A
+ b
+ c
+ d
+@@ -8,4 +8,4 @@
+ h
+ i
+ j
+-k
++K
+`
+	if got := UnifiedDiff("x", "y", from, to); got != want {
+		t.Errorf("diff mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnifiedDiff_NewFile(t *testing.T) {
+	want := "--- x\n+++ y\n@@ -0,0 +1,2 @@\n+hello\n+world\n"
+	if got := UnifiedDiff("x", "y", "", "hello\nworld\n"); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestUnifiedDiff_NoNewlineAtEnd(t *testing.T) {
+	want := "--- x\n+++ y\n@@ -1,2 +1,2 @@\n same\n-last\n\\ No newline at end of file\n+last\n"
+	if got := UnifiedDiff("x", "y", "same\nlast", "same\nlast\n"); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
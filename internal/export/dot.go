@@ -0,0 +1,83 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/memvra/memvra/internal/memory"
+)
+
+// DOTExporter renders memories and the links between them as a Graphviz
+// digraph: one node per memory, filled by type, and one edge per link,
+// labeled with its type. Rendering is left to Graphviz, e.g.
+// `memvra export --format dot | dot -Tsvg > memories.svg`.
+type DOTExporter struct{}
+
+// dotColors are the node fill colors of the built-in memory types. Custom
+// types use dotDefaultColor.
+var dotColors = map[memory.MemoryType]string{
+	memory.TypeDecision:   "#9ecae1",
+	memory.TypeConvention: "#a1d99b",
+	memory.TypeConstraint: "#fc9272",
+	memory.TypeNote:       "#fdd0a2",
+	memory.TypeTodo:       "#dadaeb",
+}
+
+const dotDefaultColor = "#d9d9d9"
+
+// dotLabelRunes caps the memory text shown in a node.
+const dotLabelRunes = 60
+
+func (e *DOTExporter) Export(data ExportData) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(data.Project.Name))
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+
+	nodes := make(map[string]bool, len(data.Memories))
+	for _, m := range data.Memories {
+		nodes[m.ID] = true
+		color, ok := dotColors[m.MemoryType]
+		if !ok {
+			color = dotDefaultColor
+		}
+		label := string(m.MemoryType) + "\n" + dotTruncate(m.Content)
+		fmt.Fprintf(&b, "  %s [label=%s, fillcolor=%s];\n", dotQuote(m.ID), dotQuote(label), dotQuote(color))
+	}
+
+	// Links to memories left out of the export (by a type or importance
+	// filter) would add bare nodes, so they are dropped.
+	for _, l := range data.Links {
+		if !nodes[l.FromID] || !nodes[l.ToID] {
+			continue
+		}
+		style := "solid"
+		if l.Type == memory.LinkRelatesTo {
+			style = "dashed"
+		}
+		fmt.Fprintf(&b, "  %s -> %s [label=%s, style=%s];\n",
+			dotQuote(l.FromID), dotQuote(l.ToID), dotQuote(strings.ReplaceAll(string(l.Type), "_", " ")), style)
+	}
+	b.WriteString("}\n")
+	return b.String(), nil
+}
+
+// dotTruncate shortens s to dotLabelRunes runes on one line.
+func dotTruncate(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) <= dotLabelRunes {
+		return s
+	}
+	return string([]rune(s)[:dotLabelRunes-1]) + "…"
+}
+
+// dotQuote returns s as a DOT double-quoted string. Newlines become \n
+// line breaks in labels.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,117 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/memvra/memvra/internal/memory"
+)
+
+// EmbeddingsFormat is the export format name for raw embedding vectors. It
+// is not a registered Exporter: vectors are streamed by WriteEmbeddings.
+const EmbeddingsFormat = "embeddings"
+
+// EmbeddingRecord is one line of the embeddings export.
+type EmbeddingRecord struct {
+	Kind      string    `json:"kind"` // "chunk" or "memory"
+	ID        string    `json:"id"`
+	Type      string    `json:"type,omitempty"`       // memory type
+	Content   string    `json:"content,omitempty"`    // memory content
+	Path      string    `json:"path,omitempty"`       // chunk file path
+	StartLine int       `json:"start_line,omitempty"` // chunk line range
+	EndLine   int       `json:"end_line,omitempty"`
+	Embedding []float32 `json:"embedding"`
+}
+
+// EmbeddingsOptions selects which vectors WriteEmbeddings dumps.
+type EmbeddingsOptions struct {
+	// Model is the embedding model key whose vectors are exported.
+	Model string
+	// MemoryType keeps only memories of this type and skips code chunks.
+	// Empty exports every memory and chunk.
+	MemoryType memory.MemoryType
+	// Filter narrows memories further. Setting Filter.Types also skips code
+	// chunks; MinImportance alone leaves them in.
+	Filter MemoryFilter
+}
+
+// WriteEmbeddings streams stored vectors to w as JSON Lines, one
+// EmbeddingRecord per line: memories first, then code chunks. It returns the
+// number of records written.
+func WriteEmbeddings(w io.Writer, store *memory.Store, vectors *memory.VectorStore, opts EmbeddingsOptions) (int, error) {
+	enc := json.NewEncoder(w)
+	written := 0
+
+	memories, err := store.ListMemories(opts.MemoryType)
+	if err != nil {
+		return 0, fmt.Errorf("export: embeddings: %w", err)
+	}
+	memories = opts.Filter.Apply(memories)
+	byID := make(map[string]memory.Memory, len(memories))
+	for _, m := range memories {
+		byID[m.ID] = m
+	}
+	err = vectors.EachMemoryEmbedding(opts.Model, func(e memory.StoredEmbedding) error {
+		m, ok := byID[e.ID]
+		if !ok {
+			return nil // filtered out by type, or an orphaned vector
+		}
+		written++
+		return enc.Encode(EmbeddingRecord{
+			Kind:      "memory",
+			ID:        e.ID,
+			Type:      string(m.MemoryType),
+			Content:   m.Content,
+			Embedding: e.Embedding,
+		})
+	})
+	if err != nil {
+		return written, fmt.Errorf("export: embeddings: %w", err)
+	}
+	if opts.MemoryType != "" || len(opts.Filter.Types) > 0 {
+		return written, nil
+	}
+
+	chunks, err := store.ListAllChunks()
+	if err != nil {
+		return written, fmt.Errorf("export: embeddings: %w", err)
+	}
+	files, err := store.ListFiles()
+	if err != nil {
+		return written, fmt.Errorf("export: embeddings: %w", err)
+	}
+	paths := make(map[string]string, len(files))
+	for _, f := range files {
+		paths[f.ID] = f.Path
+	}
+	chunkByID := make(map[string]memory.Chunk, len(chunks))
+	for _, c := range chunks {
+		chunkByID[c.ID] = c
+	}
+	err = vectors.EachChunkEmbedding(opts.Model, func(e memory.StoredEmbedding) error {
+		c, ok := chunkByID[e.ID]
+		if !ok {
+			return nil
+		}
+		written++
+		return enc.Encode(EmbeddingRecord{
+			Kind:      "chunk",
+			ID:        e.ID,
+			Path:      paths[c.FileID],
+			StartLine: c.StartLine,
+			EndLine:   c.EndLine,
+			Embedding: e.Embedding,
+		})
+	})
+	if err != nil {
+		return written, fmt.Errorf("export: embeddings: %w", err)
+	}
+	return written, nil
+}
+
+// EstimateEmbeddingsSize approximates the bytes WriteEmbeddings produces for
+// n vectors of the given dimension (about 11 bytes per JSON number).
+func EstimateEmbeddingsSize(n, dimension int) int64 {
+	return int64(n) * int64(dimension) * 11
+}
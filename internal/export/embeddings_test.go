@@ -0,0 +1,75 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/memvra/memvra/internal/db"
+	"github.com/memvra/memvra/internal/memory"
+)
+
+func TestWriteEmbeddings(t *testing.T) {
+	database, err := db.Open(filepath.Join(t.TempDir(), "embeddings_test.db"))
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	store := memory.NewStore(database)
+	vectors := memory.NewVectorStore(database)
+
+	vec := func(v float32) []float32 {
+		out := make([]float32, 768)
+		out[0] = v
+		return out
+	}
+	decID, _ := store.InsertMemory(memory.Memory{Content: "Use SQLite", MemoryType: memory.TypeDecision})
+	noteID, _ := store.InsertMemory(memory.Memory{Content: "Flaky on CI", MemoryType: memory.TypeNote})
+	fileID, _ := store.UpsertFile(memory.File{Path: "main.go", Language: "go", LastModified: time.Now(), ContentHash: "h"})
+	chunkID, _ := store.InsertChunkReturningID(memory.Chunk{FileID: fileID, Content: "func main() {}", StartLine: 3, EndLine: 5, ChunkType: "code"})
+	vectors.UpsertMemoryEmbedding("m", decID, vec(0.5))
+	vectors.UpsertMemoryEmbedding("m", noteID, vec(0.25))
+	vectors.UpsertChunkEmbedding("m", chunkID, vec(1))
+	vectors.UpsertChunkEmbedding("other", chunkID, vec(2))
+
+	decode := func(out string) []EmbeddingRecord {
+		var records []EmbeddingRecord
+		for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+			var r EmbeddingRecord
+			if err := json.Unmarshal([]byte(line), &r); err != nil {
+				t.Fatalf("invalid JSON line %q: %v", line, err)
+			}
+			records = append(records, r)
+		}
+		return records
+	}
+
+	var buf bytes.Buffer
+	n, err := WriteEmbeddings(&buf, store, vectors, EmbeddingsOptions{Model: "m"})
+	if err != nil {
+		t.Fatalf("WriteEmbeddings: %v", err)
+	}
+	records := decode(buf.String())
+	if n != 3 || len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d (n=%d)", len(records), n)
+	}
+	chunk := records[2]
+	if chunk.Kind != "chunk" || chunk.Path != "main.go" || chunk.StartLine != 3 || chunk.Embedding[0] != 1 {
+		t.Errorf("unexpected chunk record: %+v", chunk)
+	}
+	if len(chunk.Embedding) != 768 {
+		t.Errorf("expected 768 dimensions, got %d", len(chunk.Embedding))
+	}
+
+	buf.Reset()
+	if _, err := WriteEmbeddings(&buf, store, vectors, EmbeddingsOptions{Model: "m", MemoryType: memory.TypeDecision}); err != nil {
+		t.Fatalf("WriteEmbeddings: %v", err)
+	}
+	records = decode(buf.String())
+	if len(records) != 1 || records[0].ID != decID || records[0].Type != "decision" || records[0].Content != "Use SQLite" {
+		t.Errorf("expected only the decision memory, got %+v", records)
+	}
+}
@@ -3,7 +3,11 @@ package export
 
 import (
 	"fmt"
+	"math"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/memvra/memvra/internal/git"
 	"github.com/memvra/memvra/internal/memory"
@@ -17,6 +21,38 @@ type ExportData struct {
 	Memories []memory.Memory
 	Sessions []memory.Session
 	GitState git.WorkingState
+	// Links are the links between memories; only the dot format uses them.
+	Links []memory.MemoryLink
+	// Now, when set, shows memory dates relative to it ("3 days ago") for
+	// interactive output. Zero shows absolute dates, so files meant to be
+	// committed (CLAUDE.md, .cursorrules, ...) don't change from one day to
+	// the next.
+	Now time.Time
+}
+
+// MemoryFilter narrows the memories of a one-off export, independently of
+// the auto-export settings.
+type MemoryFilter struct {
+	Types         []memory.MemoryType // keep only these types (empty = all)
+	MinImportance float64             // keep only memories at least this important (0 = all)
+}
+
+// Apply returns the memories in mems that pass f, in their original order.
+func (f MemoryFilter) Apply(mems []memory.Memory) []memory.Memory {
+	mems = memory.FilterByImportance(mems, f.MinImportance)
+	if len(f.Types) == 0 {
+		return mems
+	}
+	out := make([]memory.Memory, 0, len(mems))
+	for _, m := range mems {
+		for _, t := range f.Types {
+			if m.MemoryType == t {
+				out = append(out, m)
+				break
+			}
+		}
+	}
+	return out
 }
 
 // Exporter renders ExportData to a string in a specific format.
@@ -30,6 +66,7 @@ var registry = map[string]Exporter{
 	"cursor":   &CursorRulesExporter{},
 	"markdown": &MarkdownExporter{},
 	"json":     &JSONExporter{},
+	"dot":      &DOTExporter{},
 }
 
 // Get returns the Exporter registered under name, and whether it was found.
@@ -47,25 +84,120 @@ func ValidFormats() []string {
 	return formats
 }
 
-// memorySection renders memories of the given type as a markdown list block.
-func memorySection(heading string, memType memory.MemoryType, memories []memory.Memory) string {
+// memorySection renders memories of the given type as a markdown list block,
+// most important first and newest first within equal importance. Each item
+// shows its importance as stars and its date, relative to now when now is
+// set (see ExportData.Now); items past collapseAfter are collapsed.
+// Completed todos are left out.
+func memorySection(heading string, memType memory.MemoryType, memories []memory.Memory, now time.Time) string {
 	var items []memory.Memory
 	for _, m := range memories {
-		if m.MemoryType == memType {
+		if m.MemoryType == memType && !m.Done() {
 			items = append(items, m)
 		}
 	}
 	if len(items) == 0 {
 		return ""
 	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Importance != items[j].Importance {
+			return items[i].Importance > items[j].Importance
+		}
+		return items[i].CreatedAt.After(items[j].CreatedAt)
+	})
 	out := fmt.Sprintf("## %s\n\n", heading)
-	for _, m := range items {
-		out += fmt.Sprintf("- %s\n", m.Content)
+	for i, m := range items {
+		if i == collapseAfter {
+			// Keep long sections scannable: the most important items stay
+			// visible and the rest fold into a collapsible block.
+			out += fmt.Sprintf("\n<details>\n<summary>%d more</summary>\n\n", len(items)-collapseAfter)
+		}
+		meta := importanceStars(m.Importance)
+		if date := memoryDate(m.CreatedAt, now); date != "" {
+			meta += " · " + date
+		}
+		out += fmt.Sprintf("- %s · %s%s\n", m.Content, meta, inferredNote(m, "_(", ")_"))
+	}
+	if len(items) > collapseAfter {
+		out += "\n</details>\n"
 	}
 	out += "\n"
 	return out
 }
 
+// customTypes returns the custom (non-built-in) memory types among
+// memories, sorted. Each gets its own section after the built-in ones.
+func customTypes(memories []memory.Memory) []memory.MemoryType {
+	var out []memory.MemoryType
+	for _, m := range memories {
+		if !memory.IsBuiltinType(m.MemoryType) && !slices.Contains(out, m.MemoryType) {
+			out = append(out, m.MemoryType)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// collapseAfter is how many items of a memory section are shown before the
+// remainder is folded into a <details> block.
+const collapseAfter = 10
+
+// importanceStars renders an importance in [0, 1] as a five-star rating.
+func importanceStars(importance float64) string {
+	n := min(max(int(math.Round(importance*5)), 0), 5)
+	return strings.Repeat("★", n) + strings.Repeat("☆", 5-n)
+}
+
+// memoryDate formats t as a date, or relative to now when now is set. It is
+// empty for a zero t.
+func memoryDate(t, now time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	if now.IsZero() {
+		return t.Format("2006-01-02")
+	}
+	return relativeAge(t, now)
+}
+
+// relativeAge describes how long before now t was, at day granularity
+// ("today", "3 days ago", "2 months ago"). It is empty for a zero t.
+func relativeAge(t, now time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	days := int(now.Sub(t).Hours() / 24)
+	switch {
+	case days < 1:
+		return "today"
+	case days == 1:
+		return "yesterday"
+	case days < 14:
+		return fmt.Sprintf("%d days ago", days)
+	case days < 60:
+		return fmt.Sprintf("%d weeks ago", days/7)
+	case days < 365:
+		return fmt.Sprintf("%d months ago", days/30)
+	case days < 730:
+		return "1 year ago"
+	default:
+		return fmt.Sprintf("%d years ago", days/365)
+	}
+}
+
+// inferredNote marks a memory an AI inferred, with its confidence when
+// recorded, wrapped in open/close (e.g. markdown emphasis). It is empty for
+// memories a person stated.
+func inferredNote(m memory.Memory, open, close string) string {
+	if m.Source != memory.SourceExtracted {
+		return ""
+	}
+	if m.Confidence > 0 {
+		return fmt.Sprintf(" %sAI-inferred, confidence %.2f%s", open, m.Confidence, close)
+	}
+	return " " + open + "AI-inferred" + close
+}
+
 // renderGitStateMarkdown renders the git working state as a markdown section.
 func renderGitStateMarkdown(gs git.WorkingState) string {
 	if gs.IsEmpty() || !gs.HasChanges() {
@@ -2,6 +2,7 @@ package export
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -58,7 +59,7 @@ func sampleExportData() ExportData {
 }
 
 func TestGet_ValidFormats(t *testing.T) {
-	for _, name := range []string{"claude", "cursor", "markdown", "json"} {
+	for _, name := range []string{"claude", "cursor", "markdown", "json", "dot"} {
 		exp, ok := Get(name)
 		if !ok {
 			t.Errorf("Get(%q) returned false", name)
@@ -129,6 +130,30 @@ func TestClaudeMDExporter(t *testing.T) {
 	}
 }
 
+func TestMemoryFilter_AllFormats(t *testing.T) {
+	data := sampleExportData()
+	data.Memories = MemoryFilter{Types: []memory.MemoryType{memory.TypeDecision, memory.TypeConstraint}}.Apply(data.Memories)
+
+	for _, name := range ValidFormats() {
+		exp, _ := Get(name)
+		result, err := exp.Export(data)
+		if err != nil {
+			t.Fatalf("%s: Export error: %v", name, err)
+		}
+		if strings.Contains(result, "Interesting observation") || strings.Contains(result, "Use camelCase") {
+			t.Errorf("%s export should exclude notes and conventions", name)
+		}
+		if !strings.Contains(result, "Use PostgreSQL") || !strings.Contains(result, "Never store secrets in code") {
+			t.Errorf("%s export should keep decisions and constraints", name)
+		}
+	}
+
+	kept := MemoryFilter{MinImportance: 0.85}.Apply(sampleExportData().Memories)
+	if len(kept) != 1 || kept[0].MemoryType != memory.TypeConstraint {
+		t.Errorf("MinImportance 0.85 kept %+v, want only the constraint", kept)
+	}
+}
+
 func TestCursorRulesExporter(t *testing.T) {
 	data := sampleExportData()
 	exp, _ := Get("cursor")
@@ -201,6 +226,9 @@ func TestJSONExporter(t *testing.T) {
 	}
 
 	// Check structure.
+	if parsed["schema_version"] != float64(JSONSchemaVersion) {
+		t.Errorf("schema_version: got %v, want %d", parsed["schema_version"], JSONSchemaVersion)
+	}
 	if parsed["project"] == nil {
 		t.Error("missing 'project' key")
 	}
@@ -241,6 +269,120 @@ func TestJSONExporter(t *testing.T) {
 	}
 }
 
+func TestJSONExporter_CompletedTodo(t *testing.T) {
+	done := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	data := ExportData{
+		Project: memory.Project{Name: "app"},
+		Memories: []memory.Memory{
+			{ID: "1", Content: "Fix auth flow", MemoryType: memory.TypeTodo, Source: memory.SourceUser},
+			{ID: "2", Content: "Add rate limiting", MemoryType: memory.TypeTodo, Source: memory.SourceUser, CompletedAt: &done},
+		},
+	}
+	exp, _ := Get("json")
+	result, err := exp.Export(data)
+	if err != nil {
+		t.Fatalf("Export error: %v", err)
+	}
+
+	var parsed struct {
+		Memories map[string][]map[string]any `json:"memories"`
+	}
+	if err := json.Unmarshal([]byte(result), &parsed); err != nil {
+		t.Fatalf("JSON export is invalid JSON: %v", err)
+	}
+	todos := parsed.Memories["todo"]
+	if len(todos) != 2 {
+		t.Fatalf("expected 2 todos, got %d", len(todos))
+	}
+	if _, ok := todos[0]["completed_at"]; ok {
+		t.Errorf("open todo should have no completed_at: %v", todos[0])
+	}
+	if todos[1]["completed_at"] != "2026-03-01T09:00:00Z" {
+		t.Errorf("completed_at: got %v, want 2026-03-01T09:00:00Z", todos[1]["completed_at"])
+	}
+}
+
+func TestExporters_NamedStacks(t *testing.T) {
+	data := sampleExportData()
+	data.Stack = scanner.TechStack{Stacks: []scanner.NamedStack{
+		{Name: "backend", TechStack: scanner.TechStack{Language: "Go", Database: "PostgreSQL"}},
+		{Name: "frontend", TechStack: scanner.TechStack{Language: "TypeScript", Framework: "React"}},
+	}}
+
+	for _, name := range []string{"claude", "cursor", "markdown", "json"} {
+		exp, _ := Get(name)
+		result, err := exp.Export(data)
+		if err != nil {
+			t.Fatalf("%s: Export error: %v", name, err)
+		}
+		for _, check := range []string{"backend", "frontend", "Go", "TypeScript", "React"} {
+			if !strings.Contains(result, check) {
+				t.Errorf("%s export missing %q", name, check)
+			}
+		}
+	}
+}
+
+func TestExporters_CustomTypes(t *testing.T) {
+	data := sampleExportData()
+	data.Memories = append(data.Memories,
+		memory.Memory{ID: "6", Content: "Vendor API may be sunset", MemoryType: "risk", Importance: 0.5, Source: "user"},
+		memory.Memory{ID: "7", Content: "SKU: stock keeping unit", MemoryType: "glossary", Importance: 0.5, Source: "user"},
+	)
+
+	for format, want := range map[string][]string{
+		"claude":   {"## Glossaries\n\n- SKU: stock keeping unit", "## Risks\n\n- Vendor API may be sunset"},
+		"markdown": {"## Glossaries\n\n- SKU: stock keeping unit", "## Risks\n\n- Vendor API may be sunset"},
+		"cursor":   {"Glossaries", "- SKU: stock keeping unit", "Risks", "- Vendor API may be sunset"},
+		"json":     {`"risk": [`, `"glossary": [`},
+	} {
+		exp, _ := Get(format)
+		out, err := exp.Export(data)
+		if err != nil {
+			t.Fatalf("%s: Export error: %v", format, err)
+		}
+		for _, w := range want {
+			if !strings.Contains(out, w) {
+				t.Errorf("%s: expected %q in:\n%s", format, w, out)
+			}
+		}
+	}
+}
+
+func TestDOTExporter(t *testing.T) {
+	data := sampleExportData()
+	data.Memories = append(data.Memories, memory.Memory{ID: "6", Content: `Use "MySQL"`, MemoryType: memory.TypeDecision})
+	data.Links = []memory.MemoryLink{
+		{FromID: "1", ToID: "6", Type: memory.LinkSupersedes},
+		{FromID: "4", ToID: "1", Type: memory.LinkRelatesTo},
+		{FromID: "1", ToID: "gone", Type: memory.LinkRelatesTo},
+	}
+	exp, _ := Get("dot")
+	result, err := exp.Export(data)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	for _, want := range []string{
+		`digraph "testapp" {`,
+		`"1" [label="decision\nUse PostgreSQL", fillcolor="#9ecae1"];`,
+		`"3" [label="constraint\nNever store secrets in code", fillcolor="#fc9272"];`,
+		`"6" [label="decision\nUse \"MySQL\"", fillcolor="#9ecae1"];`,
+		`"1" -> "6" [label="supersedes", style=solid];`,
+		`"4" -> "1" [label="relates to", style=dashed];`,
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("DOT output missing %s:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "gone") {
+		t.Errorf("link to a memory outside the export should be dropped:\n%s", result)
+	}
+	if !strings.HasSuffix(result, "}\n") {
+		t.Errorf("graph not closed:\n%s", result)
+	}
+}
+
 func TestJSONExporter_EmptyMemories(t *testing.T) {
 	data := ExportData{
 		Project: memory.Project{Name: "empty"},
@@ -260,6 +402,30 @@ func TestJSONExporter_EmptyMemories(t *testing.T) {
 	}
 }
 
+func TestExporters_MarkInferredMemories(t *testing.T) {
+	data := ExportData{
+		Project: memory.Project{Name: "app"},
+		Memories: []memory.Memory{
+			{ID: "1", Content: "Use PostgreSQL", MemoryType: memory.TypeDecision, Source: memory.SourceUser},
+			{ID: "2", Content: "Queue is Redis", MemoryType: memory.TypeDecision, Source: memory.SourceExtracted, Confidence: 0.6, SourceSessionID: "s1"},
+		},
+	}
+	for format, want := range map[string]string{
+		"markdown": "- Queue is Redis · ☆☆☆☆☆ _(AI-inferred, confidence 0.60)_\n",
+		"cursor":   "- Queue is Redis (AI-inferred, confidence 0.60)\n",
+		"json":     `"source_session_id": "s1"`,
+	} {
+		exp, _ := Get(format)
+		out, _ := exp.Export(data)
+		if !strings.Contains(out, want) {
+			t.Errorf("%s: expected %q in:\n%s", format, want, out)
+		}
+		if strings.Contains(out, "Use PostgreSQL _(") || strings.Contains(out, "Use PostgreSQL (") {
+			t.Errorf("%s: user memory should not be marked:\n%s", format, out)
+		}
+	}
+}
+
 func TestExport_NoSessionsNoGit(t *testing.T) {
 	data := ExportData{
 		Project: memory.Project{Name: "empty"},
@@ -290,7 +456,7 @@ func TestMemorySection(t *testing.T) {
 		{Content: "A note", MemoryType: memory.TypeNote},
 	}
 
-	result := memorySection("Decisions", memory.TypeDecision, memories)
+	result := memorySection("Decisions", memory.TypeDecision, memories, time.Now())
 	if !strings.Contains(result, "## Decisions") {
 		t.Error("missing heading")
 	}
@@ -305,9 +471,93 @@ func TestMemorySection(t *testing.T) {
 	}
 }
 
+func TestMemorySection_AbsoluteDates(t *testing.T) {
+	created := time.Date(2026, 3, 7, 9, 30, 0, 0, time.UTC)
+	data := ExportData{
+		Project:  memory.Project{Name: "app"},
+		Memories: []memory.Memory{{Content: "Use PostgreSQL", MemoryType: memory.TypeDecision, Importance: 0.9, CreatedAt: created}},
+	}
+	for _, format := range []string{"claude", "markdown"} {
+		exp, _ := Get(format)
+		out, _ := exp.Export(data)
+		if !strings.Contains(out, "- Use PostgreSQL · ★★★★★ · 2026-03-07\n") {
+			t.Errorf("%s: expected an absolute date without Now, got:\n%s", format, out)
+		}
+	}
+}
+
 func TestMemorySection_Empty(t *testing.T) {
-	result := memorySection("Decisions", memory.TypeDecision, nil)
+	result := memorySection("Decisions", memory.TypeDecision, nil, time.Now())
 	if result != "" {
 		t.Errorf("expected empty string for no memories, got %q", result)
 	}
 }
+
+func TestMemorySection_ImportanceAndAge(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	data := ExportData{
+		Project: memory.Project{Name: "app"},
+		Now:     now,
+		Memories: []memory.Memory{
+			{Content: "Minor naming idea", MemoryType: memory.TypeNote, Importance: 0.2, CreatedAt: now.Add(-2 * time.Hour)},
+			{Content: "Old decision", MemoryType: memory.TypeDecision, Importance: 0.6, CreatedAt: now.AddDate(0, 0, -40)},
+			{Content: "Use PostgreSQL", MemoryType: memory.TypeDecision, Importance: 0.9, CreatedAt: now.AddDate(0, 0, -3)},
+			{Content: "Newer decision", MemoryType: memory.TypeDecision, Importance: 0.6, CreatedAt: now.AddDate(0, 0, -1)},
+		},
+	}
+
+	for _, format := range []string{"claude", "markdown"} {
+		exp, _ := Get(format)
+		out, _ := exp.Export(data)
+		for _, want := range []string{
+			"- Use PostgreSQL · ★★★★★ · 3 days ago\n",
+			"- Newer decision · ★★★☆☆ · yesterday\n",
+			"- Old decision · ★★★☆☆ · 5 weeks ago\n",
+			"- Minor naming idea · ★☆☆☆☆ · today\n",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s: expected %q in:\n%s", format, want, out)
+			}
+		}
+		order := []int{
+			strings.Index(out, "Use PostgreSQL"),
+			strings.Index(out, "Newer decision"),
+			strings.Index(out, "Old decision"),
+			strings.Index(out, "Minor naming idea"),
+		}
+		for i := 1; i < len(order); i++ {
+			if order[i-1] > order[i] {
+				t.Errorf("%s: expected importance then recency order, got positions %v", format, order)
+				break
+			}
+		}
+	}
+}
+
+func TestMemorySection_CollapsesLongSections(t *testing.T) {
+	var memories []memory.Memory
+	for i := 0; i < collapseAfter+3; i++ {
+		memories = append(memories, memory.Memory{
+			Content:    fmt.Sprintf("note %02d", i),
+			MemoryType: memory.TypeNote,
+			Importance: 1 - float64(i)/100,
+		})
+	}
+
+	out := memorySection("Notes", memory.TypeNote, memories, time.Now())
+	open := strings.Index(out, "<details>\n<summary>3 more</summary>\n\n")
+	if open < 0 || !strings.HasSuffix(out, "\n</details>\n\n") {
+		t.Fatalf("expected a collapsed block for the last 3 items:\n%s", out)
+	}
+	if strings.Index(out, fmt.Sprintf("note %02d", collapseAfter-1)) > open {
+		t.Error("the most important items should stay outside the collapsed block")
+	}
+	if strings.Index(out, fmt.Sprintf("note %02d", collapseAfter)) < open {
+		t.Error("items past collapseAfter should be collapsed")
+	}
+
+	short := memorySection("Notes", memory.TypeNote, memories[:collapseAfter], time.Now())
+	if strings.Contains(short, "<details>") {
+		t.Errorf("sections within collapseAfter should not collapse:\n%s", short)
+	}
+}
@@ -5,17 +5,28 @@ import (
 	"time"
 
 	"github.com/memvra/memvra/internal/memory"
+	"github.com/memvra/memvra/internal/scanner"
 )
 
+// JSONSchemaVersion is written as schema_version in JSON exports. Bump it
+// whenever a field is added, removed, or changes meaning, and document the
+// change in the README so consumers can branch on the version.
+//
+//	1: project, stack, stacks, memories, work_in_progress, recent_activity
+//	2: memories carry completed_at once a todo is done
+const JSONSchemaVersion = 2
+
 // JSONExporter renders ExportData as structured JSON.
 type JSONExporter struct{}
 
 type jsonOutput struct {
-	GitState   *jsonGitState            `json:"work_in_progress,omitempty"`
-	Sessions   []jsonSession            `json:"recent_activity,omitempty"`
-	Project    jsonProject              `json:"project"`
-	Stack      jsonStack                `json:"stack"`
-	Memories   map[string][]jsonMemory  `json:"memories"`
+	SchemaVersion int                     `json:"schema_version"`
+	GitState      *jsonGitState           `json:"work_in_progress,omitempty"`
+	Sessions      []jsonSession           `json:"recent_activity,omitempty"`
+	Project       jsonProject             `json:"project"`
+	Stack         jsonStack               `json:"stack"`
+	Stacks        map[string]jsonStack    `json:"stacks,omitempty"`
+	Memories      map[string][]jsonMemory `json:"memories"`
 }
 
 type jsonGitState struct {
@@ -40,20 +51,23 @@ type jsonProject struct {
 }
 
 type jsonStack struct {
-	Language     string   `json:"language,omitempty"`
-	Framework    string   `json:"framework,omitempty"`
-	Database     string   `json:"database,omitempty"`
-	Architecture string   `json:"architecture,omitempty"`
-	TestFramework string  `json:"test_framework,omitempty"`
-	CI           string   `json:"ci,omitempty"`
-	Patterns     []string `json:"patterns,omitempty"`
+	Language      string   `json:"language,omitempty"`
+	Framework     string   `json:"framework,omitempty"`
+	Database      string   `json:"database,omitempty"`
+	Architecture  string   `json:"architecture,omitempty"`
+	TestFramework string   `json:"test_framework,omitempty"`
+	CI            string   `json:"ci,omitempty"`
+	Patterns      []string `json:"patterns,omitempty"`
 }
 
 type jsonMemory struct {
-	ID         string  `json:"id"`
-	Content    string  `json:"content"`
-	Importance float64 `json:"importance"`
-	Source     string  `json:"source"`
+	ID              string  `json:"id"`
+	Content         string  `json:"content"`
+	Importance      float64 `json:"importance"`
+	Source          string  `json:"source"`
+	SourceSessionID string  `json:"source_session_id,omitempty"`
+	Confidence      float64 `json:"confidence,omitempty"`
+	CompletedAt     string  `json:"completed_at,omitempty"`
 }
 
 func (e *JSONExporter) Export(data ExportData) (string, error) {
@@ -61,23 +75,23 @@ func (e *JSONExporter) Export(data ExportData) (string, error) {
 	proj := data.Project
 
 	out := jsonOutput{
+		SchemaVersion: JSONSchemaVersion,
 		Project: jsonProject{
 			Name:       proj.Name,
 			FileCount:  proj.FileCount,
 			ChunkCount: proj.ChunkCount,
 		},
-		Stack: jsonStack{
-			Language:      ts.Language,
-			Framework:     ts.Framework,
-			Database:      ts.Database,
-			Architecture:  ts.Architecture,
-			TestFramework: ts.TestFramework,
-			CI:            ts.CI,
-			Patterns:      ts.DetectedPatterns,
-		},
+		Stack:    toJSONStack(ts),
 		Memories: groupMemoriesByType(data.Memories),
 	}
 
+	if len(ts.Stacks) > 0 {
+		out.Stacks = make(map[string]jsonStack, len(ts.Stacks))
+		for _, ns := range ts.Stacks {
+			out.Stacks[ns.Name] = toJSONStack(ns.TechStack)
+		}
+	}
+
 	if !data.GitState.IsEmpty() && data.GitState.HasChanges() {
 		out.GitState = &jsonGitState{
 			Branch:    data.GitState.Branch,
@@ -107,16 +121,34 @@ func (e *JSONExporter) Export(data ExportData) (string, error) {
 	return string(b) + "\n", nil
 }
 
+func toJSONStack(ts scanner.TechStack) jsonStack {
+	return jsonStack{
+		Language:      ts.Language,
+		Framework:     ts.Framework,
+		Database:      ts.Database,
+		Architecture:  ts.Architecture,
+		TestFramework: ts.TestFramework,
+		CI:            ts.CI,
+		Patterns:      ts.DetectedPatterns,
+	}
+}
+
 func groupMemoriesByType(memories []memory.Memory) map[string][]jsonMemory {
 	groups := make(map[string][]jsonMemory)
 	for _, m := range memories {
 		key := string(m.MemoryType)
-		groups[key] = append(groups[key], jsonMemory{
-			ID:         m.ID,
-			Content:    m.Content,
-			Importance: m.Importance,
-			Source:     m.Source,
-		})
+		jm := jsonMemory{
+			ID:              m.ID,
+			Content:         m.Content,
+			Importance:      m.Importance,
+			Source:          m.Source,
+			SourceSessionID: m.SourceSessionID,
+			Confidence:      m.Confidence,
+		}
+		if m.Done() {
+			jm.CompletedAt = m.CompletedAt.Format(time.RFC3339)
+		}
+		groups[key] = append(groups[key], jm)
 	}
 	// Return nil map as empty object in JSON.
 	if len(groups) == 0 {
@@ -124,4 +156,3 @@ func groupMemoriesByType(memories []memory.Memory) map[string][]jsonMemory {
 	}
 	return groups
 }
-
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/memvra/memvra/internal/memory"
+	"github.com/memvra/memvra/internal/scanner"
 )
 
 // MarkdownExporter renders context as generic markdown.
@@ -21,22 +22,13 @@ func (e *MarkdownExporter) Export(data ExportData) (string, error) {
 	b.WriteString(renderSessionsMarkdown(data.Sessions))
 
 	fmt.Fprintf(&b, "## Tech Stack\n\n")
-	if ts.Language != "" {
-		fmt.Fprintf(&b, "| Language | %s |\n", ts.Language)
-	}
-	if ts.Framework != "" {
-		fmt.Fprintf(&b, "| Framework | %s |\n", ts.Framework)
+	for _, ns := range ts.Components() {
+		if ns.Name != "" {
+			fmt.Fprintf(&b, "### %s\n\n", ns.Name)
+		}
+		writeMarkdownStack(&b, ns.TechStack)
+		b.WriteString("\n")
 	}
-	if ts.Database != "" {
-		fmt.Fprintf(&b, "| Database | %s |\n", ts.Database)
-	}
-	if ts.Architecture != "" {
-		fmt.Fprintf(&b, "| Architecture | %s |\n", ts.Architecture)
-	}
-	if ts.TestFramework != "" {
-		fmt.Fprintf(&b, "| Tests | %s |\n", ts.TestFramework)
-	}
-	b.WriteString("\n")
 
 	for _, section := range []struct {
 		heading string
@@ -48,8 +40,30 @@ func (e *MarkdownExporter) Export(data ExportData) (string, error) {
 		{"Notes", memory.TypeNote},
 		{"TODOs", memory.TypeTodo},
 	} {
-		b.WriteString(memorySection(section.heading, section.mt, data.Memories))
+		b.WriteString(memorySection(section.heading, section.mt, data.Memories, data.Now))
+	}
+	for _, t := range customTypes(data.Memories) {
+		b.WriteString(memorySection(t.Label(), t, data.Memories, data.Now))
 	}
 
 	return b.String(), nil
 }
+
+// writeMarkdownStack renders a single stack's fields as table rows.
+func writeMarkdownStack(b *strings.Builder, ts scanner.TechStack) {
+	if ts.Language != "" {
+		fmt.Fprintf(b, "| Language | %s |\n", ts.Language)
+	}
+	if ts.Framework != "" {
+		fmt.Fprintf(b, "| Framework | %s |\n", ts.Framework)
+	}
+	if ts.Database != "" {
+		fmt.Fprintf(b, "| Database | %s |\n", ts.Database)
+	}
+	if ts.Architecture != "" {
+		fmt.Fprintf(b, "| Architecture | %s |\n", ts.Architecture)
+	}
+	if ts.TestFramework != "" {
+		fmt.Fprintf(b, "| Tests | %s |\n", ts.TestFramework)
+	}
+}
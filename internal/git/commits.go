@@ -0,0 +1,46 @@
+package git
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Commit is one entry of the repository history.
+type Commit struct {
+	Hash    string // abbreviated hash
+	Author  string
+	Date    time.Time // committer date
+	Subject string
+}
+
+// RecentCommits returns up to n commits reachable from HEAD, newest first,
+// limited to those committed at or after since when it is non-zero. Like
+// CaptureWorkingState it swallows errors: outside a repository, or in one
+// without commits, it returns nil.
+func RecentCommits(dir string, n int, since time.Time) []Commit {
+	if n <= 0 {
+		return nil
+	}
+	// Fields are separated by the unit separator, which cannot appear in
+	// a subject line.
+	args := []string{"log", "-n", strconv.Itoa(n), "--format=%h%x1f%an%x1f%cI%x1f%s"}
+	if !since.IsZero() {
+		args = append(args, "--since="+since.Format(time.RFC3339))
+	}
+	out := gitOutput(dir, args...)
+	if out == "" {
+		return nil
+	}
+
+	var commits []Commit
+	for _, line := range strings.Split(out, "\n") {
+		f := strings.Split(line, "\x1f")
+		if len(f) != 4 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, f[2])
+		commits = append(commits, Commit{Hash: f[0], Author: f[1], Date: date, Subject: f[3]})
+	}
+	return commits
+}
@@ -0,0 +1,40 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecentCommits(t *testing.T) {
+	dir := initTestRepo(t)
+	os.WriteFile(filepath.Join(dir, "a.go"), []byte("package main"), 0o644)
+	gitCmd(t, dir, "add", "a.go")
+	gitCmd(t, dir, "commit", "-m", "add a | with a pipe")
+
+	commits := RecentCommits(dir, 10, time.Time{})
+	if len(commits) != 2 {
+		t.Fatalf("expected 2 commits, got %d: %+v", len(commits), commits)
+	}
+	c := commits[0]
+	if c.Subject != "add a | with a pipe" || c.Author != "Test" || c.Hash == "" || c.Date.IsZero() {
+		t.Errorf("newest commit parsed wrong: %+v", c)
+	}
+	if commits[1].Subject != "initial" {
+		t.Errorf("expected initial commit last, got %q", commits[1].Subject)
+	}
+
+	if got := RecentCommits(dir, 1, time.Time{}); len(got) != 1 {
+		t.Errorf("n=1: expected 1 commit, got %d", len(got))
+	}
+	if got := RecentCommits(dir, 10, time.Now().Add(time.Hour)); len(got) != 0 {
+		t.Errorf("future since: expected no commits, got %+v", got)
+	}
+}
+
+func TestRecentCommits_NonGitDir(t *testing.T) {
+	if got := RecentCommits(t.TempDir(), 5, time.Time{}); got != nil {
+		t.Errorf("expected nil outside a repo, got %+v", got)
+	}
+}
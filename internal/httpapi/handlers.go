@@ -0,0 +1,288 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/memvra/memvra/internal/config"
+	ctxpkg "github.com/memvra/memvra/internal/context"
+	"github.com/memvra/memvra/internal/memory"
+)
+
+// Defaults and bounds for query parameters.
+const (
+	defaultSearchTopK = 10
+	defaultSessions   = 10
+	maxSessions       = 200
+	minContextTokens  = 500
+	maxContextTokens  = 1000000
+)
+
+// auditClient is the model name access log entries from the API are
+// recorded under.
+const auditClient = "http"
+
+// chunkResult is a code chunk in a search response.
+type chunkResult struct {
+	ID        string `json:"id"`
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Content   string `json:"content"`
+}
+
+// searchResponse is the body of GET /api/search.
+type searchResponse struct {
+	Chunks   []chunkResult   `json:"chunks"`
+	Memories []memory.Memory `json:"memories"`
+	// Mode is how results were found: "semantic", or "keyword" or
+	// "fallback_list" when vector search was unavailable and memories are
+	// matched by keyword or listed unranked.
+	Mode string `json:"mode"`
+	// Warning explains a "keyword" or "fallback_list" result.
+	Warning string `json:"warning,omitempty"`
+}
+
+// handleSearch serves GET /api/search?q=...&top_k=&only=&language=&exclude=&source=.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	query, exclude := memory.SplitExclusions(params.Get("q"))
+	if query == "" {
+		writeError(w, http.StatusBadRequest, "missing required parameter: q")
+		return
+	}
+	exclude = append(exclude, params["exclude"]...)
+
+	gcfg, _ := config.Load(s.root)
+	pcfg, _ := config.LoadProject(s.root)
+	topKChunks, topKMemories := pcfg.SearchTopK(defaultSearchTopK)
+	if params.Has("top_k") {
+		n, err := strconv.Atoi(params.Get("top_k"))
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "top_k must be a positive integer")
+			return
+		}
+		topKChunks, topKMemories = n, n
+	}
+
+	opts := memory.RetrieveOptions{
+		TopKChunks:          topKChunks,
+		TopKMemories:        topKMemories,
+		SimilarityThreshold: gcfg.Context.SimilarityThreshold,
+		ChunkThreshold:      gcfg.Context.ChunkThreshold,
+		MemoryThreshold:     gcfg.Context.MemoryThreshold,
+		Sources:             params["source"],
+		Exclude:             exclude,
+		Language:            params.Get("language"),
+	}
+	if err := opts.LimitToCategory(params.Get("only")); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("only: %v", err))
+		return
+	}
+
+	result, err := s.orchestrator.Retrieve(r.Context(), query, opts)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("search failed: %v", err))
+		return
+	}
+
+	resp := searchResponse{Chunks: []chunkResult{}, Memories: result.Memories, Mode: result.Mode, Warning: result.FallbackWarning()}
+	if resp.Memories == nil {
+		resp.Memories = []memory.Memory{}
+	}
+	fileIDs := make([]string, len(result.Chunks))
+	for i, c := range result.Chunks {
+		fileIDs[i] = c.FileID
+	}
+	files, _ := s.store.GetFilesByIDs(fileIDs)
+	for _, c := range result.Chunks {
+		resp.Chunks = append(resp.Chunks, chunkResult{
+			ID:        c.ID,
+			Path:      files[c.FileID].Path,
+			StartLine: c.StartLine,
+			EndLine:   c.EndLine,
+			Content:   c.Content,
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleMemories serves GET /api/memories?type=.
+func (s *Server) handleMemories(w http.ResponseWriter, r *http.Request) {
+	memType := memory.MemoryType(r.URL.Query().Get("type"))
+	if memType != "" && !memory.ValidMemoryType(memType) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown memory type %q", memType))
+		return
+	}
+	memories, err := s.store.ListMemories(memType)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("failed to list memories: %v", err))
+		return
+	}
+	if memories == nil {
+		memories = []memory.Memory{}
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Memories []memory.Memory `json:"memories"`
+	}{memories})
+}
+
+// handleSessions serves GET /api/sessions?limit=&tag=, newest first.
+func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	limit := defaultSessions
+	if params.Has("limit") {
+		n, err := strconv.Atoi(params.Get("limit"))
+		if err != nil || n < 1 || n > maxSessions {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxSessions))
+			return
+		}
+		limit = n
+	}
+
+	var sessions []memory.Session
+	var err error
+	if tag := params.Get("tag"); tag != "" {
+		sessions, err = s.store.GetLastNSessionsTagged(limit, tag)
+	} else {
+		sessions, err = s.store.GetLastNSessions(limit)
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("failed to list sessions: %v", err))
+		return
+	}
+	if sessions == nil {
+		sessions = []memory.Session{}
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Sessions []memory.Session `json:"sessions"`
+	}{sessions})
+}
+
+// contextResponse is the body of GET /api/context.
+type contextResponse struct {
+	SystemPrompt string          `json:"system_prompt"`
+	Context      string          `json:"context"`
+	TokensUsed   int             `json:"tokens_used"`
+	Sources      []ctxpkg.Source `json:"sources"`
+	Warnings     []string        `json:"warnings,omitempty"`
+}
+
+// handleContext serves GET /api/context?q=&max_tokens=&session_tag=, the
+// same context memvra_get_context returns.
+func (s *Server) handleContext(w http.ResponseWriter, r *http.Request) {
+	opts, err := s.contextOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	built, err := s.buildContext(r, opts)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, built)
+}
+
+// handleContextStream serves GET /api/context/stream, taking the same
+// parameters as /api/context, as server-sent events: a "section" event
+// ({"name", "text"}) for each part of the context as it is ready, then a
+// "done" event carrying the full /api/context response, or an "error" event.
+// Clients should render sections progressively and replace them with the
+// "done" context, which is always complete and coherent.
+func (s *Server) handleContextStream(w http.ResponseWriter, r *http.Request) {
+	opts, err := s.contextOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	send := func(event string, v any) {
+		data, _ := json.Marshal(v)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+		flusher.Flush()
+	}
+
+	opts.OnSection = func(name, text string) {
+		send("section", sectionEvent{Name: name, Text: text})
+	}
+	built, err := s.buildContext(r, opts)
+	if err != nil {
+		send("error", errorResponse{Error: err.Error()})
+		return
+	}
+	send("done", built)
+}
+
+// sectionEvent is the payload of a "section" event from /api/context/stream.
+type sectionEvent struct {
+	Name string `json:"name"`
+	Text string `json:"text"`
+}
+
+// contextOptions reads /api/context parameters into build options.
+func (s *Server) contextOptions(r *http.Request) (ctxpkg.BuildOptions, error) {
+	params := r.URL.Query()
+	gcfg, _ := config.Load(s.root)
+	pcfg, _ := config.LoadProject(s.root)
+
+	opts := ctxpkg.ConfiguredOptions(s.root, gcfg, pcfg)
+	opts.Question = params.Get("q")
+	opts.SessionTag = params.Get("session_tag")
+	if params.Has("max_tokens") {
+		n, err := strconv.Atoi(params.Get("max_tokens"))
+		if err != nil || n < minContextTokens || n > maxContextTokens {
+			return ctxpkg.BuildOptions{}, fmt.Errorf("max_tokens must be between %d and %d", minContextTokens, maxContextTokens)
+		}
+		opts.MaxTokens = n
+	}
+	return opts, nil
+}
+
+// buildContext builds the context for opts as an /api/context response.
+func (s *Server) buildContext(r *http.Request, opts ctxpkg.BuildOptions) (contextResponse, error) {
+	tokenizer := s.tokenizer(opts.Model)
+	builder := ctxpkg.NewBuilder(s.store, s.orchestrator, ctxpkg.NewFormatter(), tokenizer)
+	if path, err := config.GlobalDBPath(); err == nil {
+		if global, database, err := memory.OpenGlobalStore(path, false); err == nil && global != nil {
+			defer func() { _ = database.Close() }()
+			builder.SetGlobalStore(global)
+		}
+	}
+	built, err := builder.Build(r.Context(), opts)
+	if err != nil {
+		return contextResponse{}, fmt.Errorf("failed to build context: %w", err)
+	}
+
+	resp := contextResponse{
+		SystemPrompt: built.SystemPrompt,
+		Context:      built.ContextText,
+		TokensUsed:   built.TokensUsed,
+		Sources:      built.SourceRefs,
+		Warnings:     built.Warnings,
+	}
+	if resp.Sources == nil {
+		resp.Sources = []ctxpkg.Source{}
+	}
+	if s.opts.AuditStore != nil {
+		gcfg, _ := config.Load(s.root)
+		if err := ctxpkg.RecordAccess(s.opts.AuditStore, gcfg, built, auditClient, ""); err != nil {
+			resp.Warnings = append(resp.Warnings, fmt.Sprintf("audit log: %v", err))
+		}
+	}
+	if err := tokenizer.FallbackReason(); err != nil {
+		resp.Warnings = append(resp.Warnings, fmt.Sprintf("token counts are approximate: %v", err))
+	}
+	return resp, nil
+}
@@ -0,0 +1,153 @@
+// Package httpapi serves a project's memory as plain JSON over HTTP, for
+// custom frontends such as an internal web UI. Endpoints are read-only:
+// search, memory and session listings, and assembled context. It is separate
+// from the MCP server, which speaks the Model Context Protocol over stdio.
+package httpapi
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+
+	ctxpkg "github.com/memvra/memvra/internal/context"
+	"github.com/memvra/memvra/internal/memory"
+)
+
+// Options configures a Server.
+type Options struct {
+	// Token, when set, must be sent as "Authorization: Bearer <token>" on
+	// every request. Empty leaves the API open.
+	Token string
+	// CORSOrigins lists browser origins allowed to call the API; "*" allows
+	// any. Empty sends no CORS headers, so only same-origin pages can call it.
+	CORSOrigins []string
+	// AuditStore receives the access log entries for each context the API
+	// builds, when [audit] is enabled. The API's own store may be
+	// read-only, so this is usually a separate writable store over the same
+	// database. Nil records nothing.
+	AuditStore *memory.Store
+}
+
+// Server handles the JSON API for the project at root.
+type Server struct {
+	root         string
+	store        *memory.Store
+	orchestrator *memory.Orchestrator
+	opts         Options
+	// tokenizers caches a Tokenizer per encoding; see tokenizer.
+	tokenizers map[string]*ctxpkg.Tokenizer
+
+	// mu serialises requests: the orchestrator and its embedder are built
+	// for sequential use, and the database has a single connection anyway.
+	mu sync.Mutex
+}
+
+// NewServer returns a Server answering from store, retrieving through
+// orchestrator. Project and global config are re-read on each request, as
+// the MCP server does, so edits apply without a restart.
+func NewServer(root string, store *memory.Store, orchestrator *memory.Orchestrator, opts Options) *Server {
+	return &Server{
+		root:         root,
+		store:        store,
+		orchestrator: orchestrator,
+		opts:         opts,
+		tokenizers:   make(map[string]*ctxpkg.Tokenizer),
+	}
+}
+
+// tokenizer returns the Tokenizer for model's encoding, loading it on first
+// use. Callers hold s.mu.
+func (s *Server) tokenizer(model string) *ctxpkg.Tokenizer {
+	encoding := ctxpkg.EncodingForModel(model)
+	t, ok := s.tokenizers[encoding]
+	if !ok {
+		t = ctxpkg.NewTokenizerForModel(model)
+		s.tokenizers[encoding] = t
+	}
+	return t
+}
+
+// Handler returns the API's routes wrapped in CORS and token checks.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/search", s.serialised(s.handleSearch))
+	mux.HandleFunc("GET /api/memories", s.serialised(s.handleMemories))
+	mux.HandleFunc("GET /api/sessions", s.serialised(s.handleSessions))
+	mux.HandleFunc("GET /api/context", s.serialised(s.handleContext))
+	mux.HandleFunc("GET /api/context/stream", s.serialised(s.handleContextStream))
+	return s.cors(s.authorize(mux))
+}
+
+func (s *Server) serialised(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		h(w, r)
+	}
+}
+
+// authorize rejects requests without the configured bearer token.
+func (s *Server) authorize(next http.Handler) http.Handler {
+	if s.opts.Token == "" {
+		return next
+	}
+	want := []byte("Bearer " + s.opts.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(got, want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="memvra"`)
+			writeError(w, http.StatusUnauthorized, "missing or invalid token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// cors adds CORS headers for allowed origins and answers preflight requests,
+// which browsers send without credentials, before authorization.
+func (s *Server) cors(next http.Handler) http.Handler {
+	if len(s.opts.CORSOrigins) == 0 {
+		return next
+	}
+	anyOrigin := slices.Contains(s.opts.CORSOrigins, "*")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		allowed := origin != "" && (anyOrigin || slices.Contains(s.opts.CORSOrigins, origin))
+		if allowed {
+			h := w.Header()
+			h.Set("Access-Control-Allow-Origin", origin)
+			h.Add("Vary", "Origin")
+		}
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			if allowed {
+				h := w.Header()
+				h.Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+				h.Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+				h.Set("Access-Control-Max-Age", "600")
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeJSON writes v as the response body with status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+// errorResponse is the body of every non-2xx response.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, errorResponse{Error: msg})
+}
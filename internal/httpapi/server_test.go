@@ -0,0 +1,245 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/memvra/memvra/internal/config"
+	"github.com/memvra/memvra/internal/db"
+	"github.com/memvra/memvra/internal/memory"
+)
+
+func setupTestServer(t *testing.T, opts Options) (*memory.Store, http.Handler) {
+	t.Helper()
+	root := t.TempDir()
+	database, err := db.Open(filepath.Join(root, "memvra.db"))
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	store := memory.NewStore(database)
+	store.UpsertProject(memory.Project{Name: "testproject", RootPath: root})
+	// No embedder: search falls back to listing memories unranked.
+	orch := memory.NewOrchestrator(store, memory.NewVectorStore(database), memory.NewRanker(), nil)
+	return store, NewServer(root, store, orch, opts).Handler()
+}
+
+func get(t *testing.T, h http.Handler, target string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMemories_ListsJSON(t *testing.T) {
+	store, h := setupTestServer(t, Options{})
+	store.InsertMemory(memory.Memory{Content: "use JWT", MemoryType: memory.TypeDecision, Importance: 0.8})
+	store.InsertMemory(memory.Memory{Content: "tabs", MemoryType: memory.TypeConvention, Importance: 0.5})
+
+	rec := get(t, h, "/api/memories?type=decision", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	var body struct {
+		Memories []memory.Memory `json:"memories"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Memories) != 1 || body.Memories[0].Content != "use JWT" {
+		t.Errorf("memories = %+v, want only the decision", body.Memories)
+	}
+
+	if rec := get(t, h, "/api/memories?type=bogus", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown type: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestSearch_RequiresQuery(t *testing.T) {
+	_, h := setupTestServer(t, Options{})
+	rec := get(t, h, "/api/search", nil)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `"error"`) {
+		t.Errorf("status = %d, body %s; want 400 with an error", rec.Code, rec.Body)
+	}
+}
+
+func TestSessions_EmptyIsArray(t *testing.T) {
+	_, h := setupTestServer(t, Options{})
+	rec := get(t, h, "/api/sessions", nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"sessions": []`) {
+		t.Errorf("status = %d, body %s", rec.Code, rec.Body)
+	}
+}
+
+func TestToken_Required(t *testing.T) {
+	_, h := setupTestServer(t, Options{Token: "s3cret"})
+
+	if rec := get(t, h, "/api/memories", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("no token: status = %d, want 401", rec.Code)
+	}
+	bad := http.Header{"Authorization": {"Bearer wrong"}}
+	if rec := get(t, h, "/api/memories", bad); rec.Code != http.StatusUnauthorized {
+		t.Errorf("wrong token: status = %d, want 401", rec.Code)
+	}
+	good := http.Header{"Authorization": {"Bearer s3cret"}}
+	if rec := get(t, h, "/api/memories", good); rec.Code != http.StatusOK {
+		t.Errorf("valid token: status = %d, want 200", rec.Code)
+	}
+}
+
+func TestCORS(t *testing.T) {
+	_, h := setupTestServer(t, Options{Token: "s3cret", CORSOrigins: []string{"http://ui.local"}})
+
+	// Preflight carries no credentials and must not be rejected by auth.
+	req := httptest.NewRequest(http.MethodOptions, "/api/search", nil)
+	req.Header.Set("Origin", "http://ui.local")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("preflight status = %d, want 204", rec.Code)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "http://ui.local" {
+		t.Errorf("Allow-Origin = %q", got)
+	}
+	if !strings.Contains(rec.Header().Get("Access-Control-Allow-Headers"), "Authorization") {
+		t.Errorf("Allow-Headers = %q, want Authorization", rec.Header().Get("Access-Control-Allow-Headers"))
+	}
+
+	other := http.Header{"Origin": {"http://evil.local"}, "Authorization": {"Bearer s3cret"}}
+	if got := get(t, h, "/api/memories", other).Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("disallowed origin got Allow-Origin %q", got)
+	}
+}
+
+func TestContext_ReturnsJSON(t *testing.T) {
+	store, h := setupTestServer(t, Options{})
+	store.InsertMemory(memory.Memory{Content: "use JWT for auth", MemoryType: memory.TypeDecision, Importance: 0.8})
+
+	rec := get(t, h, "/api/context?q=auth", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	var body contextResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !strings.Contains(body.Context, "use JWT for auth") || body.TokensUsed == 0 {
+		t.Errorf("context = %+v, want the decision and a token count", body)
+	}
+
+	if rec := get(t, h, "/api/context?max_tokens=1", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("tiny max_tokens: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestContextStream_SectionsThenDone(t *testing.T) {
+	store, h := setupTestServer(t, Options{})
+	store.InsertMemory(memory.Memory{Content: "use JWT for auth", MemoryType: memory.TypeDecision, Importance: 0.8})
+	store.InsertSession(memory.Session{Question: "add login", ContextUsed: "{}", ResponseSummary: "Added JWT login.", ModelUsed: "claude"})
+
+	rec := get(t, h, "/api/context/stream?q=auth", nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("status = %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	var sections []string
+	var done contextResponse
+	for _, ev := range strings.Split(strings.TrimSpace(rec.Body.String()), "\n\n") {
+		name, data, _ := strings.Cut(ev, "\n")
+		data = strings.TrimPrefix(data, "data: ")
+		switch name {
+		case "event: section":
+			var sec sectionEvent
+			if err := json.Unmarshal([]byte(data), &sec); err != nil {
+				t.Fatalf("decode section: %v", err)
+			}
+			sections = append(sections, sec.Text)
+		case "event: done":
+			if err := json.Unmarshal([]byte(data), &done); err != nil {
+				t.Fatalf("decode done: %v", err)
+			}
+		default:
+			t.Fatalf("unexpected event %q", ev)
+		}
+	}
+	if len(sections) < 2 {
+		t.Errorf("expected session and decision sections, got %d", len(sections))
+	}
+	if got := strings.Join(sections, "\n"); got != done.Context || done.Context == "" {
+		t.Errorf("streamed sections don't add up to the final context:\n%s\n---\n%s", got, done.Context)
+	}
+}
+
+func TestContextOptions_HonorsProjectConfig(t *testing.T) {
+	root := t.TempDir()
+	sessions := 2
+	if err := config.SaveProject(root, config.ProjectConfig{
+		Project:      config.ProjectMeta{Name: "testproject"},
+		DefaultModel: "gpt-4o",
+		Context:      config.ProjectContextConfig{TopKSessions: &sessions},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(root, nil, nil, Options{})
+
+	opts, err := s.contextOptions(httptest.NewRequest(http.MethodGet, "/api/context?q=auth", nil))
+	if err != nil {
+		t.Fatalf("contextOptions: %v", err)
+	}
+	if opts.Model != "gpt-4o" || opts.MaxTokens != 0 {
+		t.Errorf("expected a budget sized for default_model gpt-4o, got model %q max_tokens %d", opts.Model, opts.MaxTokens)
+	}
+	if opts.TopKSessions != 2 {
+		t.Errorf("project top_k_sessions not applied: got %d", opts.TopKSessions)
+	}
+	if got := s.tokenizer(opts.Model); got != s.tokenizer("gpt-4.1") {
+		t.Error("models sharing an encoding should share a tokenizer")
+	}
+}
+
+func TestContext_RecordsAccess(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	gcfg := config.DefaultGlobal()
+	gcfg.Audit.Enabled = true
+	if err := config.SaveGlobal(gcfg); err != nil {
+		t.Fatal(err)
+	}
+
+	root := t.TempDir()
+	database, err := db.Open(filepath.Join(root, "memvra.db"))
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	audit := memory.NewStore(database)
+	audit.UpsertProject(memory.Project{Name: "testproject", RootPath: root})
+	id, _ := audit.InsertMemory(memory.Memory{Content: "use JWT for auth", MemoryType: memory.TypeDecision, Importance: 0.8})
+
+	store := memory.NewStore(database)
+	store.SetReadOnly(true)
+	orch := memory.NewOrchestrator(store, memory.NewVectorStore(database), memory.NewRanker(), nil)
+	h := NewServer(root, store, orch, Options{AuditStore: audit}).Handler()
+
+	for _, target := range []string{"/api/context?q=auth", "/api/context/stream?q=auth"} {
+		if rec := get(t, h, target, nil); rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, body %s", target, rec.Code, rec.Body)
+		}
+	}
+	entries, err := audit.GetAccessLog(memory.AccessFilter{ItemID: id})
+	if err != nil {
+		t.Fatalf("GetAccessLog: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Model != auditClient {
+		t.Errorf("expected one %q entry per request, got %+v", auditClient, entries)
+	}
+}
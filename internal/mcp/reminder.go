@@ -0,0 +1,66 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/memvra/memvra/internal/config"
+)
+
+// countCalls wraps a tool handler so every call counts towards the
+// save-progress reminder.
+func (s *Server) countCalls(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		s.activityMu.Lock()
+		s.callsSinceSave++
+		s.activityMu.Unlock()
+		return handler(ctx, req)
+	}
+}
+
+// progressSaved resets the reminder after a successful memvra_save_progress
+// and returns the inferred memories remembered since the previous save.
+func (s *Server) progressSaved() []string {
+	s.activityMu.Lock()
+	defer s.activityMu.Unlock()
+	s.lastSave = time.Now()
+	s.callsSinceSave = 0
+	ids := s.inferredIDs
+	s.inferredIDs = nil
+	return ids
+}
+
+// memoryInferred queues an inferred memory to be linked to the next saved
+// session.
+func (s *Server) memoryInferred(id string) {
+	s.activityMu.Lock()
+	s.inferredIDs = append(s.inferredIDs, id)
+	s.activityMu.Unlock()
+}
+
+// progressReminder returns a note asking the assistant to save progress when
+// either [mcp] threshold has been reached since the last save, or "" if not.
+func (s *Server) progressReminder(cfg config.MCPConfig) string {
+	if s.readOnly {
+		return "" // there is no memvra_save_progress to call
+	}
+	s.activityMu.Lock()
+	calls, since := s.callsSinceSave, time.Since(s.lastSave)
+	s.activityMu.Unlock()
+
+	var reason string
+	switch {
+	case cfg.ProgressReminderCalls > 0 && calls >= cfg.ProgressReminderCalls:
+		reason = fmt.Sprintf("%d tool calls", calls)
+	case cfg.ProgressReminderMinutes > 0 && since >= time.Duration(cfg.ProgressReminderMinutes)*time.Minute:
+		reason = fmt.Sprintf("%d minutes", int(since.Minutes()))
+	default:
+		return ""
+	}
+	return fmt.Sprintf("Reminder: it has been %s since progress was last saved. "+
+		"Call memvra_save_progress so another session can pick up where you are.", reason)
+}
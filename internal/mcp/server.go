@@ -5,9 +5,18 @@
 package mcp
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 
+	"github.com/memvra/memvra/internal/adapter"
 	"github.com/memvra/memvra/internal/config"
 	"github.com/memvra/memvra/internal/db"
 	"github.com/memvra/memvra/internal/memory"
@@ -19,6 +28,33 @@ type Server struct {
 	database *db.DB
 	store    *memory.Store
 	vectors  *memory.VectorStore
+
+	// embedder is built lazily and reused across tool calls; see embedderFor.
+	embMu       sync.Mutex
+	embedder    adapter.Embedder
+	embedderKey string
+
+	// Activity since the last memvra_save_progress; see progressReminder.
+	activityMu     sync.Mutex
+	lastSave       time.Time
+	callsSinceSave int
+	// inferredIDs are memories remembered as inferred since the last save;
+	// the next memvra_save_progress records its session as their origin.
+	inferredIDs []string
+
+	// allowed restricts registerTools to these tool names; nil registers
+	// every tool. See SetTools.
+	allowed map[string]bool
+	// readOnly hides writeTools and rejects writes; see NewReadOnlyServer.
+	readOnly bool
+}
+
+// writeTools are the tools that change the project database.
+var writeTools = map[string]bool{
+	"memvra_save_progress": true,
+	"memvra_remember":      true,
+	"memvra_forget":        true,
+	"memvra_complete_todo": true,
 }
 
 // NewServer opens the Memvra database at the given project root and prepares
@@ -30,11 +66,35 @@ func NewServer(root string) (*Server, error) {
 		return nil, err
 	}
 
+	// Vectors stored before embeddings were keyed by model belong to
+	// whichever model is configured now (best-effort).
+	gcfg, _ := config.Load(root)
+	_, _ = database.AdoptLegacyVectors(gcfg.EmbeddingModelKey())
+
 	return &Server{
 		root:     root,
 		database: database,
 		store:    memory.NewStore(database),
 		vectors:  memory.NewVectorStore(database),
+		lastSave: time.Now(),
+	}, nil
+}
+
+// NewReadOnlyServer is like NewServer but opens the database read-only (see
+// db.OpenReadOnly), so the process cannot change it. Tools that write are
+// not offered. This suits a query-only endpoint over a shared snapshot.
+func NewReadOnlyServer(root string) (*Server, error) {
+	database, err := db.OpenReadOnly(config.ProjectDBPath(root))
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		root:     root,
+		database: database,
+		store:    memory.NewStore(database),
+		vectors:  memory.NewVectorStore(database),
+		lastSave: time.Now(),
+		readOnly: true,
 	}, nil
 }
 
@@ -49,10 +109,34 @@ func (s *Server) Run() error {
 	)
 
 	s.registerTools(mcpServer)
+	go s.probeDimension()
 
 	return server.ServeStdio(mcpServer)
 }
 
+// probeTimeout bounds the startup embedder probe.
+const probeTimeout = 30 * time.Second
+
+// probeDimension embeds a probe text with the configured embedder and logs
+// to stderr if it fails or its dimension differs from the index, so a
+// mismatch needing `memvra update --reembed` is reported at startup rather than on the
+// first search.
+func (s *Server) probeDimension() {
+	gcfg, _ := config.Load(s.root)
+	embedder := s.embedderFor(gcfg)
+	if embedder == nil {
+		return
+	}
+	orchestrator := memory.NewOrchestrator(s.store, s.vectors, nil, embedder)
+	orchestrator.SetEmbeddingModel(gcfg.EmbeddingModelKey())
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	if _, err := orchestrator.ProbeDimension(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "memvra: %s: %v\n", gcfg.EmbeddingModelKey(), err)
+	}
+}
+
 // Close releases the database connection.
 func (s *Server) Close() {
 	if s.database != nil {
@@ -63,22 +147,86 @@ func (s *Server) Close() {
 const serverInstructions = `Memvra gives you persistent memory across AI sessions. Use these tools to:
 - Save your progress before ending a session (memvra_save_progress)
 - Store important decisions and constraints (memvra_remember)
+- Catch up on recent commits, sessions and memories (memvra_recent_changes)
 - Retrieve relevant project context (memvra_get_context)
 - Search code and memories semantically (memvra_search)
 
 IMPORTANT: Always call memvra_save_progress before ending a conversation or when
 the user is about to switch to a different AI tool. This ensures continuity.`
 
-// registerTools adds all Memvra tools to the MCP server.
+// toolDef pairs a tool definition with its handler.
+type toolDef struct {
+	tool    mcp.Tool
+	handler server.ToolHandlerFunc
+}
+
+// toolDefs returns every tool the server offers, in registration order.
+func (s *Server) toolDefs() []toolDef {
+	var defs []toolDef
+	for _, f := range []func() (mcp.Tool, server.ToolHandlerFunc){
+		s.toolSaveProgress,
+		s.toolRemember,
+		s.toolGetContext,
+		s.toolSearch,
+		s.toolForget,
+		s.toolCompleteTodo,
+		s.toolProjectStatus,
+		s.toolListMemories,
+		s.toolListSessions,
+		s.toolRecentChanges,
+	} {
+		tool, handler := f()
+		defs = append(defs, toolDef{tool, handler})
+	}
+	return defs
+}
+
+// ToolNames returns the names of every tool the server can expose, sorted.
+func ToolNames() []string {
+	var names []string
+	for _, d := range (&Server{}).toolDefs() {
+		names = append(names, d.tool.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetTools limits the server to the named tools, e.g. to leave out
+// memvra_forget for an untrusted assistant. An empty list exposes every
+// tool. Unknown names are an error rather than being ignored, so a typo
+// can't hide or expose a tool unnoticed. Call before Run.
+func (s *Server) SetTools(names []string) error {
+	if len(names) == 0 {
+		s.allowed = nil
+		return nil
+	}
+	known := make(map[string]bool)
+	for _, n := range ToolNames() {
+		known[n] = true
+	}
+	allowed := make(map[string]bool, len(names))
+	for _, n := range names {
+		n = strings.TrimSpace(n)
+		if !known[n] {
+			return fmt.Errorf("mcp: unknown tool %q (valid: %s)", n, strings.Join(ToolNames(), ", "))
+		}
+		allowed[n] = true
+	}
+	s.allowed = allowed
+	return nil
+}
+
+// registerTools adds the permitted Memvra tools to the MCP server.
 func (s *Server) registerTools(mcpServer *server.MCPServer) {
-	mcpServer.AddTool(s.toolSaveProgress())
-	mcpServer.AddTool(s.toolRemember())
-	mcpServer.AddTool(s.toolGetContext())
-	mcpServer.AddTool(s.toolSearch())
-	mcpServer.AddTool(s.toolForget())
-	mcpServer.AddTool(s.toolProjectStatus())
-	mcpServer.AddTool(s.toolListMemories())
-	mcpServer.AddTool(s.toolListSessions())
+	for _, d := range s.toolDefs() {
+		if s.allowed != nil && !s.allowed[d.tool.Name] {
+			continue
+		}
+		if s.readOnly && writeTools[d.tool.Name] {
+			continue
+		}
+		mcpServer.AddTool(d.tool, s.countCalls(d.handler))
+	}
 }
 
 // toolSaveProgress returns the tool definition and handler for saving
@@ -96,13 +244,24 @@ func (s *Server) toolSaveProgress() (mcp.Tool, server.ToolHandlerFunc) {
 			mcp.Required(),
 		),
 		mcp.WithString("model",
-			mcp.Description("Your model name (e.g. 'claude', 'gemini', 'cursor')"),
-			mcp.Required(),
+			mcp.Description("Your model name (e.g. 'claude', 'gemini', 'cursor'). Defaults to the project's default_model."),
 		),
 		mcp.WithArray("files_touched",
 			mcp.Description("Files modified during this work session"),
 			mcp.WithStringItems(),
 		),
+		mcp.WithArray("next_steps",
+			mcp.Description("Concrete follow-up tasks for whoever continues, one per item. Shown at the top of the next session's context."),
+			mcp.WithStringItems(),
+		),
+		mcp.WithString("status",
+			mcp.Description("How the session ended: completed, in_progress (work left to do), or blocked (waiting on something). Unfinished sessions are surfaced first in later context."),
+			mcp.Enum("completed", "in_progress", "blocked"),
+		),
+		mcp.WithArray("tags",
+			mcp.Description("Short labels for the session's theme (e.g. 'bugfix', 'spike'), used to filter memvra_list_sessions"),
+			mcp.WithStringItems(),
+		),
 	)
 	return tool, s.handleSaveProgress
 }
@@ -117,8 +276,23 @@ func (s *Server) toolRemember() (mcp.Tool, server.ToolHandlerFunc) {
 			mcp.Required(),
 		),
 		mcp.WithString("type",
-			mcp.Description("Memory type"),
-			mcp.Enum("decision", "convention", "constraint", "note", "todo"),
+			mcp.Description("Memory type, stored as given. When omitted, the type is inferred from the content (e.g. 'TODO:' makes a todo)."),
+			mcp.Enum(memoryTypeNames()...),
+		),
+		mcp.WithBoolean("auto_classify",
+			mcp.Description("Infer the type from the content when type is omitted (default true). Set false to store a note as-is."),
+		),
+		mcp.WithString("scope",
+			mcp.Description("Where to store it: 'project' (default) or 'global', the user's own memory shared by every project. Use global only for personal preferences that hold everywhere, such as coding style."),
+			mcp.Enum("project", "global"),
+		),
+		mcp.WithBoolean("inferred",
+			mcp.Description("Set when you concluded this yourself rather than the user stating it. Inferred memories are marked as such and linked to the session you save next."),
+		),
+		mcp.WithNumber("confidence",
+			mcp.Description("How sure you are of an inferred memory, from 0 to 1. Implies inferred."),
+			mcp.Min(0),
+			mcp.Max(1),
 		),
 	)
 	return tool, s.handleRemember
@@ -132,6 +306,23 @@ func (s *Server) toolGetContext() (mcp.Tool, server.ToolHandlerFunc) {
 		mcp.WithString("question",
 			mcp.Description("Optional focus query to retrieve the most relevant context"),
 		),
+		mcp.WithNumber("max_tokens",
+			mcp.Description("Token budget for the returned context; size it to your context window (default: project config)"),
+			mcp.Min(minContextTokens),
+			mcp.Max(maxContextTokens),
+		),
+		mcp.WithNumber("top_k_sessions",
+			mcp.Description("How many recent session summaries to include (0 = none; default: project config)"),
+			mcp.Min(0),
+			mcp.Max(maxTopKSessions),
+		),
+		mcp.WithString("session_tag",
+			mcp.Description("Prefer sessions saved with this tag in the session history"),
+		),
+		mcp.WithBoolean("include_todos",
+			mcp.Description("List all open todos in their own section, whatever the question (default: open_todos in config, on unless disabled)"),
+		),
+		withSourcesFilter(),
 	)
 	return tool, s.handleGetContext
 }
@@ -141,29 +332,85 @@ func (s *Server) toolSearch() (mcp.Tool, server.ToolHandlerFunc) {
 	tool := mcp.NewTool("memvra_search",
 		mcp.WithDescription("Search across code chunks and stored memories using semantic similarity."),
 		mcp.WithString("query",
-			mcp.Description("What to search for"),
+			mcp.Description("What to search for. Prefix a word with '-' to exclude results containing it (e.g. 'error handling -test')"),
 			mcp.Required(),
 		),
 		mcp.WithNumber("top_k",
-			mcp.Description("Maximum number of results"),
-			mcp.DefaultNumber(10),
+			mcp.Description("Maximum number of results per kind (default: project config, else 10)"),
+		),
+		mcp.WithArray("exclude",
+			mcp.Description("Drop code whose path or content, and memories whose content, contains any of these terms"),
+			mcp.WithStringItems(),
+		),
+		mcp.WithBoolean("full_chunks",
+			mcp.Description("Return whole code chunks instead of snippets around the lines matching the query"),
 		),
+		mcp.WithNumber("chunk_threshold",
+			mcp.Description("Minimum similarity (0-1) for code chunks (default: config chunk_threshold, else similarity_threshold)"),
+			mcp.Min(0),
+			mcp.Max(1),
+		),
+		mcp.WithNumber("memory_threshold",
+			mcp.Description("Minimum similarity (0-1) for memories (default: config memory_threshold, else similarity_threshold)"),
+			mcp.Min(0),
+			mcp.Max(1),
+		),
+		mcp.WithString("language",
+			mcp.Description("Only return code chunks from files of this language, e.g. 'go' or 'typescript' (memories are unaffected)"),
+		),
+		mcp.WithString("only",
+			mcp.Description("Search only code chunks or only memories (default: both)"),
+			mcp.Enum(memory.CategoryChunks, memory.CategoryMemories),
+		),
+		withSourcesFilter(),
 	)
 	return tool, s.handleSearch
 }
 
+// memoryTypeNames lists the built-in and custom memory types for tool
+// argument enums.
+func memoryTypeNames() []string {
+	var names []string
+	for _, t := range memory.AllTypes() {
+		names = append(names, string(t))
+	}
+	return names
+}
+
+// withSourcesFilter declares the optional "sources" argument that restricts
+// memories by where they came from.
+func withSourcesFilter() mcp.ToolOption {
+	return mcp.WithArray("sources",
+		mcp.Description("Only include memories from these sources: 'user' (stated by a person) or 'extracted' (inferred by an AI). Omit for all."),
+		mcp.WithStringEnumItems([]string{memory.SourceUser, memory.SourceExtracted}),
+	)
+}
+
 // toolForget returns the tool definition and handler for deleting a memory.
 func (s *Server) toolForget() (mcp.Tool, server.ToolHandlerFunc) {
 	tool := mcp.NewTool("memvra_forget",
-		mcp.WithDescription("Delete a specific memory by its ID."),
+		mcp.WithDescription("Delete a specific memory by its ID, a unique ID prefix, or its exact content. An ambiguous match deletes nothing and lists the candidates."),
 		mcp.WithString("id",
-			mcp.Description("The memory ID to delete"),
+			mcp.Description("The memory ID, a unique prefix of it, or the memory's exact content"),
 			mcp.Required(),
 		),
 	)
 	return tool, s.handleForget
 }
 
+// toolCompleteTodo returns the tool definition and handler for marking a
+// todo done.
+func (s *Server) toolCompleteTodo() (mcp.Tool, server.ToolHandlerFunc) {
+	tool := mcp.NewTool("memvra_complete_todo",
+		mcp.WithDescription("Mark a todo memory as done. Unlike memvra_forget, the todo is kept as history but no longer appears in project context."),
+		mcp.WithString("id",
+			mcp.Description("The todo's ID, a unique prefix of it, or its exact content"),
+			mcp.Required(),
+		),
+	)
+	return tool, s.handleCompleteTodo
+}
+
 // toolProjectStatus returns the tool definition and handler for getting
 // project stats.
 func (s *Server) toolProjectStatus() (mcp.Tool, server.ToolHandlerFunc) {
@@ -177,10 +424,16 @@ func (s *Server) toolProjectStatus() (mcp.Tool, server.ToolHandlerFunc) {
 // stored memories.
 func (s *Server) toolListMemories() (mcp.Tool, server.ToolHandlerFunc) {
 	tool := mcp.NewTool("memvra_list_memories",
-		mcp.WithDescription("List all stored memories, optionally filtered by type."),
+		mcp.WithDescription("List all stored memories, optionally filtered by type and creation time."),
 		mcp.WithString("type",
 			mcp.Description("Filter by memory type"),
-			mcp.Enum("decision", "convention", "constraint", "note", "todo"),
+			mcp.Enum(memoryTypeNames()...),
+		),
+		mcp.WithString("since",
+			mcp.Description("Only memories created at or after this time: RFC3339 (2026-03-01T00:00:00Z), a date (2026-03-01), or an age such as 7d or 12h"),
+		),
+		mcp.WithString("until",
+			mcp.Description("Only memories created before this time, in the same formats as since"),
 		),
 	)
 	return tool, s.handleListMemories
@@ -195,6 +448,27 @@ func (s *Server) toolListSessions() (mcp.Tool, server.ToolHandlerFunc) {
 			mcp.Description("How many recent sessions to return"),
 			mcp.DefaultNumber(10),
 		),
+		mcp.WithString("tag",
+			mcp.Description("Only list sessions saved with this tag"),
+		),
 	)
 	return tool, s.handleListSessions
 }
+
+// toolRecentChanges returns the tool definition and handler for the
+// "catch me up" summary of recent commits, sessions and memories.
+func (s *Server) toolRecentChanges() (mcp.Tool, server.ToolHandlerFunc) {
+	tool := mcp.NewTool("memvra_recent_changes",
+		mcp.WithDescription("Summarize what changed recently: the latest git commits, saved sessions and new memories in one call. Use when rejoining a project; follow up with memvra_get_context for a specific question."),
+		mcp.WithNumber("limit",
+			mcp.Description("How many commits, sessions and memories to show, each"),
+			mcp.DefaultNumber(defaultRecentLimit),
+			mcp.Min(1),
+			mcp.Max(maxRecentLimit),
+		),
+		mcp.WithString("since",
+			mcp.Description("Only changes at or after this time: RFC3339, a date (2026-03-01), or an age such as 7d or 12h"),
+		),
+	)
+	return tool, s.handleRecentChanges
+}
@@ -2,19 +2,32 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 
 	"github.com/memvra/memvra/internal/adapter"
 	"github.com/memvra/memvra/internal/config"
 	ctxpkg "github.com/memvra/memvra/internal/context"
 	"github.com/memvra/memvra/internal/export"
+	gitpkg "github.com/memvra/memvra/internal/git"
 	"github.com/memvra/memvra/internal/memory"
+	"github.com/memvra/memvra/internal/redact"
 	"github.com/memvra/memvra/internal/scanner"
 )
 
+// minSummaryLength is the shortest memvra_save_progress summary accepted, in
+// characters after trimming; anything shorter is almost certainly a mistake.
+const minSummaryLength = 4
+
 func (s *Server) handleSaveProgress(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	task, err := req.RequireString("task")
 	if err != nil {
@@ -24,9 +37,22 @@ func (s *Server) handleSaveProgress(_ context.Context, req mcp.CallToolRequest)
 	if err != nil {
 		return mcp.NewToolResultError("missing required parameter: summary"), nil
 	}
-	model, err := req.RequireString("model")
-	if err != nil {
-		return mcp.NewToolResultError("missing required parameter: model"), nil
+	task, summary = strings.TrimSpace(task), strings.TrimSpace(summary)
+	if task == "" {
+		return mcp.NewToolResultError("task is blank: say in a few words what you worked on"), nil
+	}
+	if utf8.RuneCountInString(summary) < minSummaryLength {
+		return mcp.NewToolResultError(fmt.Sprintf(
+			"summary is blank or too short (%d characters minimum): describe what was done, what changed, and what is left", minSummaryLength)), nil
+	}
+	// An omitted model falls back to the project's default_model.
+	model := strings.TrimSpace(req.GetString("model", ""))
+	if model == "" {
+		pcfg, _ := config.LoadProject(s.root)
+		model = pcfg.DefaultModel
+	}
+	if model == "" {
+		return mcp.NewToolResultError("missing required parameter: model (or set default_model in .memvra/config.toml)"), nil
 	}
 
 	// Include touched files in summary if provided.
@@ -35,18 +61,86 @@ func (s *Server) handleSaveProgress(_ context.Context, req mcp.CallToolRequest)
 		summary += "\n\nFiles touched: " + strings.Join(filesTouched, ", ")
 	}
 
+	task, taskRedacted := s.redactSecrets(task)
+	summary, summaryRedacted := s.redactSecrets(summary)
+	redacted := taskRedacted + summaryRedacted
+
+	var nextSteps []string
+	for _, step := range req.GetStringSlice("next_steps", nil) {
+		if step = strings.TrimSpace(step); step == "" {
+			continue
+		}
+		step, n := s.redactSecrets(step)
+		redacted += n
+		nextSteps = append(nextSteps, step)
+	}
+
+	status := memory.SessionStatus(req.GetString("status", string(memory.SessionCompleted)))
+	if !memory.ValidSessionStatus(status) {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid status %q (valid: completed, in_progress, blocked)", status)), nil
+	}
+
 	sess := memory.Session{
 		Question:        task,
 		ResponseSummary: summary,
 		ModelUsed:       model,
+		Status:          status,
+		NextSteps:       nextSteps,
+		Tags:            memory.NormalizeTags(req.GetStringSlice("tags", nil)),
 	}
-	_, insertErr := s.store.InsertSessionReturningID(sess)
-	if insertErr != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to save progress: %v", insertErr)), nil
+	msg := "Progress saved."
+	gcfg, _ := config.Load(s.root)
+	var sessID string
+	if prev, ok := s.similarSession(gcfg.MCP, sess); ok {
+		if err := s.store.ReplaceSession(prev.ID, sess); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to save progress: %v", err)), nil
+		}
+		sessID = prev.ID
+		msg = fmt.Sprintf("Progress saved, updating the near-identical session from %s.", prev.CreatedAt.Local().Format("2006-01-02 15:04"))
+	} else {
+		id, insertErr := s.store.InsertSessionReturningID(sess)
+		if insertErr != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to save progress: %v", insertErr)), nil
+		}
+		sessID = id
 	}
+	_ = s.store.SetMemorySourceSession(s.progressSaved(), sessID)
+	_, _ = s.store.PruneSessionsByPolicy(memory.RetentionPolicy(gcfg.Retention))
 
 	export.AutoExport(s.root, s.store)
-	return mcp.NewToolResultText("Progress saved. Other AI tools will see this context in CLAUDE.md, .cursorrules, and PROJECT_CONTEXT.md."), nil
+	msg += " Other AI tools will see this context in CLAUDE.md, .cursorrules, and PROJECT_CONTEXT.md."
+	return mcp.NewToolResultText(msg + redactionNote(redacted)), nil
+}
+
+// defaultMergeSessionWindow applies when merging is on but
+// merge_session_window_hours is unset.
+const defaultMergeSessionWindow = 24 * time.Hour
+
+// similarSession returns the recent session most similar to sess, when
+// merging is enabled and one reaches cfg.MergeSessionThreshold.
+func (s *Server) similarSession(cfg config.MCPConfig, sess memory.Session) (memory.Session, bool) {
+	if cfg.MergeSessionThreshold <= 0 {
+		return memory.Session{}, false
+	}
+	window := time.Duration(cfg.MergeSessionWindowHours) * time.Hour
+	if window <= 0 {
+		window = defaultMergeSessionWindow
+	}
+	recent, err := s.store.ListSessionsSince(s.store.Now().Add(-window))
+	if err != nil {
+		return memory.Session{}, false
+	}
+
+	text := sess.Question + "\n" + sess.ResponseSummary
+	var best memory.Session
+	bestScore := 0.0
+	for _, prev := range recent {
+		score := memory.WordSimilarity(prev.Question+"\n"+prev.ResponseSummary, text)
+		if score >= cfg.MergeSessionThreshold && score > bestScore {
+			best, bestScore = prev, score
+		}
+	}
+	return best, bestScore > 0
 }
 
 func (s *Server) handleRemember(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -56,71 +150,193 @@ func (s *Server) handleRemember(_ context.Context, req mcp.CallToolRequest) (*mc
 	}
 
 	typeStr := req.GetString("type", "")
+	scope := req.GetString("scope", scopeProject)
+	if scope != scopeProject && scope != scopeGlobal {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid scope %q (valid: project, global)", scope)), nil
+	}
 
+	// An explicit type is kept verbatim; without one the content is
+	// classified, unless auto_classify is false, which stores a note.
 	var mt memory.MemoryType
-	if typeStr != "" {
+	switch {
+	case typeStr != "":
 		mt = memory.MemoryType(typeStr)
 		if !memory.ValidMemoryType(mt) {
-			return mcp.NewToolResultError(fmt.Sprintf("invalid type %q (valid: decision, convention, constraint, note, todo)", typeStr)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("invalid type %q (valid: %s)", typeStr, memory.TypeNames())), nil
 		}
-	} else {
+	case req.GetBool("auto_classify", true):
 		mt = memory.ClassifyMemoryType(content)
+	default:
+		mt = memory.TypeNote
 	}
 
+	content, redacted := s.redactSecrets(content)
+
 	m := memory.Memory{
 		Content:    content,
 		MemoryType: mt,
-		Source:     "user",
-		Importance: 0.6,
+		Source:     memory.SourceUser,
+		Importance: memory.ImportanceFor(s.importanceDefaults(), mt),
+	}
+	confidence, hasConfidence := optionalFloat(req, "confidence")
+	if hasConfidence && (confidence < 0 || confidence > 1) {
+		return mcp.NewToolResultError("confidence must be between 0 and 1"), nil
 	}
-	if mt == memory.TypeDecision || mt == memory.TypeConstraint {
-		m.Importance = 0.8
+	inferred := req.GetBool("inferred", false) || hasConfidence
+	if inferred {
+		m.Source = memory.SourceExtracted
+		m.Confidence = confidence
+	}
+
+	if scope == scopeGlobal {
+		return rememberGlobal(m, redacted)
 	}
 
 	id, insertErr := s.store.InsertMemory(m)
 	if insertErr != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to store memory: %v", insertErr)), nil
 	}
+	if inferred {
+		s.memoryInferred(id)
+	}
 
 	// Best-effort embed.
 	s.embedMemory(id, content)
 
 	export.AutoExport(s.root, s.store)
-	return mcp.NewToolResultText(fmt.Sprintf("Remembered as %s (id: %s)", mt, id)), nil
+
+	// Structured result so callers can capture the ID for later calls; the
+	// confirmation text stays first for models that can't parse structure.
+	out := rememberResult{ID: id, Type: string(mt), Source: m.Source, Redacted: redacted > 0}
+	res := mcp.NewToolResultStructured(out, fmt.Sprintf("Remembered as %s (id: %s)", mt, id)+redactionNote(redacted))
+	if data, err := json.Marshal(out); err == nil {
+		res.Content = append(res.Content, mcp.NewTextContent(string(data)))
+	}
+	return res, nil
+}
+
+// rememberResult is the structured payload returned by memvra_remember.
+type rememberResult struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Source   string `json:"source"`
+	Redacted bool   `json:"redacted"`
+	Scope    string `json:"scope,omitempty"` // "global" for user-level memories
+}
+
+// memvra_remember scopes: the project database, or the user-level memory
+// shared by every project.
+const (
+	scopeProject = "project"
+	scopeGlobal  = "global"
+)
+
+// rememberGlobal stores m in the user-level memory. Global memories are
+// only listed into system prompts, so they are neither embedded nor tied
+// to the next saved session.
+func rememberGlobal(m memory.Memory, redacted int) (*mcp.CallToolResult, error) {
+	path, err := config.GlobalDBPath()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to store memory: %v", err)), nil
+	}
+	store, database, err := memory.OpenGlobalStore(path, true)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to store memory: %v", err)), nil
+	}
+	defer func() { _ = database.Close() }()
+
+	id, err := store.InsertMemory(m)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to store memory: %v", err)), nil
+	}
+
+	out := rememberResult{ID: id, Type: string(m.MemoryType), Source: m.Source, Redacted: redacted > 0, Scope: scopeGlobal}
+	res := mcp.NewToolResultStructured(out, fmt.Sprintf("Remembered globally as %s (id: %s)", m.MemoryType, id)+redactionNote(redacted))
+	if data, err := json.Marshal(out); err == nil {
+		res.Content = append(res.Content, mcp.NewTextContent(string(data)))
+	}
+	return res, nil
 }
 
+// useGlobalMemory adds the user-level memories, if any, to builder's system
+// prompt. Call the returned func once the context is built.
+func useGlobalMemory(builder *ctxpkg.Builder) func() {
+	path, err := config.GlobalDBPath()
+	if err != nil {
+		return func() {}
+	}
+	global, database, err := memory.OpenGlobalStore(path, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "memvra: %v\n", err)
+		return func() {}
+	}
+	if global == nil {
+		return func() {}
+	}
+	builder.SetGlobalStore(global)
+	return func() { _ = database.Close() }
+}
+
+// Bounds for the memvra_get_context size arguments.
+const (
+	minContextTokens = 500
+	maxContextTokens = 1000000
+	maxTopKSessions  = 50
+
+	// defaultSearchTopK limits memvra_search results when neither the top_k
+	// argument nor the project's [context] settings give a limit.
+	defaultSearchTopK = 10
+)
+
 func (s *Server) handleGetContext(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	question := req.GetString("question", "")
 
 	gcfg, _ := config.Load(s.root)
+	pcfg, _ := config.LoadProject(s.root)
+
+	opts := ctxpkg.ConfiguredOptions(s.root, gcfg, pcfg)
+	opts.Question = question
+	if n, ok := optionalInt(req, "max_tokens"); ok {
+		if n < minContextTokens || n > maxContextTokens {
+			return mcp.NewToolResultError(fmt.Sprintf("max_tokens must be between %d and %d", minContextTokens, maxContextTokens)), nil
+		}
+		opts.MaxTokens = n
+	}
+	if n, ok := optionalInt(req, "top_k_sessions"); ok {
+		if n < 0 || n > maxTopKSessions {
+			return mcp.NewToolResultError(fmt.Sprintf("top_k_sessions must be between 0 and %d", maxTopKSessions)), nil
+		}
+		opts.TopKSessions = n
+	}
+	memSources, err := sourcesArg(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	opts.Sources = memSources
+	opts.OpenTodos = req.GetBool("include_todos", opts.OpenTodos)
+	opts.SessionTag = req.GetString("session_tag", "")
 
 	// Build embedder for semantic search (best-effort).
 	var embedder adapter.Embedder
-	if emb := buildEmbedder(gcfg); emb != nil {
+	if emb := s.embedderFor(gcfg); emb != nil {
 		embedder = emb
 	}
 
-	ranker := memory.NewRanker()
-	orchestrator := memory.NewOrchestrator(s.store, s.vectors, ranker, embedder)
-	formatter := ctxpkg.NewFormatter()
-	tokenizer, _ := ctxpkg.NewTokenizer()
-	builder := ctxpkg.NewBuilder(s.store, orchestrator, formatter, tokenizer)
-
-	opts := ctxpkg.BuildOptions{
-		Question:            question,
-		ProjectRoot:         s.root,
-		MaxTokens:           gcfg.Context.MaxTokens,
-		TopKChunks:          gcfg.Context.TopKChunks,
-		TopKMemories:        gcfg.Context.TopKMemories,
-		TopKSessions:        gcfg.Context.TopKSessions,
-		SessionTokenBudget:  gcfg.Context.SessionTokenBudget,
-		SimilarityThreshold: gcfg.Context.SimilarityThreshold,
+	orchestrator := ctxpkg.ConfiguredOrchestrator(s.store, s.vectors, embedder, gcfg, pcfg)
+	tokenizer := ctxpkg.NewTokenizerForModel(opts.Model)
+	if err := tokenizer.FallbackReason(); err != nil {
+		fmt.Fprintf(os.Stderr, "memvra: %v; token counts are approximate\n", err)
 	}
+	builder := ctxpkg.NewBuilder(s.store, orchestrator, ctxpkg.NewFormatter(), tokenizer)
+	defer useGlobalMemory(builder)()
 
 	built, err := builder.Build(ctx, opts)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to build context: %v", err)), nil
 	}
+	if err := ctxpkg.RecordAccess(s.store, gcfg, built, clientName(ctx), ""); err != nil {
+		fmt.Fprintf(os.Stderr, "memvra: audit log: %v\n", err)
+	}
 
 	var result strings.Builder
 	if built.SystemPrompt != "" {
@@ -128,8 +344,41 @@ func (s *Server) handleGetContext(ctx context.Context, req mcp.CallToolRequest)
 		result.WriteString("\n\n")
 	}
 	result.WriteString(built.ContextText)
+	if len(built.Warnings) > 0 {
+		result.WriteString("\n\nNote: " + strings.Join(built.Warnings, "; "))
+	}
+	if reminder := s.progressReminder(gcfg.MCP); reminder != "" {
+		result.WriteString("\n\n")
+		result.WriteString(reminder)
+	}
 
-	return mcp.NewToolResultText(result.String()), nil
+	// Machine-readable provenance: the text block stays first for models that
+	// can't parse structure; clients can read the JSON block or structured content.
+	sources := contextSources{Sources: built.SourceRefs}
+	if sources.Sources == nil {
+		sources.Sources = []ctxpkg.Source{}
+	}
+	res := mcp.NewToolResultStructured(sources, result.String())
+	if data, err := json.Marshal(sources); err == nil {
+		res.Content = append(res.Content, mcp.NewTextContent(string(data)))
+	}
+	return res, nil
+}
+
+// clientName returns the name the MCP client gave at initialization, or
+// "mcp" when it gave none.
+func clientName(ctx context.Context) string {
+	if session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo); ok {
+		if name := session.GetClientInfo().Name; name != "" {
+			return name
+		}
+	}
+	return "mcp"
+}
+
+// contextSources is the structured payload returned by memvra_get_context.
+type contextSources struct {
+	Sources []ctxpkg.Source `json:"sources"`
 }
 
 func (s *Server) handleSearch(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -137,23 +386,41 @@ func (s *Server) handleSearch(ctx context.Context, req mcp.CallToolRequest) (*mc
 	if err != nil {
 		return mcp.NewToolResultError("missing required parameter: query"), nil
 	}
-	topK := req.GetInt("top_k", 10)
+	query, exclude := memory.SplitExclusions(query)
+	exclude = append(exclude, req.GetStringSlice("exclude", nil)...)
+	pcfg, _ := config.LoadProject(s.root)
+	topKChunks, topKMemories := pcfg.SearchTopK(defaultSearchTopK)
+	if n, ok := optionalInt(req, "top_k"); ok {
+		topKChunks, topKMemories = n, n
+	}
+	sources, err := sourcesArg(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	gcfg, _ := config.Load(s.root)
 
 	var embedder adapter.Embedder
-	if emb := buildEmbedder(gcfg); emb != nil {
+	if emb := s.embedderFor(gcfg); emb != nil {
 		embedder = emb
 	}
 
-	ranker := memory.NewRanker()
-	orchestrator := memory.NewOrchestrator(s.store, s.vectors, ranker, embedder)
+	orchestrator := ctxpkg.ConfiguredOrchestrator(s.store, s.vectors, embedder, gcfg, pcfg)
 
-	result, err := orchestrator.Retrieve(ctx, query, memory.RetrieveOptions{
-		TopKChunks:          topK,
-		TopKMemories:        topK,
+	opts := memory.RetrieveOptions{
+		TopKChunks:          topKChunks,
+		TopKMemories:        topKMemories,
 		SimilarityThreshold: gcfg.Context.SimilarityThreshold,
-	})
+		ChunkThreshold:      req.GetFloat("chunk_threshold", gcfg.Context.ChunkThreshold),
+		MemoryThreshold:     req.GetFloat("memory_threshold", gcfg.Context.MemoryThreshold),
+		Sources:             sources,
+		Exclude:             exclude,
+		Language:            req.GetString("language", ""),
+	}
+	if err := opts.LimitToCategory(req.GetString("only", "")); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("only: %v", err)), nil
+	}
+	result, err := orchestrator.Retrieve(ctx, query, opts)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err)), nil
 	}
@@ -168,20 +435,34 @@ func (s *Server) handleSearch(ctx context.Context, req mcp.CallToolRequest) (*mc
 	}
 	if len(result.Chunks) > 0 {
 		sb.WriteString("## Matching Code\n\n")
+		fileIDs := make([]string, len(result.Chunks))
+		for i, c := range result.Chunks {
+			fileIDs[i] = c.FileID
+		}
+		files, _ := s.store.GetFilesByIDs(fileIDs)
+		fullChunks := req.GetBool("full_chunks", false)
 		for _, c := range result.Chunks {
-			file, _ := s.store.GetFileByID(c.FileID)
-			label := file.Path
+			label := files[c.FileID].Path
 			if label == "" {
 				label = c.FileID
 			}
-			fmt.Fprintf(&sb, "### %s (lines %d-%d)\n```\n%s\n```\n\n", label, c.StartLine, c.EndLine, c.Content)
+			if fullChunks {
+				fmt.Fprintf(&sb, "### %s (lines %d-%d)\n```\n%s\n```\n\n", label, c.StartLine, c.EndLine, c.Content)
+				continue
+			}
+			snippet := memory.ExtractSnippet(c, query, memory.DefaultSnippetContext, memory.DefaultSnippetLines)
+			fmt.Fprintf(&sb, "### %s (lines %d-%d)\n```\n%s```\n\n", label, c.StartLine, c.EndLine, snippet.Format(nil))
 		}
 	}
 
-	if sb.Len() == 0 {
-		return mcp.NewToolResultText("No results found."), nil
+	text := sb.String()
+	if text == "" {
+		text = "No results found."
 	}
-	return mcp.NewToolResultText(sb.String()), nil
+	if w := result.FallbackWarning(); w != "" {
+		text = fmt.Sprintf("Note: %s\n\n", w) + text
+	}
+	return mcp.NewToolResultText(text), nil
 }
 
 func (s *Server) handleForget(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -190,6 +471,11 @@ func (s *Server) handleForget(_ context.Context, req mcp.CallToolRequest) (*mcp.
 		return mcp.NewToolResultError("missing required parameter: id"), nil
 	}
 
+	m, err := s.store.FindMemory(id)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	id = m.ID
 	if delErr := s.store.DeleteMemory(id); delErr != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to delete memory: %v", delErr)), nil
 	}
@@ -201,6 +487,27 @@ func (s *Server) handleForget(_ context.Context, req mcp.CallToolRequest) (*mcp.
 	return mcp.NewToolResultText(fmt.Sprintf("Memory %s deleted.", id)), nil
 }
 
+func (s *Server) handleCompleteTodo(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id, err := req.RequireString("id")
+	if err != nil {
+		return mcp.NewToolResultError("missing required parameter: id"), nil
+	}
+
+	m, err := s.store.FindMemory(id)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if m.Done() {
+		return mcp.NewToolResultText(fmt.Sprintf("Todo %s was already completed on %s.", m.ID, m.CompletedAt.Format("2006-01-02"))), nil
+	}
+	if err := s.store.CompleteTodo(m.ID); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to complete todo: %v", err)), nil
+	}
+
+	export.AutoExport(s.root, s.store)
+	return mcp.NewToolResultText(fmt.Sprintf("Todo %s marked done: %s", m.ID, m.Content)), nil
+}
+
 func (s *Server) handleProjectStatus(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	proj, err := s.store.GetProject()
 	if err != nil {
@@ -212,16 +519,24 @@ func (s *Server) handleProjectStatus(_ context.Context, _ mcp.CallToolRequest) (
 	sessionCount, _ := s.store.CountSessions()
 
 	var sb strings.Builder
-	fmt.Fprintf(&sb, "Project: %s\n", proj.Name)
-
-	stack := ts.Language
-	if ts.Framework != "" {
-		stack += " / " + ts.Framework
-	}
-	if ts.Database != "" {
-		stack += " + " + ts.Database
+	pcfg, _ := config.LoadProject(s.root)
+	fmt.Fprintf(&sb, "Project: %s\n", pcfg.ProjectName(proj.Name))
+
+	var stacks []string
+	for _, ns := range ts.Components() {
+		stack := ns.Language
+		if ns.Framework != "" {
+			stack += " / " + ns.Framework
+		}
+		if ns.Database != "" {
+			stack += " + " + ns.Database
+		}
+		if ns.Name != "" {
+			stack = ns.Name + ": " + stack
+		}
+		stacks = append(stacks, stack)
 	}
-	fmt.Fprintf(&sb, "Stack:   %s\n", stack)
+	fmt.Fprintf(&sb, "Stack:   %s\n", strings.Join(stacks, "; "))
 	fmt.Fprintf(&sb, "Files:   %d indexed, %d chunks\n", proj.FileCount, proj.ChunkCount)
 
 	totalMem := 0
@@ -231,7 +546,7 @@ func (s *Server) handleProjectStatus(_ context.Context, _ mcp.CallToolRequest) (
 	fmt.Fprintf(&sb, "Memories: %d total", totalMem)
 	if totalMem > 0 {
 		parts := []string{}
-		for _, t := range []memory.MemoryType{memory.TypeDecision, memory.TypeConvention, memory.TypeConstraint, memory.TypeNote, memory.TypeTodo} {
+		for _, t := range memory.AllTypes() {
 			if n, ok := memCounts[t]; ok && n > 0 {
 				parts = append(parts, fmt.Sprintf("%d %s", n, t))
 			}
@@ -248,31 +563,57 @@ func (s *Server) handleProjectStatus(_ context.Context, _ mcp.CallToolRequest) (
 
 func (s *Server) handleListMemories(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	typeStr := req.GetString("type", "")
-	memories, err := s.store.ListMemories(memory.MemoryType(typeStr))
+	now := time.Now()
+	since, err := timeArg(req, "since", now)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	until, err := timeArg(req, "until", now)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	memories, err := s.store.ListMemoriesBetween(memory.MemoryType(typeStr), since, until)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to list memories: %v", err)), nil
 	}
 
 	if len(memories) == 0 {
+		if !since.IsZero() || !until.IsZero() {
+			return mcp.NewToolResultText("No memories created in that period."), nil
+		}
 		return mcp.NewToolResultText("No memories stored."), nil
 	}
 
 	var sb strings.Builder
 	for _, m := range memories {
+		label := string(m.MemoryType)
+		if m.Done() {
+			label += ", done " + m.CompletedAt.Format("2006-01-02")
+		}
 		fmt.Fprintf(&sb, "[%s] %s\n  id: %s | source: %s | created: %s\n\n",
-			m.MemoryType, m.Content, m.ID, m.Source, m.CreatedAt.Format("2006-01-02 15:04"))
+			label, m.Content, m.ID, provenance(m), m.CreatedAt.Format("2006-01-02 15:04"))
 	}
 	return mcp.NewToolResultText(sb.String()), nil
 }
 
 func (s *Server) handleListSessions(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	limit := req.GetInt("limit", 10)
-	sessions, err := s.store.GetLastNSessions(limit)
+	tag := req.GetString("tag", "")
+	var sessions []memory.Session
+	var err error
+	if tag != "" {
+		sessions, err = s.store.GetLastNSessionsTagged(limit, tag)
+	} else {
+		sessions, err = s.store.GetLastNSessions(limit)
+	}
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to list sessions: %v", err)), nil
 	}
 
 	if len(sessions) == 0 {
+		if tag != "" {
+			return mcp.NewToolResultText(fmt.Sprintf("No sessions tagged %q.", tag)), nil
+		}
 		return mcp.NewToolResultText("No sessions recorded."), nil
 	}
 
@@ -280,20 +621,233 @@ func (s *Server) handleListSessions(_ context.Context, req mcp.CallToolRequest)
 	// Reverse to chronological order (newest-first from DB → oldest-first for display).
 	for i := len(sessions) - 1; i >= 0; i-- {
 		sess := sessions[i]
-		fmt.Fprintf(&sb, "[%s] (%s) %s\n",
-			sess.CreatedAt.Format("2006-01-02 15:04"), sess.ModelUsed, sess.Question)
+		fmt.Fprintf(&sb, "[%s] (%s) [%s] %s\n",
+			sess.CreatedAt.Format("2006-01-02 15:04"), sess.ModelUsed, sess.Status, sess.Question)
 		if sess.ResponseSummary != "" {
 			fmt.Fprintf(&sb, "  → %s\n", sess.ResponseSummary)
 		}
+		if len(sess.NextSteps) > 0 {
+			fmt.Fprintf(&sb, "  next: %s\n", strings.Join(sess.NextSteps, "; "))
+		}
+		if len(sess.Tags) > 0 {
+			fmt.Fprintf(&sb, "  tags: %s\n", strings.Join(sess.Tags, ", "))
+		}
 		sb.WriteString("\n")
 	}
 	return mcp.NewToolResultText(sb.String()), nil
 }
 
+// Bounds for the memvra_recent_changes limit argument.
+const (
+	defaultRecentLimit = 5
+	maxRecentLimit     = 50
+
+	// recentSummaryRunes caps each session summary in
+	// memvra_recent_changes, which is meant to be skimmed.
+	recentSummaryRunes = 200
+)
+
+func (s *Server) handleRecentChanges(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	limit := req.GetInt("limit", defaultRecentLimit)
+	if limit < 1 || limit > maxRecentLimit {
+		return mcp.NewToolResultError(fmt.Sprintf("limit must be between 1 and %d", maxRecentLimit)), nil
+	}
+	since, err := timeArg(req, "since", time.Now())
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	commits := gitpkg.RecentCommits(s.root, limit, since)
+
+	var sessions []memory.Session
+	if since.IsZero() {
+		sessions, err = s.store.GetLastNSessions(limit)
+	} else {
+		sessions, err = s.store.ListSessionsSince(since)
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list sessions: %v", err)), nil
+	}
+	if len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+
+	memories, err := s.store.ListMemoriesBetween("", since, time.Time{})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list memories: %v", err)), nil
+	}
+	// ListMemoriesBetween ranks by importance; this is about recency.
+	slices.SortStableFunc(memories, func(a, b memory.Memory) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	if len(memories) > limit {
+		memories = memories[:limit]
+	}
+
+	if len(commits) == 0 && len(sessions) == 0 && len(memories) == 0 {
+		if !since.IsZero() {
+			return mcp.NewToolResultText("No changes in that period."), nil
+		}
+		return mcp.NewToolResultText("No commits, sessions or memories recorded."), nil
+	}
+
+	var sb strings.Builder
+	if len(commits) > 0 {
+		sb.WriteString("Recent commits:\n")
+		for _, c := range commits {
+			fmt.Fprintf(&sb, "  %s %s %s (%s)\n", c.Hash, c.Date.Format("2006-01-02"), c.Subject, c.Author)
+		}
+		sb.WriteString("\n")
+	}
+	if len(sessions) > 0 {
+		sb.WriteString("Recent sessions:\n")
+		for _, sess := range sessions {
+			fmt.Fprintf(&sb, "  [%s] (%s) [%s] %s\n",
+				sess.CreatedAt.Format("2006-01-02 15:04"), sess.ModelUsed, sess.Status, sess.Question)
+			if sess.ResponseSummary != "" {
+				fmt.Fprintf(&sb, "    → %s\n", truncateRunes(sess.ResponseSummary, recentSummaryRunes))
+			}
+			if len(sess.NextSteps) > 0 {
+				fmt.Fprintf(&sb, "    next: %s\n", strings.Join(sess.NextSteps, "; "))
+			}
+		}
+		sb.WriteString("\n")
+	}
+	if len(memories) > 0 {
+		sb.WriteString("Recent memories:\n")
+		for _, m := range memories {
+			label := string(m.MemoryType)
+			if m.Done() {
+				label += ", done"
+			}
+			fmt.Fprintf(&sb, "  [%s] %s (id: %s, %s)\n", label, m.Content, m.ID, m.CreatedAt.Format("2006-01-02"))
+		}
+	}
+	return mcp.NewToolResultText(strings.TrimRight(sb.String(), "\n") + "\n"), nil
+}
+
+// truncateRunes shortens s to at most n runes on one line, ending in an
+// ellipsis where it was cut.
+func truncateRunes(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
+
+// importanceDefaults returns the project's per-type importance settings,
+// falling back to the built-in defaults if the project config is invalid.
+func (s *Server) importanceDefaults() map[memory.MemoryType]float64 {
+	pcfg, err := config.LoadProject(s.root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "memvra: %v\n", err)
+		return memory.DefaultImportance()
+	}
+	return memory.ParseImportance(pcfg.ImportanceDefaults())
+}
+
+// redactSecrets masks secrets in text according to the [redaction] config
+// and returns the number masked. An invalid custom pattern falls back to the
+// built-in set rather than letting secrets through.
+func (s *Server) redactSecrets(text string) (string, int) {
+	gcfg, _ := config.Load(s.root)
+	if !gcfg.Redaction.Enabled {
+		return text, 0
+	}
+	r, err := redact.New(gcfg.Redaction.Patterns)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "memvra: %v; using built-in patterns\n", err)
+		r, _ = redact.New(nil)
+	}
+	out, n := r.Redact(text)
+	if n > 0 {
+		fmt.Fprintf(os.Stderr, "memvra: warning: redacted %d secret(s) before saving\n", n)
+	}
+	return out, n
+}
+
+// redactionNote tells the calling agent that content was altered.
+func redactionNote(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" Note: %d secret(s) were replaced with %s.", n, redact.Placeholder)
+}
+
+// sourcesArg returns the optional "sources" filter, rejecting unknown values.
+func sourcesArg(req mcp.CallToolRequest) ([]string, error) {
+	sources := req.GetStringSlice("sources", nil)
+	for _, src := range sources {
+		if src != memory.SourceUser && src != memory.SourceExtracted {
+			return nil, fmt.Errorf("invalid source %q (valid: user, extracted)", src)
+		}
+	}
+	return sources, nil
+}
+
+// provenance describes where a memory came from: its source plus, for
+// inferred memories, the recorded confidence and originating session.
+func provenance(m memory.Memory) string {
+	var extra []string
+	if m.Confidence > 0 {
+		extra = append(extra, fmt.Sprintf("confidence %.2f", m.Confidence))
+	}
+	if m.SourceSessionID != "" {
+		extra = append(extra, "session "+m.SourceSessionID)
+	}
+	if len(extra) == 0 {
+		return m.Source
+	}
+	return fmt.Sprintf("%s (%s)", m.Source, strings.Join(extra, ", "))
+}
+
+// optionalInt returns the integer argument key and whether it was supplied.
+// Explicit zero values are reported as present.
+func optionalInt(req mcp.CallToolRequest, key string) (int, bool) {
+	if _, ok := req.GetArguments()[key]; !ok {
+		return 0, false
+	}
+	return req.GetInt(key, 0), true
+}
+
+// timeArg reads an optional time argument, given as RFC3339, a date
+// (2006-01-02, midnight UTC), or an age before now such as "7d" or "36h".
+// It returns the zero time when the argument is absent.
+func timeArg(req mcp.CallToolRequest, key string, now time.Time) (time.Time, error) {
+	v := strings.TrimSpace(req.GetString(key, ""))
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid %s %q: use RFC3339, a date (2006-01-02), or an age such as 7d or 12h", key, v)
+}
+
+// optionalFloat is optionalInt for numeric arguments that may be fractional.
+func optionalFloat(req mcp.CallToolRequest, key string) (float64, bool) {
+	if _, ok := req.GetArguments()[key]; !ok {
+		return 0, false
+	}
+	return req.GetFloat(key, 0), true
+}
+
 // embedMemory generates and stores a vector embedding for a memory (best-effort).
 func (s *Server) embedMemory(id, content string) {
-	gcfg, _ := config.LoadGlobal()
-	embedder := buildEmbedder(gcfg)
+	gcfg, _ := config.Load(s.root)
+	embedder := s.embedderFor(gcfg)
 	if embedder == nil {
 		return
 	}
@@ -301,22 +855,48 @@ func (s *Server) embedMemory(id, content string) {
 	if err != nil || len(vecs) == 0 {
 		return
 	}
-	_ = s.vectors.UpsertMemoryEmbedding(id, vecs[0])
+	_ = s.vectors.UpsertMemoryEmbedding(gcfg.EmbeddingModelKey(), id, vecs[0])
 }
 
-// buildEmbedder creates an embedder from config (returns nil on failure).
+// buildEmbedder creates the configured embedder via the adapter registry,
+// wrapped with retries and a circuit breaker (returns nil on failure).
 func buildEmbedder(gcfg config.GlobalConfig) adapter.Embedder {
-	name := gcfg.DefaultEmbedder
-	if name == "" {
-		name = "ollama"
-	}
+	name, model := gcfg.EmbeddingSettings()
 	var apiKey string
-	if name == adapter.ProviderOpenAI {
+	switch name {
+	case adapter.ProviderOpenAI:
 		apiKey = gcfg.Keys.OpenAI
-	}
-	emb, err := adapter.New(name, gcfg.Ollama.EmbedModel, apiKey, gcfg.Ollama.Host)
+	case adapter.ProviderGemini:
+		apiKey = gcfg.Keys.Gemini
+	case adapter.ProviderCohere:
+		apiKey = gcfg.Keys.Cohere
+	}
+	emb, err := adapter.NewEmbedder(name, adapter.EmbedderOptions{
+		Model:     model,
+		APIKey:    apiKey,
+		Host:      gcfg.Ollama.Host,
+		RateLimit: gcfg.Embedding.RateLimit,
+	})
 	if err != nil {
+		fmt.Fprintf(os.Stderr, "memvra: %v\n", err)
 		return nil
 	}
-	return emb
+	return adapter.NewRetryingEmbedder(emb, adapter.RetryOptions{})
+}
+
+// embedderFor returns the server's embedder for the resolved config, reusing
+// the previous instance while the provider and model are unchanged so the
+// circuit breaker state survives across tool calls.
+func (s *Server) embedderFor(gcfg config.GlobalConfig) adapter.Embedder {
+	name, model := gcfg.EmbeddingSettings()
+	key := name + ":" + model
+
+	s.embMu.Lock()
+	defer s.embMu.Unlock()
+	if s.embedder != nil && s.embedderKey == key {
+		return s.embedder
+	}
+	s.embedder = buildEmbedder(gcfg)
+	s.embedderKey = key
+	return s.embedder
 }
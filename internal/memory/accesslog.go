@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AccessEntry records one item packed into context for a model. Entries are
+// written only when auditing is enabled ([audit] in the config).
+type AccessEntry struct {
+	ID         int64     `json:"id"`
+	ItemID     string    `json:"item_id"`   // memory, chunk or session ID; the path for files
+	ItemType   string    `json:"item_type"` // decision, memory, session, file or chunk
+	Model      string    `json:"model"`     // model or client the context was built for
+	SessionID  string    `json:"session_id,omitempty"`
+	AccessedAt time.Time `json:"accessed_at"`
+}
+
+// AccessFilter selects access log entries. Zero fields match everything.
+type AccessFilter struct {
+	ItemID    string
+	ItemType  string
+	Model     string
+	SessionID string
+	Since     time.Time
+	Limit     int // 0 = no limit
+}
+
+// RecordAccess appends entries to the access log, stamped with the store's
+// clock. IDs and AccessedAt on the entries are ignored.
+func (s *Store) RecordAccess(entries []AccessEntry) error {
+	if err := s.writable("record access"); err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	tx, err := s.db.Conn().Begin()
+	if err != nil {
+		return fmt.Errorf("store: record access: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := s.now()
+	for _, e := range entries {
+		if _, err := tx.Exec(
+			`INSERT INTO access_log (item_id, item_type, model, session_id, accessed_at) VALUES (?, ?, ?, ?, ?)`,
+			e.ItemID, e.ItemType, e.Model, e.SessionID, now,
+		); err != nil {
+			return fmt.Errorf("store: record access: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("store: record access: %w", err)
+	}
+	return nil
+}
+
+// GetAccessLog returns access log entries matching f, newest first.
+func (s *Store) GetAccessLog(f AccessFilter) ([]AccessEntry, error) {
+	var where []string
+	var args []any
+	for _, c := range []struct{ col, val string }{
+		{"item_id", f.ItemID},
+		{"item_type", f.ItemType},
+		{"model", f.Model},
+		{"session_id", f.SessionID},
+	} {
+		if c.val != "" {
+			where = append(where, c.col+" = ?")
+			args = append(args, c.val)
+		}
+	}
+	if !f.Since.IsZero() {
+		where = append(where, "accessed_at >= ?")
+		args = append(args, f.Since.UTC().Format(sqliteTimeLayout))
+	}
+
+	query := `SELECT id, item_id, item_type, model, session_id, accessed_at FROM access_log`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY accessed_at DESC, id DESC"
+	if f.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, f.Limit)
+	}
+
+	rows, err := s.db.Conn().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("store: get access log: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []AccessEntry
+	for rows.Next() {
+		var e AccessEntry
+		var accessedAt string
+		if err := rows.Scan(&e.ID, &e.ItemID, &e.ItemType, &e.Model, &e.SessionID, &accessedAt); err != nil {
+			return nil, fmt.Errorf("store: get access log: %w", err)
+		}
+		e.AccessedAt = parseTime(accessedAt)
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
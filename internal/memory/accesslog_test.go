@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStore_AccessLog(t *testing.T) {
+	_, store := setupTestDB(t)
+	clock := NewManualClock(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	store.SetClock(clock)
+
+	if err := store.RecordAccess([]AccessEntry{
+		{ItemID: "m1", ItemType: "decision", Model: "claude", SessionID: "s1"},
+		{ItemID: "c1", ItemType: "chunk", Model: "claude", SessionID: "s1"},
+	}); err != nil {
+		t.Fatalf("RecordAccess: %v", err)
+	}
+	clock.Advance(2 * time.Hour)
+	if err := store.RecordAccess([]AccessEntry{{ItemID: "m1", ItemType: "decision", Model: "cursor"}}); err != nil {
+		t.Fatalf("RecordAccess: %v", err)
+	}
+
+	all, err := store.GetAccessLog(AccessFilter{})
+	if err != nil {
+		t.Fatalf("GetAccessLog: %v", err)
+	}
+	if len(all) != 3 || all[0].Model != "cursor" {
+		t.Fatalf("expected 3 entries, newest first; got %+v", all)
+	}
+	if !all[0].AccessedAt.Equal(clock.Now()) {
+		t.Errorf("AccessedAt = %v, want %v", all[0].AccessedAt, clock.Now())
+	}
+
+	byItem, _ := store.GetAccessLog(AccessFilter{ItemID: "m1"})
+	if len(byItem) != 2 {
+		t.Errorf("item filter: got %d entries, want 2", len(byItem))
+	}
+	bySession, _ := store.GetAccessLog(AccessFilter{SessionID: "s1", ItemType: "chunk"})
+	if len(bySession) != 1 || bySession[0].ItemID != "c1" {
+		t.Errorf("session+type filter: got %+v", bySession)
+	}
+	recent, _ := store.GetAccessLog(AccessFilter{Since: clock.Now().Add(-time.Hour)})
+	if len(recent) != 1 {
+		t.Errorf("since filter: got %d entries, want 1", len(recent))
+	}
+	limited, _ := store.GetAccessLog(AccessFilter{Limit: 2})
+	if len(limited) != 2 {
+		t.Errorf("limit: got %d entries, want 2", len(limited))
+	}
+
+	store.SetReadOnly(true)
+	if err := store.RecordAccess([]AccessEntry{{ItemID: "m1", ItemType: "decision"}}); err == nil {
+		t.Error("expected RecordAccess to fail on a read-only store")
+	}
+}
@@ -0,0 +1,206 @@
+package memory
+
+import "strings"
+
+// StripBoilerplate returns code with its license header and import block
+// removed, so a chunk is embedded by what it does rather than by what it
+// pulls in. language is a scanner.LanguageForFile name; in a language with
+// no import rules only the license header goes. If nothing but
+// boilerplate is left, content is returned unchanged.
+func StripBoilerplate(content, language string) string {
+	lines := strings.Split(content, "\n")
+	lines = stripLicenseHeader(lines, lineCommentPrefix(language))
+	if span := importRules[language]; span != nil {
+		lines = stripImports(lines, span)
+	}
+	out := strings.TrimSpace(strings.Join(lines, "\n"))
+	if out == "" {
+		return content
+	}
+	return out
+}
+
+// lineCommentPrefix returns the line comment marker of language.
+func lineCommentPrefix(language string) string {
+	switch language {
+	case "python", "ruby", "elixir":
+		return "#"
+	case "haskell":
+		return "--"
+	default:
+		return "//"
+	}
+}
+
+// stripLicenseHeader drops the comment block at the top of lines (after a
+// shebang and blank lines) when it mentions a license or copyright.
+func stripLicenseHeader(lines []string, prefix string) []string {
+	start := 0
+	for start < len(lines) {
+		t := strings.TrimSpace(lines[start])
+		if t != "" && !strings.HasPrefix(t, "#!") {
+			break
+		}
+		start++
+	}
+	if start == len(lines) {
+		return lines
+	}
+
+	end := start
+	if strings.HasPrefix(strings.TrimSpace(lines[start]), "/*") {
+		for end < len(lines) && !strings.Contains(lines[end], "*/") {
+			end++
+		}
+		if end == len(lines) {
+			return lines
+		}
+		end++
+	} else {
+		for end < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[end]), prefix) {
+			end++
+		}
+	}
+
+	header := strings.ToLower(strings.Join(lines[start:end], "\n"))
+	if !strings.Contains(header, "license") && !strings.Contains(header, "copyright") {
+		return lines
+	}
+	return append(append([]string{}, lines[:start]...), lines[end:]...)
+}
+
+// importSpan reports how many lines, starting at lines[i], form an import
+// statement, or 0 when lines[i] does not start one.
+type importSpan func(lines []string, i int) int
+
+// importRules holds the import syntax of each language, keyed by
+// scanner.LanguageForFile name. Only statements starting in column 0 are
+// matched, which leaves nested requires and dynamic imports alone.
+var importRules = map[string]importSpan{
+	"go":         goImport,
+	"python":     pythonImport,
+	"javascript": jsImport,
+	"typescript": jsImport,
+	"tsx":        jsImport,
+	"jsx":        jsImport,
+	"java":       prefixImport("import "),
+	"kotlin":     prefixImport("import "),
+	"scala":      prefixImport("import "),
+	"swift":      prefixImport("import "),
+	"c":          prefixImport("#include"),
+	"cpp":        prefixImport("#include"),
+	"ruby":       prefixImport("require ", "require_relative ", "require("),
+	"rust":       statementImport("use ", "pub use ", "extern crate "),
+	"csharp":     statementImport("using "),
+	"php":        statementImport("use ", "require", "include"),
+}
+
+// stripImports removes the import statements matched by span and folds
+// the blank lines they leave behind.
+func stripImports(lines []string, span importSpan) []string {
+	out := make([]string, 0, len(lines))
+	for i := 0; i < len(lines); {
+		if n := span(lines, i); n > 0 {
+			i += n
+			continue
+		}
+		if lines[i] == "" && len(out) > 0 && out[len(out)-1] == "" {
+			i++
+			continue
+		}
+		out = append(out, lines[i])
+		i++
+	}
+	return out
+}
+
+// linesThrough counts the lines from lines[i] up to and including the
+// first one that satisfies end.
+func linesThrough(lines []string, i int, end func(string) bool) int {
+	for j := i; j < len(lines); j++ {
+		if end(lines[j]) {
+			return j - i + 1
+		}
+	}
+	return len(lines) - i
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// prefixImport matches single-line statements starting with any of prefixes.
+func prefixImport(prefixes ...string) importSpan {
+	return func(lines []string, i int) int {
+		if hasAnyPrefix(lines[i], prefixes) {
+			return 1
+		}
+		return 0
+	}
+}
+
+// statementImport matches statements starting with any of prefixes and
+// running to the line that ends in a semicolon.
+func statementImport(prefixes ...string) importSpan {
+	return func(lines []string, i int) int {
+		if !hasAnyPrefix(lines[i], prefixes) {
+			return 0
+		}
+		return linesThrough(lines, i, func(l string) bool {
+			return strings.HasSuffix(strings.TrimSpace(l), ";")
+		})
+	}
+}
+
+func goImport(lines []string, i int) int {
+	l := strings.TrimSpace(lines[i])
+	switch {
+	case !strings.HasPrefix(lines[i], "import"):
+		return 0
+	case l == "import (":
+		return linesThrough(lines, i, func(l string) bool { return strings.TrimSpace(l) == ")" })
+	case strings.HasPrefix(l, "import "):
+		return 1
+	}
+	return 0
+}
+
+func pythonImport(lines []string, i int) int {
+	l := lines[i]
+	if !strings.HasPrefix(l, "import ") && !strings.HasPrefix(l, "from ") {
+		return 0
+	}
+	switch t := strings.TrimSpace(l); {
+	case strings.HasSuffix(t, "("):
+		return linesThrough(lines, i, func(l string) bool { return strings.Contains(l, ")") })
+	case strings.HasSuffix(t, "\\"):
+		return linesThrough(lines, i, func(l string) bool { return !strings.HasSuffix(strings.TrimSpace(l), "\\") })
+	}
+	return 1
+}
+
+func jsImport(lines []string, i int) int {
+	l := lines[i]
+	if strings.HasPrefix(l, "const ") || strings.HasPrefix(l, "var ") || strings.HasPrefix(l, "let ") {
+		if strings.Contains(l, "= require(") {
+			return 1
+		}
+		return 0
+	}
+	if !strings.HasPrefix(l, "import ") && !strings.HasPrefix(l, "import{") {
+		return 0
+	}
+	// import { a,
+	//   b } from "x";
+	if t := strings.TrimSpace(l); strings.HasSuffix(t, "{") || strings.HasSuffix(t, ",") {
+		return linesThrough(lines, i, func(l string) bool {
+			return strings.Contains(l, " from ") || strings.HasPrefix(strings.TrimSpace(l), "from ")
+		})
+	}
+	return 1
+}
@@ -0,0 +1,157 @@
+package memory
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStripBoilerplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		in       string
+		want     string
+	}{
+		{
+			name:     "go license and import block",
+			language: "go",
+			in: `// Copyright 2024 Example Inc.
+// Licensed under the Apache License, Version 2.0.
+
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+import "strings"
+
+func Load() {}`,
+			want: "package config\n\nfunc Load() {}",
+		},
+		{
+			name:     "block comment license",
+			language: "javascript",
+			in: `/*
+ * MIT License
+ */
+import { a,
+  b } from "./x";
+const fs = require("fs");
+import y from "y";
+
+export function run() {}`,
+			want: "export function run() {}",
+		},
+		{
+			name:     "python with shebang",
+			language: "python",
+			in: `#!/usr/bin/env python
+# Copyright (c) 2024
+import os
+from typing import (
+    List,
+)
+
+def main():
+    import json
+    pass`,
+			want: "#!/usr/bin/env python\n\ndef main():\n    import json\n    pass",
+		},
+		{
+			name:     "rust multi-line use",
+			language: "rust",
+			in:       "use std::{\n    io,\n    fs,\n};\nfn main() {}",
+			want:     "fn main() {}",
+		},
+		{
+			name:     "ordinary leading comment is kept",
+			language: "go",
+			in:       "// Load reads the config.\nfunc Load() {}",
+			want:     "// Load reads the config.\nfunc Load() {}",
+		},
+		{
+			name:     "unknown language only loses the license",
+			language: "",
+			in:       "// SPDX-License-Identifier: MIT\nimport x\nrun()",
+			want:     "import x\nrun()",
+		},
+		{
+			name:     "imports only falls back to content",
+			language: "go",
+			in:       "import (\n\t\"fmt\"\n)",
+			want:     "import (\n\t\"fmt\"\n)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripBoilerplate(tt.in, tt.language); got != tt.want {
+				t.Errorf("StripBoilerplate:\ngot:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// boilerplateFixture is the head of a settings loader, dominated by its
+// license and imports, next to an upload handler that shares fewer query
+// words with a smaller body.
+var boilerplateFixture = []Chunk{
+	{ID: "loader", ChunkType: "code", Content: `// Copyright 2024 Example Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at the project root.
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/example/project/internal/storage"
+)
+
+// load reads the settings.
+func load(path string) (settings, error) { return decode(path) }`},
+	{ID: "handler", ChunkType: "code", Content: `package api
+
+// serve checks the upload size, then streams each part of the file to
+// disk so large bodies never sit in memory, and we parse its headers.
+func serve(w writer, file upload) { w.write(file.name) }`},
+}
+
+func TestStripBoilerplate_ImprovesRetrieval(t *testing.T) {
+	ctx := context.Background()
+	query, _ := wordEmbedder{}.Embed(ctx, []string{"load settings file parse yaml"})
+
+	top := func(opts EmbedOptions) string {
+		t.Helper()
+		_, vs := setupVectorTestDB(t)
+		opts.Model = testModel
+		if _, err := EmbedChunks(ctx, wordEmbedder{}, vs, boilerplateFixture, opts); err != nil {
+			t.Fatalf("EmbedChunks: %v", err)
+		}
+		matches, err := vs.SearchChunks(testModel, query[0], 2, 0)
+		if err != nil || len(matches) == 0 {
+			t.Fatalf("SearchChunks: %v (%d matches)", err, len(matches))
+		}
+		return matches[0].ID
+	}
+
+	if got := top(EmbedOptions{}); got != "handler" {
+		t.Fatalf("without preprocessing the boilerplate should bury the loader; top match %q", got)
+	}
+	stripped := EmbedOptions{Text: func(c Chunk) string { return StripBoilerplate(c.Content, "go") }}
+	if got := top(stripped); got != "loader" {
+		t.Errorf("with preprocessing: top match %q, want loader", got)
+	}
+}
@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"sync"
+	"time"
+)
+
+// sqliteTimeLayout matches SQLite's CURRENT_TIMESTAMP format so timestamps
+// written from Go compare correctly against column defaults.
+const sqliteTimeLayout = "2006-01-02 15:04:05"
+
+// Clock abstracts the current time so timestamps, pruning, and decay can be
+// tested deterministically.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the default Clock, backed by time.Now.
+type SystemClock struct{}
+
+// Now returns the current wall-clock time.
+func (SystemClock) Now() time.Time { return time.Now() }
+
+// ManualClock is a Clock that only moves when Set or Advance is called.
+// It is safe for concurrent use.
+type ManualClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+// NewManualClock returns a ManualClock frozen at t.
+func NewManualClock(t time.Time) *ManualClock {
+	return &ManualClock{t: t}
+}
+
+// Now returns the clock's current time.
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+// Set moves the clock to t.
+func (c *ManualClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = t
+}
+
+// Advance moves the clock forward by d.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+// clockNow returns c.Now(), falling back to real time for a nil Clock so
+// zero-value structs keep working.
+func clockNow(c Clock) time.Time {
+	if c == nil {
+		return time.Now()
+	}
+	return c.Now()
+}
@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// gzipMagic prefixes every gzip stream. Chunk text never starts with these
+// bytes, so they mark compressed content without a separate column.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// SetChunkCompression turns gzip compression of chunk content on or off for
+// subsequent writes. Reads handle compressed and plain chunks regardless.
+func (s *Store) SetChunkCompression(enabled bool) {
+	s.compressChunks = enabled
+}
+
+// encodeChunkContent returns the value stored in chunks.content: the gzipped
+// text when compression is on and it saves space, otherwise the text itself.
+func (s *Store) encodeChunkContent(content string) any {
+	if !s.compressChunks || content == "" {
+		return content
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(content)); err != nil {
+		return content
+	}
+	if err := zw.Close(); err != nil {
+		return content
+	}
+	if buf.Len() >= len(content) {
+		return content
+	}
+	return buf.Bytes()
+}
+
+// decodeChunkContent reverses encodeChunkContent.
+func decodeChunkContent(raw []byte) (string, error) {
+	if !bytes.HasPrefix(raw, gzipMagic) {
+		return string(raw), nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return "", fmt.Errorf("store: decompress chunk: %w", err)
+	}
+	defer func() { _ = zr.Close() }()
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		return "", fmt.Errorf("store: decompress chunk: %w", err)
+	}
+	return string(out), nil
+}
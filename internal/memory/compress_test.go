@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStore_ChunkCompression_RoundTrip(t *testing.T) {
+	database, store := setupTestDB(t)
+	fileID, _ := store.UpsertFile(File{Path: "main.go", Language: "go", LastModified: time.Now(), ContentHash: "h"})
+
+	// A plain chunk written before compression was enabled.
+	plainID, _ := store.InsertChunkReturningID(Chunk{FileID: fileID, Content: "package main", StartLine: 1, EndLine: 1, ChunkType: "code"})
+
+	store.SetChunkCompression(true)
+	content := strings.Repeat("func handler(w http.ResponseWriter, r *http.Request) {}\n", 20)
+	zippedID, err := store.InsertChunkReturningID(Chunk{FileID: fileID, Content: content, StartLine: 2, EndLine: 21, ChunkType: "code"})
+	if err != nil {
+		t.Fatalf("InsertChunkReturningID: %v", err)
+	}
+
+	var stored []byte
+	database.Conn().QueryRow(`SELECT content FROM chunks WHERE id = ?`, zippedID).Scan(&stored)
+	if len(stored) >= len(content) || !strings.HasPrefix(string(stored), string(gzipMagic)) {
+		t.Fatalf("expected gzipped content on disk, got %d bytes", len(stored))
+	}
+
+	got, err := store.GetChunkByID(zippedID)
+	if err != nil || got.Content != content {
+		t.Errorf("GetChunkByID: content mismatch (err %v)", err)
+	}
+	plain, _ := store.GetChunkByID(plainID)
+	if plain.Content != "package main" {
+		t.Errorf("plain chunk: got %q", plain.Content)
+	}
+	chunks, _ := store.ListChunksByFileID(fileID)
+	for _, c := range chunks {
+		if c.ID == zippedID && c.Content != content {
+			t.Error("ListChunksByFileID: content mismatch")
+		}
+	}
+}
+
+func TestStore_ChunkCompression_DBSize(t *testing.T) {
+	// Use this repository's Go sources as a realistically sized fixture.
+	var chunks []string
+	_ = filepath.WalkDir("..", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		lines := strings.Split(string(data), "\n")
+		for i := 0; i < len(lines); i += 40 {
+			chunks = append(chunks, strings.Join(lines[i:min(i+40, len(lines))], "\n"))
+		}
+		return nil
+	})
+	if len(chunks) < 100 {
+		t.Skipf("fixture too small: %d chunks", len(chunks))
+	}
+
+	size := func(compress bool) int64 {
+		database, store := setupTestDB(t)
+		store.SetChunkCompression(compress)
+		fileID, _ := store.UpsertFile(File{Path: "fixture.go", Language: "go", LastModified: time.Now(), ContentHash: "h"})
+		for _, c := range chunks {
+			if err := store.InsertChunk(Chunk{FileID: fileID, Content: c, ChunkType: "code"}); err != nil {
+				t.Fatalf("InsertChunk: %v", err)
+			}
+		}
+		if _, err := database.Conn().Exec(`VACUUM`); err != nil {
+			t.Fatalf("VACUUM: %v", err)
+		}
+		var pages, pageSize int64
+		database.Conn().QueryRow(`PRAGMA page_count`).Scan(&pages)
+		database.Conn().QueryRow(`PRAGMA page_size`).Scan(&pageSize)
+		return pages * pageSize
+	}
+
+	before, after := size(false), size(true)
+	t.Logf("%d chunks: %d bytes uncompressed, %d bytes compressed (%.0f%%)",
+		len(chunks), before, after, 100*float64(after)/float64(before))
+	if after >= before {
+		t.Errorf("compressed DB (%d bytes) should be smaller than uncompressed (%d bytes)", after, before)
+	}
+}
@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/memvra/memvra/internal/adapter"
+)
+
+// CachedEmbedder wraps an Embedder with the persistent embedding_cache table.
+// Vectors are keyed on a hash of the model name and input text, so re-indexing
+// unchanged content is served from the database instead of the provider.
+type CachedEmbedder struct {
+	inner adapter.Embedder
+	store *Store
+	model string
+}
+
+// NewCachedEmbedder returns an Embedder that consults the store's embedding
+// cache before calling inner. model identifies the embedding model (e.g.
+// "ollama:nomic-embed-text") so switching models never returns stale vectors.
+func NewCachedEmbedder(inner adapter.Embedder, store *Store, model string) *CachedEmbedder {
+	return &CachedEmbedder{inner: inner, store: store, model: model}
+}
+
+// EmbeddingCacheKey returns the cache key for text embedded with model.
+func EmbeddingCacheKey(model, text string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(model+"\x00"+text)))
+}
+
+// Embed returns cached vectors where available and embeds only the misses.
+// Cache read/write failures are non-fatal: the inner embedder is used instead.
+func (c *CachedEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(texts))
+	for i, t := range texts {
+		keys[i] = EmbeddingCacheKey(c.model, t)
+	}
+	cached, err := c.store.GetCachedEmbeddings(keys)
+	if err != nil {
+		cached = nil
+	}
+
+	results := make([][]float32, len(texts))
+	var missIdx []int
+	var missTexts []string
+	for i, k := range keys {
+		if vec, ok := cached[k]; ok && len(vec) > 0 {
+			results[i] = vec
+			continue
+		}
+		missIdx = append(missIdx, i)
+		missTexts = append(missTexts, texts[i])
+	}
+	if len(missTexts) == 0 {
+		return results, nil
+	}
+
+	vecs, err := c.inner.Embed(ctx, missTexts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vecs) != len(missTexts) {
+		return nil, fmt.Errorf("embed cache: got %d embeddings for %d inputs", len(vecs), len(missTexts))
+	}
+	for j, vec := range vecs {
+		i := missIdx[j]
+		results[i] = vec
+		_ = c.store.PutCachedEmbedding(keys[i], c.model, vec)
+	}
+	return results, nil
+}
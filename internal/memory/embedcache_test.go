@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// countingEmbedder records how many texts it was asked to embed.
+type countingEmbedder struct {
+	calls int
+	texts int
+	err   error
+}
+
+func (c *countingEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	c.calls++
+	c.texts += len(texts)
+	out := make([][]float32, len(texts))
+	for i, t := range texts {
+		out[i] = []float32{float32(len(t)), 1}
+	}
+	return out, nil
+}
+
+func TestCachedEmbedder_SkipsSeenText(t *testing.T) {
+	_, store := setupTestDB(t)
+	inner := &countingEmbedder{}
+	emb := NewCachedEmbedder(inner, store, "test:model")
+
+	first, err := emb.Embed(context.Background(), []string{"alpha", "beta"})
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if inner.texts != 2 {
+		t.Fatalf("expected 2 texts embedded, got %d", inner.texts)
+	}
+
+	// Second pass: one cached, one new. Only the miss reaches the provider,
+	// and results keep the input order.
+	second, err := emb.Embed(context.Background(), []string{"gamma!", "alpha"})
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if inner.texts != 3 {
+		t.Errorf("expected 1 additional text embedded, got %d total", inner.texts)
+	}
+	if second[0][0] != 6 || second[1][0] != first[0][0] {
+		t.Errorf("unexpected vectors: %v", second)
+	}
+
+	// Fully cached batch makes no provider call.
+	calls := inner.calls
+	if _, err := emb.Embed(context.Background(), []string{"alpha", "beta"}); err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if inner.calls != calls {
+		t.Error("fully cached batch should not call the inner embedder")
+	}
+
+	n, _ := store.CountCachedEmbeddings()
+	if n != 3 {
+		t.Errorf("expected 3 cache entries, got %d", n)
+	}
+}
+
+func TestCachedEmbedder_KeyedByModel(t *testing.T) {
+	_, store := setupTestDB(t)
+	inner := &countingEmbedder{}
+
+	_, _ = NewCachedEmbedder(inner, store, "a").Embed(context.Background(), []string{"same"})
+	_, _ = NewCachedEmbedder(inner, store, "b").Embed(context.Background(), []string{"same"})
+
+	if inner.texts != 2 {
+		t.Errorf("different models must not share cache entries; got %d embeds", inner.texts)
+	}
+}
+
+func TestCachedEmbedder_PropagatesErrors(t *testing.T) {
+	_, store := setupTestDB(t)
+	inner := &countingEmbedder{err: errors.New("provider down")}
+	emb := NewCachedEmbedder(inner, store, "m")
+
+	if _, err := emb.Embed(context.Background(), []string{"x"}); err == nil {
+		t.Fatal("expected error from inner embedder")
+	}
+	if n, _ := store.CountCachedEmbeddings(); n != 0 {
+		t.Errorf("failed embeds must not be cached, got %d entries", n)
+	}
+}
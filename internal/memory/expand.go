@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"strings"
+	"unicode"
+)
+
+// expansionGroups lists terms that developers use interchangeably. A query
+// word found in a group pulls in the rest of that group.
+var expansionGroups = [][]string{
+	{"auth", "authentication", "authorization", "login", "logout", "session", "token", "jwt", "oauth", "password", "credentials"},
+	{"db", "database", "sql", "postgres", "postgresql", "mysql", "sqlite", "schema", "migration", "query"},
+	{"config", "configuration", "settings", "env", "environment", "options"},
+	{"test", "tests", "testing", "spec", "specs", "fixture", "mock"},
+	{"api", "endpoint", "endpoints", "route", "routes", "handler", "handlers", "http", "rest"},
+	{"error", "errors", "err", "exception", "failure", "panic"},
+	{"cache", "caching", "redis", "memcached"},
+	{"deploy", "deployment", "release", "ci", "pipeline", "docker"},
+	{"log", "logs", "logging", "logger"},
+	{"queue", "queues", "job", "jobs", "worker", "workers", "background"},
+	{"ui", "frontend", "view", "views", "component", "components", "template"},
+	{"user", "users", "account", "accounts", "profile"},
+}
+
+// expansions maps each term to the other terms of its group.
+var expansions = func() map[string][]string {
+	m := make(map[string][]string)
+	for _, group := range expansionGroups {
+		for _, term := range group {
+			for _, other := range group {
+				if other != term {
+					m[term] = append(m[term], other)
+				}
+			}
+		}
+	}
+	return m
+}()
+
+// ExpandQuery appends terms related to the words of query, so short queries
+// such as "auth" also match text that says "login" or "JWT". The original
+// query comes first; it is returned unchanged when nothing applies.
+func ExpandQuery(query string) string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	seen := make(map[string]bool, len(words))
+	for _, w := range words {
+		seen[w] = true
+	}
+
+	var extra []string
+	for _, w := range words {
+		for _, term := range expansions[w] {
+			if !seen[term] {
+				seen[term] = true
+				extra = append(extra, term)
+			}
+		}
+	}
+	if len(extra) == 0 {
+		return query
+	}
+	return query + " " + strings.Join(extra, " ")
+}
@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestExpandQuery(t *testing.T) {
+	got := ExpandQuery("auth")
+	if !strings.HasPrefix(got, "auth ") {
+		t.Errorf("expanded query should start with the original: %q", got)
+	}
+	for _, want := range []string{"login", "session", "token"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in %q", want, got)
+		}
+	}
+	if got := ExpandQuery("render the sidebar"); got != "render the sidebar" {
+		t.Errorf("query without known terms should be unchanged, got %q", got)
+	}
+	if got := ExpandQuery("auth login"); strings.Count(got, "login") != 1 {
+		t.Errorf("terms already in the query should not repeat: %q", got)
+	}
+}
+
+// wordEmbedder is a bag-of-words embedder: each word sets one hashed
+// dimension, so texts are similar exactly when they share words.
+type wordEmbedder struct{}
+
+func (wordEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		vec := make([]float32, 768)
+		words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, w := range words {
+			h := fnv.New32a()
+			_, _ = h.Write([]byte(w))
+			vec[h.Sum32()%768] = 1
+		}
+		var norm float64
+		for _, v := range vec {
+			norm += float64(v * v)
+		}
+		if norm > 0 {
+			for j := range vec {
+				vec[j] /= float32(math.Sqrt(norm))
+			}
+		}
+		out[i] = vec
+	}
+	return out, nil
+}
+
+// TestOrchestrator_Retrieve_ExpandQueryRecall measures recall for a one-word
+// query over a seeded fixture, with and without expansion.
+func TestOrchestrator_Retrieve_ExpandQueryRecall(t *testing.T) {
+	_, store, vectors := setupOrchestratorDB(t)
+	orch := NewOrchestrator(store, vectors, NewRanker(), wordEmbedder{})
+
+	relevant := []string{
+		"Users log in with a JWT issued by the gateway",
+		"OAuth login is handled by GitHub",
+		"Session cookies expire after 24 hours",
+		"Passwords are hashed with bcrypt",
+	}
+	distractors := []string{
+		"Use PostgreSQL for storage",
+		"Deploy with Docker on Fly",
+		"Prefer table-driven tests",
+		"Cache rendered pages in Redis",
+	}
+	want := make(map[string]bool)
+	for _, content := range append(append([]string{}, relevant...), distractors...) {
+		m, err := orch.Remember(context.Background(), content, TypeDecision, SourceUser)
+		if err != nil {
+			t.Fatalf("Remember: %v", err)
+		}
+		for _, r := range relevant {
+			if r == content {
+				want[m.ID] = true
+			}
+		}
+	}
+
+	recall := func(expand bool) float64 {
+		// A threshold just above the similarity of orthogonal vectors
+		// (1 / (1 + √2) ≈ 0.414) keeps only memories sharing a query word.
+		result, err := orch.Retrieve(context.Background(), "auth", RetrieveOptions{
+			TopKMemories:        len(relevant),
+			SimilarityThreshold: 0.42,
+			ExpandQuery:         expand,
+		})
+		if err != nil {
+			t.Fatalf("Retrieve: %v", err)
+		}
+		var hits int
+		for _, m := range result.Memories {
+			if want[m.ID] {
+				hits++
+			}
+		}
+		return float64(hits) / float64(len(relevant))
+	}
+
+	plain, expanded := recall(false), recall(true)
+	if plain != 0 {
+		t.Errorf("unexpanded recall: got %.2f, want 0", plain)
+	}
+	if expanded < 0.75 {
+		t.Errorf("expanded recall: got %.2f, want at least 0.75", expanded)
+	}
+}
@@ -11,8 +11,9 @@ import (
 
 // extractCandidate is the JSON shape returned by the extraction prompt.
 type extractCandidate struct {
-	Content string `json:"content"`
-	Type    string `json:"type"`
+	Content    string  `json:"content"`
+	Type       string  `json:"type"`
+	Confidence float64 `json:"confidence"`
 }
 
 // ExtractMemories sends the LLM response to the LLM and asks it to identify
@@ -27,12 +28,13 @@ func ExtractMemories(ctx context.Context, llm adapter.LLMAdapter, responseText s
 
 	prompt := fmt.Sprintf(`From the assistant response below, extract any decisions, constraints, or conventions that were explicitly stated or recommended. These are things the team should remember for future sessions.
 
-Return ONLY a compact JSON array. Each element: {"content": "...", "type": "decision|constraint|convention|todo|note"}.
+Return ONLY a compact JSON array. Each element: {"content": "...", "type": "decision|constraint|convention|todo|note", "confidence": 0.0-1.0}.
 - decision: something chosen ("we will use X", "we switched to Y")
 - constraint: a hard rule ("must", "never", "always", "only")
 - convention: a style or pattern guideline
 - todo: a future task or follow-up
 - note: anything else worth remembering
+confidence: how clearly the response states it (1.0 = explicit, 0.5 = implied)
 
 If nothing qualifies, return []. No prose, no markdown — only the JSON array.
 Maximum %d items.
@@ -102,13 +104,20 @@ func parseExtractionJSON(raw string, max int) ([]Memory, error) {
 		out = append(out, Memory{
 			Content:    content,
 			MemoryType: mt,
-			Importance: defaultImportance(mt),
-			Source:     "extracted",
+			Importance: ImportanceFor(DefaultImportance(), mt),
+			Source:     SourceExtracted,
+			Confidence: clampConfidence(c.Confidence),
 		})
 	}
 	return out, nil
 }
 
+// clampConfidence keeps a model-reported confidence within [0, 1]; values
+// the model left out stay 0 (not recorded).
+func clampConfidence(c float64) float64 {
+	return min(max(c, 0), 1)
+}
+
 // SummarizeSession asks the LLM to produce a 2-3 sentence summary of a
 // question/answer exchange. Returns empty string on failure (non-fatal).
 func SummarizeSession(ctx context.Context, llm adapter.LLMAdapter, question, responseText string, maxTokens int) (string, error) {
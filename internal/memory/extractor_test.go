@@ -2,9 +2,11 @@ package memory
 
 import (
 	"context"
+	"slices"
 	"testing"
 
 	"github.com/memvra/memvra/internal/adapter"
+	"github.com/memvra/memvra/internal/config"
 )
 
 func TestParseExtractionJSON_ValidArray(t *testing.T) {
@@ -27,6 +29,21 @@ func TestParseExtractionJSON_ValidArray(t *testing.T) {
 	}
 }
 
+func TestParseExtractionJSON_Confidence(t *testing.T) {
+	raw := `[{"content": "Use Redis", "type": "decision", "confidence": 0.8},
+		{"content": "Maybe cache sessions", "type": "note", "confidence": 3},
+		{"content": "No score", "type": "note"}]`
+	memories, _ := parseExtractionJSON(raw, 5)
+	if len(memories) != 3 {
+		t.Fatalf("expected 3 memories, got %d", len(memories))
+	}
+	for i, want := range []float64{0.8, 1, 0} {
+		if memories[i].Confidence != want {
+			t.Errorf("memory %d confidence: got %v, want %v", i, memories[i].Confidence, want)
+		}
+	}
+}
+
 func TestParseExtractionJSON_WrappedInProse(t *testing.T) {
 	raw := `Here are the extractions:
 [{"content": "Use React", "type": "decision"}]
@@ -190,21 +207,62 @@ func TestValidMemoryType(t *testing.T) {
 	}
 }
 
-func TestDefaultImportance(t *testing.T) {
-	if defaultImportance(TypeDecision) != 0.8 {
-		t.Error("decision importance should be 0.8")
+func TestRegisterType(t *testing.T) {
+	if ValidMemoryType("risk") {
+		t.Fatal("risk should be invalid before it is registered")
+	}
+	if err := RegisterTypes([]string{"risk", "glossary"}); err != nil {
+		t.Fatalf("RegisterTypes: %v", err)
+	}
+	for _, mt := range []MemoryType{"risk", "glossary"} {
+		if !ValidMemoryType(mt) {
+			t.Errorf("expected %q to be valid once registered", mt)
+		}
+		if IsBuiltinType(mt) {
+			t.Errorf("%q should not be a built-in type", mt)
+		}
+	}
+	if ValidMemoryType("hazard") {
+		t.Error("unregistered types should stay invalid")
 	}
-	if defaultImportance(TypeConstraint) != 0.8 {
-		t.Error("constraint importance should be 0.8")
+	if got := AllTypes(); !slices.Equal(got[:len(BuiltinTypes)], BuiltinTypes) {
+		t.Errorf("AllTypes should start with the built-in types, got %v", got)
 	}
-	if defaultImportance(TypeConvention) != 0.7 {
-		t.Error("convention importance should be 0.7")
+	if err := RegisterType(TypeDecision); err != nil {
+		t.Errorf("registering a built-in type should be a no-op, got %v", err)
 	}
-	if defaultImportance(TypeTodo) != 0.6 {
-		t.Error("todo importance should be 0.6")
+	for _, bad := range []MemoryType{"", "Risk", "two words", "1st"} {
+		if err := RegisterType(bad); err == nil {
+			t.Errorf("RegisterType(%q) should fail", bad)
+		}
+	}
+}
+
+func TestMemoryType_Label(t *testing.T) {
+	tests := map[MemoryType]string{
+		TypeDecision: "Decisions",
+		TypeTodo:     "Todos",
+		"risk":       "Risks",
+		"glossary":   "Glossaries",
+		"day":        "Days",
+		"fix":        "Fixes",
+	}
+	for mt, want := range tests {
+		if got := mt.Label(); got != want {
+			t.Errorf("%q.Label() = %q, want %q", mt, got, want)
+		}
+	}
+}
+
+func TestDefaultImportance(t *testing.T) {
+	table := DefaultImportance()
+	for name, want := range config.DefaultImportance() {
+		if got := ImportanceFor(table, MemoryType(name)); got != want {
+			t.Errorf("%s importance = %v, want %v", name, got, want)
+		}
 	}
-	if defaultImportance(TypeNote) != 0.5 {
-		t.Error("note importance should be 0.5")
+	if got := ImportanceFor(table, MemoryType("runbook")); got != table[TypeNote] {
+		t.Errorf("unlisted type should rank as a note: got %v, want %v", got, table[TypeNote])
 	}
 }
 
@@ -0,0 +1,28 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/memvra/memvra/internal/db"
+)
+
+// OpenGlobalStore opens the user-level memory database at path (see
+// config.GlobalDBPath), which holds conventions and constraints shared by
+// every project. Unless create is set, a missing database is not created:
+// OpenGlobalStore then returns a nil Store and no error. Close the returned
+// database when done.
+func OpenGlobalStore(path string, create bool) (*Store, *db.DB, error) {
+	if !create {
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, nil
+		}
+	}
+	database, err := db.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open global memory: %w", err)
+	}
+	return NewStore(database), database, nil
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"fmt"
+	"time"
+)
+
+// LinkType says how one memory relates to another.
+type LinkType string
+
+const (
+	// LinkSupersedes marks the source memory as replacing the target, e.g.
+	// a decision that reverses an earlier one.
+	LinkSupersedes LinkType = "supersedes"
+	// LinkRelatesTo connects memories about the same subject.
+	LinkRelatesTo LinkType = "relates_to"
+)
+
+// ValidLinkType reports whether t is a known link type.
+func ValidLinkType(t LinkType) bool {
+	return t == LinkSupersedes || t == LinkRelatesTo
+}
+
+// MemoryLink is a directed, typed edge between two memories. Links are
+// removed with either memory.
+type MemoryLink struct {
+	FromID    string    `json:"from_id"`
+	ToID      string    `json:"to_id"`
+	Type      LinkType  `json:"link_type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LinkMemories records that fromID relates to toID as t. Linking the same
+// pair twice with the same type is a no-op.
+func (s *Store) LinkMemories(fromID, toID string, t LinkType) error {
+	if err := s.writable("link memories"); err != nil {
+		return err
+	}
+	if !ValidLinkType(t) {
+		return fmt.Errorf("store: link memories: invalid link type %q (valid: %s, %s)", t, LinkSupersedes, LinkRelatesTo)
+	}
+	if fromID == toID {
+		return fmt.Errorf("store: link memories: memory %s cannot link to itself", fromID)
+	}
+	for _, id := range []string{fromID, toID} {
+		if _, err := s.GetMemoryByID(id); err != nil {
+			return fmt.Errorf("store: link memories: %w", err)
+		}
+	}
+	if _, err := s.db.Conn().Exec(
+		`INSERT OR IGNORE INTO memory_links (from_id, to_id, link_type, created_at) VALUES (?, ?, ?, ?)`,
+		fromID, toID, string(t), s.now(),
+	); err != nil {
+		return fmt.Errorf("store: link memories: %w", err)
+	}
+	return nil
+}
+
+// ListMemoryLinks returns every memory link, oldest first.
+func (s *Store) ListMemoryLinks() ([]MemoryLink, error) {
+	rows, err := s.db.Conn().Query(
+		`SELECT from_id, to_id, link_type, created_at FROM memory_links ORDER BY created_at, from_id, to_id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("store: list memory links: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []MemoryLink
+	for rows.Next() {
+		var l MemoryLink
+		var lt, createdAt string
+		if err := rows.Scan(&l.FromID, &l.ToID, &lt, &createdAt); err != nil {
+			return nil, fmt.Errorf("store: list memory links: %w", err)
+		}
+		l.Type = LinkType(lt)
+		l.CreatedAt = parseTime(createdAt)
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
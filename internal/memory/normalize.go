@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultStopPhrases are boilerplate openings that carry no meaning of their
+// own ("We decided to use Postgres" says the same as "Use Postgres").
+var DefaultStopPhrases = []string{
+	"we decided to",
+	"we have decided to",
+	"decided to",
+	"note that",
+	"please note",
+	"remember that",
+	"keep in mind that",
+	"going forward",
+	"from now on",
+}
+
+// Normalizer reduces memory content to a comparison key: lowercased,
+// punctuation trimmed from word edges, whitespace collapsed, and stop
+// phrases removed. It never changes stored content; it is used only to
+// compare memories.
+type Normalizer struct {
+	phrases [][]string // stop phrases split into words, longest first
+}
+
+// NewNormalizer returns a Normalizer that removes phrases. An empty list
+// disables phrase removal, leaving only case and whitespace normalization.
+func NewNormalizer(phrases []string) *Normalizer {
+	n := &Normalizer{}
+	for _, p := range phrases {
+		if words := normalizedWords(p); len(words) > 0 {
+			n.phrases = append(n.phrases, words)
+		}
+	}
+	sort.SliceStable(n.phrases, func(i, j int) bool { return len(n.phrases[i]) > len(n.phrases[j]) })
+	return n
+}
+
+// Normalize returns the comparison key for s.
+func (n *Normalizer) Normalize(s string) string {
+	words := normalizedWords(s)
+	if n == nil || len(n.phrases) == 0 {
+		return strings.Join(words, " ")
+	}
+	out := make([]string, 0, len(words))
+	for i := 0; i < len(words); {
+		if l := n.matchAt(words, i); l > 0 {
+			i += l
+			continue
+		}
+		out = append(out, words[i])
+		i++
+	}
+	return strings.Join(out, " ")
+}
+
+// matchAt returns the length of the stop phrase starting at words[i], or 0.
+func (n *Normalizer) matchAt(words []string, i int) int {
+	for _, p := range n.phrases {
+		if i+len(p) > len(words) {
+			continue
+		}
+		match := true
+		for j, w := range p {
+			if words[i+j] != w {
+				match = false
+				break
+			}
+		}
+		if match {
+			return len(p)
+		}
+	}
+	return 0
+}
+
+// normalizedWords lowercases s and splits it into words with punctuation
+// trimmed from their edges, dropping words that were only punctuation.
+func normalizedWords(s string) []string {
+	var out []string
+	for _, w := range strings.Fields(strings.ToLower(s)) {
+		if w = strings.TrimFunc(w, unicode.IsPunct); w != "" {
+			out = append(out, w)
+		}
+	}
+	return out
+}
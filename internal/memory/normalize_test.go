@@ -0,0 +1,32 @@
+package memory
+
+import "testing"
+
+func TestNormalizer_Normalize(t *testing.T) {
+	n := NewNormalizer(DefaultStopPhrases)
+	cases := []struct{ in, want string }{
+		{"Use PostgreSQL", "use postgresql"},
+		{"  We decided to   use PostgreSQL. ", "use postgresql"},
+		{"Note that: the API is versioned", "the api is versioned"},
+		{"We have decided to drop v1.2", "drop v1.2"},
+		{"Tests decided the outcome", "tests decided the outcome"},
+		{"...", ""},
+	}
+	for _, tc := range cases {
+		if got := n.Normalize(tc.in); got != tc.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestNormalizer_CustomAndEmptyLists(t *testing.T) {
+	de := NewNormalizer([]string{"wir haben entschieden"})
+	if got := de.Normalize("Wir haben entschieden: Postgres nutzen"); got != "postgres nutzen" {
+		t.Errorf("custom phrase: got %q", got)
+	}
+
+	none := NewNormalizer(nil)
+	if got := none.Normalize("We decided to use Go"); got != "we decided to use go" {
+		t.Errorf("empty list should only fold case and spacing, got %q", got)
+	}
+}
@@ -3,9 +3,11 @@ package memory
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/memvra/memvra/internal/adapter"
+	"github.com/memvra/memvra/internal/config"
 )
 
 // Orchestrator coordinates storage, embedding, and retrieval of memories and chunks.
@@ -14,59 +16,375 @@ type Orchestrator struct {
 	vectors  *VectorStore
 	ranker   *Ranker
 	embedder adapter.Embedder
+	// model is the key embeddings are stored and searched under; see SetEmbeddingModel.
+	model string
+	// importance is the per-type importance Remember assigns; see
+	// SetImportanceDefaults.
+	importance map[MemoryType]float64
+	// normalizer compares memory content for duplicates; see SetStopPhrases.
+	normalizer *Normalizer
+	// excludePath reports chunk file paths Retrieve must never return; see
+	// SetExcludedPaths.
+	excludePath func(path string) bool
+	// vectorErr, once set, disables vector search for the orchestrator's
+	// lifetime; see VectorSearchError.
+	vectorErr error
+}
+
+// DimensionMismatchError reports an embedder whose vectors are a different
+// size from those in the index. Searching with them would compare unrelated
+// spaces, so vector search is skipped until the project is re-embedded with
+// `memvra update --reembed`, which recreates the index at the embedder's
+// dimension.
+type DimensionMismatchError struct {
+	Stored   int // dimension of the vectors in the index
+	Embedder int // dimension the configured embedder produces
+}
+
+func (e *DimensionMismatchError) Error() string {
+	return fmt.Sprintf("embedding dimension mismatch: the index stores %d-dimensional vectors but the embedder produces %d; run `memvra update --reembed` to rebuild the index for this embedder, or switch back to a %d-dimensional one (vector search is disabled until then)",
+		e.Stored, e.Embedder, e.Stored)
 }
 
 // NewOrchestrator creates an Orchestrator.
 func NewOrchestrator(store *Store, vectors *VectorStore, ranker *Ranker, embedder adapter.Embedder) *Orchestrator {
 	return &Orchestrator{
-		store:    store,
-		vectors:  vectors,
-		ranker:   ranker,
-		embedder: embedder,
+		store:      store,
+		vectors:    vectors,
+		ranker:     ranker,
+		embedder:   embedder,
+		importance: DefaultImportance(),
 	}
 }
 
+// SetClock sets the clock on the underlying store and ranker so all
+// timestamps and age-based scoring share one time source.
+func (o *Orchestrator) SetClock(c Clock) {
+	if o.store != nil {
+		o.store.SetClock(c)
+	}
+	if o.ranker != nil {
+		o.ranker.SetClock(c)
+	}
+}
+
+// SetImportanceDefaults replaces the per-type importance assigned by
+// Remember, normally with the project's table (see
+// config.ProjectConfig.ImportanceDefaults). A nil m keeps DefaultImportance.
+func (o *Orchestrator) SetImportanceDefaults(m map[MemoryType]float64) {
+	if m != nil {
+		o.importance = m
+	}
+}
+
+// SetStopPhrases sets the phrases ignored when RememberBatch compares
+// memories for duplicates (nil = DefaultStopPhrases, empty = none), as
+// BuildOptions.StopPhrases does for context building.
+func (o *Orchestrator) SetStopPhrases(phrases []string) {
+	if phrases == nil {
+		phrases = DefaultStopPhrases
+	}
+	o.normalizer = NewNormalizer(phrases)
+}
+
+// SetExcludedPaths makes Retrieve drop chunks whose file path (relative to
+// the project root) match reports true, normally the project's
+// exclude_paths. A nil match excludes nothing.
+func (o *Orchestrator) SetExcludedPaths(match func(path string) bool) {
+	o.excludePath = match
+}
+
+// SetEmbeddingModel sets the model key (see config.EmbeddingModelKey) that
+// embeddings are written under and searched within. It should name the
+// model behind the orchestrator's embedder.
+func (o *Orchestrator) SetEmbeddingModel(model string) {
+	o.model = model
+}
+
+// VectorSearchError returns why vector search has been disabled, such as a
+// *DimensionMismatchError, or nil while it is available.
+func (o *Orchestrator) VectorSearchError() error {
+	return o.vectorErr
+}
+
+// CheckDimension compares an embedding produced by the configured embedder
+// against the index and disables vector search on a mismatch. An empty
+// index accepts any dimension.
+func (o *Orchestrator) CheckDimension(vec []float32) error {
+	if o.vectorErr != nil || o.vectors == nil {
+		return o.vectorErr
+	}
+	if stored := o.vectors.Dimension(); stored > 0 && len(vec) != stored {
+		o.vectorErr = &DimensionMismatchError{Stored: stored, Embedder: len(vec)}
+	}
+	return o.vectorErr
+}
+
+// dimensionProbe is the text ProbeDimension embeds.
+const dimensionProbe = "memvra dimension probe"
+
+// ProbeDimension embeds a short probe text to learn the dimension the
+// embedder produces, then checks it against the index as CheckDimension
+// does, so a misconfigured embedder is reported before any search. It
+// returns the dimension (0 when embedding failed) and the embedding error or
+// *DimensionMismatchError, if any.
+func (o *Orchestrator) ProbeDimension(ctx context.Context) (int, error) {
+	if o.embedder == nil {
+		return 0, fmt.Errorf("orchestrator: no embedder configured")
+	}
+	vecs, err := o.embedder.Embed(ctx, []string{dimensionProbe})
+	if err != nil {
+		return 0, fmt.Errorf("orchestrator: probe embedder: %w", err)
+	}
+	if len(vecs) != 1 || len(vecs[0]) == 0 {
+		return 0, fmt.Errorf("orchestrator: probe embedder: empty embedding")
+	}
+	return len(vecs[0]), o.CheckDimension(vecs[0])
+}
+
 // RetrieveOptions controls how many results to pull back.
 type RetrieveOptions struct {
+	// TopKChunks and TopKMemories cap each category; zero skips that
+	// category's search (see LimitToCategory).
 	TopKChunks          int
 	TopKMemories        int
 	SimilarityThreshold float64
+	// ChunkThreshold and MemoryThreshold override SimilarityThreshold for
+	// code chunks and memories respectively (0 = SimilarityThreshold). Code
+	// and prose score differently, so each may need its own cut-off.
+	ChunkThreshold  float64
+	MemoryThreshold float64
+	// Explain fills RetrievalResult.Explanations with per-result scoring.
+	Explain bool
+	// Sources keeps only memories whose Source is listed (e.g. SourceUser
+	// for a "trusted only" view). Empty means every source.
+	Sources []string
+	// ExpandQuery augments the query with related terms (see ExpandQuery)
+	// before embedding, improving recall for short queries.
+	ExpandQuery bool
+	// Exclude drops chunks whose file path or content, and memories whose
+	// content, contains any of these terms (case-insensitive). Filtering runs
+	// after ranking and before results are cut to TopKChunks/TopKMemories.
+	Exclude []string
+	// Language keeps only chunks from files of this language, as recorded
+	// in File.Language at index time (e.g. "go", "typescript"). Empty means
+	// every language. Memories are unaffected.
+	Language string
+	// ContextLines widens each returned chunk by up to this many lines on
+	// either side, taken from neighbouring chunks of the same file (capped
+	// at MaxContextLines). Zero returns chunks as indexed.
+	ContextLines int
+}
+
+// chunkThreshold returns the minimum similarity for code chunks.
+func (opts RetrieveOptions) chunkThreshold() float64 {
+	if opts.ChunkThreshold > 0 {
+		return opts.ChunkThreshold
+	}
+	return opts.SimilarityThreshold
+}
+
+// memoryThreshold returns the minimum similarity for memories.
+func (opts RetrieveOptions) memoryThreshold() float64 {
+	if opts.MemoryThreshold > 0 {
+		return opts.MemoryThreshold
+	}
+	return opts.SimilarityThreshold
+}
+
+// MaxContextLines caps RetrieveOptions.ContextLines.
+const MaxContextLines = 100
+
+// Retrieval categories, for restricting retrieval to one kind of result.
+const (
+	CategoryChunks   = "chunks"
+	CategoryMemories = "memories"
+)
+
+// LimitToCategory zeroes the top-k of the category other than only, so
+// Retrieve skips its search entirely. An empty only keeps both.
+func (opts *RetrieveOptions) LimitToCategory(only string) error {
+	switch only {
+	case "":
+	case CategoryChunks:
+		opts.TopKMemories = 0
+	case CategoryMemories:
+		opts.TopKChunks = 0
+	default:
+		return fmt.Errorf("unknown category %q (valid: %s, %s)", only, CategoryChunks, CategoryMemories)
+	}
+	return nil
 }
 
+// excludeOversample is how many times TopK candidates are fetched when
+// RetrieveOptions.Exclude or excluded paths are set, so excluded results
+// can be replaced.
+const excludeOversample = 3
+
 // RetrievalResult holds ranked results for context building.
 type RetrievalResult struct {
 	Chunks   []Chunk
 	Memories []Memory
+	// Mode tells how the result was produced: ModeSemantic, or
+	// ModeKeyword or ModeFallbackList when vector search could not run,
+	// with Reason saying why.
+	Mode   string
+	Reason string
+	// Explanations maps chunk and memory IDs to their score breakdown.
+	// It is nil unless RetrieveOptions.Explain was set.
+	Explanations map[string]ScoreExplanation
+	// VectorSearchErr is set when vector search was refused (see
+	// Orchestrator.VectorSearchError) and the unranked fallback was used.
+	VectorSearchErr error
+}
+
+// Retrieval modes, reported in RetrievalResult.Mode.
+const (
+	// ModeSemantic results are ranked by vector similarity to the query.
+	ModeSemantic = "semantic"
+	// ModeKeyword results are the memories sharing words with the query,
+	// with no code chunks, because vector search was unavailable.
+	ModeKeyword = "keyword"
+	// ModeFallbackList results list every memory by importance, with no
+	// code chunks, because vector search was unavailable and no memory
+	// matched the query's keywords.
+	ModeFallbackList = "fallback_list"
+)
+
+// keywordStopWords are too common to make a memory relevant on their own.
+var keywordStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "how": true, "what": true,
+	"why": true, "does": true, "this": true, "that": true, "should": true, "from": true,
+}
+
+// fallback is used when vector search cannot run. It returns the memories
+// sharing keywords with query, most matches first, or failing that every
+// memory (subject to opts.Sources), unranked, recording why vector search
+// was skipped.
+func (o *Orchestrator) fallback(query string, opts RetrieveOptions, reason string, vectorErr error) *RetrievalResult {
+	mems, _ := o.store.ListMemories("")
+	mems = FilterBySource(mems, opts.Sources)
+	result := &RetrievalResult{
+		Memories:        mems,
+		Mode:            ModeFallbackList,
+		Reason:          reason,
+		VectorSearchErr: vectorErr,
+	}
+	if matched := keywordMatches(query, mems, opts.TopKMemories); len(matched) > 0 {
+		result.Memories = matched
+		result.Mode = ModeKeyword
+	}
+	return result
+}
+
+// keywordMatches returns the memories sharing at least one keyword (three
+// or more letters, not a stop word) with query, ordered by how many they
+// share and otherwise keeping their order. limit > 0 caps the result.
+func keywordMatches(query string, mems []Memory, limit int) []Memory {
+	keywords := make(map[string]bool)
+	for _, w := range normalizedWords(query) {
+		if len(w) >= 3 && !keywordStopWords[w] {
+			keywords[w] = true
+		}
+	}
+	if len(keywords) == 0 {
+		return nil
+	}
+
+	type hit struct {
+		mem   Memory
+		count int
+	}
+	var hits []hit
+	for _, m := range mems {
+		seen := make(map[string]bool)
+		for _, w := range normalizedWords(m.Content) {
+			if keywords[w] {
+				seen[w] = true
+			}
+		}
+		if len(seen) > 0 {
+			hits = append(hits, hit{m, len(seen)})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].count > hits[j].count })
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	out := make([]Memory, len(hits))
+	for i, h := range hits {
+		out[i] = h.mem
+	}
+	return out
+}
+
+// FallbackWarning describes a ModeKeyword or ModeFallbackList result for
+// users, or returns "" when the result is semantic.
+func (r *RetrievalResult) FallbackWarning() string {
+	switch r.Mode {
+	case ModeKeyword:
+		return fmt.Sprintf("semantic search unavailable (%s); showing memories matching the query's keywords instead", r.Reason)
+	case ModeFallbackList:
+		return fmt.Sprintf("semantic search unavailable (%s); showing memories by importance instead", r.Reason)
+	}
+	return ""
 }
 
 // Retrieve embeds the query and returns ranked chunks and memories.
 func (o *Orchestrator) Retrieve(ctx context.Context, query string, opts RetrieveOptions) (*RetrievalResult, error) {
 	// No embedder configured — fall back to listing all memories without ranking.
 	if o.embedder == nil {
-		mems, _ := o.store.ListMemories("")
-		return &RetrievalResult{Memories: mems}, nil
+		return o.fallback(query, opts, "no embedder configured", nil), nil
+	}
+
+	// Don't pay for an embedding that can't be searched.
+	if o.vectorErr != nil {
+		return o.fallback(query, opts, o.vectorErr.Error(), o.vectorErr), nil
 	}
 
 	// Embed the query.
+	if opts.ExpandQuery {
+		query = ExpandQuery(query)
+	}
 	vecs, err := o.embedder.Embed(ctx, []string{query})
-	if err != nil || len(vecs) == 0 {
+	if err != nil {
 		// Graceful degradation: no embeddings available — fall back to all memories.
-		mems, _ := o.store.ListMemories("")
-		return &RetrievalResult{Memories: mems}, nil
+		return o.fallback(query, opts, fmt.Sprintf("embedding the query failed: %v", err), nil), nil
+	}
+	if len(vecs) == 0 {
+		return o.fallback(query, opts, "embedder returned no vector for the query", nil), nil
 	}
 	queryVec := vecs[0]
+	if err := o.CheckDimension(queryVec); err != nil {
+		return o.fallback(query, opts, err.Error(), err), nil
+	}
 
-	// Vector search for chunks.
-	chunkMatches, _ := o.vectors.SearchChunks(queryVec, opts.TopKChunks, opts.SimilarityThreshold)
+	// Fetch extra candidates when some may be excluded after ranking.
+	chunkK, memK := opts.TopKChunks, opts.TopKMemories
+	excludingChunks := len(opts.Exclude) > 0 || o.excludePath != nil
+	if excludingChunks {
+		chunkK *= excludeOversample
+	}
+	if len(opts.Exclude) > 0 {
+		memK *= excludeOversample
+	}
 
-	// Vector search for memories.
-	memMatches, _ := o.vectors.SearchMemories(queryVec, opts.TopKMemories, opts.SimilarityThreshold)
+	// Vector search for each category with a non-zero top-k.
+	var chunkMatches, memMatches []VectorMatch
+	if chunkK > 0 && opts.Language != "" {
+		chunkMatches, _ = o.vectors.SearchChunksInLanguage(o.model, queryVec, chunkK, opts.chunkThreshold(), opts.Language)
+	} else if chunkK > 0 {
+		chunkMatches, _ = o.vectors.SearchChunks(o.model, queryVec, chunkK, opts.chunkThreshold())
+	}
+	if memK > 0 {
+		memMatches, _ = o.vectors.SearchMemories(o.model, queryVec, memK, opts.memoryThreshold())
+	}
 
 	// Fetch full chunk records and build similarity map.
 	chunkSimMap := make(map[string]float64, len(chunkMatches))
 	chunks := make([]Chunk, 0, len(chunkMatches))
 	for _, m := range chunkMatches {
-		sim := 1.0 / (1.0 + m.Distance)
+		sim := DistanceToSimilarity(m.Distance)
 		chunkSimMap[m.ID] = sim
 		c, err := o.store.GetChunkByID(m.ID)
 		if err != nil {
@@ -79,7 +397,7 @@ func (o *Orchestrator) Retrieve(ctx context.Context, query string, opts Retrieve
 	memSimMap := make(map[string]float64, len(memMatches))
 	memories := make([]Memory, 0, len(memMatches))
 	for _, m := range memMatches {
-		sim := 1.0 / (1.0 + m.Distance)
+		sim := DistanceToSimilarity(m.Distance)
 		memSimMap[m.ID] = sim
 		mem, err := o.store.GetMemoryByID(m.ID)
 		if err != nil {
@@ -87,39 +405,209 @@ func (o *Orchestrator) Retrieve(ctx context.Context, query string, opts Retrieve
 		}
 		memories = append(memories, mem)
 	}
+	memories = FilterBySource(memories, opts.Sources)
+
+	// Resolve chunk file paths when path boosts or exclusions need them.
+	var paths map[string]string
+	if o.ranker.HasPathBoosts() || excludingChunks {
+		paths = o.chunkPaths(chunks)
+	}
 
 	// Rank results.
-	rankedChunks := o.ranker.RankChunks(chunks, chunkSimMap)
+	rankedChunks := o.ranker.RankChunks(chunks, chunkSimMap, paths)
 	rankedMems := o.ranker.RankMemories(memories, memSimMap)
+	if excludingChunks {
+		rankedChunks = excludeChunks(rankedChunks, paths, opts.Exclude, o.excludePath, opts.TopKChunks)
+	}
+	if len(opts.Exclude) > 0 {
+		rankedMems = excludeMemories(rankedMems, opts.Exclude, opts.TopKMemories)
+	}
+
+	var explanations map[string]ScoreExplanation
+	if opts.Explain {
+		explanations = make(map[string]ScoreExplanation, len(chunks)+len(memories))
+		for _, c := range chunks {
+			explanations[c.ID] = o.ranker.ExplainChunk(c, chunkSimMap[c.ID], paths[c.FileID])
+		}
+		for _, mem := range memories {
+			explanations[mem.ID] = o.ranker.ExplainMemory(mem, memSimMap[mem.ID])
+		}
+		// Record the raw vector distances behind each similarity.
+		for _, matches := range [][]VectorMatch{chunkMatches, memMatches} {
+			for _, m := range matches {
+				if e, ok := explanations[m.ID]; ok {
+					e.Distance = m.Distance
+					explanations[m.ID] = e
+				}
+			}
+		}
+	}
 
 	// Convert back to plain slices for the caller.
 	outChunks := make([]Chunk, len(rankedChunks))
 	for i, rc := range rankedChunks {
 		outChunks[i] = rc.Chunk
 	}
+	if opts.ContextLines > 0 {
+		o.expandChunks(outChunks, min(opts.ContextLines, MaxContextLines))
+	}
 	outMems := make([]Memory, len(rankedMems))
 	for i, rm := range rankedMems {
 		outMems[i] = rm.Memory
 	}
 
 	return &RetrievalResult{
-		Chunks:   outChunks,
-		Memories: outMems,
+		Chunks:       outChunks,
+		Memories:     outMems,
+		Mode:         ModeSemantic,
+		Explanations: explanations,
 	}, nil
 }
 
-// Remember stores a memory with its embedding.
-func (o *Orchestrator) Remember(ctx context.Context, content string, memType MemoryType, source string) (Memory, error) {
-	if !ValidMemoryType(memType) {
-		return Memory{}, fmt.Errorf("orchestrator: invalid memory type %q", memType)
+// chunkPaths maps the file IDs of chunks to their relative paths.
+func (o *Orchestrator) chunkPaths(chunks []Chunk) map[string]string {
+	fileIDs := make([]string, len(chunks))
+	for i, c := range chunks {
+		fileIDs[i] = c.FileID
+	}
+	files, _ := o.store.GetFilesByIDs(fileIDs)
+	paths := make(map[string]string, len(files))
+	for id, f := range files {
+		paths[id] = f.Path
+	}
+	return paths
+}
+
+// expandChunks widens each of chunks in place by up to n lines on either
+// side, using the indexed chunks of the same file. Expansion stops early at
+// the start of the file or at lines that were never indexed.
+func (o *Orchestrator) expandChunks(chunks []Chunk, n int) {
+	fileLines := make(map[string]map[int]string)
+	for i, c := range chunks {
+		lines, ok := fileLines[c.FileID]
+		if !ok {
+			lines = o.indexedLines(c.FileID)
+			fileLines[c.FileID] = lines
+		}
+
+		start, end := c.StartLine, c.EndLine
+		for start > 1 && c.StartLine-start < n {
+			if _, ok := lines[start-1]; !ok {
+				break
+			}
+			start--
+		}
+		for end-c.EndLine < n {
+			if _, ok := lines[end+1]; !ok {
+				break
+			}
+			end++
+		}
+		if start == c.StartLine && end == c.EndLine {
+			continue
+		}
+
+		parts := make([]string, 0, end-start+1)
+		for l := start; l < c.StartLine; l++ {
+			parts = append(parts, lines[l])
+		}
+		parts = append(parts, c.Content)
+		for l := c.EndLine + 1; l <= end; l++ {
+			parts = append(parts, lines[l])
+		}
+		chunks[i].Content = strings.Join(parts, "\n")
+		chunks[i].StartLine, chunks[i].EndLine = start, end
+	}
+}
+
+// indexedLines maps line numbers to text for every indexed line of a file.
+func (o *Orchestrator) indexedLines(fileID string) map[int]string {
+	chunks, _ := o.store.ListChunksByFileID(fileID)
+	lines := make(map[int]string)
+	for _, c := range chunks {
+		for i, line := range strings.Split(c.Content, "\n") {
+			lines[c.StartLine+i] = line
+		}
 	}
+	return lines
+}
 
-	m := Memory{
+// excludeChunks drops chunks whose file path (from paths) or content
+// contains one of terms, or whose path matches excludePath when it is
+// non-nil, then keeps at most topK.
+func excludeChunks(ranked []RankedChunk, paths map[string]string, terms []string, excludePath func(string) bool, topK int) []RankedChunk {
+	kept := ranked[:0]
+	for _, rc := range ranked {
+		path := paths[rc.Chunk.FileID]
+		if containsAny(path, terms) || containsAny(rc.Chunk.Content, terms) || (excludePath != nil && excludePath(path)) {
+			continue
+		}
+		kept = append(kept, rc)
+	}
+	if topK > 0 && len(kept) > topK {
+		kept = kept[:topK]
+	}
+	return kept
+}
+
+// excludeMemories drops memories whose content contains one of terms, then
+// keeps at most topK.
+func excludeMemories(ranked []RankedMemory, terms []string, topK int) []RankedMemory {
+	kept := ranked[:0]
+	for _, rm := range ranked {
+		if !containsAny(rm.Memory.Content, terms) {
+			kept = append(kept, rm)
+		}
+	}
+	if topK > 0 && len(kept) > topK {
+		kept = kept[:topK]
+	}
+	return kept
+}
+
+// containsAny reports whether s contains any of terms, ignoring case.
+func containsAny(s string, terms []string) bool {
+	lower := strings.ToLower(s)
+	for _, t := range terms {
+		if t != "" && strings.Contains(lower, strings.ToLower(t)) {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitExclusions separates exclusion terms written as "-term" from query,
+// returning the remaining query and the terms without their dashes. Words
+// made only of dashes are kept as part of the query.
+func SplitExclusions(query string) (string, []string) {
+	var words, exclude []string
+	for _, w := range strings.Fields(query) {
+		if term := strings.TrimLeft(w, "-"); term != w && term != "" {
+			exclude = append(exclude, term)
+			continue
+		}
+		words = append(words, w)
+	}
+	return strings.Join(words, " "), exclude
+}
+
+// Remember stores a memory with its embedding.
+func (o *Orchestrator) Remember(ctx context.Context, content string, memType MemoryType, source string) (Memory, error) {
+	return o.RememberMemory(ctx, Memory{
 		Content:    content,
 		MemoryType: memType,
-		Importance: defaultImportance(memType),
 		Source:     source,
+	})
+}
+
+// RememberMemory stores m with its embedding, keeping provenance fields such
+// as SourceSessionID and Confidence. Importance is assigned from the type, as
+// in Remember.
+func (o *Orchestrator) RememberMemory(ctx context.Context, m Memory) (Memory, error) {
+	if !ValidMemoryType(m.MemoryType) {
+		return Memory{}, fmt.Errorf("orchestrator: invalid memory type %q", m.MemoryType)
 	}
+	m.Importance = ImportanceFor(o.importance, m.MemoryType)
 
 	id, err := o.store.InsertMemory(m)
 	if err != nil {
@@ -129,15 +617,189 @@ func (o *Orchestrator) Remember(ctx context.Context, content string, memType Mem
 
 	// Generate and store embedding (best-effort — non-fatal on failure).
 	if o.embedder != nil {
-		vecs, err := o.embedder.Embed(ctx, []string{content})
+		vecs, err := o.embedder.Embed(ctx, []string{m.Content})
 		if err == nil && len(vecs) > 0 {
-			_ = o.vectors.UpsertMemoryEmbedding(id, vecs[0])
+			_ = o.vectors.UpsertMemoryEmbedding(o.model, id, vecs[0])
 		}
 	}
 
 	return m, nil
 }
 
+// RememberItem is one memory to store with RememberBatch.
+type RememberItem struct {
+	Content    string
+	MemoryType MemoryType
+	Source     string // "" = SourceUser
+}
+
+// Outcomes of a RememberBatch item, reported in RememberResult.Status.
+const (
+	RememberCreated = "created" // stored as a new memory
+	RememberMerged  = "merged"  // same type and content as an existing memory or an earlier item
+	RememberFailed  = "error"   // not stored; see RememberResult.Err
+)
+
+// RememberResult is the outcome of one RememberBatch item. Memory is the
+// stored memory, or for a merged item the memory it matched.
+type RememberResult struct {
+	Memory Memory
+	Status string
+	Err    error
+}
+
+// RememberBatch stores items like RememberMemory, but embeds every new
+// memory in one Embed call and inserts the memories and their embeddings in
+// one transaction. Items are validated first: one with an unknown type or no
+// content fails on its own without affecting the rest. An item whose content
+// matches a memory of the same type (ignoring case, punctuation and stop
+// phrases, see SetStopPhrases) is merged into it rather than stored again.
+// Results are in item order.
+//
+// Unlike RememberMemory, embedding is not best-effort: if it fails, or
+// storing any memory or embedding fails, the error is returned and no item
+// is stored.
+func (o *Orchestrator) RememberBatch(ctx context.Context, items []RememberItem) ([]RememberResult, error) {
+	if err := o.store.writable("remember batch"); err != nil {
+		return nil, fmt.Errorf("orchestrator: %w", err)
+	}
+	normalizer := o.normalizer
+	if normalizer == nil {
+		normalizer = NewNormalizer(DefaultStopPhrases)
+	}
+	keys := make([]string, len(items))
+	for i, item := range items {
+		keys[i] = string(item.MemoryType) + "\x00" + normalizer.Normalize(item.Content)
+	}
+
+	// Embed what looks new before taking the transaction, so a slow
+	// embedder doesn't hold the database.
+	existing, err := o.store.ListMemories("")
+	if err != nil {
+		return nil, fmt.Errorf("orchestrator: remember batch: %w", err)
+	}
+	results, created, _ := o.planBatch(items, keys, existing, normalizer)
+	var vecs map[string][]float32
+	if o.embedder != nil && len(created) > 0 {
+		contents := make([]string, len(created))
+		for n, i := range created {
+			contents[n] = items[i].Content
+		}
+		embedded, err := o.embedder.Embed(ctx, contents)
+		if err != nil {
+			return nil, fmt.Errorf("orchestrator: remember batch: embed: %w", err)
+		}
+		if len(embedded) != len(contents) {
+			return nil, fmt.Errorf("orchestrator: remember batch: got %d embeddings for %d memories", len(embedded), len(contents))
+		}
+		vecs = make(map[string][]float32, len(created))
+		for n, i := range created {
+			vecs[keys[i]] = embedded[n]
+		}
+		if err := o.vectors.ensureTables(dimensionOf(embedded)); err != nil {
+			return nil, fmt.Errorf("orchestrator: remember batch: %w", err)
+		}
+	}
+
+	tx, err := o.store.db.Conn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("orchestrator: remember batch: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Check for duplicates again inside the transaction, so a memory stored
+	// concurrently since the first check is merged rather than repeated.
+	existing, err = o.store.listMemories(tx)
+	if err != nil {
+		return nil, fmt.Errorf("orchestrator: remember batch: %w", err)
+	}
+	results, created, mergedInto := o.planBatch(items, keys, existing, normalizer)
+	var ids []string
+	var embeddings [][]float32
+	for _, i := range created {
+		vec, ok := vecs[keys[i]]
+		if o.embedder != nil && !ok {
+			// It matched a memory that has since been removed.
+			results[i] = RememberResult{Status: RememberFailed, Err: fmt.Errorf("orchestrator: memory changed during the batch; remember it again")}
+			continue
+		}
+		id, err := o.store.insertMemory(tx, results[i].Memory)
+		if err != nil {
+			return nil, fmt.Errorf("orchestrator: remember batch: insert memory: %w", err)
+		}
+		results[i].Memory.ID = id
+		if ok {
+			ids = append(ids, id)
+			embeddings = append(embeddings, vec)
+		}
+	}
+	if err := upsertMemoryEmbeddings(tx, o.model, ids, embeddings); err != nil {
+		return nil, fmt.Errorf("orchestrator: remember batch: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("orchestrator: remember batch: %w", err)
+	}
+
+	for i, j := range mergedInto {
+		if results[j].Status == RememberFailed {
+			results[i] = results[j]
+		} else {
+			results[i].Memory = results[j].Memory
+		}
+	}
+	return results, nil
+}
+
+// planBatch decides the outcome of each RememberBatch item against the
+// existing memories. It returns the results so far, the indexes of items to
+// create, and for each item merged into an earlier one that item's index.
+func (o *Orchestrator) planBatch(items []RememberItem, keys []string, existing []Memory, normalizer *Normalizer) ([]RememberResult, []int, map[int]int) {
+	stored := make(map[string]Memory, len(existing))
+	for _, m := range existing {
+		stored[string(m.MemoryType)+"\x00"+normalizer.Normalize(m.Content)] = m
+	}
+
+	results := make([]RememberResult, len(items))
+	firstItem := make(map[string]int, len(items)) // key -> index of the item creating it
+	mergedInto := make(map[int]int)               // item index -> earlier item index
+	var created []int
+	for i, item := range items {
+		if !ValidMemoryType(item.MemoryType) {
+			results[i] = RememberResult{Status: RememberFailed, Err: fmt.Errorf("orchestrator: invalid memory type %q", item.MemoryType)}
+			continue
+		}
+		if strings.TrimSpace(item.Content) == "" {
+			results[i] = RememberResult{Status: RememberFailed, Err: fmt.Errorf("orchestrator: empty memory content")}
+			continue
+		}
+		if m, ok := stored[keys[i]]; ok {
+			results[i] = RememberResult{Memory: m, Status: RememberMerged}
+			continue
+		}
+		if j, ok := firstItem[keys[i]]; ok {
+			results[i].Status = RememberMerged
+			mergedInto[i] = j
+			continue
+		}
+		firstItem[keys[i]] = i
+		source := item.Source
+		if source == "" {
+			source = SourceUser
+		}
+		results[i] = RememberResult{
+			Memory: Memory{
+				Content:    item.Content,
+				MemoryType: item.MemoryType,
+				Source:     source,
+				Importance: ImportanceFor(o.importance, item.MemoryType),
+			},
+			Status: RememberCreated,
+		}
+		created = append(created, i)
+	}
+	return results, created, mergedInto
+}
+
 // Forget removes a memory by ID (and its vector embedding).
 func (o *Orchestrator) Forget(id string) error {
 	if err := o.store.DeleteMemory(id); err != nil {
@@ -177,15 +839,30 @@ func ClassifyMemoryType(statement string) MemoryType {
 	}
 }
 
-func defaultImportance(t MemoryType) float64 {
-	switch t {
-	case TypeDecision, TypeConstraint:
-		return 0.8
-	case TypeConvention:
-		return 0.7
-	case TypeTodo:
-		return 0.6
-	default:
-		return 0.5
+// ImportanceFor returns the importance for t from table, such as
+// DefaultImportance or a project's ImportanceDefaults. Custom types the
+// table does not list get the importance of a note.
+func ImportanceFor(table map[MemoryType]float64, t MemoryType) float64 {
+	if v, ok := table[t]; ok {
+		return v
+	}
+	return table[TypeNote]
+}
+
+// DefaultImportance returns config.DefaultImportance keyed by MemoryType,
+// the importance of each type when no project overrides it.
+func DefaultImportance() map[MemoryType]float64 {
+	return ParseImportance(config.DefaultImportance())
+}
+
+// ParseImportance converts a type-name → importance map (e.g. from config)
+// to MemoryType keys, dropping unrecognised types.
+func ParseImportance(m map[string]float64) map[MemoryType]float64 {
+	out := make(map[MemoryType]float64, len(m))
+	for name, v := range m {
+		if t := MemoryType(name); ValidMemoryType(t) {
+			out[t] = v
+		}
 	}
+	return out
 }
@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/memvra/memvra/internal/adapter"
+	"github.com/memvra/memvra/internal/config"
 	"github.com/memvra/memvra/internal/db"
 )
 
@@ -67,6 +70,9 @@ func TestOrchestrator_Retrieve_NoEmbedder(t *testing.T) {
 	if len(result.Chunks) != 0 {
 		t.Errorf("expected 0 chunks (no vector search), got %d", len(result.Chunks))
 	}
+	if result.Mode != ModeFallbackList || !strings.Contains(result.Reason, "no embedder") {
+		t.Errorf("mode = %q (%q), want %q with a reason", result.Mode, result.Reason, ModeFallbackList)
+	}
 }
 
 func TestOrchestrator_Retrieve_EmbedError(t *testing.T) {
@@ -88,6 +94,115 @@ func TestOrchestrator_Retrieve_EmbedError(t *testing.T) {
 	if len(result.Memories) != 1 {
 		t.Errorf("expected 1 memory (fallback), got %d", len(result.Memories))
 	}
+	if result.Mode != ModeFallbackList || !strings.Contains(result.Reason, "embed failed") {
+		t.Errorf("mode = %q (%q), want %q citing the embed error", result.Mode, result.Reason, ModeFallbackList)
+	}
+	if w := result.FallbackWarning(); !strings.Contains(w, "semantic search unavailable") {
+		t.Errorf("FallbackWarning() = %q", w)
+	}
+}
+
+func TestOrchestrator_Retrieve_KeywordFallback(t *testing.T) {
+	_, store, vectors := setupOrchestratorDB(t)
+
+	store.InsertMemory(Memory{Content: "use PostgreSQL for the billing database", MemoryType: TypeDecision, Importance: 0.8})
+	store.InsertMemory(Memory{Content: "billing runs nightly", MemoryType: TypeNote, Importance: 0.5})
+	store.InsertMemory(Memory{Content: "always validate input", MemoryType: TypeConstraint, Importance: 0.9})
+
+	orch := NewOrchestrator(store, vectors, NewRanker(), nil)
+	result, err := orch.Retrieve(context.Background(), "which database does billing use?", RetrieveOptions{TopKMemories: 5})
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if result.Mode != ModeKeyword || !strings.Contains(result.Reason, "no embedder") {
+		t.Errorf("mode = %q (%q), want %q with a reason", result.Mode, result.Reason, ModeKeyword)
+	}
+	if len(result.Memories) != 2 || !strings.Contains(result.Memories[0].Content, "PostgreSQL") {
+		t.Errorf("expected the two billing memories, best match first, got %+v", result.Memories)
+	}
+	if w := result.FallbackWarning(); !strings.Contains(w, "keywords") {
+		t.Errorf("FallbackWarning() = %q", w)
+	}
+
+	// TopKMemories caps the matches.
+	capped, _ := orch.Retrieve(context.Background(), "billing database", RetrieveOptions{TopKMemories: 1})
+	if len(capped.Memories) != 1 {
+		t.Errorf("expected 1 memory with TopKMemories 1, got %d", len(capped.Memories))
+	}
+
+	// Only stop words and short words: nothing to match on.
+	listed, _ := orch.Retrieve(context.Background(), "how do I", RetrieveOptions{TopKMemories: 5})
+	if listed.Mode != ModeFallbackList || len(listed.Memories) != 3 {
+		t.Errorf("expected a fallback listing of all 3 memories, got %q with %d", listed.Mode, len(listed.Memories))
+	}
+}
+
+func TestOrchestrator_Retrieve_DimensionMismatch(t *testing.T) {
+	_, store, vectors := setupOrchestratorDB(t)
+
+	memID, _ := store.InsertMemory(Memory{Content: "use Go", MemoryType: TypeDecision, Importance: 0.8})
+	vectors.UpsertMemoryEmbedding("", memID, makeVec(1.0))
+
+	// An embedder producing shorter vectors than the index stores.
+	emb := &stubEmbedder{embeddings: [][]float32{{0.1, 0.2, 0.3}}}
+	orch := NewOrchestrator(store, vectors, NewRanker(), emb)
+
+	result, err := orch.Retrieve(context.Background(), "go", RetrieveOptions{TopKChunks: 10, TopKMemories: 5})
+	if err != nil {
+		t.Fatalf("Retrieve should fall back, not fail: %v", err)
+	}
+	var mismatch *DimensionMismatchError
+	if !errors.As(result.VectorSearchErr, &mismatch) {
+		t.Fatalf("expected DimensionMismatchError, got %v", result.VectorSearchErr)
+	}
+	if mismatch.Stored != vectors.Dimension() || mismatch.Embedder != 3 {
+		t.Errorf("mismatch = %+v", mismatch)
+	}
+	if !strings.Contains(mismatch.Error(), "memvra update --reembed") {
+		t.Errorf("mismatch error should name the fix, got %q", mismatch.Error())
+	}
+	if len(result.Memories) != 1 {
+		t.Errorf("expected fallback to list memories, got %d", len(result.Memories))
+	}
+	if orch.VectorSearchError() == nil {
+		t.Error("vector search should stay disabled after a mismatch")
+	}
+
+	// A matching embedder is accepted.
+	ok := NewOrchestrator(store, vectors, NewRanker(), &stubEmbedder{embeddings: [][]float32{makeVec(1.0)}})
+	if err := ok.CheckDimension(makeVec(1.0)); err != nil {
+		t.Errorf("matching dimension rejected: %v", err)
+	}
+}
+
+func TestOrchestrator_ProbeDimension(t *testing.T) {
+	_, store, vectors := setupOrchestratorDB(t)
+	ctx := context.Background()
+
+	memID, _ := store.InsertMemory(Memory{Content: "use Go", MemoryType: TypeDecision, Importance: 0.8})
+	vectors.UpsertMemoryEmbedding("", memID, makeVec(1.0))
+
+	match := NewOrchestrator(store, vectors, nil, &stubEmbedder{embeddings: [][]float32{makeVec(1.0)}})
+	if dim, err := match.ProbeDimension(ctx); err != nil || dim != vectors.Dimension() {
+		t.Errorf("matching embedder: ProbeDimension = %d, %v", dim, err)
+	}
+
+	short := NewOrchestrator(store, vectors, nil, &stubEmbedder{embeddings: [][]float32{{0.1, 0.2, 0.3}}})
+	dim, err := short.ProbeDimension(ctx)
+	var mismatch *DimensionMismatchError
+	if dim != 3 || !errors.As(err, &mismatch) {
+		t.Fatalf("short embedder: ProbeDimension = %d, %v; want 3 and a DimensionMismatchError", dim, err)
+	}
+	if short.VectorSearchError() == nil {
+		t.Error("vector search should be disabled after a failed probe")
+	}
+
+	if _, err := NewOrchestrator(store, vectors, nil, &stubEmbedder{err: errors.New("down")}).ProbeDimension(ctx); err == nil {
+		t.Error("expected embed error")
+	}
+	if _, err := NewOrchestrator(store, vectors, nil, nil).ProbeDimension(ctx); err == nil {
+		t.Error("expected error without an embedder")
+	}
 }
 
 func TestOrchestrator_Retrieve_WithEmbedder(t *testing.T) {
@@ -104,8 +219,8 @@ func TestOrchestrator_Retrieve_WithEmbedder(t *testing.T) {
 
 	// Store embeddings for both.
 	vec := makeVec(1.0)
-	vectors.UpsertChunkEmbedding(chunkID, vec)
-	vectors.UpsertMemoryEmbedding(memID, vec)
+	vectors.UpsertChunkEmbedding("", chunkID, vec)
+	vectors.UpsertMemoryEmbedding("", memID, vec)
 
 	// Embedder returns a similar vector.
 	emb := &stubEmbedder{embeddings: [][]float32{makeVec(1.1)}}
@@ -128,6 +243,257 @@ func TestOrchestrator_Retrieve_WithEmbedder(t *testing.T) {
 	if len(result.Chunks) > 0 && result.Chunks[0].Content != "func main() {}" {
 		t.Errorf("unexpected chunk content: %q", result.Chunks[0].Content)
 	}
+	if result.Mode != ModeSemantic || result.FallbackWarning() != "" {
+		t.Errorf("mode = %q, want %q with no warning", result.Mode, ModeSemantic)
+	}
+}
+
+func TestOrchestrator_Retrieve_OnlyCategory(t *testing.T) {
+	_, store, vectors := setupOrchestratorDB(t)
+
+	fileID, _ := store.UpsertFile(File{Path: "main.go", Language: "go", LastModified: time.Now(), ContentHash: "h1"})
+	chunkID, _ := store.InsertChunkReturningID(Chunk{
+		FileID: fileID, Content: "func main() {}", StartLine: 1, EndLine: 1, ChunkType: "code",
+	})
+	memID, _ := store.InsertMemory(Memory{Content: "use Go", MemoryType: TypeDecision, Importance: 0.8})
+	vec := makeVec(1.0)
+	vectors.UpsertChunkEmbedding("", chunkID, vec)
+	vectors.UpsertMemoryEmbedding("", memID, vec)
+
+	orch := NewOrchestrator(store, vectors, NewRanker(), &stubEmbedder{embeddings: [][]float32{makeVec(1.1)}})
+
+	for _, tc := range []struct {
+		only             string
+		chunks, memories int
+	}{
+		{"", 1, 1},
+		{CategoryChunks, 1, 0},
+		{CategoryMemories, 0, 1},
+	} {
+		opts := RetrieveOptions{TopKChunks: 10, TopKMemories: 5}
+		if err := opts.LimitToCategory(tc.only); err != nil {
+			t.Fatalf("LimitToCategory(%q): %v", tc.only, err)
+		}
+		result, err := orch.Retrieve(context.Background(), "main", opts)
+		if err != nil {
+			t.Fatalf("Retrieve(only=%q): %v", tc.only, err)
+		}
+		if len(result.Chunks) != tc.chunks || len(result.Memories) != tc.memories {
+			t.Errorf("only=%q: got %d chunks, %d memories; want %d, %d",
+				tc.only, len(result.Chunks), len(result.Memories), tc.chunks, tc.memories)
+		}
+	}
+
+	opts := RetrieveOptions{}
+	if err := opts.LimitToCategory("sessions"); err == nil {
+		t.Error("LimitToCategory accepted an unknown category")
+	}
+}
+
+func TestOrchestrator_Retrieve_PerCategoryThreshold(t *testing.T) {
+	_, store, vectors := setupOrchestratorDB(t)
+
+	fileID, _ := store.UpsertFile(File{Path: "main.go", Language: "go", LastModified: time.Now(), ContentHash: "h1"})
+	chunkID, _ := store.InsertChunkReturningID(Chunk{
+		FileID: fileID, Content: "func main() {}", StartLine: 1, EndLine: 1, ChunkType: "code",
+	})
+	memID, _ := store.InsertMemory(Memory{Content: "use Go", MemoryType: TypeDecision, Importance: 0.8})
+	// Chunk and memory sit at the same distance from the query.
+	vec := makeVec(1.0)
+	vectors.UpsertChunkEmbedding("", chunkID, vec)
+	vectors.UpsertMemoryEmbedding("", memID, vec)
+
+	orch := NewOrchestrator(store, vectors, NewRanker(), &stubEmbedder{embeddings: [][]float32{makeVec(1.1)}})
+
+	for _, tc := range []struct {
+		name             string
+		opts             RetrieveOptions
+		chunks, memories int
+	}{
+		{"memory passes, chunk filtered", RetrieveOptions{ChunkThreshold: 0.5, MemoryThreshold: 0.2}, 0, 1},
+		{"chunk passes, memory filtered", RetrieveOptions{ChunkThreshold: 0.2, MemoryThreshold: 0.5}, 1, 0},
+		{"shared fallback", RetrieveOptions{SimilarityThreshold: 0.5, MemoryThreshold: 0.2}, 0, 1},
+	} {
+		tc.opts.TopKChunks, tc.opts.TopKMemories = 10, 5
+		result, err := orch.Retrieve(context.Background(), "main", tc.opts)
+		if err != nil {
+			t.Fatalf("%s: Retrieve: %v", tc.name, err)
+		}
+		if len(result.Chunks) != tc.chunks || len(result.Memories) != tc.memories {
+			t.Errorf("%s: got %d chunks, %d memories; want %d, %d",
+				tc.name, len(result.Chunks), len(result.Memories), tc.chunks, tc.memories)
+		}
+	}
+}
+
+func TestOrchestrator_Retrieve_Language(t *testing.T) {
+	_, store, vectors := setupOrchestratorDB(t)
+
+	// The JS chunk is the closer match, so an unfiltered top-1 would pick it.
+	for _, f := range []struct {
+		path, lang, content string
+		base                float32
+	}{
+		{"router.go", "go", "func NewRouter() {}", 1.5},
+		{"router.js", "javascript", "function router() {}", 1.1},
+	} {
+		fileID, _ := store.UpsertFile(File{Path: f.path, Language: f.lang, LastModified: time.Now(), ContentHash: f.path})
+		chunkID, _ := store.InsertChunkReturningID(Chunk{FileID: fileID, Content: f.content, StartLine: 1, EndLine: 1, ChunkType: "code"})
+		vectors.UpsertChunkEmbedding("", chunkID, makeVec(f.base))
+	}
+
+	orch := NewOrchestrator(store, vectors, NewRanker(), &stubEmbedder{embeddings: [][]float32{makeVec(1.0)}})
+
+	result, err := orch.Retrieve(context.Background(), "routing", RetrieveOptions{TopKChunks: 1, Language: "Go"})
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if len(result.Chunks) != 1 || result.Chunks[0].Content != "func NewRouter() {}" {
+		t.Fatalf("Language=Go: got %+v, want only the Go chunk", result.Chunks)
+	}
+
+	result, _ = orch.Retrieve(context.Background(), "routing", RetrieveOptions{TopKChunks: 5, Language: "javascript"})
+	if len(result.Chunks) != 1 || result.Chunks[0].Content != "function router() {}" {
+		t.Errorf("Language=javascript: got %+v, want only the JS chunk", result.Chunks)
+	}
+}
+
+func TestOrchestrator_Retrieve_ContextLines(t *testing.T) {
+	_, store, vectors := setupOrchestratorDB(t)
+
+	fileID, _ := store.UpsertFile(File{Path: "main.go", Language: "go", LastModified: time.Now(), ContentHash: "h1"})
+	store.InsertChunkReturningID(Chunk{FileID: fileID, Content: "package main\n\nimport \"fmt\"", StartLine: 1, EndLine: 3, ChunkType: "code"})
+	hitID, _ := store.InsertChunkReturningID(Chunk{FileID: fileID, Content: "func main() {\n\tfmt.Println(1)\n}", StartLine: 4, EndLine: 6, ChunkType: "code"})
+	store.InsertChunkReturningID(Chunk{FileID: fileID, Content: "\nfunc helper() {}", StartLine: 7, EndLine: 8, ChunkType: "code"})
+	vectors.UpsertChunkEmbedding("", hitID, makeVec(1.0))
+
+	orch := NewOrchestrator(store, vectors, NewRanker(), &stubEmbedder{embeddings: [][]float32{makeVec(1.0)}})
+
+	result, err := orch.Retrieve(context.Background(), "main", RetrieveOptions{TopKChunks: 1, ContextLines: 2})
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if len(result.Chunks) != 1 {
+		t.Fatalf("expected 1 chunk, got %d", len(result.Chunks))
+	}
+	c := result.Chunks[0]
+	want := "\nimport \"fmt\"\nfunc main() {\n\tfmt.Println(1)\n}\n\nfunc helper() {}"
+	if c.StartLine != 2 || c.EndLine != 8 || c.Content != want {
+		t.Errorf("expanded chunk = lines %d-%d %q, want lines 2-8 %q", c.StartLine, c.EndLine, c.Content, want)
+	}
+
+	// Without ContextLines the chunk is returned as indexed.
+	result, _ = orch.Retrieve(context.Background(), "main", RetrieveOptions{TopKChunks: 1})
+	if c := result.Chunks[0]; c.StartLine != 4 || c.EndLine != 6 {
+		t.Errorf("unexpanded chunk = lines %d-%d, want 4-6", c.StartLine, c.EndLine)
+	}
+}
+
+func TestOrchestrator_Retrieve_FiltersBySource(t *testing.T) {
+	_, store, vectors := setupOrchestratorDB(t)
+
+	vec := makeVec(1.0)
+	userID, _ := store.InsertMemory(Memory{Content: "use Go", MemoryType: TypeDecision, Importance: 0.8, Source: SourceUser})
+	aiID, _ := store.InsertMemory(Memory{Content: "probably uses Go", MemoryType: TypeNote, Importance: 0.5, Source: SourceExtracted})
+	vectors.UpsertMemoryEmbedding("", userID, vec)
+	vectors.UpsertMemoryEmbedding("", aiID, vec)
+
+	for name, emb := range map[string]*stubEmbedder{
+		"vector":   {embeddings: [][]float32{makeVec(1.1)}},
+		"fallback": {err: errors.New("embed failed")},
+	} {
+		orch := NewOrchestrator(store, vectors, NewRanker(), emb)
+		result, err := orch.Retrieve(context.Background(), "language", RetrieveOptions{
+			TopKMemories: 5,
+			Sources:      []string{SourceUser},
+		})
+		if err != nil {
+			t.Fatalf("%s: Retrieve: %v", name, err)
+		}
+		if len(result.Memories) != 1 || result.Memories[0].ID != userID {
+			t.Errorf("%s: expected only the user memory, got %+v", name, result.Memories)
+		}
+	}
+}
+
+func TestOrchestrator_Retrieve_UsesEmbeddingModel(t *testing.T) {
+	_, store, vectors := setupOrchestratorDB(t)
+
+	fileID, _ := store.UpsertFile(File{Path: "main.go", Language: "go", LastModified: time.Now(), ContentHash: "h1"})
+	chunkID, _ := store.InsertChunkReturningID(Chunk{
+		FileID: fileID, Content: "func main() {}", StartLine: 1, EndLine: 1, ChunkType: "code",
+	})
+	vectors.UpsertChunkEmbedding("ollama:nomic-embed-text", chunkID, makeVec(1.0))
+
+	emb := &stubEmbedder{embeddings: [][]float32{makeVec(1.0)}}
+	orch := NewOrchestrator(store, vectors, NewRanker(), emb)
+	orch.SetEmbeddingModel("openai:text-embedding-3-small")
+	opts := RetrieveOptions{TopKChunks: 10, TopKMemories: 5}
+
+	result, _ := orch.Retrieve(context.Background(), "main", opts)
+	if len(result.Chunks) != 0 {
+		t.Errorf("vectors from another model should not be searched, got %d chunks", len(result.Chunks))
+	}
+
+	// Remember writes under the active model.
+	mem, _ := orch.Remember(context.Background(), "use Go", TypeDecision, "user")
+	if matches, _ := vectors.SearchMemories("openai:text-embedding-3-small", makeVec(1.0), 10, 0.0); len(matches) != 1 || matches[0].ID != mem.ID {
+		t.Errorf("expected memory under the active model, got %+v", matches)
+	}
+
+	// Switching back finds the original vectors again.
+	orch.SetEmbeddingModel("ollama:nomic-embed-text")
+	result, _ = orch.Retrieve(context.Background(), "main", opts)
+	if len(result.Chunks) != 1 {
+		t.Errorf("expected 1 chunk after switching back, got %d", len(result.Chunks))
+	}
+}
+
+func TestOrchestrator_Retrieve_Explain(t *testing.T) {
+	_, store, vectors := setupOrchestratorDB(t)
+
+	fileID, _ := store.UpsertFile(File{Path: "main.go", Language: "go", LastModified: time.Now(), ContentHash: "h1"})
+	chunkID, _ := store.InsertChunkReturningID(Chunk{
+		FileID: fileID, Content: "func main() {}", StartLine: 1, EndLine: 1, ChunkType: "code",
+	})
+	memID, _ := store.InsertMemory(Memory{Content: "use Go", MemoryType: TypeDecision, Importance: 0.8})
+	vectors.UpsertChunkEmbedding("", chunkID, makeVec(1.0))
+	vectors.UpsertMemoryEmbedding("", memID, makeVec(1.0))
+
+	emb := &stubEmbedder{embeddings: [][]float32{makeVec(1.1)}}
+	orch := NewOrchestrator(store, vectors, NewRanker(), emb)
+	opts := RetrieveOptions{TopKChunks: 10, TopKMemories: 5}
+
+	plain, _ := orch.Retrieve(context.Background(), "main", opts)
+	if plain.Explanations != nil {
+		t.Error("explanations should be nil when Explain is off")
+	}
+
+	opts.Explain = true
+	result, err := orch.Retrieve(context.Background(), "main", opts)
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	mem, ok := result.Explanations[memID]
+	if !ok {
+		t.Fatal("missing explanation for memory")
+	}
+	if mem.Distance <= 0 {
+		t.Errorf("expected raw distance to be recorded, got %f", mem.Distance)
+	}
+	wantSim := 1.0 / (1.0 + mem.Distance)
+	if mem.Similarity != wantSim {
+		t.Errorf("similarity: got %f, want %f", mem.Similarity, wantSim)
+	}
+	if len(mem.Adjustments) != 1 || mem.Adjustments[0].Name != "importance" || mem.Adjustments[0].Factor != 0.8 {
+		t.Errorf("unexpected adjustments: %+v", mem.Adjustments)
+	}
+	if mem.FinalScore != wantSim*0.8 {
+		t.Errorf("final score: got %f, want %f", mem.FinalScore, wantSim*0.8)
+	}
+	if _, ok := result.Explanations[chunkID]; !ok {
+		t.Error("missing explanation for chunk")
+	}
 }
 
 // --- Remember tests ---
@@ -163,6 +529,26 @@ func TestOrchestrator_Remember_StoresMemory(t *testing.T) {
 	}
 }
 
+func TestOrchestrator_Remember_ImportanceOverrides(t *testing.T) {
+	_, store, vectors := setupOrchestratorDB(t)
+	orch := NewOrchestrator(store, vectors, NewRanker(), nil)
+	pcfg := config.ProjectConfig{Importance: map[string]float64{"todo": 0.95, "bogus": 1}}
+	orch.SetImportanceDefaults(ParseImportance(pcfg.ImportanceDefaults()))
+
+	todo, err := orch.Remember(context.Background(), "ship the release", TypeTodo, "user")
+	if err != nil {
+		t.Fatalf("Remember: %v", err)
+	}
+	if todo.Importance != 0.95 {
+		t.Errorf("todo importance: got %f, want 0.95", todo.Importance)
+	}
+
+	note, _ := orch.Remember(context.Background(), "fyi", TypeNote, "user")
+	if want := config.DefaultImportance()["note"]; note.Importance != want {
+		t.Errorf("note importance should keep default %v, got %f", want, note.Importance)
+	}
+}
+
 func TestOrchestrator_Remember_WithEmbedding(t *testing.T) {
 	_, store, vectors := setupOrchestratorDB(t)
 
@@ -176,7 +562,7 @@ func TestOrchestrator_Remember_WithEmbedding(t *testing.T) {
 
 	// The embedding should be searchable.
 	query := makeVec(2.0)
-	matches, _ := vectors.SearchMemories(query, 10, 0.0)
+	matches, _ := vectors.SearchMemories("", query, 10, 0.0)
 	found := false
 	for _, m := range matches {
 		if m.ID == mem.ID {
@@ -198,6 +584,143 @@ func TestOrchestrator_Remember_InvalidType(t *testing.T) {
 	}
 }
 
+func TestOrchestrator_RememberBatch(t *testing.T) {
+	_, store, vectors := setupOrchestratorDB(t)
+	existing, _ := store.InsertMemory(Memory{Content: "Use PostgreSQL", MemoryType: TypeDecision, Importance: 0.8})
+
+	emb := &batchEmbedder{}
+	orch := NewOrchestrator(store, vectors, NewRanker(), emb)
+
+	results, err := orch.RememberBatch(context.Background(), []RememberItem{
+		{Content: "always validate input", MemoryType: TypeConstraint},
+		{Content: "We decided to use PostgreSQL.", MemoryType: TypeDecision},
+		{Content: "bogus", MemoryType: MemoryType("invalid")},
+		{Content: "write table tests", MemoryType: TypeConvention, Source: SourceExtracted},
+		{Content: "Always validate input", MemoryType: TypeConstraint},
+	})
+	if err != nil {
+		t.Fatalf("RememberBatch: %v", err)
+	}
+
+	want := []string{RememberCreated, RememberMerged, RememberFailed, RememberCreated, RememberMerged}
+	for i, r := range results {
+		if r.Status != want[i] {
+			t.Errorf("item %d: status %q, want %q (err %v)", i, r.Status, want[i], r.Err)
+		}
+	}
+	if results[2].Err == nil {
+		t.Error("expected an error for the invalid type")
+	}
+	if results[1].Memory.ID != existing {
+		t.Errorf("expected item 1 to merge into the stored decision, got %+v", results[1].Memory)
+	}
+	if results[4].Memory.ID == "" || results[4].Memory.ID != results[0].Memory.ID {
+		t.Errorf("expected item 4 to merge into item 0, got %q and %q", results[4].Memory.ID, results[0].Memory.ID)
+	}
+	if results[3].Memory.Source != SourceExtracted || results[0].Memory.Source != SourceUser {
+		t.Errorf("sources = %q, %q", results[0].Memory.Source, results[3].Memory.Source)
+	}
+
+	if emb.calls != 1 || emb.texts != 2 {
+		t.Errorf("expected the 2 new memories embedded in one call, got %d calls for %d texts", emb.calls, emb.texts)
+	}
+	if all, _ := store.ListMemories(""); len(all) != 3 {
+		t.Errorf("expected 3 stored memories, got %d", len(all))
+	}
+}
+
+func TestOrchestrator_RememberBatch_StoresEmbeddings(t *testing.T) {
+	_, store, vectors := setupOrchestratorDB(t)
+	orch := NewOrchestrator(store, vectors, NewRanker(), &stubEmbedder{embeddings: [][]float32{makeVec(1.0), makeVec(2.0)}})
+
+	results, err := orch.RememberBatch(context.Background(), []RememberItem{
+		{Content: "use Go", MemoryType: TypeDecision},
+		{Content: "always validate input", MemoryType: TypeConstraint},
+	})
+	if err != nil {
+		t.Fatalf("RememberBatch: %v", err)
+	}
+	matches, _ := vectors.SearchMemories("", makeVec(2.0), 1, 0.0)
+	if len(matches) != 1 || matches[0].ID != results[1].Memory.ID {
+		t.Errorf("expected the second memory's embedding to be searchable, got %+v", matches)
+	}
+}
+
+// batchEmbedder returns makeVec vectors, counting calls and texts. When
+// badAt > 0, the text at that (1-based) position gets a vector of the wrong
+// size, which the vector index rejects.
+type batchEmbedder struct {
+	calls, texts int
+	badAt        int
+}
+
+func (b *batchEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	b.calls++
+	b.texts += len(texts)
+	out := make([][]float32, len(texts))
+	for i := range texts {
+		out[i] = makeVec(float32(i + 1))
+		if i+1 == b.badAt {
+			out[i] = []float32{1, 2, 3}
+		}
+	}
+	return out, nil
+}
+
+func TestOrchestrator_RememberBatch_RollsBackOnFailure(t *testing.T) {
+	items := []RememberItem{
+		{Content: "use Go", MemoryType: TypeDecision},
+		{Content: "always validate input", MemoryType: TypeConstraint},
+		{Content: "write table tests", MemoryType: TypeConvention},
+	}
+	for name, emb := range map[string]adapter.Embedder{
+		"embed error":         &stubEmbedder{err: errors.New("embed failed")},
+		"upsert fails midway": &batchEmbedder{badAt: 2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, store, vectors := setupOrchestratorDB(t)
+			orch := NewOrchestrator(store, vectors, NewRanker(), emb)
+
+			if _, err := orch.RememberBatch(context.Background(), items); err == nil {
+				t.Fatal("expected RememberBatch to fail")
+			}
+			if all, _ := store.ListMemories(""); len(all) != 0 {
+				t.Errorf("expected no memories after a failed batch, got %d", len(all))
+			}
+			if matches, _ := vectors.SearchMemories("", makeVec(1.0), 10, 0.0); len(matches) != 0 {
+				t.Errorf("expected no embeddings after a failed batch, got %d", len(matches))
+			}
+		})
+	}
+}
+
+func TestOrchestrator_RememberBatch_StopPhrases(t *testing.T) {
+	_, store, vectors := setupOrchestratorDB(t)
+	store.InsertMemory(Memory{Content: "use PostgreSQL", MemoryType: TypeDecision, Importance: 0.8})
+	orch := NewOrchestrator(store, vectors, NewRanker(), nil)
+	item := []RememberItem{{Content: "We decided to use PostgreSQL", MemoryType: TypeDecision}}
+
+	// With stop phrases turned off, the boilerplate opening counts.
+	orch.SetStopPhrases([]string{})
+	results, err := orch.RememberBatch(context.Background(), item)
+	if err != nil {
+		t.Fatalf("RememberBatch: %v", err)
+	}
+	if results[0].Status != RememberCreated {
+		t.Errorf("status %q, want %q without stop phrases", results[0].Status, RememberCreated)
+	}
+
+	// A configured phrase is ignored, where the defaults would not be enough.
+	orch.SetStopPhrases([]string{"heads up"})
+	results, err = orch.RememberBatch(context.Background(), []RememberItem{{Content: "Heads up: use PostgreSQL", MemoryType: TypeDecision}})
+	if err != nil {
+		t.Fatalf("RememberBatch: %v", err)
+	}
+	if results[0].Status != RememberMerged {
+		t.Errorf("status %q, want %q with a configured stop phrase", results[0].Status, RememberMerged)
+	}
+}
+
 // --- Forget tests ---
 
 func TestOrchestrator_Forget(t *testing.T) {
@@ -220,7 +743,7 @@ func TestOrchestrator_Forget(t *testing.T) {
 
 	// Embedding should also be gone.
 	query := makeVec(3.0)
-	matches, _ := vectors.SearchMemories(query, 10, 0.0)
+	matches, _ := vectors.SearchMemories("", query, 10, 0.0)
 	for _, m := range matches {
 		if m.ID == mem.ID {
 			t.Error("deleted memory embedding should not appear in search")
@@ -258,3 +781,71 @@ func TestOrchestrator_ForgetByType_InvalidType(t *testing.T) {
 		t.Error("expected error for invalid memory type")
 	}
 }
+
+func TestOrchestrator_Retrieve_Exclude(t *testing.T) {
+	_, store, vectors := setupOrchestratorDB(t)
+
+	addChunk := func(path, content string, vec []float32) {
+		fileID, _ := store.UpsertFile(File{Path: path, Language: "go", LastModified: time.Now(), ContentHash: path})
+		id, _ := store.InsertChunkReturningID(Chunk{FileID: fileID, Content: content, StartLine: 1, EndLine: 1, ChunkType: "code"})
+		vectors.UpsertChunkEmbedding("", id, vec)
+	}
+	// The test file is the closest match, so it wins without exclusions.
+	addChunk("handler_test.go", "func TestHandleError(t *testing.T) {}", makeVec(1.0))
+	addChunk("fixtures/errors.go", "var errFixture = errors.New(\"boom\")", makeVec(1.05))
+	addChunk("handler.go", "func handleError(err error) {}", makeVec(1.2))
+
+	memID, _ := store.InsertMemory(Memory{Content: "wrap errors with context", MemoryType: TypeConvention, Importance: 0.7})
+	testMemID, _ := store.InsertMemory(Memory{Content: "Test errors with errors.Is", MemoryType: TypeConvention, Importance: 0.7})
+	vectors.UpsertMemoryEmbedding("", memID, makeVec(1.2))
+	vectors.UpsertMemoryEmbedding("", testMemID, makeVec(1.0))
+
+	orch := NewOrchestrator(store, vectors, NewRanker(), &stubEmbedder{embeddings: [][]float32{makeVec(1.0)}})
+	opts := RetrieveOptions{TopKChunks: 1, TopKMemories: 1}
+
+	result, _ := orch.Retrieve(context.Background(), "error handling", opts)
+	if len(result.Chunks) != 1 || result.Chunks[0].Content != "func TestHandleError(t *testing.T) {}" {
+		t.Fatalf("expected the test chunk without exclusions, got %+v", result.Chunks)
+	}
+
+	opts.Exclude = []string{"TEST", "fixture"}
+	result, _ = orch.Retrieve(context.Background(), "error handling", opts)
+	if len(result.Chunks) != 1 || result.Chunks[0].Content != "func handleError(err error) {}" {
+		t.Errorf("expected excluded chunks to be replaced by handler.go, got %+v", result.Chunks)
+	}
+	if len(result.Memories) != 1 || result.Memories[0].ID != memID {
+		t.Errorf("expected only the non-test memory, got %+v", result.Memories)
+	}
+}
+
+func TestOrchestrator_Retrieve_ExcludedPaths(t *testing.T) {
+	_, store, vectors := setupOrchestratorDB(t)
+	for _, f := range []struct {
+		path string
+		vec  float32
+	}{{"secrets/keys.go", 1.0}, {"config/secrets/db.go", 1.05}, {"handler.go", 1.2}} {
+		fileID, _ := store.UpsertFile(File{Path: f.path, Language: "go", LastModified: time.Now(), ContentHash: f.path})
+		id, _ := store.InsertChunkReturningID(Chunk{FileID: fileID, Content: "func load() {}", StartLine: 1, EndLine: 1, ChunkType: "code"})
+		vectors.UpsertChunkEmbedding("", id, makeVec(f.vec))
+	}
+
+	orch := NewOrchestrator(store, vectors, NewRanker(), &stubEmbedder{embeddings: [][]float32{makeVec(1.0)}})
+	orch.SetExcludedPaths(func(path string) bool { return strings.Contains(path, "secrets/") })
+	result, _ := orch.Retrieve(context.Background(), "load", RetrieveOptions{TopKChunks: 1})
+	if len(result.Chunks) != 1 {
+		t.Fatalf("expected the excluded chunks to be replaced, got %+v", result.Chunks)
+	}
+	if f, _ := store.GetFilesByIDs([]string{result.Chunks[0].FileID}); f[result.Chunks[0].FileID].Path != "handler.go" {
+		t.Errorf("expected handler.go, got %+v", f)
+	}
+}
+
+func TestSplitExclusions(t *testing.T) {
+	query, exclude := SplitExclusions("error handling -test --fixture - x")
+	if query != "error handling - x" {
+		t.Errorf("query: got %q", query)
+	}
+	if len(exclude) != 2 || exclude[0] != "test" || exclude[1] != "fixture" {
+		t.Errorf("exclude: got %v", exclude)
+	}
+}
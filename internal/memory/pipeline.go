@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/memvra/memvra/internal/adapter"
+)
+
+// DefaultEmbedBatchSize is the number of chunks sent per Embed call.
+const DefaultEmbedBatchSize = 32
+
+// ProgressFunc receives progress from long-running operations: done steps
+// out of total, where total is 0 if not known in advance. A nil ProgressFunc
+// means the caller doesn't want progress.
+type ProgressFunc func(done, total int)
+
+// EmbedOptions controls EmbedChunks.
+type EmbedOptions struct {
+	// Model is the key vectors are stored under; see VectorStore.
+	Model string
+	// BatchSize is the number of chunks per Embed call (default DefaultEmbedBatchSize).
+	BatchSize int
+	// Workers is the number of batches embedded concurrently (default 1).
+	// It bounds the load on the provider; rate-limit errors are retried by
+	// the embedder itself (see adapter.RetryingEmbedder).
+	Workers int
+	// Progress, if set, is called on the calling goroutine after each batch
+	// is stored, with the number of chunks processed and the total.
+	Progress ProgressFunc
+	// Text, if set, returns the text embedded for a chunk in place of its
+	// content, e.g. with StripBoilerplate applied.
+	Text func(Chunk) string
+}
+
+// EmbedChunks embeds chunks in batches on a pool of workers and stores the
+// vectors via the batch upsert. Batches are written in input order, so the
+// outcome does not depend on which worker finishes first. On the first
+// failed batch, or when ctx is cancelled, outstanding work stops and the
+// error is returned with the number of chunks stored before it.
+func EmbedChunks(ctx context.Context, embedder adapter.Embedder, vectors *VectorStore, chunks []Chunk, opts EmbedOptions) (int, error) {
+	if len(chunks) == 0 {
+		return 0, nil
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultEmbedBatchSize
+	}
+	workers := max(opts.Workers, 1)
+	numBatches := (len(chunks) + batchSize - 1) / batchSize
+	workers = min(workers, numBatches)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type batchResult struct {
+		vecs [][]float32
+		err  error
+	}
+	results := make([]chan batchResult, numBatches)
+	for i := range results {
+		results[i] = make(chan batchResult, 1)
+	}
+	batchAt := func(i int) []Chunk {
+		return chunks[i*batchSize : min((i+1)*batchSize, len(chunks))]
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				batch := batchAt(i)
+				texts := make([]string, len(batch))
+				for j, c := range batch {
+					texts[j] = c.Content
+					if opts.Text != nil {
+						texts[j] = opts.Text(c)
+					}
+				}
+				vecs, err := embedder.Embed(ctx, texts)
+				results[i] <- batchResult{vecs: vecs, err: err}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for i := 0; i < numBatches; i++ {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	// Stop dispatching and wait for in-flight batches before returning.
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	embedded := 0
+	for i := 0; i < numBatches; i++ {
+		var r batchResult
+		select {
+		case r = <-results[i]:
+		case <-ctx.Done():
+			return embedded, ctx.Err()
+		}
+		if r.err != nil {
+			return embedded, fmt.Errorf("embed batch at offset %d: %w", i*batchSize, r.err)
+		}
+
+		batch := batchAt(i)
+		n := min(len(r.vecs), len(batch))
+		ids := make([]string, n)
+		for j := range ids {
+			ids[j] = batch[j].ID
+		}
+		// A failed upsert is non-fatal: skip the batch and keep going.
+		if err := vectors.UpsertChunkEmbeddings(opts.Model, ids, r.vecs[:n]); err == nil {
+			for _, vec := range r.vecs[:n] {
+				if len(vec) > 0 {
+					embedded++
+				}
+			}
+		}
+		if opts.Progress != nil {
+			opts.Progress(min((i+1)*batchSize, len(chunks)), len(chunks))
+		}
+	}
+	return embedded, nil
+}
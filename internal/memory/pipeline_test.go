@@ -0,0 +1,168 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/memvra/memvra/internal/db"
+)
+
+// latencyEmbedder maps "chunk-N" to makeVec(N) after a delay, simulating a
+// remote provider. Texts listed in fail make the whole batch fail.
+type latencyEmbedder struct {
+	latency  time.Duration
+	jitter   bool
+	fail     string
+	calls    atomic.Int32
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (e *latencyEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	e.calls.Add(1)
+	n := e.inFlight.Add(1)
+	defer e.inFlight.Add(-1)
+	for {
+		p := e.peak.Load()
+		if n <= p || e.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+
+	d := e.latency
+	if e.jitter {
+		d = time.Duration(rand.Int63n(int64(e.latency) + 1))
+	}
+	select {
+	case <-time.After(d):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	out := make([][]float32, len(texts))
+	for i, t := range texts {
+		if e.fail != "" && t == e.fail {
+			return nil, errors.New("provider unavailable")
+		}
+		n, _ := strconv.Atoi(strings.TrimPrefix(t, "chunk-"))
+		out[i] = makeVec(float32(n))
+	}
+	return out, nil
+}
+
+func pipelineChunks(n int) []Chunk {
+	chunks := make([]Chunk, n)
+	for i := range chunks {
+		chunks[i] = Chunk{ID: fmt.Sprintf("id-%d", i), Content: fmt.Sprintf("chunk-%d", i)}
+	}
+	return chunks
+}
+
+func TestEmbedChunks_ConcurrentAndDeterministic(t *testing.T) {
+	_, vs := setupVectorTestDB(t)
+	emb := &latencyEmbedder{latency: 5 * time.Millisecond, jitter: true}
+
+	chunks := pipelineChunks(100)
+	n, err := EmbedChunks(context.Background(), emb, vs, chunks, EmbedOptions{Model: testModel, BatchSize: 8, Workers: 4})
+	if err != nil {
+		t.Fatalf("EmbedChunks: %v", err)
+	}
+	if n != 100 {
+		t.Errorf("embedded: got %d, want 100", n)
+	}
+	if calls := emb.calls.Load(); calls != 13 {
+		t.Errorf("embed calls: got %d, want 13 batches", calls)
+	}
+	if peak := emb.peak.Load(); peak > 4 {
+		t.Errorf("worker pool exceeded: %d concurrent calls", peak)
+	}
+
+	// Every chunk is stored with its own vector, whatever the finish order.
+	for _, i := range []int{0, 37, 99} {
+		matches, _ := vs.SearchChunks(testModel, makeVec(float32(i)), 1, 0.0)
+		if len(matches) != 1 || matches[0].ID != fmt.Sprintf("id-%d", i) {
+			t.Errorf("nearest to chunk %d: got %+v", i, matches)
+		}
+	}
+}
+
+func TestEmbedChunks_StopsAtFirstFailedBatch(t *testing.T) {
+	_, vs := setupVectorTestDB(t)
+	emb := &latencyEmbedder{latency: time.Millisecond, jitter: true, fail: "chunk-20"}
+
+	n, err := EmbedChunks(context.Background(), emb, vs, pipelineChunks(64), EmbedOptions{Model: testModel, BatchSize: 8, Workers: 4})
+	if err == nil || !strings.Contains(err.Error(), "offset 16") {
+		t.Fatalf("expected error for batch at offset 16, got %v", err)
+	}
+	// Only the two batches before the failure are stored, regardless of
+	// which later batches finished first.
+	if n != 16 {
+		t.Errorf("embedded before failure: got %d, want 16", n)
+	}
+	if models, _ := vs.Models(); len(models) != 1 || models[0].Chunks != 16 {
+		t.Errorf("stored vectors: %+v", models)
+	}
+}
+
+func TestEmbedChunks_Cancelled(t *testing.T) {
+	_, vs := setupVectorTestDB(t)
+	emb := &latencyEmbedder{latency: time.Second}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := EmbedChunks(ctx, emb, vs, pipelineChunks(64), EmbedOptions{Model: testModel, Workers: 2})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline error, got %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("cancellation took %v", time.Since(start))
+	}
+}
+
+func TestEmbedChunks_ReportsProgress(t *testing.T) {
+	_, vs := setupVectorTestDB(t)
+	emb := &latencyEmbedder{latency: time.Millisecond, jitter: true}
+
+	var calls [][2]int
+	progress := func(done, total int) { calls = append(calls, [2]int{done, total}) }
+	_, err := EmbedChunks(context.Background(), emb, vs, pipelineChunks(20), EmbedOptions{Model: testModel, BatchSize: 8, Workers: 3, Progress: progress})
+	if err != nil {
+		t.Fatalf("EmbedChunks: %v", err)
+	}
+	want := [][2]int{{8, 20}, {16, 20}, {20, 20}}
+	if fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Errorf("progress calls: got %v, want %v", calls, want)
+	}
+}
+
+func benchmarkEmbedChunks(b *testing.B, workers int) {
+	database, err := db.Open(filepath.Join(b.TempDir(), "bench.db"))
+	if err != nil {
+		b.Fatalf("open: %v", err)
+	}
+	defer database.Close()
+	vs := NewVectorStore(database)
+	emb := &latencyEmbedder{latency: 20 * time.Millisecond}
+	chunks := pipelineChunks(512)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := EmbedChunks(context.Background(), emb, vs, chunks, EmbedOptions{Model: testModel, Workers: workers}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// 512 chunks against a provider with 20ms latency per call.
+func BenchmarkEmbedChunks_1Worker(b *testing.B)  { benchmarkEmbedChunks(b, 1) }
+func BenchmarkEmbedChunks_4Workers(b *testing.B) { benchmarkEmbedChunks(b, 4) }
+func BenchmarkEmbedChunks_8Workers(b *testing.B) { benchmarkEmbedChunks(b, 8) }
@@ -1,12 +1,98 @@
 package memory
 
-import "sort"
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	gitignore "github.com/sabhiram/go-gitignore"
+)
 
 // Ranker ranks retrieval results by combining similarity score and importance.
-type Ranker struct{}
+// Its clock is the time source for age-based scoring.
+type Ranker struct {
+	clock  Clock
+	boosts []pathBoost
+	opts   RankerOptions
+}
+
+// RankerOptions weights the parts of a memory's retrieval score.
+//
+// With every weight zero (the default) a memory scores
+//
+//	similarity × importance
+//
+// Setting any weight switches to the weighted sum
+//
+//	SimilarityWeight×similarity + ImportanceWeight×importance + RecencyWeight×recency
+//
+// where similarity and importance are in [0, 1] and recency is
+// 0.5^(age / half-life), 1 for a memory updated just now. Weights that sum
+// to 1 keep scores in [0, 1]. Chunks have no importance and always score by
+// similarity and their adjustments.
+//
+// PreferConcise breaks near ties between chunks (scores within
+// conciseTieWidth) in favour of the shorter one, which costs fewer tokens
+// for the same relevance.
+type RankerOptions struct {
+	SimilarityWeight    float64
+	ImportanceWeight    float64
+	RecencyWeight       float64
+	RecencyHalfLifeDays float64 // 0 = DefaultRecencyHalfLifeDays
+	PreferConcise       bool
+}
+
+// conciseTieWidth is the width of the score bands within which
+// RankerOptions.PreferConcise orders chunks by length.
+const conciseTieWidth = 0.01
+
+// DefaultRecencyHalfLifeDays is the age at which a memory's recency term
+// halves when RankerOptions.RecencyHalfLifeDays is unset.
+const DefaultRecencyHalfLifeDays = 30
+
+func (o RankerOptions) blended() bool {
+	return o.SimilarityWeight != 0 || o.ImportanceWeight != 0 || o.RecencyWeight != 0
+}
+
+// pathBoost is a compiled path pattern and the multiplier it applies.
+type pathBoost struct {
+	pattern string
+	matcher *gitignore.GitIgnore
+	factor  float64
+}
 
 // NewRanker creates a new Ranker.
-func NewRanker() *Ranker { return &Ranker{} }
+func NewRanker() *Ranker { return &Ranker{clock: SystemClock{}} }
+
+// SetClock replaces the clock used for age-based scoring.
+func (r *Ranker) SetClock(c Clock) { r.clock = c }
+
+// SetOptions sets the memory score weights; see RankerOptions.
+func (r *Ranker) SetOptions(o RankerOptions) { r.opts = o }
+
+// SetPathBoosts sets score multipliers for chunks by file path. Keys are
+// gitignore-style patterns (e.g. "ARCHITECTURE.md", "vendor/"); a factor
+// above 1 boosts matching chunks and below 1 deprioritises them. A chunk
+// matching several patterns gets the product of their factors. Factors that
+// are not positive are ignored.
+func (r *Ranker) SetPathBoosts(boosts map[string]float64) {
+	r.boosts = nil
+	for pattern, factor := range boosts {
+		if factor <= 0 {
+			continue
+		}
+		r.boosts = append(r.boosts, pathBoost{
+			pattern: pattern,
+			matcher: gitignore.CompileIgnoreLines(pattern),
+			factor:  factor,
+		})
+	}
+	sort.Slice(r.boosts, func(i, j int) bool { return r.boosts[i].pattern < r.boosts[j].pattern })
+}
+
+// HasPathBoosts reports whether any path boosts are set, i.e. whether
+// RankChunks needs chunk paths.
+func (r *Ranker) HasPathBoosts() bool { return len(r.boosts) > 0 }
 
 // RankedChunk pairs a Chunk with a retrieval score.
 type RankedChunk struct {
@@ -20,41 +106,71 @@ type RankedMemory struct {
 	FinalScore float64
 }
 
+// Adjustment is one multiplicative factor applied to a similarity score.
+type Adjustment struct {
+	Name   string  `json:"name"`
+	Factor float64 `json:"factor"`
+}
+
+// ScoreTerm is one weighted part of a blended memory score.
+type ScoreTerm struct {
+	Name   string  `json:"name"`
+	Value  float64 `json:"value"`
+	Weight float64 `json:"weight"`
+}
+
+// ScoreExplanation breaks a retrieval score into its parts: the raw vector
+// distance, the similarity derived from it, and either each adjustment
+// multiplied in or, for blended memory scores (see RankerOptions), each
+// weighted term summed to reach the final score.
+type ScoreExplanation struct {
+	Distance    float64      `json:"distance"`
+	Similarity  float64      `json:"similarity"`
+	Adjustments []Adjustment `json:"adjustments"`
+	Terms       []ScoreTerm  `json:"terms,omitempty"`
+	FinalScore  float64      `json:"final_score"`
+}
+
 // RankChunks scores and sorts chunks by similarity, highest first.
-// similarityByID maps chunk ID → cosine similarity (0-1).
-func (r *Ranker) RankChunks(chunks []Chunk, similarityByID map[string]float64) []RankedChunk {
+// similarityByID maps chunk ID → cosine similarity (0-1); paths maps file ID
+// → relative path for path boosts and may be nil when none are set.
+func (r *Ranker) RankChunks(chunks []Chunk, similarityByID map[string]float64, paths map[string]string) []RankedChunk {
 	ranked := make([]RankedChunk, 0, len(chunks))
 	for _, c := range chunks {
-		sim := similarityByID[c.ID]
-		// Test files are deprioritised by default (importance 0.3).
-		importance := 1.0
-		if c.ChunkType == "test" {
-			importance = 0.3
-		}
 		ranked = append(ranked, RankedChunk{
 			Chunk:      c,
-			FinalScore: sim * importance,
+			FinalScore: explain(similarityByID[c.ID], r.chunkAdjustments(c, paths[c.FileID])...).FinalScore,
 		})
 	}
+	if r.opts.PreferConcise {
+		sort.SliceStable(ranked, func(i, j int) bool {
+			bi, bj := scoreBand(ranked[i].FinalScore), scoreBand(ranked[j].FinalScore)
+			if bi != bj {
+				return bi > bj
+			}
+			return len(ranked[i].Content) < len(ranked[j].Content)
+		})
+		return ranked
+	}
 	sort.Slice(ranked, func(i, j int) bool {
 		return ranked[i].FinalScore > ranked[j].FinalScore
 	})
 	return ranked
 }
 
-// RankMemories scores and sorts memories by similarity × importance, highest first.
+// scoreBand groups scores that are near-equal for PreferConcise.
+func scoreBand(score float64) float64 {
+	return math.Round(score / conciseTieWidth)
+}
+
+// RankMemories scores and sorts memories, highest first. By default the
+// score is similarity × importance; see RankerOptions for the blend.
 func (r *Ranker) RankMemories(memories []Memory, similarityByID map[string]float64) []RankedMemory {
 	ranked := make([]RankedMemory, 0, len(memories))
 	for _, m := range memories {
-		sim := similarityByID[m.ID]
-		// Importance is already 0-1 from the DB; use it as a multiplier.
-		importance := m.Importance
-		if importance == 0 {
-			importance = 0.5
-		}
 		ranked = append(ranked, RankedMemory{
 			Memory:     m,
-			FinalScore: sim * importance,
+			FinalScore: r.ExplainMemory(m, similarityByID[m.ID]).FinalScore,
 		})
 	}
 	sort.Slice(ranked, func(i, j int) bool {
@@ -62,3 +178,84 @@ func (r *Ranker) RankMemories(memories []Memory, similarityByID map[string]float
 	})
 	return ranked
 }
+
+// ExplainChunk returns the score breakdown RankChunks uses for c, whose
+// file is at path.
+func (r *Ranker) ExplainChunk(c Chunk, similarity float64, path string) ScoreExplanation {
+	return explain(similarity, r.chunkAdjustments(c, path)...)
+}
+
+// chunkAdjustments returns the chunk-type weight followed by any path
+// boosts matching path.
+func (r *Ranker) chunkAdjustments(c Chunk, path string) []Adjustment {
+	adjustments := []Adjustment{chunkWeight(c)}
+	if path == "" {
+		return adjustments
+	}
+	for _, b := range r.boosts {
+		if b.matcher.MatchesPath(path) {
+			adjustments = append(adjustments, Adjustment{Name: fmt.Sprintf("path %s", b.pattern), Factor: b.factor})
+		}
+	}
+	return adjustments
+}
+
+// ExplainMemory returns the score breakdown RankMemories uses for m.
+func (r *Ranker) ExplainMemory(m Memory, similarity float64) ScoreExplanation {
+	if !r.opts.blended() {
+		return explain(similarity, memoryWeight(m))
+	}
+	terms := []ScoreTerm{
+		{Name: "similarity", Value: similarity, Weight: r.opts.SimilarityWeight},
+		{Name: "importance", Value: memoryWeight(m).Factor, Weight: r.opts.ImportanceWeight},
+		{Name: "recency", Value: r.recency(m), Weight: r.opts.RecencyWeight},
+	}
+	var score float64
+	for _, t := range terms {
+		score += t.Weight * t.Value
+	}
+	return ScoreExplanation{Similarity: similarity, Terms: terms, FinalScore: score}
+}
+
+// recency decays from 1 for a memory updated now, halving every half-life.
+func (r *Ranker) recency(m Memory) float64 {
+	t := m.UpdatedAt
+	if t.IsZero() {
+		t = m.CreatedAt
+	}
+	if t.IsZero() {
+		return 0
+	}
+	halfLife := r.opts.RecencyHalfLifeDays
+	if halfLife <= 0 {
+		halfLife = DefaultRecencyHalfLifeDays
+	}
+	ageDays := max(0, clockNow(r.clock).Sub(t).Hours()/24)
+	return math.Pow(0.5, ageDays/halfLife)
+}
+
+func explain(similarity float64, adjustments ...Adjustment) ScoreExplanation {
+	score := similarity
+	for _, a := range adjustments {
+		score *= a.Factor
+	}
+	return ScoreExplanation{Similarity: similarity, Adjustments: adjustments, FinalScore: score}
+}
+
+// chunkWeight deprioritises test files (0.3); other chunks keep full weight.
+func chunkWeight(c Chunk) Adjustment {
+	if c.ChunkType == "test" {
+		return Adjustment{Name: "test file", Factor: 0.3}
+	}
+	return Adjustment{Name: c.ChunkType, Factor: 1.0}
+}
+
+// memoryWeight uses the stored importance (0-1) as a multiplier, treating
+// an unset importance as 0.5.
+func memoryWeight(m Memory) Adjustment {
+	importance := m.Importance
+	if importance == 0 {
+		importance = 0.5
+	}
+	return Adjustment{Name: "importance", Factor: importance}
+}
@@ -1,6 +1,11 @@
 package memory
 
-import "testing"
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestRankChunks_SortsBySimilarity(t *testing.T) {
 	chunks := []Chunk{
@@ -11,7 +16,7 @@ func TestRankChunks_SortsBySimilarity(t *testing.T) {
 	simMap := map[string]float64{"a": 0.5, "b": 0.9, "c": 0.7}
 
 	ranker := NewRanker()
-	ranked := ranker.RankChunks(chunks, simMap)
+	ranked := ranker.RankChunks(chunks, simMap, nil)
 
 	if len(ranked) != 3 {
 		t.Fatalf("expected 3 ranked chunks, got %d", len(ranked))
@@ -32,7 +37,7 @@ func TestRankChunks_TestFilesDeprioritised(t *testing.T) {
 	simMap := map[string]float64{"code1": 0.5, "test1": 0.5}
 
 	ranker := NewRanker()
-	ranked := ranker.RankChunks(chunks, simMap)
+	ranked := ranker.RankChunks(chunks, simMap, nil)
 
 	// Code chunk should rank higher because test files get 0.3 importance.
 	if ranked[0].ID != "code1" {
@@ -45,9 +50,26 @@ func TestRankChunks_TestFilesDeprioritised(t *testing.T) {
 	}
 }
 
+func TestRankChunks_PreferConcise(t *testing.T) {
+	chunks := []Chunk{
+		{ID: "long", Content: strings.Repeat("validate(input)\n", 40), ChunkType: "code"},
+		{ID: "short", Content: "validate(input)", ChunkType: "code"},
+		{ID: "best", Content: strings.Repeat("login()\n", 40), ChunkType: "code"},
+	}
+	simMap := map[string]float64{"long": 0.7, "short": 0.7, "best": 0.9}
+
+	ranker := NewRanker()
+	ranker.SetOptions(RankerOptions{PreferConcise: true})
+	ranked := ranker.RankChunks(chunks, simMap, nil)
+	got := []string{ranked[0].ID, ranked[1].ID, ranked[2].ID}
+	if got[0] != "best" || got[1] != "short" || got[2] != "long" {
+		t.Errorf("expected [best short long], got %v", got)
+	}
+}
+
 func TestRankChunks_Empty(t *testing.T) {
 	ranker := NewRanker()
-	ranked := ranker.RankChunks(nil, nil)
+	ranked := ranker.RankChunks(nil, nil, nil)
 	if len(ranked) != 0 {
 		t.Errorf("expected empty result, got %d", len(ranked))
 	}
@@ -85,3 +107,91 @@ func TestRankMemories_ZeroImportanceUsesDefault(t *testing.T) {
 		t.Errorf("expected score %f, got %f", expected, ranked[0].FinalScore)
 	}
 }
+
+func TestRankMemories_WeightedBlend(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	memories := []Memory{
+		{ID: "important", Importance: 1.0, UpdatedAt: now.AddDate(0, 0, -60)},
+		{ID: "similar", Importance: 0.2, UpdatedAt: now.AddDate(0, 0, -60)},
+		{ID: "fresh", Importance: 0.2, UpdatedAt: now},
+	}
+	simMap := map[string]float64{"important": 0.5, "similar": 0.9, "fresh": 0.5}
+
+	ranker := NewRanker()
+	ranker.SetClock(NewManualClock(now))
+
+	// Default: similarity × importance favours the important memory.
+	if got := ranker.RankMemories(memories, simMap)[0].ID; got != "important" {
+		t.Errorf("default ranking: got %q first, want important", got)
+	}
+
+	ranker.SetOptions(RankerOptions{SimilarityWeight: 1})
+	if got := ranker.RankMemories(memories, simMap)[0].ID; got != "similar" {
+		t.Errorf("similarity only: got %q first, want similar", got)
+	}
+
+	ranker.SetOptions(RankerOptions{SimilarityWeight: 0.5, RecencyWeight: 0.5, RecencyHalfLifeDays: 30})
+	ranked := ranker.RankMemories(memories, simMap)
+	if ranked[0].ID != "fresh" {
+		t.Errorf("recency blend: got %q first, want fresh", ranked[0].ID)
+	}
+	// fresh: 0.5×0.5 + 0.5×1 = 0.75; two half-lives old decays to 0.25.
+	e := ranker.ExplainMemory(memories[1], 0.9)
+	if want := 0.5*0.9 + 0.5*0.25; math.Abs(e.FinalScore-want) > 1e-9 {
+		t.Errorf("similar: score %v, want %v", e.FinalScore, want)
+	}
+	if len(e.Terms) != 3 || e.Adjustments != nil {
+		t.Errorf("blended explanation should list terms, got %+v", e)
+	}
+	if math.Abs(ranked[0].FinalScore-0.75) > 1e-9 {
+		t.Errorf("fresh: score %v, want 0.75", ranked[0].FinalScore)
+	}
+}
+
+func TestRanker_ExplainMatchesRanking(t *testing.T) {
+	ranker := NewRanker()
+	c := Chunk{ID: "t", ChunkType: "test"}
+	ranked := ranker.RankChunks([]Chunk{c}, map[string]float64{"t": 0.6}, nil)
+
+	e := ranker.ExplainChunk(c, 0.6, "")
+	if e.FinalScore != ranked[0].FinalScore {
+		t.Errorf("explained score %f != ranked score %f", e.FinalScore, ranked[0].FinalScore)
+	}
+	if len(e.Adjustments) != 1 || e.Adjustments[0].Factor != 0.3 {
+		t.Errorf("expected test-file adjustment of 0.3, got %+v", e.Adjustments)
+	}
+}
+
+func TestRankChunks_PathBoosts(t *testing.T) {
+	ranker := NewRanker()
+	ranker.SetPathBoosts(map[string]float64{
+		"ARCHITECTURE.md": 1.5,
+		"vendor/":         0.5,
+		"ignored/":        0,
+	})
+
+	chunks := []Chunk{
+		{ID: "vendored", FileID: "f1", ChunkType: "code"},
+		{ID: "plain", FileID: "f2", ChunkType: "code"},
+		{ID: "arch", FileID: "f3", ChunkType: "doc"},
+	}
+	simMap := map[string]float64{"vendored": 0.8, "plain": 0.8, "arch": 0.8}
+	paths := map[string]string{"f1": "vendor/lib/x.go", "f2": "internal/x.go", "f3": "ARCHITECTURE.md"}
+
+	ranked := ranker.RankChunks(chunks, simMap, paths)
+	if ranked[0].ID != "arch" || ranked[1].ID != "plain" || ranked[2].ID != "vendored" {
+		t.Fatalf("expected arch, plain, vendored; got %s, %s, %s", ranked[0].ID, ranked[1].ID, ranked[2].ID)
+	}
+
+	e := ranker.ExplainChunk(chunks[2], 0.8, paths["f3"])
+	if e.FinalScore != ranked[0].FinalScore || len(e.Adjustments) != 2 || e.Adjustments[1].Factor != 1.5 {
+		t.Errorf("expected a 1.5 path adjustment matching the ranking, got %+v", e)
+	}
+
+	// Without paths the boosts cannot apply, so the chunks tie.
+	for _, rc := range ranker.RankChunks(chunks, simMap, nil) {
+		if rc.FinalScore != 0.8 {
+			t.Errorf("%s: expected unboosted score 0.8, got %f", rc.ID, rc.FinalScore)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package memory
+
+import "math"
+
+// CosineSimilarity returns the cosine of the angle between a and b, in
+// [-1, 1]. Vectors of different lengths, empty vectors and zero vectors have
+// no meaningful angle and score 0.
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		x, y := float64(a[i]), float64(b[i])
+		dot += x * y
+		normA += x * x
+		normB += y * y
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// EuclideanDistance returns the L2 distance between a and b, the metric
+// sqlite-vec reports. Vectors of different lengths are infinitely far apart.
+func EuclideanDistance(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return math.Inf(1)
+	}
+	var sum float64
+	for i := range a {
+		d := float64(a[i]) - float64(b[i])
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+// DistanceToSimilarity maps an L2 distance to a similarity in (0, 1], with
+// identical vectors scoring 1. It is how vector search results are scored
+// against similarity thresholds and by the ranker.
+func DistanceToSimilarity(distance float64) float64 {
+	return 1.0 / (1.0 + distance)
+}
+
+// WordSimilarity returns the Jaccard overlap of the distinct words in a and
+// b, in [0, 1], ignoring case, order and surrounding punctuation. Two empty
+// texts score 0.
+func WordSimilarity(a, b string) float64 {
+	setA := make(map[string]bool)
+	for _, w := range normalizedWords(a) {
+		setA[w] = true
+	}
+	setB := make(map[string]bool)
+	for _, w := range normalizedWords(b) {
+		setB[w] = true
+	}
+	shared := 0
+	for w := range setA {
+		if setB[w] {
+			shared++
+		}
+	}
+	union := len(setA) + len(setB) - shared
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
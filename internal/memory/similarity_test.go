@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float32
+		want float64
+	}{
+		{"identical", []float32{1, 2, 3}, []float32{1, 2, 3}, 1},
+		{"scaled", []float32{1, 2, 3}, []float32{2, 4, 6}, 1},
+		{"orthogonal", []float32{1, 0}, []float32{0, 1}, 0},
+		{"opposite", []float32{1, 0}, []float32{-1, 0}, -1},
+		{"45 degrees", []float32{1, 0}, []float32{1, 1}, 1 / math.Sqrt2},
+		{"mismatched lengths", []float32{1, 0}, []float32{1, 0, 0}, 0},
+		{"zero vector", []float32{0, 0}, []float32{1, 1}, 0},
+		{"empty", nil, nil, 0},
+	}
+	for _, tt := range tests {
+		if got := CosineSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: CosineSimilarity = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEuclideanDistance(t *testing.T) {
+	if got := EuclideanDistance([]float32{0, 0}, []float32{3, 4}); got != 5 {
+		t.Errorf("EuclideanDistance = %v, want 5", got)
+	}
+	if got := EuclideanDistance([]float32{1, 2}, []float32{1, 2}); got != 0 {
+		t.Errorf("identical vectors: got %v, want 0", got)
+	}
+	if got := EuclideanDistance([]float32{1}, []float32{1, 2}); !math.IsInf(got, 1) {
+		t.Errorf("mismatched lengths: got %v, want +Inf", got)
+	}
+}
+
+func TestDistanceToSimilarity(t *testing.T) {
+	if got := DistanceToSimilarity(0); got != 1 {
+		t.Errorf("distance 0: got %v, want 1", got)
+	}
+	if got := DistanceToSimilarity(1); got != 0.5 {
+		t.Errorf("distance 1: got %v, want 0.5", got)
+	}
+}
+
+func TestWordSimilarity(t *testing.T) {
+	if got := WordSimilarity("Fix the login bug.", "fix THE login bug"); got != 1 {
+		t.Errorf("same words: got %v, want 1", got)
+	}
+	if got := WordSimilarity("fix login", "fix signup"); got != 1.0/3 {
+		t.Errorf("one of three words shared: got %v, want 1/3", got)
+	}
+	if got := WordSimilarity("", "  "); got != 0 {
+		t.Errorf("empty texts: got %v, want 0", got)
+	}
+}
@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Snippet sizing defaults for search output.
+const (
+	DefaultSnippetContext = 2  // lines shown around each matching line
+	DefaultSnippetLines   = 12 // most lines shown per chunk
+)
+
+// SnippetLine is one line of a chunk excerpt.
+type SnippetLine struct {
+	Number int    // line number in the source file
+	Text   string // line content
+	// Hits are the [start, end) byte ranges of query terms within Text.
+	Hits [][2]int
+}
+
+// Snippet is an excerpt of a chunk centred on the lines matching a query.
+type Snippet struct {
+	Lines []SnippetLine
+	// Matched reports whether any query term occurs in the chunk. When it is
+	// false (a purely semantic match), Lines is the head of the chunk.
+	Matched bool
+}
+
+// snippetStopWords are query words too common to be worth highlighting.
+var snippetStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "how": true,
+	"what": true, "where": true, "why": true, "does": true, "from": true,
+	"this": true, "that": true, "are": true, "into": true, "use": true,
+}
+
+// QueryTerms splits a search query into lower-cased words worth matching
+// literally: at least three characters and not a stop word.
+func QueryTerms(query string) []string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+	seen := make(map[string]bool, len(words))
+	var terms []string
+	for _, w := range words {
+		if len(w) < 3 || snippetStopWords[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		terms = append(terms, w)
+	}
+	return terms
+}
+
+// ExtractSnippet returns up to maxLines lines of c around the lines that
+// contain query terms, with contextLines of surrounding code on each side.
+// Chunks without a literal match fall back to their first maxLines lines.
+func ExtractSnippet(c Chunk, query string, contextLines, maxLines int) Snippet {
+	if maxLines <= 0 {
+		maxLines = DefaultSnippetLines
+	}
+	contextLines = max(contextLines, 0)
+	lines := strings.Split(strings.TrimRight(c.Content, "\n"), "\n")
+	terms := QueryTerms(query)
+
+	hits := make([][][2]int, len(lines))
+	var hitLines []int
+	for i, line := range lines {
+		if hits[i] = findTerms(line, terms); len(hits[i]) > 0 {
+			hitLines = append(hitLines, i)
+		}
+	}
+
+	var keep []int
+	if len(hitLines) == 0 {
+		for i := 0; i < len(lines) && i < maxLines; i++ {
+			keep = append(keep, i)
+		}
+	} else {
+		// Windows are added in line order, so the earliest matches win when
+		// the budget runs out.
+		selected := make(map[int]bool)
+		for _, h := range hitLines {
+			for i := max(h-contextLines, 0); i <= min(h+contextLines, len(lines)-1); i++ {
+				if len(selected) >= maxLines {
+					break
+				}
+				selected[i] = true
+			}
+		}
+		for i := range selected {
+			keep = append(keep, i)
+		}
+		sort.Ints(keep)
+	}
+
+	s := Snippet{Matched: len(hitLines) > 0}
+	for _, i := range keep {
+		s.Lines = append(s.Lines, SnippetLine{Number: c.StartLine + i, Text: lines[i], Hits: hits[i]})
+	}
+	return s
+}
+
+// findTerms returns the sorted, merged byte ranges of terms in line,
+// matched case-insensitively.
+func findTerms(line string, terms []string) [][2]int {
+	if len(terms) == 0 {
+		return nil
+	}
+	lower := strings.ToLower(line)
+	if len(lower) != len(line) {
+		// Case folding changed byte offsets; ranges would not line up.
+		lower = line
+	}
+	var ranges [][2]int
+	for _, t := range terms {
+		for from := 0; ; {
+			i := strings.Index(lower[from:], t)
+			if i < 0 {
+				break
+			}
+			start := from + i
+			ranges = append(ranges, [2]int{start, start + len(t)})
+			from = start + len(t)
+		}
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+	out := ranges[:0]
+	for _, r := range ranges {
+		if n := len(out); n > 0 && r[0] <= out[n-1][1] {
+			out[n-1][1] = max(out[n-1][1], r[1])
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// Format renders the snippet grep-style: a line-number gutter, ">" on lines
+// with hits, and "..." where lines were skipped. highlight, if non-nil, wraps
+// each matched term.
+func (s Snippet) Format(highlight func(string) string) string {
+	width := 1
+	if n := len(s.Lines); n > 0 {
+		width = len(fmt.Sprint(s.Lines[n-1].Number))
+	}
+	var b strings.Builder
+	for i, l := range s.Lines {
+		if i > 0 && l.Number != s.Lines[i-1].Number+1 {
+			fmt.Fprintf(&b, "  %*s\n", width, "...")
+		}
+		marker := ' '
+		if len(l.Hits) > 0 {
+			marker = '>'
+		}
+		fmt.Fprintf(&b, "%c %*d | %s\n", marker, width, l.Number, l.highlighted(highlight))
+	}
+	return b.String()
+}
+
+// highlighted returns the line text with each hit wrapped by highlight.
+func (l SnippetLine) highlighted(highlight func(string) string) string {
+	if highlight == nil || len(l.Hits) == 0 {
+		return l.Text
+	}
+	var b strings.Builder
+	last := 0
+	for _, h := range l.Hits {
+		b.WriteString(l.Text[last:h[0]])
+		b.WriteString(highlight(l.Text[h[0]:h[1]]))
+		last = h[1]
+	}
+	b.WriteString(l.Text[last:])
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// numberedChunk returns a chunk of n lines "line 1".."line n" starting at
+// source line start, with extra replacing selected lines (1-based).
+func numberedChunk(start, n int, extra map[int]string) Chunk {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i+1)
+		if s, ok := extra[i+1]; ok {
+			lines[i] = s
+		}
+	}
+	return Chunk{Content: strings.Join(lines, "\n"), StartLine: start}
+}
+
+func TestQueryTerms(t *testing.T) {
+	got := QueryTerms("How does the Rate-Limiter handle retry_after? rate")
+	want := []string{"rate", "limiter", "handle", "retry_after"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestExtractSnippet_CentersOnMatches(t *testing.T) {
+	c := numberedChunk(100, 40, map[int]string{20: "func RateLimit(next Handler) Handler {"})
+	s := ExtractSnippet(c, "rate limit middleware", 2, 12)
+
+	if !s.Matched {
+		t.Fatal("expected a keyword match")
+	}
+	if len(s.Lines) != 5 || s.Lines[0].Number != 117 || s.Lines[4].Number != 121 {
+		t.Fatalf("expected lines 117-121, got %+v", s.Lines)
+	}
+	hit := s.Lines[2]
+	if len(hit.Hits) != 1 || hit.Text[hit.Hits[0][0]:hit.Hits[0][1]] != "RateLimit" {
+		t.Errorf("expected one merged hit on RateLimit, got %v", hit.Hits)
+	}
+}
+
+func TestExtractSnippet_FallsBackToHead(t *testing.T) {
+	s := ExtractSnippet(numberedChunk(1, 40, nil), "authentication", 2, 5)
+	if s.Matched {
+		t.Error("no query term occurs in the chunk")
+	}
+	if len(s.Lines) != 5 || s.Lines[0].Number != 1 {
+		t.Errorf("expected the first 5 lines, got %+v", s.Lines)
+	}
+}
+
+func TestExtractSnippet_RespectsMaxLines(t *testing.T) {
+	extra := map[int]string{}
+	for i := 1; i <= 40; i += 4 {
+		extra[i] = "token check"
+	}
+	s := ExtractSnippet(numberedChunk(1, 40, extra), "token", 1, 6)
+	if len(s.Lines) != 6 || s.Lines[0].Number != 1 {
+		t.Errorf("expected the first 6 lines around the earliest matches, got %+v", s.Lines)
+	}
+}
+
+func TestSnippet_Format(t *testing.T) {
+	c := numberedChunk(8, 12, map[int]string{2: "cache hit", 11: "cache miss"})
+	s := ExtractSnippet(c, "cache", 1, 12)
+	got := s.Format(func(term string) string { return "[" + term + "]" })
+	want := "   8 | line 1\n" +
+		">  9 | [cache] hit\n" +
+		"  10 | line 3\n" +
+		"  ...\n" +
+		"  17 | line 10\n" +
+		"> 18 | [cache] miss\n" +
+		"  19 | line 12\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
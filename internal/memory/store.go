@@ -1,9 +1,13 @@
 package memory
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/memvra/memvra/internal/db"
@@ -11,12 +15,52 @@ import (
 
 // Store provides read/write access to the Memvra SQLite database.
 type Store struct {
-	db *db.DB
+	db    *db.DB
+	clock Clock
+	// compressChunks gzips chunk content on write; see SetChunkCompression.
+	compressChunks bool
+	// readOnly rejects every write; see SetReadOnly.
+	readOnly bool
 }
 
 // NewStore creates a Store backed by the given DB.
 func NewStore(database *db.DB) *Store {
-	return &Store{db: database}
+	return &Store{db: database, clock: SystemClock{}}
+}
+
+// SetClock replaces the clock used for timestamps and age-based pruning.
+func (s *Store) SetClock(c Clock) {
+	s.clock = c
+}
+
+// Now returns the current time according to the store's clock.
+func (s *Store) Now() time.Time {
+	return clockNow(s.clock)
+}
+
+// SetReadOnly makes every write method fail with an error wrapping
+// db.ErrReadOnly instead of touching the database. A store over a database
+// opened with db.OpenReadOnly is always read-only.
+func (s *Store) SetReadOnly(readOnly bool) {
+	s.readOnly = readOnly
+}
+
+// ReadOnly reports whether writes are rejected.
+func (s *Store) ReadOnly() bool {
+	return s.readOnly || s.db.ReadOnly()
+}
+
+// writable returns an error naming op if the store is read-only.
+func (s *Store) writable(op string) error {
+	if s.ReadOnly() {
+		return fmt.Errorf("store: %s: %w", op, db.ErrReadOnly)
+	}
+	return nil
+}
+
+// now returns the store clock's current time in SQLite timestamp format.
+func (s *Store) now() string {
+	return clockNow(s.clock).UTC().Format(sqliteTimeLayout)
 }
 
 // Conn exposes the underlying *sql.DB for low-level queries.
@@ -27,11 +71,23 @@ func (s *Store) Conn() *sql.DB {
 // ---- Project ----
 
 // UpsertProject inserts or replaces the project record.
+// The TechStack JSON is validated and compacted first so malformed data is
+// rejected at write time instead of silently degrading later context builds.
 func (s *Store) UpsertProject(p Project) error {
-	_, err := s.db.Conn().Exec(`
-		INSERT INTO project (id, name, root_path, tech_stack, architecture, conventions, file_count, chunk_count, updated_at)
+	if err := s.writable("upsert project"); err != nil {
+		return err
+	}
+	techStack, err := normalizeTechStackJSON(p.TechStack)
+	if err != nil {
+		return err
+	}
+	p.TechStack = techStack
+	now := s.now()
+
+	_, err = s.db.Conn().Exec(`
+		INSERT INTO project (id, name, root_path, tech_stack, architecture, conventions, file_count, chunk_count, created_at, updated_at)
 		VALUES (COALESCE((SELECT id FROM project LIMIT 1), lower(hex(randomblob(16)))),
-		        ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		        ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 		    name         = excluded.name,
 		    root_path    = excluded.root_path,
@@ -40,13 +96,34 @@ func (s *Store) UpsertProject(p Project) error {
 		    conventions  = excluded.conventions,
 		    file_count   = excluded.file_count,
 		    chunk_count  = excluded.chunk_count,
-		    updated_at   = CURRENT_TIMESTAMP`,
+		    updated_at   = excluded.updated_at`,
 		p.Name, p.RootPath, p.TechStack, p.Architecture, p.Conventions,
-		p.FileCount, p.ChunkCount,
+		p.FileCount, p.ChunkCount, now, now,
 	)
 	return err
 }
 
+// normalizeTechStackJSON checks that s is a JSON object and returns it in
+// compact form. An empty string is treated as an empty stack ("{}").
+func normalizeTechStackJSON(s string) (string, error) {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return "{}", nil
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(trimmed), &obj); err != nil {
+		return "", fmt.Errorf("store: invalid tech stack JSON: %w", err)
+	}
+	if obj == nil {
+		return "", fmt.Errorf("store: invalid tech stack JSON: expected an object, got null")
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(trimmed)); err != nil {
+		return "", fmt.Errorf("store: invalid tech stack JSON: %w", err)
+	}
+	return buf.String(), nil
+}
+
 // GetProject returns the single project record, or an error if not found.
 func (s *Store) GetProject() (Project, error) {
 	var p Project
@@ -69,21 +146,115 @@ func (s *Store) GetProject() (Project, error) {
 
 // UpsertFile inserts or updates a file record. Returns the file ID.
 func (s *Store) UpsertFile(f File) (string, error) {
+	if err := s.writable("upsert file"); err != nil {
+		return "", err
+	}
 	var id string
 	err := s.db.Conn().QueryRow(`
-		INSERT INTO files (id, path, language, last_modified, content_hash)
-		VALUES (lower(hex(randomblob(16))), ?, ?, ?, ?)
+		INSERT INTO files (id, path, language, last_modified, content_hash, indexed_at)
+		VALUES (lower(hex(randomblob(16))), ?, ?, ?, ?, ?)
 		ON CONFLICT(path) DO UPDATE SET
 		    language      = excluded.language,
 		    last_modified = excluded.last_modified,
 		    content_hash  = excluded.content_hash,
-		    indexed_at    = CURRENT_TIMESTAMP
+		    indexed_at    = excluded.indexed_at
 		RETURNING id`,
-		f.Path, f.Language, f.LastModified.UTC(), f.ContentHash,
+		f.Path, f.Language, f.LastModified.UTC(), f.ContentHash, s.now(),
 	).Scan(&id)
 	return id, err
 }
 
+// FileIndexResult reports what IndexFile did.
+type FileIndexResult struct {
+	FileID  string
+	Added   bool // the path had no file row before
+	Changed bool // chunks were (re)written; false when the content hash matched
+	// StaleChunkIDs are the replaced chunks. Their embeddings live in the
+	// VectorStore and must be deleted by the caller.
+	StaleChunkIDs []string
+}
+
+// IndexFile upserts f and stores chunks for it in one transaction. When the
+// stored row already has f's ContentHash and force is false, nothing is
+// written, so existing chunks keep their IDs and embeddings. Otherwise the
+// file's old chunks are deleted and reported in StaleChunkIDs.
+func (s *Store) IndexFile(f File, chunks []Chunk, force bool) (FileIndexResult, error) {
+	if err := s.writable("index file"); err != nil {
+		return FileIndexResult{}, err
+	}
+	var res FileIndexResult
+	tx, err := s.db.Conn().Begin()
+	if err != nil {
+		return res, fmt.Errorf("store: index file %s: %w", f.Path, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var existingID, existingHash string
+	err = tx.QueryRow(`SELECT id, COALESCE(content_hash, '') FROM files WHERE path = ?`, f.Path).Scan(&existingID, &existingHash)
+	switch {
+	case err == sql.ErrNoRows:
+		res.Added = true
+	case err != nil:
+		return res, fmt.Errorf("store: index file %s: %w", f.Path, err)
+	case existingHash == f.ContentHash && !force:
+		res.FileID = existingID
+		return res, nil
+	}
+
+	if err := tx.QueryRow(`
+		INSERT INTO files (id, path, language, last_modified, content_hash, indexed_at)
+		VALUES (lower(hex(randomblob(16))), ?, ?, ?, ?, ?)
+		ON CONFLICT(path) DO UPDATE SET
+		    language      = excluded.language,
+		    last_modified = excluded.last_modified,
+		    content_hash  = excluded.content_hash,
+		    indexed_at    = excluded.indexed_at
+		RETURNING id`,
+		f.Path, f.Language, f.LastModified.UTC(), f.ContentHash, s.now(),
+	).Scan(&res.FileID); err != nil {
+		return res, fmt.Errorf("store: index file %s: %w", f.Path, err)
+	}
+
+	if !res.Added {
+		rows, err := tx.Query(`SELECT id FROM chunks WHERE file_id = ?`, res.FileID)
+		if err != nil {
+			return res, fmt.Errorf("store: index file %s: %w", f.Path, err)
+		}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				_ = rows.Close()
+				return res, fmt.Errorf("store: index file %s: %w", f.Path, err)
+			}
+			res.StaleChunkIDs = append(res.StaleChunkIDs, id)
+		}
+		err = rows.Err()
+		_ = rows.Close()
+		if err != nil {
+			return res, fmt.Errorf("store: index file %s: %w", f.Path, err)
+		}
+		if _, err := tx.Exec(`DELETE FROM chunks WHERE file_id = ?`, res.FileID); err != nil {
+			return res, fmt.Errorf("store: index file %s: %w", f.Path, err)
+		}
+	}
+
+	for _, c := range chunks {
+		if _, err := tx.Exec(`
+			INSERT INTO chunks (id, file_id, content, start_line, end_line, chunk_type)
+			VALUES (lower(hex(randomblob(16))), ?, ?, ?, ?, ?)`,
+			res.FileID, s.encodeChunkContent(c.Content), c.StartLine, c.EndLine, c.ChunkType,
+		); err != nil {
+			return res, fmt.Errorf("store: index file %s: chunk: %w", f.Path, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return res, fmt.Errorf("store: index file %s: %w", f.Path, err)
+	}
+	res.Changed = true
+	return res, nil
+}
+
 // GetFileByPath returns the file record for the given relative path.
 func (s *Store) GetFileByPath(path string) (File, error) {
 	var f File
@@ -101,22 +272,28 @@ func (s *Store) GetFileByPath(path string) (File, error) {
 
 // InsertChunk stores a new chunk. fileID must be a valid files.id.
 func (s *Store) InsertChunk(c Chunk) error {
+	if err := s.writable("insert chunk"); err != nil {
+		return err
+	}
 	_, err := s.db.Conn().Exec(`
 		INSERT INTO chunks (id, file_id, content, start_line, end_line, chunk_type)
 		VALUES (lower(hex(randomblob(16))), ?, ?, ?, ?, ?)`,
-		c.FileID, c.Content, c.StartLine, c.EndLine, c.ChunkType,
+		c.FileID, s.encodeChunkContent(c.Content), c.StartLine, c.EndLine, c.ChunkType,
 	)
 	return err
 }
 
 // InsertChunkReturningID inserts a chunk and returns its generated ID.
 func (s *Store) InsertChunkReturningID(c Chunk) (string, error) {
+	if err := s.writable("insert chunk"); err != nil {
+		return "", err
+	}
 	var id string
 	err := s.db.Conn().QueryRow(`
 		INSERT INTO chunks (id, file_id, content, start_line, end_line, chunk_type)
 		VALUES (lower(hex(randomblob(16))), ?, ?, ?, ?, ?)
 		RETURNING id`,
-		c.FileID, c.Content, c.StartLine, c.EndLine, c.ChunkType,
+		c.FileID, s.encodeChunkContent(c.Content), c.StartLine, c.EndLine, c.ChunkType,
 	).Scan(&id)
 	return id, err
 }
@@ -134,7 +311,11 @@ func (s *Store) ListAllChunks() ([]Chunk, error) {
 	var chunks []Chunk
 	for rows.Next() {
 		var c Chunk
-		if err := rows.Scan(&c.ID, &c.FileID, &c.Content, &c.StartLine, &c.EndLine, &c.ChunkType); err != nil {
+		var content []byte
+		if err := rows.Scan(&c.ID, &c.FileID, &content, &c.StartLine, &c.EndLine, &c.ChunkType); err != nil {
+			return nil, err
+		}
+		if c.Content, err = decodeChunkContent(content); err != nil {
 			return nil, err
 		}
 		chunks = append(chunks, c)
@@ -144,6 +325,9 @@ func (s *Store) ListAllChunks() ([]Chunk, error) {
 
 // DeleteChunksByFileID removes all chunks for a given file (used on re-index).
 func (s *Store) DeleteChunksByFileID(fileID string) error {
+	if err := s.writable("delete chunks"); err != nil {
+		return err
+	}
 	_, err := s.db.Conn().Exec(`DELETE FROM chunks WHERE file_id = ?`, fileID)
 	return err
 }
@@ -166,6 +350,43 @@ func (s *Store) CountFiles() (int, error) {
 
 // InsertMemory persists a new memory and returns its generated ID.
 func (s *Store) InsertMemory(m Memory) (string, error) {
+	if err := s.writable("insert memory"); err != nil {
+		return "", err
+	}
+	return s.insertMemory(s.db.Conn(), m)
+}
+
+// InsertMemories persists ms in one transaction and returns their generated
+// IDs in order. Either every memory is stored or none is.
+func (s *Store) InsertMemories(ms []Memory) ([]string, error) {
+	if err := s.writable("insert memories"); err != nil {
+		return nil, err
+	}
+	tx, err := s.db.Conn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("store: insert memories: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	ids := make([]string, len(ms))
+	for i, m := range ms {
+		if ids[i], err = s.insertMemory(tx, m); err != nil {
+			return nil, fmt.Errorf("store: insert memories: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("store: insert memories: %w", err)
+	}
+	return ids, nil
+}
+
+// rowQuerier is satisfied by both *sql.DB and *sql.Tx.
+type rowQuerier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// insertMemory inserts m through q, generating its ID.
+func (s *Store) insertMemory(q rowQuerier, m Memory) (string, error) {
 	relatedJSON := "[]"
 	if len(m.RelatedFiles) > 0 {
 		b, _ := json.Marshal(m.RelatedFiles)
@@ -176,18 +397,138 @@ func (s *Store) InsertMemory(m Memory) (string, error) {
 		source = "user"
 	}
 
+	now := s.now()
 	var id string
-	err := s.db.Conn().QueryRow(`
-		INSERT INTO memories (id, content, memory_type, importance, source, related_files)
-		VALUES (lower(hex(randomblob(16))), ?, ?, ?, ?, ?)
+	err := q.QueryRow(`
+		INSERT INTO memories (id, content, memory_type, importance, source, related_files, source_session_id, confidence, created_at, updated_at)
+		VALUES (lower(hex(randomblob(16))), ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		RETURNING id`,
-		m.Content, string(m.MemoryType), m.Importance, source, relatedJSON,
+		m.Content, string(m.MemoryType), m.Importance, source, relatedJSON, m.SourceSessionID, m.Confidence, now, now,
 	).Scan(&id)
 	return id, err
 }
 
+// ContentMemoryID derives a stable memory ID from its type and content, so
+// the same memory gets the same ID on every machine. It has the shape of the
+// random IDs InsertMemory generates (32 lowercase hex characters).
+func ContentMemoryID(m Memory) string {
+	sum := sha256.Sum256([]byte(string(m.MemoryType) + "\x00" + m.Content))
+	return hex.EncodeToString(sum[:16])
+}
+
+// InsertMemoryWithID stores m under m.ID, or under ContentMemoryID(m) when
+// m.ID is empty, and returns that ID. An existing memory with the ID is
+// updated in place instead of duplicated, so importing the same memories
+// twice is idempotent. m's importance, completion time and, when non-zero,
+// CreatedAt are stored as given, on insert and on update alike; a zero
+// CreatedAt keeps an existing memory's creation time.
+func (s *Store) InsertMemoryWithID(m Memory) (string, error) {
+	if err := s.writable("insert memory"); err != nil {
+		return "", err
+	}
+	id := m.ID
+	if id == "" {
+		id = ContentMemoryID(m)
+	}
+	relatedJSON := "[]"
+	if len(m.RelatedFiles) > 0 {
+		b, _ := json.Marshal(m.RelatedFiles)
+		relatedJSON = string(b)
+	}
+	source := m.Source
+	if source == "" {
+		source = "user"
+	}
+
+	now := s.now()
+	created := now
+	var createdArg any // nil keeps an existing row's created_at
+	if !m.CreatedAt.IsZero() {
+		created = m.CreatedAt.UTC().Format(sqliteTimeLayout)
+		createdArg = created
+	}
+	completed := ""
+	if m.CompletedAt != nil {
+		completed = m.CompletedAt.UTC().Format(sqliteTimeLayout)
+	}
+	_, err := s.db.Conn().Exec(`
+		INSERT INTO memories (id, content, memory_type, importance, source, related_files, source_session_id, confidence, created_at, updated_at, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+		    content           = excluded.content,
+		    memory_type       = excluded.memory_type,
+		    importance        = excluded.importance,
+		    source            = excluded.source,
+		    related_files     = excluded.related_files,
+		    source_session_id = excluded.source_session_id,
+		    confidence        = excluded.confidence,
+		    created_at        = COALESCE(?, memories.created_at),
+		    updated_at        = excluded.updated_at,
+		    completed_at      = excluded.completed_at`,
+		id, m.Content, string(m.MemoryType), m.Importance, source, relatedJSON, m.SourceSessionID, m.Confidence, created, now, completed,
+		createdArg,
+	)
+	if err != nil {
+		return "", fmt.Errorf("store: insert memory with id: %w", err)
+	}
+	return id, nil
+}
+
+// CompleteTodo marks the todo memory id as done. Completed todos stay in
+// the store, so they can still be listed and exported, but drop out of
+// built context. Completing a todo twice keeps the first completion time.
+func (s *Store) CompleteTodo(id string) error {
+	if err := s.writable("complete todo"); err != nil {
+		return err
+	}
+	m, err := s.GetMemoryByID(id)
+	if err != nil {
+		return err
+	}
+	if m.MemoryType != TypeTodo {
+		return fmt.Errorf("store: complete todo: memory %s is a %s, not a todo", id, m.MemoryType)
+	}
+	if m.Done() {
+		return nil
+	}
+	now := s.now()
+	if _, err := s.db.Conn().Exec(
+		`UPDATE memories SET completed_at = ?, updated_at = ? WHERE id = ?`, now, now, id,
+	); err != nil {
+		return fmt.Errorf("store: complete todo: %w", err)
+	}
+	return nil
+}
+
+// SetMemorySourceSession records sessionID as the origin of the given
+// memories, leaving any that already have a source session untouched.
+func (s *Store) SetMemorySourceSession(ids []string, sessionID string) error {
+	if err := s.writable("set memory source session"); err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := make([]any, 0, len(ids)+1)
+	args = append(args, sessionID)
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	_, err := s.db.Conn().Exec(
+		`UPDATE memories SET source_session_id = ? WHERE source_session_id = '' AND id IN (`+placeholders+`)`, args...,
+	)
+	if err != nil {
+		return fmt.Errorf("store: set memory source session: %w", err)
+	}
+	return nil
+}
+
 // DeleteMemory removes a memory by ID.
 func (s *Store) DeleteMemory(id string) error {
+	if err := s.writable("delete memory"); err != nil {
+		return err
+	}
 	res, err := s.db.Conn().Exec(`DELETE FROM memories WHERE id = ?`, id)
 	if err != nil {
 		return err
@@ -201,6 +542,9 @@ func (s *Store) DeleteMemory(id string) error {
 
 // DeleteMemoriesByType removes all memories of a given type.
 func (s *Store) DeleteMemoriesByType(t MemoryType) (int, error) {
+	if err := s.writable("delete memories"); err != nil {
+		return 0, err
+	}
 	res, err := s.db.Conn().Exec(`DELETE FROM memories WHERE memory_type = ?`, string(t))
 	if err != nil {
 		return 0, err
@@ -211,6 +555,9 @@ func (s *Store) DeleteMemoriesByType(t MemoryType) (int, error) {
 
 // DeleteAllMemories removes every memory record.
 func (s *Store) DeleteAllMemories() (int, error) {
+	if err := s.writable("delete memories"); err != nil {
+		return 0, err
+	}
 	res, err := s.db.Conn().Exec(`DELETE FROM memories`)
 	if err != nil {
 		return 0, err
@@ -227,11 +574,11 @@ func (s *Store) ListMemories(filterType MemoryType) ([]Memory, error) {
 
 	if filterType == "" {
 		rows, err = s.db.Conn().Query(
-			`SELECT id, content, memory_type, importance, source, related_files, created_at, updated_at FROM memories ORDER BY importance DESC, created_at DESC`,
+			`SELECT id, content, memory_type, importance, source, related_files, source_session_id, confidence, created_at, updated_at, completed_at FROM memories ORDER BY importance DESC, created_at DESC`,
 		)
 	} else {
 		rows, err = s.db.Conn().Query(
-			`SELECT id, content, memory_type, importance, source, related_files, created_at, updated_at FROM memories WHERE memory_type = ? ORDER BY importance DESC, created_at DESC`,
+			`SELECT id, content, memory_type, importance, source, related_files, source_session_id, confidence, created_at, updated_at, completed_at FROM memories WHERE memory_type = ? ORDER BY importance DESC, created_at DESC`,
 			string(filterType),
 		)
 	}
@@ -243,6 +590,141 @@ func (s *Store) ListMemories(filterType MemoryType) ([]Memory, error) {
 	return scanMemories(rows)
 }
 
+// listMemories returns every memory through tx, ordered like ListMemories.
+func (s *Store) listMemories(tx *sql.Tx) ([]Memory, error) {
+	rows, err := tx.Query(
+		`SELECT id, content, memory_type, importance, source, related_files, source_session_id, confidence, created_at, updated_at, completed_at FROM memories ORDER BY importance DESC, created_at DESC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanMemories(rows)
+}
+
+// ListMemoriesBetween returns memories of filterType ("" = all types) created
+// at or after since and before until, ordered like ListMemories. A zero
+// bound is left open, so with both zero it matches ListMemories.
+func (s *Store) ListMemoriesBetween(filterType MemoryType, since, until time.Time) ([]Memory, error) {
+	query := `SELECT id, content, memory_type, importance, source, related_files, source_session_id, confidence, created_at, updated_at, completed_at FROM memories WHERE 1 = 1`
+	var args []any
+	if filterType != "" {
+		query += ` AND memory_type = ?`
+		args = append(args, string(filterType))
+	}
+	if !since.IsZero() {
+		query += ` AND created_at >= ?`
+		args = append(args, since.UTC().Format(sqliteTimeLayout))
+	}
+	if !until.IsZero() {
+		query += ` AND created_at < ?`
+		args = append(args, until.UTC().Format(sqliteTimeLayout))
+	}
+	rows, err := s.db.Conn().Query(query+` ORDER BY importance DESC, created_at DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("store: list memories between: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	return scanMemories(rows)
+}
+
+// MemoriesContaining returns the memories whose content contains substr
+// (case-sensitive), ordered like ListMemories.
+func (s *Store) MemoriesContaining(substr string) ([]Memory, error) {
+	rows, err := s.db.Conn().Query(
+		`SELECT id, content, memory_type, importance, source, related_files, source_session_id, confidence, created_at, updated_at, completed_at FROM memories WHERE instr(content, ?) > 0 ORDER BY importance DESC, created_at DESC`,
+		substr,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("store: memories containing: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	return scanMemories(rows)
+}
+
+// AmbiguousMemoryError is returned by FindMemory when a query matches more
+// than one memory.
+type AmbiguousMemoryError struct {
+	Query      string
+	Candidates []Memory
+}
+
+// maxAmbiguousListed caps the candidates named in AmbiguousMemoryError's
+// message.
+const maxAmbiguousListed = 5
+
+func (e *AmbiguousMemoryError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "store: %q matches %d memories:", e.Query, len(e.Candidates))
+	for i, m := range e.Candidates {
+		if i == maxAmbiguousListed {
+			fmt.Fprintf(&sb, "\n  ... and %d more", len(e.Candidates)-i)
+			break
+		}
+		preview := m.Content
+		if len(preview) > 60 {
+			preview = preview[:57] + "..."
+		}
+		fmt.Fprintf(&sb, "\n  %s  %s", m.ID, preview)
+	}
+	return sb.String()
+}
+
+// FindMemory resolves query to a single memory: by exact ID first, then by
+// unique ID prefix or exact content. A query matching several memories
+// returns an *AmbiguousMemoryError listing them.
+func (s *Store) FindMemory(query string) (Memory, error) {
+	if query == "" {
+		return Memory{}, fmt.Errorf("store: find memory: empty query")
+	}
+	if m, err := s.GetMemoryByID(query); err == nil {
+		return m, nil
+	}
+	rows, err := s.db.Conn().Query(
+		`SELECT id, content, memory_type, importance, source, related_files, source_session_id, confidence, created_at, updated_at, completed_at FROM memories WHERE substr(id, 1, ?) = ? OR content = ? ORDER BY created_at DESC`,
+		len(query), query, query,
+	)
+	if err != nil {
+		return Memory{}, fmt.Errorf("store: find memory: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	matches, err := scanMemories(rows)
+	if err != nil {
+		return Memory{}, fmt.Errorf("store: find memory: %w", err)
+	}
+	switch len(matches) {
+	case 0:
+		return Memory{}, fmt.Errorf("store: memory %q not found", query)
+	case 1:
+		return matches[0], nil
+	default:
+		return Memory{}, &AmbiguousMemoryError{Query: query, Candidates: matches}
+	}
+}
+
+// ReplaceInMemories replaces every occurrence of old with new in memory
+// content and returns the number of memories changed. The match is
+// case-sensitive. IDs are kept; callers must re-embed the changed rows
+// (see MemoriesContaining) to keep vectors in step with the new text.
+func (s *Store) ReplaceInMemories(old, new string) (int, error) {
+	if err := s.writable("replace in memories"); err != nil {
+		return 0, err
+	}
+	if old == "" {
+		return 0, fmt.Errorf("store: replace in memories: empty search string")
+	}
+	res, err := s.db.Conn().Exec(
+		`UPDATE memories SET content = replace(content, ?, ?), updated_at = ? WHERE instr(content, ?) > 0`,
+		old, new, s.now(), old,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("store: replace in memories: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
 // CountMemoriesByType returns a count per memory type.
 func (s *Store) CountMemoriesByType() (map[MemoryType]int, error) {
 	rows, err := s.db.Conn().Query(
@@ -274,28 +756,58 @@ func (s *Store) CountSessions() (int, error) {
 
 // InsertSession records a completed ask session.
 func (s *Store) InsertSession(sess Session) error {
+	if err := s.writable("insert session"); err != nil {
+		return err
+	}
 	_, err := s.db.Conn().Exec(`
-		INSERT INTO sessions (id, question, context_used, response_summary, model_used, tokens_used)
-		VALUES (lower(hex(randomblob(16))), ?, ?, ?, ?, ?)`,
-		sess.Question, sess.ContextUsed, sess.ResponseSummary, sess.ModelUsed, sess.TokensUsed,
+		INSERT INTO sessions (id, question, context_used, response_summary, model_used, tokens_used, status, next_steps, tags, created_at)
+		VALUES (lower(hex(randomblob(16))), ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		sess.Question, sess.ContextUsed, sess.ResponseSummary, sess.ModelUsed, sess.TokensUsed, sessionStatus(sess), nextStepsJSON(sess), tagsJSON(sess), s.now(),
 	)
 	return err
 }
 
 // InsertSessionReturningID records a completed ask session and returns its generated ID.
 func (s *Store) InsertSessionReturningID(sess Session) (string, error) {
+	if err := s.writable("insert session"); err != nil {
+		return "", err
+	}
 	var id string
 	err := s.db.Conn().QueryRow(`
-		INSERT INTO sessions (id, question, context_used, response_summary, model_used, tokens_used)
-		VALUES (lower(hex(randomblob(16))), ?, ?, ?, ?, ?)
+		INSERT INTO sessions (id, question, context_used, response_summary, model_used, tokens_used, status, next_steps, tags, created_at)
+		VALUES (lower(hex(randomblob(16))), ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		RETURNING id`,
-		sess.Question, sess.ContextUsed, sess.ResponseSummary, sess.ModelUsed, sess.TokensUsed,
+		sess.Question, sess.ContextUsed, sess.ResponseSummary, sess.ModelUsed, sess.TokensUsed, sessionStatus(sess), nextStepsJSON(sess), tagsJSON(sess), s.now(),
 	).Scan(&id)
 	return id, err
 }
 
+// ReplaceSession overwrites session id with sess and moves it to the store's
+// current time, so a repeat of the same work replaces the earlier record.
+func (s *Store) ReplaceSession(id string, sess Session) error {
+	if err := s.writable("replace session"); err != nil {
+		return err
+	}
+	res, err := s.db.Conn().Exec(`
+		UPDATE sessions
+		SET question = ?, context_used = ?, response_summary = ?, model_used = ?, tokens_used = ?, status = ?, next_steps = ?, tags = ?, created_at = ?
+		WHERE id = ?`,
+		sess.Question, sess.ContextUsed, sess.ResponseSummary, sess.ModelUsed, sess.TokensUsed, sessionStatus(sess), nextStepsJSON(sess), tagsJSON(sess), s.now(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("store: replace session: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("store: replace session: %s not found", id)
+	}
+	return nil
+}
+
 // UpdateSessionSummary replaces the response_summary for an existing session.
 func (s *Store) UpdateSessionSummary(id, summary string) error {
+	if err := s.writable("update session summary"); err != nil {
+		return err
+	}
 	_, err := s.db.Conn().Exec(
 		`UPDATE sessions SET response_summary = ? WHERE id = ?`,
 		summary, id,
@@ -303,12 +815,16 @@ func (s *Store) UpdateSessionSummary(id, summary string) error {
 	return err
 }
 
-// PruneSessions deletes sessions older than the given number of days.
-// Returns the number of deleted rows.
+// PruneSessions deletes sessions older than the given number of days,
+// measured from the store's clock. Returns the number of deleted rows.
 func (s *Store) PruneSessions(olderThanDays int) (int, error) {
+	if err := s.writable("prune sessions"); err != nil {
+		return 0, err
+	}
+	cutoff := clockNow(s.clock).AddDate(0, 0, -olderThanDays).UTC().Format(sqliteTimeLayout)
 	res, err := s.db.Conn().Exec(
-		`DELETE FROM sessions WHERE created_at < datetime('now', '-' || ? || ' days')`,
-		olderThanDays,
+		`DELETE FROM sessions WHERE created_at < ?`,
+		cutoff,
 	)
 	if err != nil {
 		return 0, fmt.Errorf("store: prune sessions: %w", err)
@@ -320,6 +836,9 @@ func (s *Store) PruneSessions(olderThanDays int) (int, error) {
 // PruneSessionsKeepLatest deletes all but the latest N sessions.
 // Returns the number of deleted rows.
 func (s *Store) PruneSessionsKeepLatest(keep int) (int, error) {
+	if err := s.writable("prune sessions keep latest"); err != nil {
+		return 0, err
+	}
 	res, err := s.db.Conn().Exec(`
 		DELETE FROM sessions WHERE id NOT IN (
 			SELECT id FROM sessions ORDER BY created_at DESC LIMIT ?
@@ -332,13 +851,40 @@ func (s *Store) PruneSessionsKeepLatest(keep int) (int, error) {
 	return int(n), nil
 }
 
+// PruneSessionsByPolicy deletes finished sessions outside policy, keeping
+// in-progress and blocked sessions whatever their age. Returns the number
+// of deleted rows.
+func (s *Store) PruneSessionsByPolicy(policy RetentionPolicy) (int, error) {
+	if err := s.writable("prune sessions by policy"); err != nil {
+		return 0, err
+	}
+	if policy.IsZero() {
+		return 0, nil
+	}
+	cutoff := clockNow(s.clock).AddDate(0, 0, -policy.KeepDays).UTC().Format(sqliteTimeLayout)
+	res, err := s.db.Conn().Exec(`
+		DELETE FROM sessions
+		WHERE status NOT IN (?, ?)
+		  AND (? <= 0 OR id NOT IN (SELECT id FROM sessions ORDER BY created_at DESC LIMIT ?))
+		  AND (? <= 0 OR created_at < ?)`,
+		string(SessionInProgress), string(SessionBlocked),
+		policy.KeepLastN, policy.KeepLastN,
+		policy.KeepDays, cutoff,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("store: prune sessions by policy: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
 // GetLastNSessions returns the N most recent sessions, ordered newest first.
 func (s *Store) GetLastNSessions(n int) ([]Session, error) {
 	if n <= 0 {
 		return nil, nil
 	}
 	rows, err := s.db.Conn().Query(`
-		SELECT id, question, context_used, response_summary, model_used, tokens_used, created_at
+		SELECT id, question, context_used, response_summary, model_used, tokens_used, status, next_steps, tags, created_at
 		FROM sessions
 		ORDER BY created_at DESC
 		LIMIT ?`, n,
@@ -347,29 +893,35 @@ func (s *Store) GetLastNSessions(n int) ([]Session, error) {
 		return nil, fmt.Errorf("store: get last n sessions: %w", err)
 	}
 	defer func() { _ = rows.Close() }()
+	return scanSessions(rows)
+}
 
-	var out []Session
-	for rows.Next() {
-		var sess Session
-		var createdAt string
-		if err := rows.Scan(
-			&sess.ID, &sess.Question, &sess.ContextUsed,
-			&sess.ResponseSummary, &sess.ModelUsed, &sess.TokensUsed,
-			&createdAt,
-		); err != nil {
-			return nil, err
-		}
-		sess.CreatedAt = parseTime(createdAt)
-		out = append(out, sess)
+// GetLastNSessionsTagged returns the n most recent sessions carrying tag,
+// ordered newest first.
+func (s *Store) GetLastNSessionsTagged(n int, tag string) ([]Session, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if n <= 0 || tag == "" {
+		return nil, nil
 	}
-	return out, rows.Err()
+	rows, err := s.db.Conn().Query(`
+		SELECT id, question, context_used, response_summary, model_used, tokens_used, status, next_steps, tags, created_at
+		FROM sessions
+		WHERE EXISTS (SELECT 1 FROM json_each(sessions.tags) WHERE json_each.value = ?)
+		ORDER BY created_at DESC
+		LIMIT ?`, tag, n,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("store: get last n sessions tagged: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	return scanSessions(rows)
 }
 
 // ListMemoriesSince returns all memories created or updated since the given time.
 func (s *Store) ListMemoriesSince(since time.Time) ([]Memory, error) {
-	ts := since.UTC().Format("2006-01-02 15:04:05")
+	ts := since.UTC().Format(sqliteTimeLayout)
 	rows, err := s.db.Conn().Query(
-		`SELECT id, content, memory_type, importance, source, related_files, created_at, updated_at
+		`SELECT id, content, memory_type, importance, source, related_files, source_session_id, confidence, created_at, updated_at, completed_at
 		 FROM memories
 		 WHERE created_at >= ? OR updated_at >= ?
 		 ORDER BY memory_type, created_at DESC`,
@@ -384,9 +936,9 @@ func (s *Store) ListMemoriesSince(since time.Time) ([]Memory, error) {
 
 // ListSessionsSince returns all sessions created since the given time.
 func (s *Store) ListSessionsSince(since time.Time) ([]Session, error) {
-	ts := since.UTC().Format("2006-01-02 15:04:05")
+	ts := since.UTC().Format(sqliteTimeLayout)
 	rows, err := s.db.Conn().Query(
-		`SELECT id, question, context_used, response_summary, model_used, tokens_used, created_at
+		`SELECT id, question, context_used, response_summary, model_used, tokens_used, status, next_steps, tags, created_at
 		 FROM sessions
 		 WHERE created_at >= ?
 		 ORDER BY created_at DESC`,
@@ -396,26 +948,91 @@ func (s *Store) ListSessionsSince(since time.Time) ([]Session, error) {
 		return nil, fmt.Errorf("store: list sessions since: %w", err)
 	}
 	defer func() { _ = rows.Close() }()
+	return scanSessions(rows)
+}
+
+// ---- Helpers ----
+
+// ChunkIDs returns the IDs of all stored chunks.
+func (s *Store) ChunkIDs() (map[string]struct{}, error) {
+	return s.idSet(`SELECT id FROM chunks`)
+}
+
+// MemoryIDs returns the IDs of all stored memories.
+func (s *Store) MemoryIDs() (map[string]struct{}, error) {
+	return s.idSet(`SELECT id FROM memories`)
+}
+
+func (s *Store) idSet(query string) (map[string]struct{}, error) {
+	rows, err := s.db.Conn().Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("store: list ids: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	ids := make(map[string]struct{})
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("store: list ids: %w", err)
+		}
+		ids[id] = struct{}{}
+	}
+	return ids, rows.Err()
+}
+
+// sessionStatus returns the status to store for sess; sessions recorded
+// without one (e.g. from memvra ask) count as completed.
+func sessionStatus(sess Session) SessionStatus {
+	if sess.Status == "" {
+		return SessionCompleted
+	}
+	return sess.Status
+}
+
+// nextStepsJSON encodes sess.NextSteps for the next_steps column.
+func nextStepsJSON(sess Session) string {
+	if len(sess.NextSteps) == 0 {
+		return "[]"
+	}
+	b, _ := json.Marshal(sess.NextSteps)
+	return string(b)
+}
+
+// tagsJSON encodes sess.Tags, normalized, for the tags column.
+func tagsJSON(sess Session) string {
+	tags := NormalizeTags(sess.Tags)
+	if len(tags) == 0 {
+		return "[]"
+	}
+	b, _ := json.Marshal(tags)
+	return string(b)
+}
 
+// scanSessions reads session rows selected with the standard column list.
+func scanSessions(rows *sql.Rows) ([]Session, error) {
 	var out []Session
 	for rows.Next() {
 		var sess Session
-		var createdAt string
+		var nextSteps, tags, createdAt string
 		if err := rows.Scan(
 			&sess.ID, &sess.Question, &sess.ContextUsed,
 			&sess.ResponseSummary, &sess.ModelUsed, &sess.TokensUsed,
-			&createdAt,
+			&sess.Status, &nextSteps, &tags, &createdAt,
 		); err != nil {
 			return nil, err
 		}
 		sess.CreatedAt = parseTime(createdAt)
+		if nextSteps != "" && nextSteps != "[]" {
+			_ = json.Unmarshal([]byte(nextSteps), &sess.NextSteps)
+		}
+		if tags != "" && tags != "[]" {
+			_ = json.Unmarshal([]byte(tags), &sess.Tags)
+		}
 		out = append(out, sess)
 	}
 	return out, rows.Err()
 }
 
-// ---- Helpers ----
-
 // parseTime tries multiple SQLite timestamp layouts.
 // go-sqlite3 may return RFC3339 or the plain "2006-01-02 15:04:05" format depending on
 // the connection string and platform.
@@ -434,17 +1051,27 @@ func parseTime(s string) time.Time {
 	return time.Time{}
 }
 
+// parseOptionalTime parses a timestamp column where "" means unset.
+func parseOptionalTime(s string) *time.Time {
+	if s == "" {
+		return nil
+	}
+	t := parseTime(s)
+	return &t
+}
+
 func scanMemories(rows *sql.Rows) ([]Memory, error) {
 	var out []Memory
 	for rows.Next() {
 		var m Memory
-		var mt, createdAt, updatedAt, relatedFiles string
-		if err := rows.Scan(&m.ID, &m.Content, &mt, &m.Importance, &m.Source, &relatedFiles, &createdAt, &updatedAt); err != nil {
+		var mt, createdAt, updatedAt, completedAt, relatedFiles string
+		if err := rows.Scan(&m.ID, &m.Content, &mt, &m.Importance, &m.Source, &relatedFiles, &m.SourceSessionID, &m.Confidence, &createdAt, &updatedAt, &completedAt); err != nil {
 			return nil, err
 		}
 		m.MemoryType = MemoryType(mt)
 		m.CreatedAt = parseTime(createdAt)
 		m.UpdatedAt = parseTime(updatedAt)
+		m.CompletedAt = parseOptionalTime(completedAt)
 		if relatedFiles != "" && relatedFiles != "[]" {
 			_ = json.Unmarshal([]byte(relatedFiles), &m.RelatedFiles)
 		}
@@ -456,23 +1083,28 @@ func scanMemories(rows *sql.Rows) ([]Memory, error) {
 // GetChunkByID returns a single chunk by its ID.
 func (s *Store) GetChunkByID(id string) (Chunk, error) {
 	var c Chunk
+	var content []byte
 	var createdAt string
 	err := s.db.Conn().QueryRow(
 		`SELECT id, file_id, content, start_line, end_line, chunk_type, created_at FROM chunks WHERE id = ?`, id,
-	).Scan(&c.ID, &c.FileID, &c.Content, &c.StartLine, &c.EndLine, &c.ChunkType, &createdAt)
+	).Scan(&c.ID, &c.FileID, &content, &c.StartLine, &c.EndLine, &c.ChunkType, &createdAt)
 	if err == sql.ErrNoRows {
 		return c, fmt.Errorf("store: chunk %q not found", id)
 	}
+	if err != nil {
+		return c, err
+	}
+	c.Content, err = decodeChunkContent(content)
 	return c, err
 }
 
 // GetMemoryByID returns a single memory by its ID.
 func (s *Store) GetMemoryByID(id string) (Memory, error) {
 	var m Memory
-	var mt, createdAt, updatedAt, relatedFiles string
+	var mt, createdAt, updatedAt, completedAt, relatedFiles string
 	err := s.db.Conn().QueryRow(
-		`SELECT id, content, memory_type, importance, source, related_files, created_at, updated_at FROM memories WHERE id = ?`, id,
-	).Scan(&m.ID, &m.Content, &mt, &m.Importance, &m.Source, &relatedFiles, &createdAt, &updatedAt)
+		`SELECT id, content, memory_type, importance, source, related_files, source_session_id, confidence, created_at, updated_at, completed_at FROM memories WHERE id = ?`, id,
+	).Scan(&m.ID, &m.Content, &mt, &m.Importance, &m.Source, &relatedFiles, &m.SourceSessionID, &m.Confidence, &createdAt, &updatedAt, &completedAt)
 	if err == sql.ErrNoRows {
 		return m, fmt.Errorf("store: memory %q not found", id)
 	}
@@ -482,6 +1114,7 @@ func (s *Store) GetMemoryByID(id string) (Memory, error) {
 	m.MemoryType = MemoryType(mt)
 	m.CreatedAt = parseTime(createdAt)
 	m.UpdatedAt = parseTime(updatedAt)
+	m.CompletedAt = parseOptionalTime(completedAt)
 	if relatedFiles != "" && relatedFiles != "[]" {
 		_ = json.Unmarshal([]byte(relatedFiles), &m.RelatedFiles)
 	}
@@ -524,7 +1157,11 @@ func (s *Store) ListChunksByFileID(fileID string) ([]Chunk, error) {
 	var chunks []Chunk
 	for rows.Next() {
 		var c Chunk
-		if err := rows.Scan(&c.ID, &c.FileID, &c.Content, &c.StartLine, &c.EndLine, &c.ChunkType); err != nil {
+		var content []byte
+		if err := rows.Scan(&c.ID, &c.FileID, &content, &c.StartLine, &c.EndLine, &c.ChunkType); err != nil {
+			return nil, err
+		}
+		if c.Content, err = decodeChunkContent(content); err != nil {
 			return nil, err
 		}
 		chunks = append(chunks, c)
@@ -534,6 +1171,9 @@ func (s *Store) ListChunksByFileID(fileID string) ([]Chunk, error) {
 
 // DeleteFile removes a file record. Chunks are cascade-deleted by SQLite.
 func (s *Store) DeleteFile(id string) error {
+	if err := s.writable("delete file"); err != nil {
+		return err
+	}
 	_, err := s.db.Conn().Exec(`DELETE FROM files WHERE id = ?`, id)
 	return err
 }
@@ -550,3 +1190,96 @@ func (s *Store) GetFileByID(id string) (File, error) {
 	}
 	return f, err
 }
+
+// GetFilesByIDs returns the file records for ids in a single query, keyed by
+// file ID. IDs with no matching file are absent from the returned map.
+func (s *Store) GetFilesByIDs(ids []string) (map[string]File, error) {
+	out := make(map[string]File, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := s.db.Conn().Query(
+		`SELECT id, path, language, last_modified, content_hash, indexed_at FROM files WHERE id IN (`+placeholders+`)`, args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("store: get files by ids: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var f File
+		var lastMod, indexedAt string
+		if err := rows.Scan(&f.ID, &f.Path, &f.Language, &lastMod, &f.ContentHash, &indexedAt); err != nil {
+			return nil, err
+		}
+		out[f.ID] = f
+	}
+	return out, rows.Err()
+}
+
+// ---- Embedding cache ----
+
+// GetCachedEmbeddings returns the cached vectors for the given cache keys.
+// Keys with no cached entry are absent from the returned map.
+func (s *Store) GetCachedEmbeddings(keys []string) (map[string][]float32, error) {
+	out := make(map[string][]float32, len(keys))
+	if len(keys) == 0 {
+		return out, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(keys)), ",")
+	args := make([]any, len(keys))
+	for i, k := range keys {
+		args[i] = k
+	}
+	rows, err := s.db.Conn().Query(
+		`SELECT key, embedding FROM embedding_cache WHERE key IN (`+placeholders+`)`, args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("store: get cached embeddings: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var key string
+		var blob []byte
+		if err := rows.Scan(&key, &blob); err != nil {
+			return nil, err
+		}
+		out[key] = BlobToFloat32Slice(blob)
+	}
+	return out, rows.Err()
+}
+
+// PutCachedEmbedding stores (or replaces) the vector for a cache key.
+func (s *Store) PutCachedEmbedding(key, model string, embedding []float32) error {
+	if err := s.writable("put cached embedding"); err != nil {
+		return err
+	}
+	if len(embedding) == 0 {
+		return nil
+	}
+	_, err := s.db.Conn().Exec(`
+		INSERT INTO embedding_cache (key, model, embedding, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET
+		    model      = excluded.model,
+		    embedding  = excluded.embedding,
+		    created_at = excluded.created_at`,
+		key, model, float32SliceToBlob(embedding), s.now(),
+	)
+	if err != nil {
+		return fmt.Errorf("store: put cached embedding: %w", err)
+	}
+	return nil
+}
+
+// CountCachedEmbeddings returns the number of entries in the embedding cache.
+func (s *Store) CountCachedEmbeddings() (int, error) {
+	var n int
+	err := s.db.Conn().QueryRow(`SELECT COUNT(*) FROM embedding_cache`).Scan(&n)
+	return n, err
+}
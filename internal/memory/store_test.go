@@ -1,8 +1,10 @@
 package memory
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -20,6 +22,54 @@ func setupTestDB(t *testing.T) (*db.DB, *Store) {
 	return database, NewStore(database)
 }
 
+func TestStore_ReadOnly(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	rw, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := NewStore(rw).InsertMemory(Memory{Content: "use Go", MemoryType: TypeDecision}); err != nil {
+		t.Fatalf("InsertMemory: %v", err)
+	}
+	rw.Close()
+
+	ro, err := db.OpenReadOnly(dbPath)
+	if err != nil {
+		t.Fatalf("OpenReadOnly: %v", err)
+	}
+	defer ro.Close()
+	store := NewStore(ro)
+
+	if _, err := store.InsertMemory(Memory{Content: "use Rust", MemoryType: TypeDecision}); !errors.Is(err, db.ErrReadOnly) {
+		t.Errorf("InsertMemory: expected ErrReadOnly, got %v", err)
+	}
+	if _, err := store.DeleteAllMemories(); !errors.Is(err, db.ErrReadOnly) {
+		t.Errorf("DeleteAllMemories: expected ErrReadOnly, got %v", err)
+	}
+	if err := NewVectorStore(ro).UpsertMemoryEmbedding("m", "id", []float32{1}); !errors.Is(err, db.ErrReadOnly) {
+		t.Errorf("UpsertMemoryEmbedding: expected ErrReadOnly, got %v", err)
+	}
+	// SQLite itself refuses writes that bypass the store.
+	if _, err := ro.Conn().Exec(`DELETE FROM memories`); err == nil {
+		t.Error("expected raw write to fail on a read-only connection")
+	}
+	if mems, err := store.ListMemories(""); err != nil || len(mems) != 1 {
+		t.Errorf("reads should still work: %d memories, err %v", len(mems), err)
+	}
+}
+
+func TestStore_SetReadOnly(t *testing.T) {
+	_, store := setupTestDB(t)
+	store.SetReadOnly(true)
+	if _, err := store.InsertMemory(Memory{Content: "x", MemoryType: TypeNote}); !errors.Is(err, db.ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+	store.SetReadOnly(false)
+	if _, err := store.InsertMemory(Memory{Content: "x", MemoryType: TypeNote}); err != nil {
+		t.Errorf("InsertMemory after SetReadOnly(false): %v", err)
+	}
+}
+
 func TestStore_UpsertAndGetProject(t *testing.T) {
 	_, store := setupTestDB(t)
 
@@ -59,6 +109,45 @@ func TestStore_UpsertProject_Updates(t *testing.T) {
 	}
 }
 
+func TestStore_UpsertProject_InvalidTechStack(t *testing.T) {
+	_, store := setupTestDB(t)
+
+	for _, bad := range []string{`{"language":`, `not json`, `["Go"]`, `null`} {
+		err := store.UpsertProject(Project{Name: "bad", RootPath: "/tmp/test", TechStack: bad})
+		if err == nil {
+			t.Errorf("expected error for tech stack %q", bad)
+			continue
+		}
+		if !strings.Contains(err.Error(), "invalid tech stack JSON") {
+			t.Errorf("unexpected error for %q: %v", bad, err)
+		}
+	}
+
+	if _, err := store.GetProject(); err == nil {
+		t.Error("invalid tech stack should not have been stored")
+	}
+}
+
+func TestStore_UpsertProject_NormalizesTechStack(t *testing.T) {
+	_, store := setupTestDB(t)
+
+	if err := store.UpsertProject(Project{Name: "p", RootPath: "/tmp/test", TechStack: "{ \"language\" : \"Go\" }\n"}); err != nil {
+		t.Fatalf("UpsertProject: %v", err)
+	}
+	got, _ := store.GetProject()
+	if got.TechStack != `{"language":"Go"}` {
+		t.Errorf("tech stack not compacted: got %q", got.TechStack)
+	}
+
+	if err := store.UpsertProject(Project{Name: "p", RootPath: "/tmp/test"}); err != nil {
+		t.Fatalf("UpsertProject with empty tech stack: %v", err)
+	}
+	got, _ = store.GetProject()
+	if got.TechStack != "{}" {
+		t.Errorf("empty tech stack: got %q, want {}", got.TechStack)
+	}
+}
+
 func TestStore_GetProject_NotInitialised(t *testing.T) {
 	_, store := setupTestDB(t)
 
@@ -234,6 +323,94 @@ func TestStore_DeleteMemory_NotFound(t *testing.T) {
 	}
 }
 
+func TestStore_CompleteTodo(t *testing.T) {
+	_, store := setupTestDB(t)
+	clock := NewManualClock(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	store.SetClock(clock)
+
+	id, _ := store.InsertMemory(Memory{Content: "add rate limiting", MemoryType: TypeTodo, Importance: 0.6})
+	if m, _ := store.GetMemoryByID(id); m.Done() {
+		t.Fatal("new todo should be open")
+	}
+
+	clock.Advance(time.Hour)
+	if err := store.CompleteTodo(id); err != nil {
+		t.Fatalf("CompleteTodo: %v", err)
+	}
+	m, _ := store.GetMemoryByID(id)
+	if !m.Done() || !m.CompletedAt.Equal(clock.Now()) {
+		t.Errorf("CompletedAt = %v, want %v", m.CompletedAt, clock.Now())
+	}
+
+	// Completing again keeps the first completion time.
+	clock.Advance(time.Hour)
+	store.CompleteTodo(id)
+	if again, _ := store.GetMemoryByID(id); !again.CompletedAt.Equal(*m.CompletedAt) {
+		t.Errorf("second CompleteTodo moved CompletedAt to %v", again.CompletedAt)
+	}
+
+	// Completed todos are still listed, for history.
+	if todos, _ := store.ListMemories(TypeTodo); len(todos) != 1 || !todos[0].Done() {
+		t.Errorf("expected the completed todo to stay listed, got %+v", todos)
+	}
+	if open := FilterOpen([]Memory{m, {Content: "open"}}); len(open) != 1 || open[0].Content != "open" {
+		t.Errorf("FilterOpen = %+v", open)
+	}
+
+	noteID, _ := store.InsertMemory(Memory{Content: "a note", MemoryType: TypeNote, Importance: 0.5})
+	if err := store.CompleteTodo(noteID); err == nil {
+		t.Error("expected an error completing a note")
+	}
+	if err := store.CompleteTodo("nonexistent"); err == nil {
+		t.Error("expected an error for a missing memory")
+	}
+}
+
+func TestStore_LinkMemories(t *testing.T) {
+	_, store := setupTestDB(t)
+	oldID, _ := store.InsertMemory(Memory{Content: "Use MySQL", MemoryType: TypeDecision, Importance: 0.8})
+	newID, _ := store.InsertMemory(Memory{Content: "Use PostgreSQL", MemoryType: TypeDecision, Importance: 0.8})
+	noteID, _ := store.InsertMemory(Memory{Content: "pgx pool sizing", MemoryType: TypeNote, Importance: 0.5})
+
+	if err := store.LinkMemories(newID, oldID, LinkSupersedes); err != nil {
+		t.Fatalf("LinkMemories: %v", err)
+	}
+	if err := store.LinkMemories(noteID, newID, LinkRelatesTo); err != nil {
+		t.Fatalf("LinkMemories: %v", err)
+	}
+	// Linking again is a no-op.
+	if err := store.LinkMemories(newID, oldID, LinkSupersedes); err != nil {
+		t.Fatalf("repeat LinkMemories: %v", err)
+	}
+
+	for _, bad := range []struct {
+		from, to string
+		lt       LinkType
+	}{
+		{newID, newID, LinkSupersedes},
+		{newID, "missing", LinkSupersedes},
+		{newID, oldID, LinkType("blocks")},
+	} {
+		if err := store.LinkMemories(bad.from, bad.to, bad.lt); err == nil {
+			t.Errorf("LinkMemories(%s, %s, %s): expected an error", bad.from, bad.to, bad.lt)
+		}
+	}
+
+	links, err := store.ListMemoryLinks()
+	if err != nil || len(links) != 2 {
+		t.Fatalf("ListMemoryLinks: %v, %+v", err, links)
+	}
+
+	// Deleting a memory drops its links.
+	if err := store.DeleteMemory(oldID); err != nil {
+		t.Fatalf("DeleteMemory: %v", err)
+	}
+	links, _ = store.ListMemoryLinks()
+	if len(links) != 1 || links[0].FromID != noteID || links[0].Type != LinkRelatesTo {
+		t.Errorf("after delete: got %+v, want only the relates_to link", links)
+	}
+}
+
 func TestStore_DeleteMemoriesByType(t *testing.T) {
 	_, store := setupTestDB(t)
 
@@ -270,6 +447,75 @@ func TestStore_DeleteAllMemories(t *testing.T) {
 	}
 }
 
+func TestStore_ReplaceInMemories(t *testing.T) {
+	_, store := setupTestDB(t)
+
+	id1, _ := store.InsertMemory(Memory{Content: "Use MySQL for storage; MySQL 8 only", MemoryType: TypeDecision, Importance: 0.8})
+	id2, _ := store.InsertMemory(Memory{Content: "Back up mysql nightly", MemoryType: TypeNote, Importance: 0.5})
+
+	matches, err := store.MemoriesContaining("MySQL")
+	if err != nil {
+		t.Fatalf("MemoriesContaining: %v", err)
+	}
+	if len(matches) != 1 || matches[0].ID != id1 {
+		t.Fatalf("expected only %s to match (case-sensitive), got %+v", id1, matches)
+	}
+
+	n, err := store.ReplaceInMemories("MySQL", "Postgres")
+	if err != nil {
+		t.Fatalf("ReplaceInMemories: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 memory changed, got %d", n)
+	}
+	m, _ := store.GetMemoryByID(id1)
+	if m.Content != "Use Postgres for storage; Postgres 8 only" {
+		t.Errorf("content = %q", m.Content)
+	}
+	m, _ = store.GetMemoryByID(id2)
+	if m.Content != "Back up mysql nightly" {
+		t.Errorf("unmatched memory changed: %q", m.Content)
+	}
+
+	if _, err := store.ReplaceInMemories("", "x"); err == nil {
+		t.Error("expected error for empty search string")
+	}
+}
+
+func TestStore_FindMemory(t *testing.T) {
+	_, store := setupTestDB(t)
+
+	id1, _ := store.InsertMemory(Memory{Content: "Use tabs", MemoryType: TypeConvention, Importance: 0.8})
+	id2, _ := store.InsertMemory(Memory{Content: "Ship on Fridays", MemoryType: TypeNote, Importance: 0.5})
+	id3, _ := store.InsertMemory(Memory{Content: "Ship on Fridays", MemoryType: TypeNote, Importance: 0.5})
+
+	// The shortest prefix of id1 that neither other ID shares.
+	n := 1
+	for id1[:n] == id2[:n] || id1[:n] == id3[:n] {
+		n++
+	}
+
+	for _, query := range []string{id1, id1[:n], "Use tabs"} {
+		m, err := store.FindMemory(query)
+		if err != nil {
+			t.Fatalf("FindMemory(%q): %v", query, err)
+		}
+		if m.ID != id1 {
+			t.Errorf("FindMemory(%q) = %s, want %s", query, m.ID, id1)
+		}
+	}
+
+	_, err := store.FindMemory("Ship on Fridays")
+	var ambiguous *AmbiguousMemoryError
+	if !errors.As(err, &ambiguous) || len(ambiguous.Candidates) != 2 {
+		t.Errorf("duplicate content: err = %v, want AmbiguousMemoryError with 2 candidates", err)
+	}
+
+	if _, err := store.FindMemory("Use"); err == nil || errors.As(err, &ambiguous) {
+		t.Errorf("partial content: err = %v, want not found", err)
+	}
+}
+
 func TestStore_CountMemoriesByType(t *testing.T) {
 	_, store := setupTestDB(t)
 
@@ -334,6 +580,29 @@ func TestStore_GetMemoryByID(t *testing.T) {
 	}
 }
 
+func TestStore_MemoryProvenance(t *testing.T) {
+	_, store := setupTestDB(t)
+
+	extracted, _ := store.InsertMemory(Memory{Content: "uses Redis", MemoryType: TypeNote, Source: SourceExtracted, Confidence: 0.6})
+	linked, _ := store.InsertMemory(Memory{Content: "uses Go", MemoryType: TypeNote, Source: SourceExtracted, SourceSessionID: "earlier"})
+
+	if err := store.SetMemorySourceSession([]string{extracted, linked}, "sess-1"); err != nil {
+		t.Fatalf("SetMemorySourceSession: %v", err)
+	}
+	got, _ := store.GetMemoryByID(extracted)
+	if got.Confidence != 0.6 || got.SourceSessionID != "sess-1" {
+		t.Errorf("provenance: got confidence %v, session %q", got.Confidence, got.SourceSessionID)
+	}
+	if got, _ := store.GetMemoryByID(linked); got.SourceSessionID != "earlier" {
+		t.Errorf("existing source session should be kept, got %q", got.SourceSessionID)
+	}
+
+	mems := FilterByConfidence([]Memory{{ID: "low", Confidence: 0.3}, {ID: "high", Confidence: 0.9}, {ID: "user"}}, 0.5)
+	if len(mems) != 2 || mems[0].ID != "high" || mems[1].ID != "user" {
+		t.Errorf("FilterByConfidence: got %+v", mems)
+	}
+}
+
 func TestStore_GetChunkByID(t *testing.T) {
 	_, store := setupTestDB(t)
 
@@ -349,6 +618,29 @@ func TestStore_GetChunkByID(t *testing.T) {
 	}
 }
 
+func TestStore_GetFilesByIDs(t *testing.T) {
+	_, store := setupTestDB(t)
+
+	idA, _ := store.UpsertFile(File{Path: "a.go", Language: "go", LastModified: time.Now(), ContentHash: "h1"})
+	idB, _ := store.UpsertFile(File{Path: "b.go", Language: "go", LastModified: time.Now(), ContentHash: "h2"})
+
+	files, err := store.GetFilesByIDs([]string{idA, idB, idA, "missing"})
+	if err != nil {
+		t.Fatalf("GetFilesByIDs: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(files))
+	}
+	if files[idA].Path != "a.go" || files[idB].Path != "b.go" {
+		t.Errorf("unexpected paths: %q, %q", files[idA].Path, files[idB].Path)
+	}
+
+	empty, err := store.GetFilesByIDs(nil)
+	if err != nil || len(empty) != 0 {
+		t.Errorf("nil ids: got %v, %v", empty, err)
+	}
+}
+
 func TestStore_ListFiles(t *testing.T) {
 	_, store := setupTestDB(t)
 
@@ -404,6 +696,58 @@ func TestStore_InsertMemory_DefaultSource(t *testing.T) {
 	}
 }
 
+func TestStore_InsertMemoryWithID_Idempotent(t *testing.T) {
+	_, store := setupTestDB(t)
+
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	m := Memory{Content: "use SQLite", MemoryType: TypeDecision, Importance: 0.9, CreatedAt: created}
+	id1, err := store.InsertMemoryWithID(m)
+	if err != nil {
+		t.Fatalf("InsertMemoryWithID: %v", err)
+	}
+	if id1 != ContentMemoryID(m) || len(id1) != 32 {
+		t.Errorf("expected the content-derived ID, got %q", id1)
+	}
+
+	// Importing again, even with new importance, updates the same row.
+	m.Importance = 0.4
+	id2, _ := store.InsertMemoryWithID(m)
+	if id2 != id1 {
+		t.Errorf("re-import changed the ID: %q != %q", id2, id1)
+	}
+	all, _ := store.ListMemories("")
+	if len(all) != 1 {
+		t.Fatalf("expected 1 memory after re-import, got %d", len(all))
+	}
+	if all[0].Importance != 0.4 || !all[0].CreatedAt.Equal(created) {
+		t.Errorf("expected updated importance and kept created_at, got %v %v", all[0].Importance, all[0].CreatedAt)
+	}
+
+	// A re-import carries the dump's creation and completion times.
+	done := time.Date(2024, 4, 2, 9, 0, 0, 0, time.UTC)
+	todo := Memory{Content: "add retries", MemoryType: TypeTodo, CreatedAt: created}
+	todoID, _ := store.InsertMemoryWithID(todo)
+	todo.CreatedAt, todo.CompletedAt = created.AddDate(0, 0, -7), &done
+	store.InsertMemoryWithID(todo)
+	got, _ := store.GetMemoryByID(todoID)
+	if !got.Done() || !got.CompletedAt.Equal(done) || !got.CreatedAt.Equal(todo.CreatedAt) {
+		t.Errorf("re-imported todo: created %v, completed %v; want %v, %v", got.CreatedAt, got.CompletedAt, todo.CreatedAt, done)
+	}
+	store.InsertMemoryWithID(Memory{Content: "add retries", MemoryType: TypeTodo, CompletedAt: &done})
+	if got, _ := store.GetMemoryByID(todoID); !got.CreatedAt.Equal(todo.CreatedAt) {
+		t.Errorf("a zero CreatedAt should keep the stored one, got %v", got.CreatedAt)
+	}
+
+	// A caller-supplied ID is used as is.
+	id3, _ := store.InsertMemoryWithID(Memory{ID: "fixed-id", Content: "x", MemoryType: TypeNote})
+	if id3 != "fixed-id" {
+		t.Errorf("expected caller-supplied ID, got %q", id3)
+	}
+	if ContentMemoryID(Memory{Content: "x", MemoryType: TypeNote}) == ContentMemoryID(Memory{Content: "x", MemoryType: TypeTodo}) {
+		t.Error("content IDs should differ by memory type")
+	}
+}
+
 func TestStore_PruneSessionsKeepLatest(t *testing.T) {
 	_, store := setupTestDB(t)
 
@@ -460,6 +804,94 @@ func TestStore_PruneSessions_OlderThanDays(t *testing.T) {
 	}
 }
 
+func TestStore_PruneSessionsByPolicy(t *testing.T) {
+	_, store := setupTestDB(t)
+	clock := NewManualClock(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	store.SetClock(clock)
+
+	// Five sessions a day apart, oldest first; the oldest is still blocked.
+	store.InsertSession(Session{Question: "blocked", ContextUsed: "{}", ModelUsed: "claude", Status: SessionBlocked})
+	for i := 1; i < 5; i++ {
+		clock.Advance(24 * time.Hour)
+		store.InsertSession(Session{Question: fmt.Sprintf("done %d", i), ContextUsed: "{}", ModelUsed: "claude"})
+	}
+
+	if n, _ := store.PruneSessionsByPolicy(RetentionPolicy{}); n != 0 {
+		t.Errorf("zero policy pruned %d, want 0", n)
+	}
+
+	// Sessions from the last 2 days or among the latest 3 stay: "done 1" goes.
+	pruned, err := store.PruneSessionsByPolicy(RetentionPolicy{KeepLastN: 3, KeepDays: 2})
+	if err != nil {
+		t.Fatalf("PruneSessionsByPolicy: %v", err)
+	}
+	if pruned != 1 {
+		t.Errorf("expected 1 pruned, got %d", pruned)
+	}
+
+	pruned, _ = store.PruneSessionsByPolicy(RetentionPolicy{KeepLastN: 1})
+	if pruned != 2 {
+		t.Errorf("keep last 1: expected 2 pruned, got %d", pruned)
+	}
+	sessions, _ := store.GetLastNSessions(10)
+	var got []string
+	for _, sess := range sessions {
+		got = append(got, sess.Question)
+	}
+	if strings.Join(got, ",") != "done 4,blocked" {
+		t.Errorf("remaining = %v, want the latest and the blocked session", got)
+	}
+}
+
+func TestStore_Clock_PruneSessions(t *testing.T) {
+	_, store := setupTestDB(t)
+	clock := NewManualClock(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	store.SetClock(clock)
+
+	store.InsertSession(Session{Question: "old", ContextUsed: "{}", ModelUsed: "claude"})
+	clock.Advance(10 * 24 * time.Hour)
+	store.InsertSession(Session{Question: "new", ContextUsed: "{}", ModelUsed: "claude"})
+
+	pruned, err := store.PruneSessions(7)
+	if err != nil {
+		t.Fatalf("PruneSessions: %v", err)
+	}
+	if pruned != 1 {
+		t.Errorf("expected 1 pruned, got %d", pruned)
+	}
+
+	sessions, _ := store.GetLastNSessions(10)
+	if len(sessions) != 1 || sessions[0].Question != "new" {
+		t.Fatalf("expected only the new session to remain, got %+v", sessions)
+	}
+	if !sessions[0].CreatedAt.Equal(clock.Now()) {
+		t.Errorf("created_at: got %v, want %v", sessions[0].CreatedAt, clock.Now())
+	}
+}
+
+func TestStore_Clock_MemoryTimestamps(t *testing.T) {
+	_, store := setupTestDB(t)
+	frozen := time.Date(2026, 1, 15, 8, 30, 0, 0, time.UTC)
+	store.SetClock(NewManualClock(frozen))
+
+	id, err := store.InsertMemory(Memory{Content: "pinned", MemoryType: TypeNote})
+	if err != nil {
+		t.Fatalf("InsertMemory: %v", err)
+	}
+	m, err := store.GetMemoryByID(id)
+	if err != nil {
+		t.Fatalf("GetMemoryByID: %v", err)
+	}
+	if !m.CreatedAt.Equal(frozen) || !m.UpdatedAt.Equal(frozen) {
+		t.Errorf("timestamps: created %v updated %v, want %v", m.CreatedAt, m.UpdatedAt, frozen)
+	}
+
+	since, _ := store.ListMemoriesSince(frozen.Add(time.Minute))
+	if len(since) != 0 {
+		t.Errorf("expected no memories after the frozen time, got %d", len(since))
+	}
+}
+
 func TestStore_InsertSessionReturningID(t *testing.T) {
 	_, store := setupTestDB(t)
 
@@ -479,6 +911,71 @@ func TestStore_InsertSessionReturningID(t *testing.T) {
 	}
 }
 
+func TestStore_SessionStatus(t *testing.T) {
+	_, store := setupTestDB(t)
+
+	store.InsertSession(Session{Question: "ask", ContextUsed: "{}", ModelUsed: "claude"})
+	store.InsertSession(Session{Question: "stuck", ContextUsed: "{}", ModelUsed: "claude", Status: SessionBlocked})
+
+	sessions, err := store.GetLastNSessions(2)
+	if err != nil {
+		t.Fatalf("GetLastNSessions: %v", err)
+	}
+	got := map[string]SessionStatus{}
+	for _, s := range sessions {
+		got[s.Question] = s.Status
+	}
+	if got["ask"] != SessionCompleted {
+		t.Errorf("session without status: got %q, want completed", got["ask"])
+	}
+	if got["stuck"] != SessionBlocked {
+		t.Errorf("blocked session: got %q", got["stuck"])
+	}
+}
+
+func TestStore_SessionNextSteps(t *testing.T) {
+	_, store := setupTestDB(t)
+
+	steps := []string{"add refresh tokens", "write migration for sessions table"}
+	store.InsertSession(Session{Question: "auth", ContextUsed: "{}", ModelUsed: "claude", NextSteps: steps})
+
+	sessions, _ := store.GetLastNSessions(1)
+	if len(sessions) != 1 || strings.Join(sessions[0].NextSteps, "|") != strings.Join(steps, "|") {
+		t.Errorf("next steps round trip: got %+v", sessions)
+	}
+}
+
+func TestStore_SessionTags(t *testing.T) {
+	_, store := setupTestDB(t)
+	clock := NewManualClock(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	store.SetClock(clock)
+
+	store.InsertSession(Session{Question: "fix login", Tags: []string{" Bugfix ", "auth", "bugfix"}})
+	clock.Advance(time.Hour)
+	store.InsertSession(Session{Question: "try pgvector", Tags: []string{"spike"}})
+	clock.Advance(time.Hour)
+	store.InsertSession(Session{Question: "fix logout", Tags: []string{"bugfix"}})
+
+	sessions, _ := store.GetLastNSessions(3)
+	if got := strings.Join(sessions[2].Tags, "|"); got != "bugfix|auth" {
+		t.Errorf("tags not normalized: %q", got)
+	}
+
+	tagged, err := store.GetLastNSessionsTagged(10, "BUGFIX")
+	if err != nil {
+		t.Fatalf("GetLastNSessionsTagged: %v", err)
+	}
+	if len(tagged) != 2 || tagged[0].Question != "fix logout" || tagged[1].Question != "fix login" {
+		t.Errorf("expected both bugfix sessions newest first, got %+v", tagged)
+	}
+	if tagged, _ := store.GetLastNSessionsTagged(10, "auth"); len(tagged) != 1 || !tagged[0].HasTag("Auth") {
+		t.Errorf("expected one auth session, got %+v", tagged)
+	}
+	if tagged, _ := store.GetLastNSessionsTagged(10, "spik"); len(tagged) != 0 {
+		t.Errorf("tag match must be exact, got %+v", tagged)
+	}
+}
+
 func TestStore_UpdateSessionSummary(t *testing.T) {
 	_, store := setupTestDB(t)
 
@@ -1,7 +1,14 @@
 // Package memory defines types for Memvra's persistent memory store.
 package memory
 
-import "time"
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
 
 // MemoryType classifies a stored memory.
 type MemoryType string
@@ -14,13 +21,190 @@ const (
 	TypeTodo       MemoryType = "todo"
 )
 
-// ValidMemoryType returns true if t is a recognised memory type.
+// BuiltinTypes are the memory types every project has, in display order.
+var BuiltinTypes = []MemoryType{TypeDecision, TypeConvention, TypeConstraint, TypeNote, TypeTodo}
+
+// Custom memory types registered from project config (see RegisterType).
+var (
+	customTypesMu sync.RWMutex
+	customTypes   = map[MemoryType]bool{}
+)
+
+// customTypeName is the shape of a custom type name: lowercase, starting
+// with a letter, so it is safe as a config key, flag value and heading.
+var customTypeName = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// RegisterType makes t valid alongside the built-in types, e.g. "risk" or
+// "glossary". Registering a built-in or already registered type is a no-op.
+func RegisterType(t MemoryType) error {
+	if IsBuiltinType(t) {
+		return nil
+	}
+	if !customTypeName.MatchString(string(t)) {
+		return fmt.Errorf("memory: invalid custom type %q: use lowercase letters, digits, '-' and '_'", t)
+	}
+	customTypesMu.Lock()
+	defer customTypesMu.Unlock()
+	customTypes[t] = true
+	return nil
+}
+
+// RegisterTypes registers each of names (see RegisterType), as listed in
+// a project's memory_types setting.
+func RegisterTypes(names []string) error {
+	for _, name := range names {
+		if err := RegisterType(MemoryType(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CustomTypes returns the registered custom types, sorted.
+func CustomTypes() []MemoryType {
+	customTypesMu.RLock()
+	defer customTypesMu.RUnlock()
+	out := make([]MemoryType, 0, len(customTypes))
+	for t := range customTypes {
+		out = append(out, t)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// AllTypes returns the built-in types followed by the custom ones.
+func AllTypes() []MemoryType {
+	return append(slices.Clone(BuiltinTypes), CustomTypes()...)
+}
+
+// IsBuiltinType reports whether t is one of BuiltinTypes.
+func IsBuiltinType(t MemoryType) bool {
+	return slices.Contains(BuiltinTypes, t)
+}
+
+// ValidMemoryType returns true if t is a built-in or registered custom type.
 func ValidMemoryType(t MemoryType) bool {
-	switch t {
-	case TypeDecision, TypeConvention, TypeConstraint, TypeNote, TypeTodo:
+	if IsBuiltinType(t) {
 		return true
 	}
-	return false
+	customTypesMu.RLock()
+	defer customTypesMu.RUnlock()
+	return customTypes[t]
+}
+
+// TypeNames returns AllTypes as a comma-separated list for messages.
+func TypeNames() string {
+	names := make([]string, 0, len(BuiltinTypes))
+	for _, t := range AllTypes() {
+		names = append(names, string(t))
+	}
+	return strings.Join(names, ", ")
+}
+
+// Label returns the plural, capitalised name of t for section headings,
+// e.g. "Decisions" or "Glossaries".
+func (t MemoryType) Label() string {
+	s := string(t)
+	if s == "" {
+		return ""
+	}
+	switch {
+	case len(s) > 1 && s[len(s)-1] == 'y' && !strings.ContainsRune("aeiou", rune(s[len(s)-2])):
+		s = s[:len(s)-1] + "ies"
+	case strings.HasSuffix(s, "s") || strings.HasSuffix(s, "x") || strings.HasSuffix(s, "ch") || strings.HasSuffix(s, "sh"):
+		s += "es"
+	default:
+		s += "s"
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// ParseMemoryTypes converts type names (e.g. from config) to MemoryTypes,
+// dropping unrecognised names. A nil input yields nil so callers can
+// distinguish "unset" from an explicitly empty list.
+func ParseMemoryTypes(names []string) []MemoryType {
+	if names == nil {
+		return nil
+	}
+	out := make([]MemoryType, 0, len(names))
+	for _, n := range names {
+		if t := MemoryType(n); ValidMemoryType(t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// FilterByImportance returns the memories whose importance is at least min.
+// A min of 0 or less returns mems unchanged.
+func FilterByImportance(mems []Memory, min float64) []Memory {
+	if min <= 0 {
+		return mems
+	}
+	out := make([]Memory, 0, len(mems))
+	for _, m := range mems {
+		if m.Importance >= min {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// Memory sources recorded in Memory.Source.
+const (
+	SourceUser      = "user"      // stated by a person (remember, MCP remember)
+	SourceExtracted = "extracted" // inferred by an AI from a response
+)
+
+// FilterBySource returns the memories whose Source is one of sources.
+// An empty sources list returns mems unchanged.
+func FilterBySource(mems []Memory, sources []string) []Memory {
+	if len(sources) == 0 {
+		return mems
+	}
+	out := make([]Memory, 0, len(mems))
+	for _, m := range mems {
+		for _, s := range sources {
+			if m.Source == s {
+				out = append(out, m)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// FilterByConfidence drops inferred memories whose recorded confidence is
+// below min. Memories without a confidence (including everything a person
+// stated) are kept. A min of 0 or less returns mems unchanged.
+func FilterByConfidence(mems []Memory, min float64) []Memory {
+	if min <= 0 {
+		return mems
+	}
+	out := make([]Memory, 0, len(mems))
+	for _, m := range mems {
+		if !m.BelowConfidence(min) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// FilterOpen returns mems without completed todos, which are kept for
+// history but no longer belong in context.
+func FilterOpen(mems []Memory) []Memory {
+	out := make([]Memory, 0, len(mems))
+	for _, m := range mems {
+		if !m.Done() {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// BelowConfidence reports whether m has a recorded confidence under min.
+func (m Memory) BelowConfidence(min float64) bool {
+	return m.Confidence > 0 && m.Confidence < min
 }
 
 // Memory is a single stored memory record.
@@ -31,8 +215,20 @@ type Memory struct {
 	Importance   float64    `json:"importance"`
 	Source       string     `json:"source"` // "user" or "extracted"
 	RelatedFiles []string   `json:"related_files,omitempty"`
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
+	// SourceSessionID is the session an inferred memory came out of, and
+	// Confidence how sure the inferring model was (0-1; 0 = not recorded).
+	SourceSessionID string    `json:"source_session_id,omitempty"`
+	Confidence      float64   `json:"confidence,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	// CompletedAt is when a todo was marked done (see Store.CompleteTodo);
+	// nil while it is open.
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// Done reports whether m is a completed todo.
+func (m Memory) Done() bool {
+	return m.CompletedAt != nil
 }
 
 // Project holds the top-level project record stored in SQLite.
@@ -72,13 +268,80 @@ type Chunk struct {
 
 // Session records a single memvra ask interaction.
 type Session struct {
-	ID              string    `json:"id"`
-	Question        string    `json:"question"`
-	ContextUsed     string    `json:"context_used"`      // JSON
-	ResponseSummary string    `json:"response_summary"`
-	ModelUsed       string    `json:"model_used"`
-	TokensUsed      int       `json:"tokens_used"`
-	CreatedAt       time.Time `json:"created_at"`
+	ID              string        `json:"id"`
+	Question        string        `json:"question"`
+	ContextUsed     string        `json:"context_used"` // JSON
+	ResponseSummary string        `json:"response_summary"`
+	ModelUsed       string        `json:"model_used"`
+	TokensUsed      int           `json:"tokens_used"`
+	Status          SessionStatus `json:"status"`
+	NextSteps       []string      `json:"next_steps,omitempty"`
+	Tags            []string      `json:"tags,omitempty"` // e.g. "bugfix", "spike"; see NormalizeTags
+	CreatedAt       time.Time     `json:"created_at"`
+}
+
+// RetentionPolicy bounds session history. A session is pruned only when it
+// falls outside every limit that is set; in-progress and blocked sessions
+// are always kept. The zero policy keeps everything.
+type RetentionPolicy struct {
+	KeepLastN int // keep the N most recent sessions (0 = no count limit)
+	KeepDays  int // keep sessions from the last N days (0 = no age limit)
+}
+
+// IsZero reports whether p prunes nothing.
+func (p RetentionPolicy) IsZero() bool {
+	return p.KeepLastN <= 0 && p.KeepDays <= 0
+}
+
+// SessionStatus records how a session ended.
+type SessionStatus string
+
+const (
+	SessionCompleted  SessionStatus = "completed"
+	SessionInProgress SessionStatus = "in_progress"
+	SessionBlocked    SessionStatus = "blocked"
+)
+
+// ValidSessionStatus returns true if s is a recognised session status.
+func ValidSessionStatus(s SessionStatus) bool {
+	switch s {
+	case SessionCompleted, SessionInProgress, SessionBlocked:
+		return true
+	}
+	return false
+}
+
+// Unfinished reports whether the session left work for a later one.
+func (s Session) Unfinished() bool {
+	return s.Status == SessionInProgress || s.Status == SessionBlocked
+}
+
+// HasTag reports whether the session carries tag, compared as NormalizeTags
+// would store it.
+func (s Session) HasTag(tag string) bool {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	for _, t := range s.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeTags lowercases and trims tags, dropping empty and repeated ones.
+// Order is otherwise kept.
+func NormalizeTags(tags []string) []string {
+	var out []string
+	seen := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	return out
 }
 
 // Stats summarises what's stored for a project.
@@ -5,47 +5,269 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
+	"sync"
 
 	"github.com/memvra/memvra/internal/db"
 )
 
 // VectorStore provides vector similarity search via sqlite-vec.
+//
+// Embeddings are namespaced by a model key (see config.EmbeddingModelKey), so
+// a chunk or memory can hold one vector per embedding model. Searches only
+// compare vectors from the requested model, which makes switching between
+// models of the same dimension, and back, safe without re-embedding. The
+// tables hold vectors of a single dimension, though: a model of another size
+// needs Resize, which discards every stored vector.
+//
+// A database without the vector tables (created before vector support, or
+// where sqlite-vec failed to load) is tolerated: searches return no matches,
+// deletes are no-ops, and the first upsert creates the tables.
 type VectorStore struct {
-	conn *sql.DB
+	database *db.DB
+	conn     *sql.DB
+
+	tablesMu    sync.Mutex
+	tablesReady bool // cached once the tables are known to exist
+	dimension   int  // size the tables were created for, cached with tablesReady
 }
 
 // NewVectorStore creates a VectorStore backed by the given DB.
 func NewVectorStore(database *db.DB) *VectorStore {
-	return &VectorStore{conn: database.Conn()}
+	return &VectorStore{database: database, conn: database.Conn()}
+}
+
+// hasTables reports whether the vector tables exist, caching a positive answer.
+func (v *VectorStore) hasTables() bool {
+	v.tablesMu.Lock()
+	defer v.tablesMu.Unlock()
+	if !v.tablesReady {
+		v.tablesReady = v.database.HasVectorTables()
+	}
+	return v.tablesReady
+}
+
+// writable returns an error if the database was opened read-only.
+func (v *VectorStore) writable() error {
+	if v.database.ReadOnly() {
+		return fmt.Errorf("vector: %w", db.ErrReadOnly)
+	}
+	return nil
+}
+
+// ensureTables creates the vector tables if they do not exist yet and makes
+// them fit vectors of dim components (0 = any size); see fitDimension. It
+// also rejects writes to a read-only database, so every upsert goes through
+// it.
+func (v *VectorStore) ensureTables(dim int) error {
+	if err := v.writable(); err != nil {
+		return err
+	}
+	if !v.hasTables() {
+		if err := v.database.EnsureVectorTables(); err != nil {
+			return fmt.Errorf("vector: %w", err)
+		}
+		v.tablesMu.Lock()
+		v.tablesReady = true
+		v.tablesMu.Unlock()
+	}
+	return v.fitDimension(dim)
+}
+
+// fitDimension recreates the vector tables for dim-dimensional vectors when
+// they were created for another size but hold no vectors yet, so the first
+// embedder to store a vector sizes the index. Tables already holding
+// vectors of another size are kept and a *DimensionMismatchError returned;
+// Resize replaces them.
+func (v *VectorStore) fitDimension(dim int) error {
+	stored := v.tableDimension()
+	if dim == 0 || stored == 0 || stored == dim {
+		return nil
+	}
+	if !v.empty() {
+		return fmt.Errorf("vector: %w", &DimensionMismatchError{Stored: stored, Embedder: dim})
+	}
+	_, err := v.Resize(dim)
+	return err
+}
+
+// Resize recreates the vector tables for dim-dimensional vectors unless
+// they already have that size, discarding every stored embedding of every
+// model. It reports whether the tables were recreated.
+func (v *VectorStore) Resize(dim int) (bool, error) {
+	if err := v.writable(); err != nil {
+		return false, err
+	}
+	if v.hasTables() && v.tableDimension() == dim {
+		return false, nil
+	}
+	if err := v.database.ResizeVectorTables(dim); err != nil {
+		return false, fmt.Errorf("vector: %w", err)
+	}
+	v.tablesMu.Lock()
+	v.tablesReady, v.dimension = true, dim
+	v.tablesMu.Unlock()
+	return true, nil
+}
+
+// tableDimension returns the vector size the tables were created for, or 0
+// when they don't exist.
+func (v *VectorStore) tableDimension() int {
+	if !v.hasTables() {
+		return 0
+	}
+	v.tablesMu.Lock()
+	defer v.tablesMu.Unlock()
+	if v.dimension == 0 {
+		v.dimension = v.database.VectorDimension()
+	}
+	return v.dimension
+}
+
+// empty reports whether the vector tables hold no embeddings.
+func (v *VectorStore) empty() bool {
+	for _, table := range []string{"vec_chunk_embeddings", "vec_memory_embeddings"} {
+		var n int
+		if err := v.conn.QueryRow(`SELECT COUNT(*) FROM (SELECT 1 FROM ` + table + ` LIMIT 1)`).Scan(&n); err != nil || n > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Dimension returns the vector size the index stores, or 0 when it holds
+// no vectors yet (any dimension is then acceptable, as the first upsert
+// resizes the tables).
+func (v *VectorStore) Dimension() int {
+	if !v.hasTables() || v.empty() {
+		return 0
+	}
+	return v.tableDimension()
+}
+
+// dimensionOf returns the size of the first non-empty embedding, or 0.
+func dimensionOf(embeddings [][]float32) int {
+	for _, e := range embeddings {
+		if len(e) > 0 {
+			return len(e)
+		}
+	}
+	return 0
 }
 
-// UpsertChunkEmbedding inserts or replaces a chunk embedding in vec_chunks.
+// UpsertChunkEmbedding inserts or replaces the chunk embedding for model.
 // sqlite-vec virtual tables don't support ON CONFLICT upsert, so we
 // delete the existing row first then insert.
-func (v *VectorStore) UpsertChunkEmbedding(id string, embedding []float32) error {
+func (v *VectorStore) UpsertChunkEmbedding(model, id string, embedding []float32) error {
 	if len(embedding) == 0 {
 		return nil
 	}
+	if err := v.ensureTables(len(embedding)); err != nil {
+		return err
+	}
 	blob := float32SliceToBlob(embedding)
-	if _, err := v.conn.Exec(`DELETE FROM vec_chunks WHERE id = ?`, id); err != nil {
+	if _, err := v.conn.Exec(`DELETE FROM vec_chunk_embeddings WHERE key = ?`, db.VectorKey(model, id)); err != nil {
 		return fmt.Errorf("vector: delete old chunk embedding: %w", err)
 	}
-	if _, err := v.conn.Exec(`INSERT INTO vec_chunks (id, embedding) VALUES (?, ?)`, id, blob); err != nil {
+	if _, err := v.conn.Exec(`INSERT INTO vec_chunk_embeddings (key, model, id, embedding) VALUES (?, ?, ?, ?)`, db.VectorKey(model, id), model, id, blob); err != nil {
 		return fmt.Errorf("vector: insert chunk embedding: %w", err)
 	}
 	return nil
 }
 
-// UpsertMemoryEmbedding inserts or replaces a memory embedding in vec_memories.
-func (v *VectorStore) UpsertMemoryEmbedding(id string, embedding []float32) error {
+// UpsertChunkEmbeddings stores embeddings[i] for ids[i] under model in a
+// single transaction. Empty embeddings are skipped.
+func (v *VectorStore) UpsertChunkEmbeddings(model string, ids []string, embeddings [][]float32) error {
+	if len(ids) != len(embeddings) {
+		return fmt.Errorf("vector: got %d embeddings for %d chunks", len(embeddings), len(ids))
+	}
+	if err := v.ensureTables(dimensionOf(embeddings)); err != nil {
+		return err
+	}
+	tx, err := v.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("vector: begin chunk batch: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for i, id := range ids {
+		if len(embeddings[i]) == 0 {
+			continue
+		}
+		key := db.VectorKey(model, id)
+		if _, err := tx.Exec(`DELETE FROM vec_chunk_embeddings WHERE key = ?`, key); err != nil {
+			return fmt.Errorf("vector: delete old chunk embedding: %w", err)
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO vec_chunk_embeddings (key, model, id, embedding) VALUES (?, ?, ?, ?)`,
+			key, model, id, float32SliceToBlob(embeddings[i]),
+		); err != nil {
+			return fmt.Errorf("vector: insert chunk embedding: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("vector: commit chunk batch: %w", err)
+	}
+	return nil
+}
+
+// UpsertMemoryEmbeddings stores embeddings[i] for memory ids[i] under model
+// in a single transaction. Empty embeddings are skipped.
+func (v *VectorStore) UpsertMemoryEmbeddings(model string, ids []string, embeddings [][]float32) error {
+	if len(ids) != len(embeddings) {
+		return fmt.Errorf("vector: got %d embeddings for %d memories", len(embeddings), len(ids))
+	}
+	if err := v.ensureTables(dimensionOf(embeddings)); err != nil {
+		return err
+	}
+	tx, err := v.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("vector: begin memory batch: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := upsertMemoryEmbeddings(tx, model, ids, embeddings); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("vector: commit memory batch: %w", err)
+	}
+	return nil
+}
+
+// upsertMemoryEmbeddings writes embeddings[i] for memory ids[i] through tx,
+// skipping empty embeddings. The vector tables must already exist.
+func upsertMemoryEmbeddings(tx *sql.Tx, model string, ids []string, embeddings [][]float32) error {
+	for i, id := range ids {
+		if len(embeddings[i]) == 0 {
+			continue
+		}
+		key := db.VectorKey(model, id)
+		if _, err := tx.Exec(`DELETE FROM vec_memory_embeddings WHERE key = ?`, key); err != nil {
+			return fmt.Errorf("vector: delete old memory embedding: %w", err)
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO vec_memory_embeddings (key, model, id, embedding) VALUES (?, ?, ?, ?)`,
+			key, model, id, float32SliceToBlob(embeddings[i]),
+		); err != nil {
+			return fmt.Errorf("vector: insert memory embedding: %w", err)
+		}
+	}
+	return nil
+}
+
+// UpsertMemoryEmbedding inserts or replaces the memory embedding for model.
+func (v *VectorStore) UpsertMemoryEmbedding(model, id string, embedding []float32) error {
 	if len(embedding) == 0 {
 		return nil
 	}
+	if err := v.ensureTables(len(embedding)); err != nil {
+		return err
+	}
 	blob := float32SliceToBlob(embedding)
-	if _, err := v.conn.Exec(`DELETE FROM vec_memories WHERE id = ?`, id); err != nil {
+	if _, err := v.conn.Exec(`DELETE FROM vec_memory_embeddings WHERE key = ?`, db.VectorKey(model, id)); err != nil {
 		return fmt.Errorf("vector: delete old memory embedding: %w", err)
 	}
-	if _, err := v.conn.Exec(`INSERT INTO vec_memories (id, embedding) VALUES (?, ?)`, id, blob); err != nil {
+	if _, err := v.conn.Exec(`INSERT INTO vec_memory_embeddings (key, model, id, embedding) VALUES (?, ?, ?, ?)`, db.VectorKey(model, id), model, id, blob); err != nil {
 		return fmt.Errorf("vector: insert memory embedding: %w", err)
 	}
 	return nil
@@ -57,16 +279,17 @@ type VectorMatch struct {
 	Distance float64
 }
 
-// SearchChunks finds the top-k most similar chunk embeddings to the query vector.
-func (v *VectorStore) SearchChunks(query []float32, topK int, minSimilarity float64) ([]VectorMatch, error) {
-	if len(query) == 0 {
+// SearchChunks finds the top-k chunk embeddings for model most similar to the query vector.
+func (v *VectorStore) SearchChunks(model string, query []float32, topK int, minSimilarity float64) ([]VectorMatch, error) {
+	if len(query) == 0 || !v.hasTables() {
 		return nil, nil
 	}
 	blob := float32SliceToBlob(query)
 	rows, err := v.conn.Query(
-		`SELECT id, distance FROM vec_chunks WHERE embedding MATCH ? AND k = ?
+		`SELECT id, distance FROM vec_chunk_embeddings
+		 WHERE embedding MATCH ? AND k = ? AND model = ?
 		 ORDER BY distance`,
-		blob, topK,
+		blob, topK, model,
 	)
 	if err != nil {
 		// sqlite-vec may not be loaded; degrade gracefully.
@@ -76,16 +299,54 @@ func (v *VectorStore) SearchChunks(query []float32, topK int, minSimilarity floa
 	return scanMatches(rows, minSimilarity)
 }
 
-// SearchMemories finds the top-k most similar memory embeddings to the query vector.
-func (v *VectorStore) SearchMemories(query []float32, topK int, minSimilarity float64) ([]VectorMatch, error) {
-	if len(query) == 0 {
+// languageOversample is how many times topK neighbours SearchChunksInLanguage
+// scans, since its language filter can only run after the KNN step.
+const languageOversample = 5
+
+// maxKNN is sqlite-vec's upper bound on k in a KNN query.
+const maxKNN = 4096
+
+// SearchChunksInLanguage is SearchChunks restricted to chunks of files whose
+// language matches language (case-insensitive). It keeps the closest topK
+// matching chunks among the nearest languageOversample×topK.
+func (v *VectorStore) SearchChunksInLanguage(model string, query []float32, topK int, minSimilarity float64, language string) ([]VectorMatch, error) {
+	if len(query) == 0 || !v.hasTables() {
 		return nil, nil
 	}
 	blob := float32SliceToBlob(query)
 	rows, err := v.conn.Query(
-		`SELECT id, distance FROM vec_memories WHERE embedding MATCH ? AND k = ?
+		// MATERIALIZED stops SQLite pushing the join on id (an auxiliary
+		// column, which vec0 can't filter) into the KNN query.
+		`WITH knn AS MATERIALIZED (
+			SELECT id, distance FROM vec_chunk_embeddings
+			WHERE embedding MATCH ? AND k = ? AND model = ?
+		 )
+		 SELECT knn.id, knn.distance FROM knn
+		 JOIN chunks c ON c.id = knn.id
+		 JOIN files f ON f.id = c.file_id
+		 WHERE lower(f.language) = lower(?)
+		 ORDER BY knn.distance
+		 LIMIT ?`,
+		blob, min(topK*languageOversample, maxKNN), model, language, topK,
+	)
+	if err != nil {
+		return nil, nil //nolint:nilerr
+	}
+	defer func() { _ = rows.Close() }()
+	return scanMatches(rows, minSimilarity)
+}
+
+// SearchMemories finds the top-k memory embeddings for model most similar to the query vector.
+func (v *VectorStore) SearchMemories(model string, query []float32, topK int, minSimilarity float64) ([]VectorMatch, error) {
+	if len(query) == 0 || !v.hasTables() {
+		return nil, nil
+	}
+	blob := float32SliceToBlob(query)
+	rows, err := v.conn.Query(
+		`SELECT id, distance FROM vec_memory_embeddings
+		 WHERE embedding MATCH ? AND k = ? AND model = ?
 		 ORDER BY distance`,
-		blob, topK,
+		blob, topK, model,
 	)
 	if err != nil {
 		return nil, nil //nolint:nilerr
@@ -94,18 +355,189 @@ func (v *VectorStore) SearchMemories(query []float32, topK int, minSimilarity fl
 	return scanMatches(rows, minSimilarity)
 }
 
-// DeleteChunkEmbedding removes a chunk embedding.
+// DeleteChunkEmbedding removes a chunk's embeddings for every model.
 func (v *VectorStore) DeleteChunkEmbedding(id string) error {
-	_, err := v.conn.Exec(`DELETE FROM vec_chunks WHERE id = ?`, id)
+	if err := v.writable(); err != nil {
+		return err
+	}
+	if !v.hasTables() {
+		return nil
+	}
+	_, err := v.conn.Exec(`DELETE FROM vec_chunk_embeddings WHERE id = ?`, id)
 	return err
 }
 
-// DeleteMemoryEmbedding removes a memory embedding.
+// DeleteMemoryEmbedding removes a memory's embeddings for every model.
 func (v *VectorStore) DeleteMemoryEmbedding(id string) error {
-	_, err := v.conn.Exec(`DELETE FROM vec_memories WHERE id = ?`, id)
+	if err := v.writable(); err != nil {
+		return err
+	}
+	if !v.hasTables() {
+		return nil
+	}
+	_, err := v.conn.Exec(`DELETE FROM vec_memory_embeddings WHERE id = ?`, id)
 	return err
 }
 
+// StoredEmbedding is one stored vector and the chunk or memory it belongs to.
+type StoredEmbedding struct {
+	ID        string
+	Embedding []float32
+}
+
+// EachChunkEmbedding calls fn for every chunk embedding stored for model,
+// stopping at the first error fn returns. Rows are streamed, so callers can
+// process large indexes without holding every vector in memory.
+func (v *VectorStore) EachChunkEmbedding(model string, fn func(StoredEmbedding) error) error {
+	return v.eachEmbedding("vec_chunk_embeddings", model, fn)
+}
+
+// EachMemoryEmbedding calls fn for every memory embedding stored for model,
+// stopping at the first error fn returns.
+func (v *VectorStore) EachMemoryEmbedding(model string, fn func(StoredEmbedding) error) error {
+	return v.eachEmbedding("vec_memory_embeddings", model, fn)
+}
+
+func (v *VectorStore) eachEmbedding(table, model string, fn func(StoredEmbedding) error) error {
+	if !v.hasTables() {
+		return nil
+	}
+	rows, err := v.conn.Query(`SELECT id, embedding FROM `+table+` WHERE model = ? ORDER BY id`, model)
+	if err != nil {
+		return fmt.Errorf("vector: list embeddings: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var id string
+		var blob []byte
+		if err := rows.Scan(&id, &blob); err != nil {
+			return fmt.Errorf("vector: list embeddings: %w", err)
+		}
+		if err := fn(StoredEmbedding{ID: id, Embedding: BlobToFloat32Slice(blob)}); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// GCResult counts the orphaned embeddings removed by GarbageCollect.
+type GCResult struct {
+	Chunks   int
+	Memories int
+}
+
+// Total is the number of embeddings removed.
+func (r GCResult) Total() int { return r.Chunks + r.Memories }
+
+// GarbageCollect deletes embeddings, under every model, whose chunk or memory
+// no longer exists in store. Orphans are left behind when rows are deleted
+// without going through the orchestrator, and would otherwise keep matching
+// searches that can no longer resolve them.
+func (v *VectorStore) GarbageCollect(store *Store) (GCResult, error) {
+	var res GCResult
+	if err := v.writable(); err != nil {
+		return res, err
+	}
+	if !v.hasTables() {
+		return res, nil
+	}
+	chunkIDs, err := store.ChunkIDs()
+	if err != nil {
+		return res, fmt.Errorf("vector: gc: %w", err)
+	}
+	if res.Chunks, err = v.deleteOrphans("vec_chunk_embeddings", chunkIDs); err != nil {
+		return res, err
+	}
+	memoryIDs, err := store.MemoryIDs()
+	if err != nil {
+		return res, fmt.Errorf("vector: gc: %w", err)
+	}
+	if res.Memories, err = v.deleteOrphans("vec_memory_embeddings", memoryIDs); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// deleteOrphans removes rows of table whose id is not in live and returns
+// how many it removed.
+func (v *VectorStore) deleteOrphans(table string, live map[string]struct{}) (int, error) {
+	rows, err := v.conn.Query(`SELECT key, id FROM ` + table)
+	if err != nil {
+		return 0, fmt.Errorf("vector: gc %s: %w", table, err)
+	}
+	var orphans []string
+	for rows.Next() {
+		var key, id string
+		if err := rows.Scan(&key, &id); err != nil {
+			_ = rows.Close()
+			return 0, fmt.Errorf("vector: gc %s: %w", table, err)
+		}
+		if _, ok := live[id]; !ok {
+			orphans = append(orphans, key)
+		}
+	}
+	err = rows.Err()
+	_ = rows.Close()
+	if err != nil {
+		return 0, fmt.Errorf("vector: gc %s: %w", table, err)
+	}
+	if len(orphans) == 0 {
+		return 0, nil
+	}
+
+	tx, err := v.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("vector: gc %s: %w", table, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, key := range orphans {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE key = ?`, key); err != nil {
+			return 0, fmt.Errorf("vector: gc %s: %w", table, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("vector: gc %s: %w", table, err)
+	}
+	return len(orphans), nil
+}
+
+// ModelCount is the number of stored embeddings for one model key.
+type ModelCount struct {
+	Model    string
+	Chunks   int
+	Memories int
+}
+
+// Models lists the model keys that have stored embeddings, with how many
+// chunks and memories each covers, ordered by model key.
+func (v *VectorStore) Models() ([]ModelCount, error) {
+	if !v.hasTables() {
+		return nil, nil
+	}
+	rows, err := v.conn.Query(
+		`SELECT model, SUM(c), SUM(m) FROM (
+			SELECT model, 1 AS c, 0 AS m FROM vec_chunk_embeddings
+			UNION ALL
+			SELECT model, 0 AS c, 1 AS m FROM vec_memory_embeddings
+		 ) GROUP BY model ORDER BY model`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("vector: list models: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []ModelCount
+	for rows.Next() {
+		var mc ModelCount
+		if err := rows.Scan(&mc.Model, &mc.Chunks, &mc.Memories); err != nil {
+			return nil, fmt.Errorf("vector: list models: %w", err)
+		}
+		out = append(out, mc)
+	}
+	return out, rows.Err()
+}
+
 // ---- Helpers ----
 
 func scanMatches(rows *sql.Rows, minSimilarity float64) ([]VectorMatch, error) {
@@ -115,10 +547,8 @@ func scanMatches(rows *sql.Rows, minSimilarity float64) ([]VectorMatch, error) {
 		if err := rows.Scan(&m.ID, &m.Distance); err != nil {
 			return nil, err
 		}
-		// sqlite-vec returns L2 distance; convert to cosine-like similarity:
-		// similarity = 1 / (1 + distance). Filter by threshold.
-		similarity := 1.0 / (1.0 + m.Distance)
-		if similarity >= minSimilarity {
+		// sqlite-vec returns L2 distance; filter on the derived similarity.
+		if DistanceToSimilarity(m.Distance) >= minSimilarity {
 			out = append(out, m)
 		}
 	}
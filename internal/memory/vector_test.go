@@ -2,10 +2,12 @@ package memory
 
 import (
 	"encoding/binary"
+	"errors"
 	"math"
 	"path/filepath"
 	"testing"
 
+	"github.com/memvra/memvra/internal/adapter"
 	"github.com/memvra/memvra/internal/db"
 )
 
@@ -78,6 +80,9 @@ func makeVec(base float32) []float32 {
 	return v
 }
 
+// testModel is the embedding model key used by vector tests.
+const testModel = "test:model"
+
 func setupVectorTestDB(t *testing.T) (*db.DB, *VectorStore) {
 	t.Helper()
 	dbPath := filepath.Join(t.TempDir(), "vec_test.db")
@@ -96,16 +101,16 @@ func TestVectorStore_UpsertAndSearchChunks(t *testing.T) {
 	vecA := makeVec(1.0)
 	vecB := makeVec(5.0)
 
-	if err := vs.UpsertChunkEmbedding("chunk-1", vecA); err != nil {
+	if err := vs.UpsertChunkEmbedding(testModel, "chunk-1", vecA); err != nil {
 		t.Fatalf("UpsertChunkEmbedding A: %v", err)
 	}
-	if err := vs.UpsertChunkEmbedding("chunk-2", vecB); err != nil {
+	if err := vs.UpsertChunkEmbedding(testModel, "chunk-2", vecB); err != nil {
 		t.Fatalf("UpsertChunkEmbedding B: %v", err)
 	}
 
 	// Search with a query close to vecA.
 	query := makeVec(1.1)
-	matches, err := vs.SearchChunks(query, 10, 0.0)
+	matches, err := vs.SearchChunks(testModel, query, 10, 0.0)
 	if err != nil {
 		t.Fatalf("SearchChunks: %v", err)
 	}
@@ -124,15 +129,15 @@ func TestVectorStore_UpsertAndSearchMemories(t *testing.T) {
 	vecA := makeVec(1.0)
 	vecB := makeVec(10.0)
 
-	if err := vs.UpsertMemoryEmbedding("mem-1", vecA); err != nil {
+	if err := vs.UpsertMemoryEmbedding(testModel, "mem-1", vecA); err != nil {
 		t.Fatalf("UpsertMemoryEmbedding A: %v", err)
 	}
-	if err := vs.UpsertMemoryEmbedding("mem-2", vecB); err != nil {
+	if err := vs.UpsertMemoryEmbedding(testModel, "mem-2", vecB); err != nil {
 		t.Fatalf("UpsertMemoryEmbedding B: %v", err)
 	}
 
 	query := makeVec(1.1)
-	matches, err := vs.SearchMemories(query, 10, 0.0)
+	matches, err := vs.SearchMemories(testModel, query, 10, 0.0)
 	if err != nil {
 		t.Fatalf("SearchMemories: %v", err)
 	}
@@ -147,7 +152,7 @@ func TestVectorStore_UpsertAndSearchMemories(t *testing.T) {
 func TestVectorStore_Search_EmptyQuery(t *testing.T) {
 	_, vs := setupVectorTestDB(t)
 
-	matches, err := vs.SearchChunks(nil, 10, 0.0)
+	matches, err := vs.SearchChunks(testModel, nil, 10, 0.0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -155,7 +160,7 @@ func TestVectorStore_Search_EmptyQuery(t *testing.T) {
 		t.Errorf("expected nil for empty query, got %v", matches)
 	}
 
-	matches, err = vs.SearchMemories([]float32{}, 10, 0.0)
+	matches, err = vs.SearchMemories(testModel, []float32{}, 10, 0.0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -171,12 +176,12 @@ func TestVectorStore_Search_SimilarityThreshold(t *testing.T) {
 	close := makeVec(1.0)
 	far := makeVec(100.0)
 
-	vs.UpsertChunkEmbedding("close", close)
-	vs.UpsertChunkEmbedding("far", far)
+	vs.UpsertChunkEmbedding(testModel, "close", close)
+	vs.UpsertChunkEmbedding(testModel, "far", far)
 
 	// Search with a high threshold — should only return the close match.
 	query := makeVec(1.0)
-	matches, err := vs.SearchChunks(query, 10, 0.99)
+	matches, err := vs.SearchChunks(testModel, query, 10, 0.99)
 	if err != nil {
 		t.Fatalf("SearchChunks: %v", err)
 	}
@@ -199,13 +204,13 @@ func TestVectorStore_Upsert_EmptyEmbedding(t *testing.T) {
 	_, vs := setupVectorTestDB(t)
 
 	// Empty embeddings should be no-ops (no error).
-	if err := vs.UpsertChunkEmbedding("id", nil); err != nil {
+	if err := vs.UpsertChunkEmbedding(testModel, "id", nil); err != nil {
 		t.Errorf("expected no error for nil embedding, got: %v", err)
 	}
-	if err := vs.UpsertChunkEmbedding("id", []float32{}); err != nil {
+	if err := vs.UpsertChunkEmbedding(testModel, "id", []float32{}); err != nil {
 		t.Errorf("expected no error for empty embedding, got: %v", err)
 	}
-	if err := vs.UpsertMemoryEmbedding("id", nil); err != nil {
+	if err := vs.UpsertMemoryEmbedding(testModel, "id", nil); err != nil {
 		t.Errorf("expected no error for nil embedding, got: %v", err)
 	}
 }
@@ -214,14 +219,14 @@ func TestVectorStore_DeleteChunkEmbedding(t *testing.T) {
 	_, vs := setupVectorTestDB(t)
 
 	vec := makeVec(1.0)
-	vs.UpsertChunkEmbedding("to-delete", vec)
+	vs.UpsertChunkEmbedding(testModel, "to-delete", vec)
 
 	if err := vs.DeleteChunkEmbedding("to-delete"); err != nil {
 		t.Fatalf("DeleteChunkEmbedding: %v", err)
 	}
 
 	// Searching should return no results.
-	matches, _ := vs.SearchChunks(vec, 10, 0.0)
+	matches, _ := vs.SearchChunks(testModel, vec, 10, 0.0)
 	for _, m := range matches {
 		if m.ID == "to-delete" {
 			t.Error("deleted embedding should not appear in search results")
@@ -233,13 +238,13 @@ func TestVectorStore_DeleteMemoryEmbedding(t *testing.T) {
 	_, vs := setupVectorTestDB(t)
 
 	vec := makeVec(1.0)
-	vs.UpsertMemoryEmbedding("to-delete", vec)
+	vs.UpsertMemoryEmbedding(testModel, "to-delete", vec)
 
 	if err := vs.DeleteMemoryEmbedding("to-delete"); err != nil {
 		t.Fatalf("DeleteMemoryEmbedding: %v", err)
 	}
 
-	matches, _ := vs.SearchMemories(vec, 10, 0.0)
+	matches, _ := vs.SearchMemories(testModel, vec, 10, 0.0)
 	for _, m := range matches {
 		if m.ID == "to-delete" {
 			t.Error("deleted embedding should not appear in search results")
@@ -253,13 +258,13 @@ func TestVectorStore_Upsert_Replaces(t *testing.T) {
 	original := makeVec(1.0)
 	updated := makeVec(50.0)
 
-	vs.UpsertChunkEmbedding("replace-me", original)
+	vs.UpsertChunkEmbedding(testModel, "replace-me", original)
 	// Upsert with a very different vector.
-	vs.UpsertChunkEmbedding("replace-me", updated)
+	vs.UpsertChunkEmbedding(testModel, "replace-me", updated)
 
 	// Search with a query near the updated vector — should find it.
 	query := makeVec(50.0)
-	matches, _ := vs.SearchChunks(query, 10, 0.0)
+	matches, _ := vs.SearchChunks(testModel, query, 10, 0.0)
 
 	found := false
 	for _, m := range matches {
@@ -275,3 +280,217 @@ func TestVectorStore_Upsert_Replaces(t *testing.T) {
 		t.Error("upserted embedding not found in search results")
 	}
 }
+
+func TestVectorStore_ModelsCoexist(t *testing.T) {
+	_, vs := setupVectorTestDB(t)
+
+	vs.UpsertChunkEmbedding("ollama:nomic-embed-text", "chunk-1", makeVec(1.0))
+	vs.UpsertChunkEmbedding("openai:text-embedding-3-small", "chunk-1", makeVec(50.0))
+
+	// Each model only sees its own vector for the chunk.
+	old, _ := vs.SearchChunks("ollama:nomic-embed-text", makeVec(1.0), 10, 0.0)
+	if len(old) != 1 || old[0].Distance > 0.01 {
+		t.Fatalf("ollama search: got %+v", old)
+	}
+	newer, _ := vs.SearchChunks("openai:text-embedding-3-small", makeVec(1.0), 10, 0.0)
+	if len(newer) != 1 || newer[0].Distance < 1.0 {
+		t.Fatalf("openai search should match the openai vector, got %+v", newer)
+	}
+	if none, _ := vs.SearchChunks("voyage:voyage-code-3", makeVec(1.0), 10, 0.0); len(none) != 0 {
+		t.Errorf("unknown model should have no matches, got %d", len(none))
+	}
+
+	models, err := vs.Models()
+	if err != nil {
+		t.Fatalf("Models: %v", err)
+	}
+	if len(models) != 2 || models[0].Model != "ollama:nomic-embed-text" || models[0].Chunks != 1 {
+		t.Errorf("unexpected models: %+v", models)
+	}
+
+	// Deleting a chunk removes its vectors for every model.
+	if err := vs.DeleteChunkEmbedding("chunk-1"); err != nil {
+		t.Fatalf("DeleteChunkEmbedding: %v", err)
+	}
+	if models, _ := vs.Models(); len(models) != 0 {
+		t.Errorf("expected no models after delete, got %+v", models)
+	}
+}
+
+func TestVectorStore_MissingTables(t *testing.T) {
+	database, _ := setupVectorTestDB(t)
+	// Simulate a database created before vector support.
+	for _, table := range []string{"vec_chunk_embeddings", "vec_memory_embeddings"} {
+		if _, err := database.Conn().Exec(`DROP TABLE ` + table); err != nil {
+			t.Fatalf("drop %s: %v", table, err)
+		}
+	}
+	vs := NewVectorStore(database)
+
+	matches, err := vs.SearchChunks(testModel, makeVec(1.0), 10, 0.0)
+	if err != nil || len(matches) != 0 {
+		t.Fatalf("SearchChunks on a bare DB: got %v, %v; want no matches", matches, err)
+	}
+	if matches, err := vs.SearchMemories(testModel, makeVec(1.0), 10, 0.0); err != nil || len(matches) != 0 {
+		t.Fatalf("SearchMemories on a bare DB: got %v, %v; want no matches", matches, err)
+	}
+	if err := vs.DeleteChunkEmbedding("chunk-1"); err != nil {
+		t.Errorf("DeleteChunkEmbedding on a bare DB: %v", err)
+	}
+	if models, err := vs.Models(); err != nil || len(models) != 0 {
+		t.Errorf("Models on a bare DB: got %v, %v", models, err)
+	}
+
+	// The first upsert creates the tables.
+	if err := vs.UpsertChunkEmbedding(testModel, "chunk-1", makeVec(1.0)); err != nil {
+		t.Fatalf("UpsertChunkEmbedding: %v", err)
+	}
+	matches, err = vs.SearchChunks(testModel, makeVec(1.0), 10, 0.0)
+	if err != nil || len(matches) != 1 || matches[0].ID != "chunk-1" {
+		t.Errorf("expected chunk-1 after lazy creation, got %v, %v", matches, err)
+	}
+}
+
+func TestVectorStore_EmptyTables(t *testing.T) {
+	_, vs := setupVectorTestDB(t)
+	matches, err := vs.SearchChunks(testModel, makeVec(1.0), 10, 0.0)
+	if err != nil || len(matches) != 0 {
+		t.Errorf("expected no matches from an empty table, got %v, %v", matches, err)
+	}
+}
+
+// sizedVec is makeVec for vectors of dim components.
+func sizedVec(dim int, base float32) []float32 {
+	v := make([]float32, dim)
+	for i := range v {
+		v[i] = base
+	}
+	return v
+}
+
+func TestVectorStore_CohereDimension(t *testing.T) {
+	_, vs := setupVectorTestDB(t)
+	dim := adapter.CohereEmbedDimension
+
+	// The empty tables are resized by the first upsert.
+	if err := vs.UpsertChunkEmbedding(testModel, "chunk-1", sizedVec(dim, 1.0)); err != nil {
+		t.Fatalf("UpsertChunkEmbedding: %v", err)
+	}
+	if err := vs.UpsertMemoryEmbeddings(testModel, []string{"mem-1"}, [][]float32{sizedVec(dim, 1.0)}); err != nil {
+		t.Fatalf("UpsertMemoryEmbeddings: %v", err)
+	}
+	if got := vs.Dimension(); got != dim {
+		t.Errorf("Dimension = %d, want %d", got, dim)
+	}
+	matches, err := vs.SearchChunks(testModel, sizedVec(dim, 1.1), 10, 0.0)
+	if err != nil || len(matches) != 1 || matches[0].ID != "chunk-1" {
+		t.Errorf("SearchChunks: got %v, %v; want chunk-1", matches, err)
+	}
+	matches, err = vs.SearchMemories(testModel, sizedVec(dim, 1.1), 10, 0.0)
+	if err != nil || len(matches) != 1 || matches[0].ID != "mem-1" {
+		t.Errorf("SearchMemories: got %v, %v; want mem-1", matches, err)
+	}
+
+	// Once vectors are stored, another size is refused until Resize.
+	err = vs.UpsertChunkEmbedding("other:model", "chunk-1", makeVec(1.0))
+	var mismatch *DimensionMismatchError
+	if !errors.As(err, &mismatch) || mismatch.Stored != dim || mismatch.Embedder != 768 {
+		t.Fatalf("expected a DimensionMismatchError, got %v", err)
+	}
+	if resized, err := vs.Resize(dim); err != nil || resized {
+		t.Errorf("Resize to the current size: got %v, %v; want a no-op", resized, err)
+	}
+	if resized, err := vs.Resize(768); err != nil || !resized {
+		t.Fatalf("Resize(768): got %v, %v", resized, err)
+	}
+	if got := vs.Dimension(); got != 0 {
+		t.Errorf("Resize should discard stored vectors, Dimension = %d", got)
+	}
+	if err := vs.UpsertChunkEmbedding("other:model", "chunk-1", makeVec(1.0)); err != nil {
+		t.Errorf("upsert after Resize: %v", err)
+	}
+}
+
+func TestVectorStore_EachEmbedding(t *testing.T) {
+	_, vs := setupVectorTestDB(t)
+
+	vs.UpsertChunkEmbedding(testModel, "chunk-b", makeVec(0.2))
+	vs.UpsertChunkEmbedding(testModel, "chunk-a", makeVec(0.1))
+	vs.UpsertChunkEmbedding("other:model", "chunk-c", makeVec(0.3))
+	vs.UpsertMemoryEmbedding(testModel, "mem-1", makeVec(0.4))
+
+	var ids []string
+	err := vs.EachChunkEmbedding(testModel, func(e StoredEmbedding) error {
+		ids = append(ids, e.ID)
+		if len(e.Embedding) != 768 {
+			t.Errorf("%s: expected 768 dimensions, got %d", e.ID, len(e.Embedding))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("EachChunkEmbedding: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "chunk-a" || ids[1] != "chunk-b" {
+		t.Errorf("expected chunk-a, chunk-b for the model, got %v", ids)
+	}
+
+	var mem StoredEmbedding
+	_ = vs.EachMemoryEmbedding(testModel, func(e StoredEmbedding) error { mem = e; return nil })
+	if mem.ID != "mem-1" || mem.Embedding[0] != 0.4 {
+		t.Errorf("unexpected memory embedding: %s %v", mem.ID, mem.Embedding[:1])
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = vs.EachChunkEmbedding(testModel, func(StoredEmbedding) error { calls++; return stop })
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("expected iteration to stop at the first error, got %v after %d calls", err, calls)
+	}
+}
+
+func TestVectorStore_GarbageCollect(t *testing.T) {
+	database, vs := setupVectorTestDB(t)
+	store := NewStore(database)
+
+	fileID, err := store.UpsertFile(File{Path: "a.go", ContentHash: "h"})
+	if err != nil {
+		t.Fatalf("UpsertFile: %v", err)
+	}
+	keep, _ := store.InsertChunkReturningID(Chunk{FileID: fileID, Content: "keep", StartLine: 1, EndLine: 1})
+	gone, _ := store.InsertChunkReturningID(Chunk{FileID: fileID, Content: "gone", StartLine: 2, EndLine: 2})
+	mem, _ := store.InsertMemory(Memory{Content: "use Go", MemoryType: TypeDecision})
+	for _, model := range []string{testModel, "other:model"} {
+		_ = vs.UpsertChunkEmbedding(model, keep, makeVec(0.1))
+		_ = vs.UpsertChunkEmbedding(model, gone, makeVec(0.2))
+	}
+	_ = vs.UpsertMemoryEmbedding(testModel, mem, makeVec(0.3))
+	_ = vs.UpsertMemoryEmbedding(testModel, "deleted-memory", makeVec(0.4))
+
+	// Delete the chunk row directly, bypassing the orchestrator.
+	if _, err := database.Conn().Exec(`DELETE FROM chunks WHERE id = ?`, gone); err != nil {
+		t.Fatalf("delete chunk: %v", err)
+	}
+
+	res, err := vs.GarbageCollect(store)
+	if err != nil {
+		t.Fatalf("GarbageCollect: %v", err)
+	}
+	if res.Chunks != 2 || res.Memories != 1 || res.Total() != 3 {
+		t.Errorf("expected 2 chunk and 1 memory orphans, got %+v", res)
+	}
+
+	matches, _ := vs.SearchChunks(testModel, makeVec(0.2), 10, 0)
+	for _, m := range matches {
+		if m.ID == gone {
+			t.Error("orphaned chunk embedding still searchable")
+		}
+	}
+	if len(matches) != 1 || matches[0].ID != keep {
+		t.Errorf("expected only the live chunk, got %+v", matches)
+	}
+
+	// A second pass finds nothing.
+	if res, _ := vs.GarbageCollect(store); res.Total() != 0 {
+		t.Errorf("second GC removed %+v", res)
+	}
+}
@@ -0,0 +1,94 @@
+// Package redact detects and masks secrets (API keys, tokens, private keys)
+// in free text before it is persisted or exported.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Placeholder replaces every detected secret.
+const Placeholder = "[REDACTED]"
+
+// DefaultPatterns are the built-in secret patterns. A pattern with a named
+// group "secret" only has that group replaced, so surrounding text such as
+// "api_key=" stays readable.
+var DefaultPatterns = []string{
+	// Private key blocks (PEM).
+	`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`,
+	// AWS access key IDs.
+	`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`,
+	// GitHub tokens.
+	`\bgh[pousr]_[A-Za-z0-9]{36,}\b`,
+	`\bgithub_pat_[A-Za-z0-9_]{22,}`,
+	// Anthropic / OpenAI style keys.
+	`\bsk-(?:ant-|proj-)?[A-Za-z0-9_\-]{20,}`,
+	// Stripe keys.
+	`\b(?:sk|rk|pk)_(?:live|test)_[0-9A-Za-z]{16,}`,
+	// Slack tokens.
+	`\bxox[abposr]-[A-Za-z0-9-]{10,}`,
+	// Google API keys.
+	`\bAIza[0-9A-Za-z_\-]{35}`,
+	// JSON Web Tokens.
+	`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`,
+	// Generic key = value assignments.
+	`(?i)\b(?:api[_-]?key|secret|token|password|passwd|access[_-]?key)\b["']?\s*[:=]\s*["']?(?P<secret>[^\s"',;\[]{8,})`,
+}
+
+// Redactor masks text matching a set of secret patterns.
+type Redactor struct {
+	patterns []*regexp.Regexp
+}
+
+// New compiles patterns into a Redactor. An empty list uses DefaultPatterns.
+func New(patterns []string) (*Redactor, error) {
+	if len(patterns) == 0 {
+		patterns = DefaultPatterns
+	}
+	r := &Redactor{patterns: make([]*regexp.Regexp, 0, len(patterns))}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("redact: compile pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// Redact returns s with every secret replaced by Placeholder, along with the
+// number of secrets found.
+func (r *Redactor) Redact(s string) (string, int) {
+	total := 0
+	for _, re := range r.patterns {
+		var n int
+		s, n = replace(re, s)
+		total += n
+	}
+	return s, total
+}
+
+// replace masks all matches of re in s, limited to the "secret" group when
+// the pattern defines one.
+func replace(re *regexp.Regexp, s string) (string, int) {
+	matches := re.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s, 0
+	}
+	group := re.SubexpIndex("secret")
+
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if group > 0 && m[2*group] >= 0 {
+			start, end = m[2*group], m[2*group+1]
+		}
+		sb.WriteString(s[last:start])
+		sb.WriteString(Placeholder)
+		last = end
+	}
+	sb.WriteString(s[last:])
+	return sb.String(), len(matches)
+}
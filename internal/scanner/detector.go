@@ -3,9 +3,12 @@
 package scanner
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -24,19 +27,86 @@ type TechStack struct {
 	DetectedPatterns []string `json:"detected_patterns,omitempty"`
 	FileCount        int      `json:"file_count"`
 	ChunkCount       int      `json:"chunk_count"`
+
+	// Stacks holds the named components of a polyglot project (e.g. a Go
+	// "backend" and a TypeScript "frontend"). When non-empty it replaces the
+	// single-stack fields above in the stored JSON and in rendered output.
+	Stacks []NamedStack `json:"-"`
+}
+
+// NamedStack is one named component of a polyglot project.
+type NamedStack struct {
+	Name string
+	TechStack
+}
+
+// Components returns the stacks to render for this project: the named
+// stacks for a polyglot project, or a single unnamed entry otherwise.
+func (ts TechStack) Components() []NamedStack {
+	if len(ts.Stacks) > 0 {
+		return ts.Stacks
+	}
+	return []NamedStack{{TechStack: ts}}
 }
 
 // ToJSON serialises the tech stack as a JSON string for storage.
+// Polyglot stacks are stored as an object keyed by stack name.
 func (ts TechStack) ToJSON() string {
+	if len(ts.Stacks) > 0 {
+		named := make(map[string]TechStack, len(ts.Stacks))
+		for _, ns := range ts.Stacks {
+			named[ns.Name] = ns.TechStack
+		}
+		b, _ := json.Marshal(named)
+		return string(b)
+	}
 	b, _ := json.Marshal(ts)
 	return string(b)
 }
 
-// TechStackFromJSON parses a stored JSON string.
+// TechStackFromJSON parses a stored JSON string. It accepts both the classic
+// single-stack object and the named shape ({"backend": {...}, "frontend": {...}}).
 func TechStackFromJSON(s string) (TechStack, error) {
 	var ts TechStack
-	err := json.Unmarshal([]byte(s), &ts)
-	return ts, err
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(s), &raw); err != nil {
+		return ts, err
+	}
+	if !isNamedStackShape(raw) {
+		err := json.Unmarshal([]byte(s), &ts)
+		return ts, err
+	}
+
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var sub TechStack
+		if err := json.Unmarshal(raw[name], &sub); err != nil {
+			return TechStack{}, fmt.Errorf("stack %q: %w", name, err)
+		}
+		ts.Stacks = append(ts.Stacks, NamedStack{Name: name, TechStack: sub})
+	}
+	return ts, nil
+}
+
+// isNamedStackShape reports whether every top-level value is a JSON object,
+// which distinguishes named stacks from the flat single-stack fields.
+func isNamedStackShape(raw map[string]json.RawMessage) bool {
+	if len(raw) == 0 {
+		return false
+	}
+	for _, v := range raw {
+		trimmed := bytes.TrimSpace(v)
+		if len(trimmed) == 0 || trimmed[0] != '{' {
+			return false
+		}
+	}
+	return true
 }
 
 // DetectTechStack inspects the project root and returns a best-effort profile.
@@ -65,9 +135,13 @@ func DetectTechStack(root string) TechStack {
 	}
 
 	// ----- Language / Framework detection -----
+	// Manifests outweigh extension counts, so a Go service with a
+	// package.json for frontend tooling is still detected as Go.
+	sources := sampleSources(root)
+	lang := primaryLanguage(has, sources)
 
-	switch {
-	case has("Gemfile", "Gemfile.lock"):
+	switch lang {
+	case langRuby:
 		ts.Language = "Ruby"
 		gemfile := read("Gemfile")
 		switch {
@@ -93,7 +167,7 @@ func DetectTechStack(root string) TechStack {
 			ts.DetectedPatterns = append(ts.DetectedPatterns, "multi-tenant")
 		}
 
-	case has("package.json"):
+	case langJavaScript:
 		pkgJSON := read("package.json")
 		ts.Language = "JavaScript/TypeScript"
 		switch {
@@ -107,7 +181,7 @@ func DetectTechStack(root string) TechStack {
 			ts.Framework = "Express"
 		case strings.Contains(pkgJSON, `"fastify"`):
 			ts.Framework = "Fastify"
-		case strings.Contains(pkgJSON, `"nest"`):
+		case strings.Contains(pkgJSON, `"@nestjs/core"`), strings.Contains(pkgJSON, `"nest"`):
 			ts.Framework = "NestJS"
 		}
 		if has("tsconfig.json") {
@@ -119,7 +193,7 @@ func DetectTechStack(root string) TechStack {
 			ts.TestFramework = "Vitest"
 		}
 
-	case has("go.mod"):
+	case langGo:
 		ts.Language = "Go"
 		goMod := read("go.mod")
 		switch {
@@ -129,14 +203,12 @@ func DetectTechStack(root string) TechStack {
 			ts.Framework = "Echo"
 		case strings.Contains(goMod, "github.com/gofiber/fiber"):
 			ts.Framework = "Fiber"
-		default:
-			ts.Framework = "stdlib"
 		}
 		if has("cmd") {
 			ts.EntryPoints = append(ts.EntryPoints, "cmd/")
 		}
 
-	case has("Cargo.toml"):
+	case langRust:
 		ts.Language = "Rust"
 		cargo := read("Cargo.toml")
 		if strings.Contains(cargo, "actix") {
@@ -145,11 +217,12 @@ func DetectTechStack(root string) TechStack {
 			ts.Framework = "Axum"
 		}
 
-	case has("pyproject.toml", "requirements.txt", "setup.py"):
+	case langPython:
 		ts.Language = "Python"
-		req := read("requirements.txt") + read("pyproject.toml")
+		// Package names are case-insensitive ("Django==5.0" is common).
+		req := strings.ToLower(read("requirements.txt") + read("pyproject.toml") + read("setup.py"))
 		switch {
-		case strings.Contains(req, "django"):
+		case strings.Contains(req, "django"), has("manage.py"):
 			ts.Framework = "Django"
 		case strings.Contains(req, "fastapi"):
 			ts.Framework = "FastAPI"
@@ -159,13 +232,28 @@ func DetectTechStack(root string) TechStack {
 		if strings.Contains(req, "pytest") {
 			ts.TestFramework = "pytest"
 		}
+		if has("manage.py") {
+			ts.EntryPoints = append(ts.EntryPoints, "manage.py")
+		}
 
-	case has("pom.xml", "build.gradle", "build.gradle.kts"):
-		ts.Language = "Java/Kotlin"
-		ts.Framework = "Spring Boot"
-		if has("build.gradle.kts") {
+	case langJava:
+		build := read("pom.xml") + read("build.gradle") + read("build.gradle.kts")
+		ts.Language = "Java"
+		if has("build.gradle.kts") || strings.Contains(build, "kotlin") {
 			ts.Language = "Kotlin"
 		}
+		if strings.Contains(build, "spring-boot") || strings.Contains(build, "org.springframework.boot") {
+			ts.Framework = "Spring Boot"
+		}
+	}
+
+	// Manifests don't always name the framework (or don't exist), so fall
+	// back to imports in the source itself.
+	if ts.Framework == "" && lang != "" {
+		ts.Framework = frameworkFromImports(lang, sources.files[lang])
+	}
+	if ts.Framework == "" && lang == langGo {
+		ts.Framework = "stdlib"
 	}
 
 	// ----- Database detection -----
@@ -35,6 +35,69 @@ func TestDetectTechStack_RailsProject(t *testing.T) {
 	}
 }
 
+func TestDetectTechStack_NodeFrameworkFromManifest(t *testing.T) {
+	ts := DetectTechStack("../../testdata/node_project")
+	if ts.Framework != "Express" {
+		t.Errorf("framework: got %q, want %q", ts.Framework, "Express")
+	}
+	if ts.TestFramework != "Jest" {
+		t.Errorf("test framework: got %q, want %q", ts.TestFramework, "Jest")
+	}
+}
+
+func TestDetectTechStack_ManifestOutweighsToolingPackageJSON(t *testing.T) {
+	// go.mod plus a package.json that only carries frontend tooling.
+	ts := DetectTechStack("../../testdata/go_tooling_project")
+	if ts.Language != "Go" {
+		t.Errorf("language: got %q, want %q", ts.Language, "Go")
+	}
+	if ts.Framework != "Gin" {
+		t.Errorf("framework: got %q, want %q", ts.Framework, "Gin")
+	}
+}
+
+func TestDetectTechStack_DjangoProject(t *testing.T) {
+	ts := DetectTechStack("../../testdata/django_project")
+	if ts.Language != "Python" {
+		t.Errorf("language: got %q, want %q", ts.Language, "Python")
+	}
+	if ts.Framework != "Django" {
+		t.Errorf("framework: got %q, want %q", ts.Framework, "Django")
+	}
+	if ts.TestFramework != "pytest" {
+		t.Errorf("test framework: got %q, want %q", ts.TestFramework, "pytest")
+	}
+}
+
+func TestDetectTechStack_FrameworkFromImports(t *testing.T) {
+	// No manifest at all: the language comes from file extensions and the
+	// framework from imports.
+	ts := DetectTechStack("../../testdata/flask_scripts")
+	if ts.Language != "Python" {
+		t.Errorf("language: got %q, want %q", ts.Language, "Python")
+	}
+	if ts.Framework != "Flask" {
+		t.Errorf("framework: got %q, want %q", ts.Framework, "Flask")
+	}
+}
+
+func TestDetectTechStack_SpringProject(t *testing.T) {
+	ts := DetectTechStack("../../testdata/spring_project")
+	if ts.Language != "Java" {
+		t.Errorf("language: got %q, want %q", ts.Language, "Java")
+	}
+	if ts.Framework != "Spring Boot" {
+		t.Errorf("framework: got %q, want %q", ts.Framework, "Spring Boot")
+	}
+}
+
+func TestDetectTechStack_GoStdlibFallback(t *testing.T) {
+	ts := DetectTechStack("../../testdata/go_project")
+	if ts.Framework != "stdlib" {
+		t.Errorf("framework: got %q, want %q", ts.Framework, "stdlib")
+	}
+}
+
 func TestDetectTechStack_NonExistentDir(t *testing.T) {
 	ts := DetectTechStack("/tmp/memvra-nonexistent-dir")
 	if ts.Language != "" {
@@ -87,3 +150,46 @@ func TestTechStack_ToJSON_Valid(t *testing.T) {
 		t.Errorf("expected language=Ruby, got %v", m["language"])
 	}
 }
+
+func TestTechStackFromJSON_NamedStacks(t *testing.T) {
+	raw := `{"frontend":{"language":"TypeScript","framework":"React"},"backend":{"language":"Go","database":"PostgreSQL"}}`
+
+	ts, err := TechStackFromJSON(raw)
+	if err != nil {
+		t.Fatalf("TechStackFromJSON error: %v", err)
+	}
+	if len(ts.Stacks) != 2 {
+		t.Fatalf("expected 2 named stacks, got %d", len(ts.Stacks))
+	}
+	// Stacks are sorted by name for stable rendering.
+	if ts.Stacks[0].Name != "backend" || ts.Stacks[0].Language != "Go" {
+		t.Errorf("stack 0: got %+v", ts.Stacks[0])
+	}
+	if ts.Stacks[1].Name != "frontend" || ts.Stacks[1].Framework != "React" {
+		t.Errorf("stack 1: got %+v", ts.Stacks[1])
+	}
+
+	// Round-trip through ToJSON keeps the named shape.
+	again, err := TechStackFromJSON(ts.ToJSON())
+	if err != nil {
+		t.Fatalf("round-trip error: %v", err)
+	}
+	if len(again.Stacks) != 2 || again.Stacks[0].Database != "PostgreSQL" {
+		t.Errorf("round-trip failed: got %+v", again.Stacks)
+	}
+}
+
+func TestTechStack_Components(t *testing.T) {
+	single := TechStack{Language: "Go"}
+	if c := single.Components(); len(c) != 1 || c[0].Name != "" || c[0].Language != "Go" {
+		t.Errorf("single stack components: got %+v", c)
+	}
+
+	empty, err := TechStackFromJSON("{}")
+	if err != nil {
+		t.Fatalf("TechStackFromJSON({}) error: %v", err)
+	}
+	if len(empty.Stacks) != 0 {
+		t.Errorf("empty object should parse as a single stack, got %d named stacks", len(empty.Stacks))
+	}
+}
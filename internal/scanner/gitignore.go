@@ -10,6 +10,26 @@ import (
 // IgnoreMatcher wraps a gitignore pattern matcher.
 type IgnoreMatcher struct {
 	gi *gitignore.GitIgnore
+	// excludes holds extra gitignore-style patterns added via WithPatterns,
+	// such as the project's exclude_paths.
+	excludes []*gitignore.GitIgnore
+}
+
+// NewPathMatcher returns a matcher for gitignore-style patterns alone,
+// without reading any .gitignore file.
+func NewPathMatcher(patterns []string) *IgnoreMatcher {
+	return (&IgnoreMatcher{}).WithPatterns(patterns)
+}
+
+// WithPatterns returns a copy of m that also matches the given
+// gitignore-style patterns. m itself is not modified.
+func (m *IgnoreMatcher) WithPatterns(patterns []string) *IgnoreMatcher {
+	if len(patterns) == 0 {
+		return m
+	}
+	out := &IgnoreMatcher{gi: m.gi}
+	out.excludes = append(append(out.excludes, m.excludes...), gitignore.CompileIgnoreLines(patterns...))
+	return out
 }
 
 // NewIgnoreMatcher loads .gitignore from the project root.
@@ -28,6 +48,11 @@ func NewIgnoreMatcher(root string) *IgnoreMatcher {
 
 // Match returns true if the given relative path should be ignored.
 func (m *IgnoreMatcher) Match(relPath string) bool {
+	for _, ex := range m.excludes {
+		if ex.MatchesPath(relPath) {
+			return true
+		}
+	}
 	if m.gi == nil {
 		return false
 	}
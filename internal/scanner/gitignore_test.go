@@ -85,3 +85,42 @@ func TestIgnoreMatcher_WithGitignore(t *testing.T) {
 		t.Error("expected main.go to NOT be ignored")
 	}
 }
+
+func TestPathMatcher_Patterns(t *testing.T) {
+	m := NewPathMatcher([]string{"secrets/", "*.pem", "generated/**/*.go"})
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"secrets/keys.go", true},
+		{"config/secrets/db.yml", true},
+		{"certs/server.pem", true},
+		{"generated/api/client.go", true},
+		{"main.go", false},
+		{"internal/secretsmanager.go", false},
+	}
+	for _, tt := range tests {
+		if got := m.Match(tt.path); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestIgnoreMatcher_WithPatternsKeepsGitignore(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*.log\n"), 0o644)
+
+	base := NewIgnoreMatcher(dir)
+	m := base.WithPatterns([]string{"secrets/"})
+
+	if !m.Match("app.log") {
+		t.Error("expected .gitignore pattern to still match")
+	}
+	if !m.Match("secrets/token.txt") {
+		t.Error("expected extra pattern to match")
+	}
+	if base.Match("secrets/token.txt") {
+		t.Error("WithPatterns must not modify the original matcher")
+	}
+}
@@ -29,7 +29,12 @@ type ScannedFile struct {
 type ScanOptions struct {
 	Root         string
 	MaxChunkLines int
+	// ExcludeGlobs are gitignore-style patterns (e.g. the project's
+	// exclude_paths) whose files are never read or chunked.
 	ExcludeGlobs []string
+	// Progress, if set, is called after each file is read with the number
+	// scanned so far. The total is unknown while walking, so it is always 0.
+	Progress memory.ProgressFunc
 }
 
 // Scan walks the project tree, hashes files, and splits them into chunks.
@@ -41,7 +46,7 @@ func Scan(opts ScanOptions) ScanResult {
 		maxLines = DefaultMaxLines
 	}
 
-	ignore := NewIgnoreMatcher(root)
+	ignore := NewIgnoreMatcher(root).WithPatterns(opts.ExcludeGlobs)
 	stack := DetectTechStack(root)
 
 	var result ScanResult
@@ -122,6 +127,9 @@ func Scan(opts ScanOptions) ScanResult {
 		}
 
 		result.Files = append(result.Files, sf)
+		if opts.Progress != nil {
+			opts.Progress(len(result.Files), 0)
+		}
 		return nil
 	})
 
@@ -92,6 +92,24 @@ func TestScan_CountsCorrectly(t *testing.T) {
 	}
 }
 
+func TestScan_ReportsProgress(t *testing.T) {
+	var last, calls int
+	result := Scan(ScanOptions{
+		Root: "../../testdata/go_project",
+		Progress: func(done, total int) {
+			if done != last+1 || total != 0 {
+				t.Errorf("progress(%d, %d) after %d", done, total, last)
+			}
+			last = done
+			calls++
+		},
+	})
+
+	if calls != len(result.Files) || last != len(result.Files) {
+		t.Errorf("progress: %d calls ending at %d, want %d", calls, last, len(result.Files))
+	}
+}
+
 func TestScanFile_RecognisedFile(t *testing.T) {
 	dir := t.TempDir()
 	os.WriteFile(filepath.Join(dir, "hello.go"), []byte("package main\n\nfunc main() {}\n"), 0o644)
@@ -170,3 +188,20 @@ func TestFindProjectRoot_FromSubdir(t *testing.T) {
 		t.Logf("found root: %s", root)
 	}
 }
+
+func TestScan_ExcludeGlobs(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644)
+	os.MkdirAll(filepath.Join(dir, "secrets"), 0o755)
+	os.WriteFile(filepath.Join(dir, "secrets", "keys.go"), []byte("package secrets\n"), 0o644)
+
+	result := Scan(ScanOptions{Root: dir, ExcludeGlobs: []string{"secrets/"}})
+
+	if len(result.Files) != 1 || result.Files[0].File.Path != "main.go" {
+		var paths []string
+		for _, sf := range result.Files {
+			paths = append(paths, sf.File.Path)
+		}
+		t.Errorf("expected only main.go, got %v", paths)
+	}
+}
@@ -0,0 +1,200 @@
+package scanner
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Primary languages recognised by DetectTechStack.
+const (
+	langGo         = "go"
+	langRuby       = "ruby"
+	langJavaScript = "javascript"
+	langPython     = "python"
+	langJava       = "java"
+	langRust       = "rust"
+)
+
+// primaryLanguages fixes the order in which equal scores are resolved.
+var primaryLanguages = []string{langRuby, langGo, langRust, langPython, langJava, langJavaScript}
+
+// languageSignals are manifest files that identify a project's primary
+// language and how strongly each one counts. package.json weighs less than
+// the others because it often sits beside another stack only for frontend
+// tooling.
+var languageSignals = []struct {
+	file   string
+	lang   string
+	weight float64
+}{
+	{"go.mod", langGo, 10},
+	{"Gemfile", langRuby, 10},
+	{"Gemfile.lock", langRuby, 10},
+	{"Cargo.toml", langRust, 10},
+	{"pyproject.toml", langPython, 10},
+	{"setup.py", langPython, 10},
+	{"requirements.txt", langPython, 10},
+	{"manage.py", langPython, 10},
+	{"pom.xml", langJava, 10},
+	{"build.gradle", langJava, 10},
+	{"build.gradle.kts", langJava, 10},
+	{"package.json", langJavaScript, 8},
+}
+
+// extensionWeight is what a language scores for making up every source file
+// in the project. It is kept below the manifest weights so a signal file
+// outranks raw extension counts.
+const extensionWeight = 4
+
+// Limits on the walk that counts source files and on the files read when
+// looking for framework imports.
+const (
+	maxSignalFiles  = 5000
+	maxImportFiles  = 50
+	importHeadBytes = 16 << 10
+)
+
+// sourceLanguages maps LanguageForFile names to primary languages.
+var sourceLanguages = map[string]string{
+	"go":         langGo,
+	"ruby":       langRuby,
+	"javascript": langJavaScript,
+	"typescript": langJavaScript,
+	"tsx":        langJavaScript,
+	"jsx":        langJavaScript,
+	"python":     langPython,
+	"java":       langJava,
+	"kotlin":     langJava,
+	"rust":       langRust,
+}
+
+// frameworkImports are source-level markers of a framework, used when a
+// project's manifests don't name one (or there is no manifest at all).
+var frameworkImports = map[string][]struct {
+	framework string
+	markers   []string
+}{
+	langGo: {
+		{"Gin", []string{`"github.com/gin-gonic/gin"`}},
+		{"Echo", []string{`"github.com/labstack/echo`}},
+		{"Fiber", []string{`"github.com/gofiber/fiber`}},
+	},
+	langRuby: {
+		{"Rails", []string{"Rails::Application", `require "rails`, `require 'rails`}},
+		{"Sinatra", []string{`require "sinatra`, `require 'sinatra`}},
+	},
+	langJavaScript: {
+		{"NestJS", []string{`from '@nestjs/`, `from "@nestjs/`}},
+		{"Express", []string{`require('express')`, `require("express")`, `from 'express'`, `from "express"`}},
+		{"Fastify", []string{`require('fastify')`, `require("fastify")`, `from 'fastify'`, `from "fastify"`}},
+	},
+	langPython: {
+		{"Django", []string{"from django", "import django"}},
+		{"FastAPI", []string{"from fastapi", "import fastapi"}},
+		{"Flask", []string{"from flask", "import flask"}},
+	},
+	langJava: {
+		{"Spring Boot", []string{"org.springframework.boot"}},
+	},
+	langRust: {
+		{"Actix", []string{"actix_web"}},
+		{"Axum", []string{"axum::"}},
+	},
+}
+
+// sourceSample is a bounded census of a project's source files.
+type sourceSample struct {
+	counts map[string]int      // primary language -> file count
+	files  map[string][]string // primary language -> paths, up to maxImportFiles
+	total  int
+}
+
+// sampleSources walks root counting source files per primary language,
+// skipping hard-ignored and hidden directories. It stops after
+// maxSignalFiles files, which is plenty to judge proportions.
+func sampleSources(root string) sourceSample {
+	s := sourceSample{counts: map[string]int{}, files: map[string][]string{}}
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && (HardIgnore(d.Name()) || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		lang, ok := sourceLanguages[LanguageForFile(path)]
+		if !ok {
+			return nil
+		}
+		s.counts[lang]++
+		s.total++
+		if len(s.files[lang]) < maxImportFiles {
+			s.files[lang] = append(s.files[lang], path)
+		}
+		if s.total >= maxSignalFiles {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return s
+}
+
+// primaryLanguage scores each language by its strongest manifest plus its
+// share of source files and returns the best, or "" when nothing matched.
+// Ties go to the earlier entry in primaryLanguages.
+func primaryLanguage(has func(...string) bool, sources sourceSample) string {
+	scores := map[string]float64{}
+	for _, sig := range languageSignals {
+		if has(sig.file) {
+			scores[sig.lang] = max(scores[sig.lang], sig.weight)
+		}
+	}
+	if sources.total > 0 {
+		for lang, n := range sources.counts {
+			scores[lang] += extensionWeight * float64(n) / float64(sources.total)
+		}
+	}
+
+	best, bestScore := "", 0.0
+	for _, lang := range primaryLanguages {
+		if scores[lang] > bestScore {
+			best, bestScore = lang, scores[lang]
+		}
+	}
+	return best
+}
+
+// frameworkFromImports returns the first framework whose markers appear in
+// the head of any of files, or "" if none do.
+func frameworkFromImports(lang string, files []string) string {
+	candidates := frameworkImports[lang]
+	if len(candidates) == 0 {
+		return ""
+	}
+	for _, path := range files {
+		head := readHeadString(path, importHeadBytes)
+		for _, c := range candidates {
+			for _, m := range c.markers {
+				if strings.Contains(head, m) {
+					return c.framework
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func readHeadString(path string, n int64) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+	b, _ := io.ReadAll(io.LimitReader(f, n))
+	return string(b)
+}
@@ -0,0 +1,358 @@
+// Package memvra is a Go client for a Memvra project. It lets other programs
+// store memories, search code and memories, build LLM context, and record
+// session progress without shelling out to the memvra CLI.
+//
+// The project must already be initialised with `memvra init`. Configuration
+// is read the same way the CLI reads it: the global config, overlaid by the
+// project's .memvra/config.toml.
+//
+//	c, err := memvra.Open(".")
+//	if err != nil { ... }
+//	defer c.Close()
+//	built, err := c.BuildContext(ctx, "how does auth work?", memvra.ContextOptions{})
+package memvra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/memvra/memvra/internal/adapter"
+	"github.com/memvra/memvra/internal/config"
+	ctxpkg "github.com/memvra/memvra/internal/context"
+	"github.com/memvra/memvra/internal/db"
+	"github.com/memvra/memvra/internal/export"
+	"github.com/memvra/memvra/internal/memory"
+)
+
+// ErrNotInitialized is returned by Open when the project has no Memvra database.
+var ErrNotInitialized = errors.New("memvra: project not initialized (run `memvra init`)")
+
+// auditClient is the model name BuildContext records in the access log when
+// no model is set.
+const auditClient = "library"
+
+// Session statuses accepted by SaveProgress.
+const (
+	StatusCompleted  = string(memory.SessionCompleted)
+	StatusInProgress = string(memory.SessionInProgress)
+	StatusBlocked    = string(memory.SessionBlocked)
+)
+
+// Embedder turns texts into vectors. Open uses the configured provider; use
+// SetEmbedder to supply your own.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Memory is a stored project memory.
+type Memory struct {
+	ID         string
+	Content    string
+	Type       string // decision, convention, constraint, note, or todo
+	Source     string // "user" or "extracted"
+	Importance float64
+	CreatedAt  time.Time
+}
+
+// CodeMatch is an indexed code chunk returned by Search.
+type CodeMatch struct {
+	Path      string
+	StartLine int
+	EndLine   int
+	Content   string
+}
+
+// SearchResult holds the memories and code found by Search, best first.
+type SearchResult struct {
+	Memories []Memory
+	Code     []CodeMatch
+}
+
+// SearchOptions controls Search. Zero values use the project configuration.
+type SearchOptions struct {
+	TopK      int      // results per kind (0 = 10)
+	Threshold float64  // minimum similarity (0 = context.similarity_threshold)
+	Sources   []string // restrict memories to these sources (empty = all)
+}
+
+// ContextOptions controls BuildContext. Zero values use the project configuration.
+type ContextOptions struct {
+	MaxTokens int      // token budget (0 = context.max_tokens)
+	Model     string   // target LLM, used to size the budget when MaxTokens and the config leave it unset (default: default_model)
+	Files     []string // files to always include, relative to the project root
+}
+
+// Context is an assembled prompt for an LLM.
+type Context struct {
+	SystemPrompt string
+	Text         string
+	TokensUsed   int
+}
+
+// Progress describes a work session for SaveProgress.
+type Progress struct {
+	Task         string   // what was being worked on (required)
+	Summary      string   // what was done and decided (required)
+	Model        string   // who did the work, e.g. "claude" (default: the project's default_model)
+	Status       string   // StatusCompleted (default), StatusInProgress, or StatusBlocked
+	NextSteps    []string // follow-up tasks for whoever continues
+	FilesTouched []string // appended to the summary
+	Tags         []string // theme labels, e.g. "bugfix"; lowercased
+}
+
+// Client is an open Memvra project. It is safe for sequential use; callers
+// sharing a Client across goroutines must serialise calls.
+type Client struct {
+	root     string
+	database *db.DB
+	store    *memory.Store
+	vectors  *memory.VectorStore
+	gcfg     config.GlobalConfig
+	pcfg     config.ProjectConfig
+	embedder adapter.Embedder
+}
+
+// Open opens the Memvra project rooted at root. The configured embedder is
+// used when available; without one, searches fall back to recent memories.
+func Open(root string) (*Client, error) {
+	dbPath := config.ProjectDBPath(root)
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return nil, ErrNotInitialized
+	}
+	database, err := db.Open(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("memvra: open database: %w", err)
+	}
+
+	gcfg, _ := config.Load(root)
+	pcfg, _ := config.LoadProject(root)
+	if err := memory.RegisterTypes(pcfg.MemoryTypes); err != nil {
+		_ = database.Close()
+		return nil, fmt.Errorf("memvra: memory_types: %w", err)
+	}
+	// Vectors stored before embeddings were keyed by model belong to
+	// whichever model is configured now (best-effort).
+	_, _ = database.AdoptLegacyVectors(gcfg.EmbeddingModelKey())
+
+	c := &Client{
+		root:     root,
+		database: database,
+		store:    memory.NewStore(database),
+		vectors:  memory.NewVectorStore(database),
+		gcfg:     gcfg,
+		pcfg:     pcfg,
+	}
+	c.embedder = c.cached(buildEmbedder(gcfg))
+	return c, nil
+}
+
+// SetEmbedder replaces the configured embedder. Its vectors must match the
+// configured embedding model's dimensions. A nil e disables semantic search.
+func (c *Client) SetEmbedder(e Embedder) {
+	if e == nil {
+		c.embedder = nil
+		return
+	}
+	c.embedder = c.cached(e)
+}
+
+// Close releases the database connection.
+func (c *Client) Close() error {
+	return c.database.Close()
+}
+
+// Remember stores a memory and returns it with its ID. An empty memType is
+// inferred from the content.
+func (c *Client) Remember(ctx context.Context, content, memType string) (Memory, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return Memory{}, errors.New("memvra: empty memory content")
+	}
+	mt := memory.MemoryType(memType)
+	if memType == "" {
+		mt = memory.ClassifyMemoryType(content)
+	} else if !memory.ValidMemoryType(mt) {
+		return Memory{}, fmt.Errorf("memvra: invalid memory type %q (valid: %s)", memType, memory.TypeNames())
+	}
+
+	m, err := c.orchestrator().Remember(ctx, content, mt, memory.SourceUser)
+	if err != nil {
+		return Memory{}, fmt.Errorf("memvra: %w", err)
+	}
+	export.AutoExport(c.root, c.store)
+	return toMemory(m), nil
+}
+
+// Search finds the code chunks and memories most relevant to query.
+func (c *Client) Search(ctx context.Context, query string, opts SearchOptions) (SearchResult, error) {
+	topK := opts.TopK
+	if topK <= 0 {
+		topK = 10
+	}
+	retrieve := memory.RetrieveOptions{
+		TopKChunks:          topK,
+		TopKMemories:        topK,
+		SimilarityThreshold: opts.Threshold,
+		Sources:             opts.Sources,
+	}
+	if opts.Threshold == 0 {
+		retrieve.SimilarityThreshold = c.gcfg.Context.SimilarityThreshold
+		retrieve.ChunkThreshold = c.gcfg.Context.ChunkThreshold
+		retrieve.MemoryThreshold = c.gcfg.Context.MemoryThreshold
+	}
+
+	result, err := c.orchestrator().Retrieve(ctx, query, retrieve)
+	if err != nil {
+		return SearchResult{}, fmt.Errorf("memvra: search: %w", err)
+	}
+
+	var out SearchResult
+	for _, m := range result.Memories {
+		out.Memories = append(out.Memories, toMemory(m))
+	}
+	fileIDs := make([]string, len(result.Chunks))
+	for i, ch := range result.Chunks {
+		fileIDs[i] = ch.FileID
+	}
+	files, _ := c.store.GetFilesByIDs(fileIDs)
+	for _, ch := range result.Chunks {
+		path := ch.FileID
+		if f, ok := files[ch.FileID]; ok {
+			path = f.Path
+		}
+		out.Code = append(out.Code, CodeMatch{Path: path, StartLine: ch.StartLine, EndLine: ch.EndLine, Content: ch.Content})
+	}
+	return out, nil
+}
+
+// BuildContext assembles the same prompt `memvra context` produces for
+// question: pinned conventions and constraints in the system prompt, then
+// decisions, recent sessions, and retrieved code within the token budget.
+func (c *Client) BuildContext(ctx context.Context, question string, opts ContextOptions) (Context, error) {
+	build := ctxpkg.ConfiguredOptions(c.root, c.gcfg, c.pcfg)
+	build.Question = question
+	build.ExtraFiles = opts.Files
+	if opts.Model != "" {
+		build.Model = opts.Model
+	}
+	if opts.MaxTokens > 0 {
+		build.MaxTokens = opts.MaxTokens
+	}
+
+	builder := ctxpkg.NewBuilder(c.store, c.orchestrator(), ctxpkg.NewFormatter(), ctxpkg.NewTokenizerForModel(build.Model))
+	if path, err := config.GlobalDBPath(); err == nil {
+		if global, database, err := memory.OpenGlobalStore(path, false); err == nil && global != nil {
+			defer func() { _ = database.Close() }()
+			builder.SetGlobalStore(global)
+		}
+	}
+	built, err := builder.Build(ctx, build)
+	if err != nil {
+		return Context{}, fmt.Errorf("memvra: build context: %w", err)
+	}
+	client := build.Model
+	if client == "" {
+		client = auditClient
+	}
+	if err := ctxpkg.RecordAccess(c.store, c.gcfg, built, client, ""); err != nil {
+		return Context{}, fmt.Errorf("memvra: audit log: %w", err)
+	}
+	return Context{SystemPrompt: built.SystemPrompt, Text: built.ContextText, TokensUsed: built.TokensUsed}, nil
+}
+
+// SaveProgress records a work session so the next tool or person can pick up
+// where it left off, and returns the session ID.
+func (c *Client) SaveProgress(_ context.Context, p Progress) (string, error) {
+	if p.Model == "" {
+		p.Model = c.pcfg.DefaultModel
+	}
+	if p.Task == "" || p.Summary == "" || p.Model == "" {
+		return "", errors.New("memvra: progress needs a task, summary, and model")
+	}
+	status := memory.SessionStatus(p.Status)
+	if p.Status == "" {
+		status = memory.SessionCompleted
+	} else if !memory.ValidSessionStatus(status) {
+		return "", fmt.Errorf("memvra: invalid status %q (valid: completed, in_progress, blocked)", p.Status)
+	}
+
+	summary := p.Summary
+	if len(p.FilesTouched) > 0 {
+		summary += "\n\nFiles touched: " + strings.Join(p.FilesTouched, ", ")
+	}
+	var nextSteps []string
+	for _, step := range p.NextSteps {
+		if step = strings.TrimSpace(step); step != "" {
+			nextSteps = append(nextSteps, step)
+		}
+	}
+
+	id, err := c.store.InsertSessionReturningID(memory.Session{
+		Question:        p.Task,
+		ResponseSummary: summary,
+		ModelUsed:       p.Model,
+		Status:          status,
+		NextSteps:       nextSteps,
+		Tags:            memory.NormalizeTags(p.Tags),
+	})
+	if err != nil {
+		return "", fmt.Errorf("memvra: save progress: %w", err)
+	}
+	_, _ = c.store.PruneSessionsByPolicy(memory.RetentionPolicy(c.gcfg.Retention))
+	export.AutoExport(c.root, c.store)
+	return id, nil
+}
+
+// orchestrator returns an orchestrator over the client's store and embedder.
+func (c *Client) orchestrator() *memory.Orchestrator {
+	return ctxpkg.ConfiguredOrchestrator(c.store, c.vectors, c.embedder, c.gcfg, c.pcfg)
+}
+
+// cached wraps e in the project's persistent embedding cache. It returns a
+// nil interface for a nil e so the orchestrator sees no embedder at all.
+func (c *Client) cached(e adapter.Embedder) adapter.Embedder {
+	if e == nil {
+		return nil
+	}
+	return memory.NewCachedEmbedder(e, c.store, c.gcfg.EmbeddingModelKey())
+}
+
+// buildEmbedder creates the configured embedder, wrapped with retries and a
+// circuit breaker. It returns nil when no provider can be built.
+func buildEmbedder(gcfg config.GlobalConfig) adapter.Embedder {
+	name, model := gcfg.EmbeddingSettings()
+	var apiKey string
+	switch name {
+	case adapter.ProviderOpenAI:
+		apiKey = gcfg.Keys.OpenAI
+	case adapter.ProviderGemini:
+		apiKey = gcfg.Keys.Gemini
+	case adapter.ProviderCohere:
+		apiKey = gcfg.Keys.Cohere
+	}
+	emb, err := adapter.NewEmbedder(name, adapter.EmbedderOptions{
+		Model:     model,
+		APIKey:    apiKey,
+		Host:      gcfg.Ollama.Host,
+		RateLimit: gcfg.Embedding.RateLimit,
+	})
+	if err != nil {
+		return nil
+	}
+	return adapter.NewRetryingEmbedder(emb, adapter.RetryOptions{})
+}
+
+func toMemory(m memory.Memory) Memory {
+	return Memory{
+		ID:         m.ID,
+		Content:    m.Content,
+		Type:       string(m.MemoryType),
+		Source:     m.Source,
+		Importance: m.Importance,
+		CreatedAt:  m.CreatedAt,
+	}
+}
@@ -0,0 +1,180 @@
+package memvra
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/memvra/memvra/internal/config"
+	"github.com/memvra/memvra/internal/db"
+	"github.com/memvra/memvra/internal/memory"
+)
+
+// constEmbedder returns the same 768-dim vector for every text.
+type constEmbedder struct{ calls int }
+
+func (e *constEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	e.calls++
+	out := make([][]float32, len(texts))
+	for i := range texts {
+		v := make([]float32, 768)
+		v[0] = 1
+		out[i] = v
+	}
+	return out, nil
+}
+
+// openTestClient initialises a project in a temp dir and opens it.
+func openTestClient(t *testing.T) *Client {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".memvra"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	database, err := db.Open(config.ProjectDBPath(root))
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	database.Close()
+	config.SaveProject(root, config.ProjectConfig{Project: config.ProjectMeta{Name: "testproject"}})
+
+	c, err := Open(root)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestOpen_NotInitialized(t *testing.T) {
+	if _, err := Open(t.TempDir()); !errors.Is(err, ErrNotInitialized) {
+		t.Errorf("expected ErrNotInitialized, got %v", err)
+	}
+}
+
+func TestRememberAndSearch(t *testing.T) {
+	c := openTestClient(t)
+	emb := &constEmbedder{}
+	c.SetEmbedder(emb)
+	ctx := context.Background()
+
+	m, err := c.Remember(ctx, "Use PostgreSQL for persistence", "decision")
+	if err != nil {
+		t.Fatalf("Remember: %v", err)
+	}
+	if m.ID == "" || m.Type != "decision" || m.Source != "user" {
+		t.Errorf("unexpected memory %+v", m)
+	}
+	if _, err := c.Remember(ctx, "anything", "bogus"); err == nil {
+		t.Error("expected an error for an invalid type")
+	}
+
+	got, err := c.Search(ctx, "which database?", SearchOptions{})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(got.Memories) != 1 || got.Memories[0].ID != m.ID {
+		t.Errorf("expected the remembered memory, got %+v", got.Memories)
+	}
+	if emb.calls == 0 {
+		t.Error("expected the supplied embedder to be used")
+	}
+}
+
+func TestRemember_ClassifiesEmptyType(t *testing.T) {
+	c := openTestClient(t)
+	c.SetEmbedder(nil)
+
+	m, err := c.Remember(context.Background(), "TODO: add rate limiting", "")
+	if err != nil {
+		t.Fatalf("Remember: %v", err)
+	}
+	if m.Type != "todo" {
+		t.Errorf("expected todo, got %q", m.Type)
+	}
+}
+
+func TestRemember_ImportanceMatchesConfigDefaults(t *testing.T) {
+	c := openTestClient(t)
+	c.SetEmbedder(nil)
+
+	// The project config sets no [importance], so every type takes the
+	// shared default table, as the CLI and MCP server do.
+	for name, want := range config.DefaultImportance() {
+		m, err := c.Remember(context.Background(), "remember this "+name, name)
+		if err != nil {
+			t.Fatalf("Remember %s: %v", name, err)
+		}
+		if m.Importance != want {
+			t.Errorf("%s importance = %v, want %v", name, m.Importance, want)
+		}
+	}
+}
+
+func TestSaveProgress(t *testing.T) {
+	c := openTestClient(t)
+	ctx := context.Background()
+
+	if _, err := c.SaveProgress(ctx, Progress{Task: "t", Summary: "s", Model: "m", Status: "paused"}); err == nil {
+		t.Error("expected an error for an invalid status")
+	}
+	if _, err := c.SaveProgress(ctx, Progress{Task: "t"}); err == nil {
+		t.Error("expected an error for missing fields")
+	}
+
+	id, err := c.SaveProgress(ctx, Progress{
+		Task:         "auth middleware",
+		Summary:      "added JWT checks",
+		Model:        "claude",
+		Status:       StatusInProgress,
+		NextSteps:    []string{"write tests", " "},
+		FilesTouched: []string{"auth.go"},
+	})
+	if err != nil {
+		t.Fatalf("SaveProgress: %v", err)
+	}
+
+	sessions, err := c.store.GetLastNSessions(1)
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("GetLastNSessions: %v (%d sessions)", err, len(sessions))
+	}
+	sess := sessions[0]
+	if sess.ID != id || string(sess.Status) != StatusInProgress {
+		t.Errorf("unexpected session %+v", sess)
+	}
+	if !strings.Contains(sess.ResponseSummary, "Files touched: auth.go") {
+		t.Errorf("expected touched files in summary, got %q", sess.ResponseSummary)
+	}
+	if len(sess.NextSteps) != 1 || sess.NextSteps[0] != "write tests" {
+		t.Errorf("expected blank next steps dropped, got %v", sess.NextSteps)
+	}
+}
+
+func TestBuildContext_RecordsAccess(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	gcfg := config.DefaultGlobal()
+	gcfg.Audit.Enabled = true
+	if err := config.SaveGlobal(gcfg); err != nil {
+		t.Fatal(err)
+	}
+	c := openTestClient(t)
+	c.SetEmbedder(nil)
+	m, err := c.Remember(context.Background(), "Use PostgreSQL for persistence", "decision")
+	if err != nil {
+		t.Fatalf("Remember: %v", err)
+	}
+
+	if _, err := c.BuildContext(context.Background(), "which database?", ContextOptions{Model: "claude"}); err != nil {
+		t.Fatalf("BuildContext: %v", err)
+	}
+	entries, err := c.store.GetAccessLog(memory.AccessFilter{ItemID: m.ID})
+	if err != nil {
+		t.Fatalf("GetAccessLog: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Model != "claude" {
+		t.Errorf("expected one access entry for claude, got %+v", entries)
+	}
+}
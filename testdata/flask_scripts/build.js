@@ -0,0 +1,2 @@
+// Bundles static assets for the Flask app.
+console.log("build");
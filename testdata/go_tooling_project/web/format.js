@@ -0,0 +1,2 @@
+// Formats the embedded templates.
+module.exports = {};